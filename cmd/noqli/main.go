@@ -1,325 +1,516 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/bogwi/noqli/pkg"
+	"github.com/bogwi/noqli/pkg/remote"
+	"github.com/bogwi/noqli/pkg/repl"
+	"github.com/bogwi/noqli/pkg/server"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
-	"github.com/peterh/liner"
+	"golang.org/x/term"
 
 	"flag"
-	"log"
 )
 
-var debug = flag.Bool("debug", false, "enable debug mode")
+var debug = flag.Bool("debug", false, "write generated SQL, parameters, timings and row counts to the debug log, as SET debug on does")
+var timeout = flag.Duration("timeout", 0, "cancel commands running longer than this duration (e.g. 30s)")
+var remoteURL = flag.String("remote", "", "send commands to a noqli server (e.g. https://host:8080, with --token) instead of connecting to MySQL")
+var serve = flag.String("serve", "", "serve queries over HTTP and WebSocket on this address (e.g. :8080) instead of starting the shell")
+var token = flag.String("token", "", "with --serve, the bearer token remote clients must send; with --remote, the token to send (default server_token from the config)")
+var tlsCert = flag.String("tls-cert", "", "with --serve, serve HTTPS using this certificate file")
+var tlsKey = flag.String("tls-key", "", "with --serve, the private key file of --tls-cert")
+var execute = flag.String("e", "", "run these commands, separated by ';', and exit instead of starting the shell")
+var profile = flag.String("profile", "", "connect with the settings of this [profile.name] config section instead of .env")
+var version = flag.Bool("version", false, "print the version, git commit, build date and Go version and exit")
+var checkUpdate = flag.Bool("check-update", false, "with --version, also check GitHub for a newer release")
+var ping = flag.Bool("ping", false, "check the connection with SELECT 1, print the latency and exit with status 0 when healthy")
 
 func main() {
 	flag.Parse()
-	if *debug {
-		log.SetOutput(os.Stdout)
-	} else {
-		f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
-		if err != nil {
-			log.Fatalf("failed to open os.DevNull: %v", err)
-		}
-		defer f.Close()
-		log.SetOutput(f)
+	if *version {
+		os.Exit(printVersion())
 	}
-
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("Error loading .env file:", err)
-		return
+	if flag.Arg(0) == "doctor" {
+		os.Exit(runDoctor())
+	}
+	if flag.Arg(0) == "init" {
+		os.Exit(runInit())
+	}
+	if flag.Arg(0) == "keychain" {
+		os.Exit(storePassword(flag.Arg(1)))
 	}
 
-	// Connect to database
-	connStr := fmt.Sprintf("%s:%s@tcp(%s)/%s",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_NAME"),
-	)
-
-	db, err := sql.Open("mysql", connStr)
+	// Load user config
+	config, err := pkg.LoadConfig(pkg.DefaultConfigPath())
 	if err != nil {
-		fmt.Println("Error connecting to database:", err)
-		return
+		fmt.Println("Warning: Could not load config:", err)
+		config = make(pkg.Config)
 	}
-	defer db.Close()
+	pkg.JSONKeyOrder = pkg.JSONKeyOrderFromConfig(config)
 
-	// Test connection
-	if err := db.Ping(); err != nil {
-		fmt.Println("Error pinging database:", err)
-		return
+	var session *pkg.Session
+	var intercept func(ctx context.Context, s *pkg.Session, line string) (bool, error)
+
+	if *token == "" {
+		*token = config.Get("server_token")
 	}
-	fmt.Println("Connected to MySQL")
 
-	// Set initial database from env
-	pkg.CurrentDB = os.Getenv("DB_NAME")
+	if *remoteURL != "" {
+		// Forward commands to a noqli server instead of connecting to MySQL
+		session = pkg.NewSession(nil)
+		client := remote.NewClient(*remoteURL)
+		client.Token = *token
+		intercept = client.Intercept
+		fmt.Printf("Using noqli server at %s\n", *remoteURL)
+	} else {
+		conn, err := connection(config)
+		if err != nil {
+			fmt.Println(err)
+			if *ping {
+				os.Exit(2)
+			}
+			return
+		}
+		db, err := connect(config, conn)
+		if err != nil {
+			fmt.Println(err)
+			if *ping {
+				os.Exit(1)
+			}
+			return
+		}
+
+		// Ping mode checks the connection and exits
+		if *ping {
+			code := pingServer(db, conn)
+			db.Close()
+			os.Exit(code)
+		}
+		defer db.Close()
+		fmt.Println("Connected to MySQL")
+
+		// Server mode replaces the interactive shell
+		if *serve != "" {
+			if (*tlsCert == "") != (*tlsKey == "") {
+				fmt.Println("--tls-cert and --tls-key must be given together")
+				os.Exit(1)
+			}
+			srv := server.New(db, conn.Database)
+			srv.Config = config
+			srv.Environment = conn.Environment
+			srv.Token = *token
+			if srv.Token == "" {
+				fmt.Println("No --token given: remote clients can't run commands")
+			}
+			var err error
+			if *tlsCert != "" {
+				fmt.Printf("Serving HTTPS on %s\n", *serve)
+				err = srv.ListenAndServeTLS(*serve, *tlsCert, *tlsKey)
+			} else {
+				fmt.Printf("Serving on %s\n", *serve)
+				err = srv.ListenAndServe(*serve)
+			}
+			if err != nil {
+				fmt.Println("Server error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// Create the session, starting in the database from env
+		session = pkg.NewSession(db)
+		session.CurrentDB = conn.Database
+		session.BinlogReader = pkg.MySQLBinlogReader(conn.Host, conn.User, conn.Password)
+		session.Environment = conn.Environment
+		if replicas := connectReplicas(config, conn); replicas != nil {
+			session.Replicas = replicas
+			defer func() {
+				for _, db := range replicas.DBs {
+					db.(*sql.DB).Close()
+				}
+			}()
+		}
+		if conn.Environment == pkg.EnvironmentProduction {
+			fmt.Println("Production connection: read-only, UNLOCK to allow writes for 5 minutes")
+		}
+	}
 
 	// Initialize command history
 	history := pkg.NewCommandHistory(100) // Keep 100 commands per namespace
 	history.LoadHistory()
-	history.UpdateNamespace(pkg.CurrentDB, pkg.CurrentTable)
+	history.UpdateNamespace(session.CurrentDB, session.CurrentTable)
 	defer history.SaveHistory() // Save history on exit
 
-	// Start CLI with liner for enhanced input
-	fmt.Println("NoQLi CLI. Type EXIT to quit.")
-
-	for {
-		// Setup liner for this prompt
-		line := history.SetupLiner()
-
-		// Using a closure to properly handle defer
-		func() {
-			defer line.Close()
-
-			// Display prompt based on current db/table selection
-			prompt := pkg.DisplayPrompt()
-
-			// Read input with line editing support
-			input, err := line.Prompt(prompt)
-			if err != nil {
-				if err == io.EOF {
-					fmt.Println("EOF")
-					os.Exit(0)
-				} else if err == liner.ErrPromptAborted {
-					fmt.Println("Aborted")
-					return
-				} else {
-					fmt.Println("Error reading input:", err)
-					os.Exit(1)
-				}
-			}
+	// Load saved command templates
+	session.TemplatesFile = pkg.DefaultTemplatesPath()
+	if templates, err := pkg.LoadTemplates(session.TemplatesFile); err != nil {
+		fmt.Println("Warning: Could not load templates:", err)
+	} else {
+		session.Templates = templates
+	}
 
-			// Process the command
-			trimmedInput := strings.TrimSpace(input)
-			if trimmedInput == "" {
-				return
-			}
+	session.SnapshotDir = pkg.DefaultSnapshotDir()
 
-			// Check for exit command
-			if strings.ToUpper(trimmedInput) == "EXIT" {
-				os.Exit(0)
-			}
+	// Register key shortcuts
+	session.Config = config
+	history.SetBindings(config.Section("bind"))
 
-			// Add to history if it's a valid command
-			history.AddHistory(trimmedInput)
+	// Start with the debug log on
+	if *debug {
+		if err := session.SetDebug(true); err != nil {
+			fmt.Println("Warning: Could not open the debug log:", err)
+		} else {
+			defer session.SetDebug(false)
+		}
+	}
 
-			// Process command
-			if err := handleCommand(db, trimmedInput, history); err != nil {
-				fmt.Println("Error:", err)
-			}
-		}()
+	// Notify a webhook about long-running commands
+	if hook, err := pkg.NotifyHookFromConfig(config); err != nil {
+		fmt.Println("Warning:", err)
+	} else if hook != nil {
+		session.AddAfterHook(hook)
 	}
-}
 
-func handleCommand(db *sql.DB, line string, history *pkg.CommandHistory) error {
-	trimmed := strings.TrimSpace(line)
+	// Log commands slower than slow_query_time
+	if hook, err := pkg.SlowLogHookFromConfig(session.Config); err != nil {
+		fmt.Println("Warning:", err)
+	} else if hook != nil {
+		session.AddAfterHook(hook)
+	}
 
-	// Check for USE command first
-	useCommandRegex := pkg.GetUseCommandRegex()
-	useMatches := useCommandRegex.FindStringSubmatch(trimmed)
+	// Suggest indexes for filters that scan whole tables
+	if hook, err := pkg.IndexAdvisorHookFromConfig(session.Config); err != nil {
+		fmt.Println("Warning:", err)
+	} else if hook != nil {
+		session.AddAfterHook(hook)
+	}
 
-	if useMatches != nil {
-		// Handle USE command
-		err := handleUse(db, useMatches[1])
-		if err == nil {
-			// Update history namespace when DB/table changes
-			history.UpdateNamespace(pkg.CurrentDB, pkg.CurrentTable)
+	// Run the commands given with -e instead of the shell
+	if *execute != "" {
+		opts := &repl.Options{Intercept: intercept, CommandContext: commandContext}
+		if err := repl.RunLine(context.Background(), session, *execute, opts); err != nil && !errors.Is(err, repl.ErrExit) {
+			repl.PrintError(session, err)
+			os.Exit(1)
 		}
-		return err
+		return
 	}
 
-	// Handle other commands
-	re := pkg.GetCommandRegex()
-	matches := re.FindStringSubmatch(trimmed)
+	// Start CLI with liner for enhanced input
+	fmt.Println("NoQLi CLI. Type EXIT to quit.")
 
-	if matches == nil {
-		return fmt.Errorf("invalid command. Use CREATE, GET, UPDATE, DELETE, USE, or EXIT")
+	err = repl.Run(context.Background(), session, repl.IO{In: os.Stdin, Out: os.Stdout}, &repl.Options{
+		Reader:         repl.NewLinerReader(history),
+		History:        history,
+		Intercept:      intercept,
+		CommandContext: commandContext,
+	})
+	if err != nil {
+		fmt.Println(err)
+		history.SaveHistory()
+		os.Exit(1)
 	}
+}
 
-	originalCommand := matches[1]
-	command := strings.ToUpper(originalCommand)
-	args := matches[2]
-
-	// Check if command was originally uppercase (for formatting choice)
-	useJsonOutput := originalCommand != command
-
-	// Special handling for GET dbs and GET tables
-	if pkg.IsGetDbsCommand(command, args) {
-		return handleGetDatabases(db, line)
-	} else if pkg.IsGetTablesCommand(command, args) {
-		return handleGetTables(db, line)
+// connection returns the connection settings from .env, or from the config
+// profile named by --profile with .env filling in what it leaves out
+func connection(config pkg.Config) (pkg.Connection, error) {
+	envErr := godotenv.Load()
+	conn := pkg.ConnectionFromEnv()
+	if *profile != "" {
+		var err error
+		if conn, err = config.Profile(*profile, conn); err != nil {
+			return conn, err
+		}
+	} else if envErr != nil {
+		return conn, fmt.Errorf("Error loading .env file: %v", envErr)
 	}
+	password, err := pkg.ResolvePassword(conn.Password)
+	if err != nil {
+		return conn, err
+	}
+	conn.Password = password
+	return conn, nil
+}
 
-	// Handle regular CRUD operations
-	var argObj map[string]any
-	var err error
-
-	if args != "" {
-		argObj, err = pkg.ParseArg(args)
-		if err != nil {
-			return fmt.Errorf("could not parse argument object: %v", err)
-		}
+// connect opens the MySQL connection, reading dates in the configured
+// timezone and using the configured charset
+func connect(config pkg.Config, conn pkg.Connection) (*sql.DB, error) {
+	loc, err := config.Location()
+	if err != nil {
+		fmt.Println("Warning:", err)
+		loc = time.Local
 	}
 
-	// Ensure a table is selected before executing CRUD operations
-	if pkg.CurrentTable == "" && (command == "CREATE" || command == "GET" || command == "UPDATE" || command == "DELETE") {
-		return fmt.Errorf("no table selected. Use 'USE table_name' to select a table")
+	// Connect to database
+	connStr := fmt.Sprintf("%s:%s@tcp(%s)/%s?%s&%s",
+		conn.User,
+		conn.Password,
+		conn.Host,
+		conn.Database,
+		pkg.DSNTimeParams(loc),
+		pkg.DSNCharsetParams(config.Charset()),
+	)
+
+	db, err := sql.Open("mysql", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to database: %w", err)
 	}
 
-	switch command {
-	case "CREATE":
-		return pkg.HandleCreate(db, argObj, useJsonOutput)
-	case "GET":
-		return pkg.HandleGet(db, argObj, useJsonOutput)
-	case "UPDATE":
-		return pkg.HandleUpdate(db, argObj, useJsonOutput)
-	case "DELETE":
-		return pkg.HandleDelete(db, argObj, useJsonOutput)
-	default:
-		return fmt.Errorf("unknown command: %s", command)
+	// Test connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Error pinging database: %w", err)
 	}
+	return db, nil
 }
 
-// handleUse handles the USE command to select database or table
-func handleUse(db *sql.DB, name string) error {
-	// Check if name is a database
-	var exists int
-	err := db.QueryRow("SELECT 1 FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?", name).Scan(&exists)
-	if err == nil {
-		// It's a database, switch to it
-		_, err = db.Exec("USE " + name)
+// connectReplicas connects to the read replicas of conn with its user,
+// password and database, leaving out the ones that cannot be reached. It
+// returns nil when there are none.
+func connectReplicas(config pkg.Config, conn pkg.Connection) *pkg.Replicas {
+	var dbs []pkg.DBTX
+	var hosts []string
+	for _, host := range conn.Replicas {
+		replica := conn
+		replica.Host = host
+		db, err := connect(config, replica)
 		if err != nil {
-			return fmt.Errorf("failed to switch to database %s: %v", name, err)
+			fmt.Printf("Warning: Could not connect to replica %s: %v\n", host, err)
+			continue
 		}
-		pkg.CurrentDB = name
-		pkg.CurrentTable = "" // Reset table selection when changing database
-		fmt.Printf("Switched to database '%s'\n", name)
+		dbs = append(dbs, db)
+		hosts = append(hosts, host)
+	}
+	if len(dbs) == 0 {
 		return nil
 	}
+	fmt.Printf("Reading from %d replicas\n", len(dbs))
+	return pkg.NewReplicas(dbs, hosts, conn.MaxLag)
+}
 
-	// Not a database, check if it's a table in the current database
-	if pkg.CurrentDB == "" {
-		return fmt.Errorf("no database selected. Use 'USE database_name' first")
+// pingServer runs SELECT 1 for --ping and returns the exit status: 0 when
+// the server answered, 1 otherwise
+func pingServer(db *sql.DB, conn pkg.Connection) int {
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
 	}
-
-	err = db.QueryRow("SELECT 1 FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
-		pkg.CurrentDB, name).Scan(&exists)
-	if err == nil {
-		// It's a table, select it
-		pkg.CurrentTable = name
-		fmt.Printf("Using table '%s'\n", name)
-		return nil
-	} else if err == sql.ErrNoRows {
-		return fmt.Errorf("table '%s' does not exist in database '%s'", name, pkg.CurrentDB)
-	} else {
-		return err
+	health, err := pkg.NewSession(db).Health(ctx)
+	if err != nil {
+		fmt.Printf("FAIL %s: %v\n", conn.Host, err)
+		return 1
 	}
+	fmt.Printf("OK %s: MySQL %s answered in %.3f ms\n", conn.Host, health.Version, health.LatencyMS)
+	return 0
 }
 
-// handleGetDatabases shows all available databases
-func handleGetDatabases(db *sql.DB, line string) error {
-	rows, err := db.Query("SHOW DATABASES")
+// printVersion prints the build information for --version, checking for a
+// newer release with --check-update, and returns the exit status
+func printVersion() int {
+	build := pkg.CurrentBuild()
+	fmt.Println(build)
+	if !*checkUpdate {
+		return 0
+	}
+	latest, err := pkg.LatestRelease(context.Background())
 	if err != nil {
-		return err
+		fmt.Println("Could not check for updates:", err)
+		return 1
 	}
-	defer rows.Close()
+	fmt.Println(pkg.UpdateMessage(build, latest))
+	return 0
+}
 
-	// Check if the command was in uppercase (for formatting choice)
-	useJsonOutput := false
-	for _, r := range line {
-		if r == 'g' || r == 'G' {
-			useJsonOutput = (r == 'g')
-			break
-		}
+// runDoctor checks the .env and config files, the profile, the connection,
+// the privileges on the database and the history directory for
+// `noqli doctor`, printing a fix for each problem, and returns the exit
+// status: 1 when a check failed
+func runDoctor() int {
+	config, configCheck := pkg.CheckConfigFile(pkg.DefaultConfigPath())
+	checks := []pkg.Check{pkg.CheckEnvFile(".env"), configCheck}
+
+	godotenv.Load()
+	conn := pkg.ConnectionFromEnv()
+	if *profile != "" {
+		var check pkg.Check
+		conn, check = pkg.CheckProfile(config, *profile, conn)
+		checks = append(checks, check)
 	}
-
-	if useJsonOutput {
-		// Colorized JSON output
-		var databases []string
-		for rows.Next() {
-			var dbName string
-			if err := rows.Scan(&dbName); err != nil {
-				return err
-			}
-			databases = append(databases, dbName)
+	settings := pkg.CheckConnectionSettings(conn)
+	var passwordCheck pkg.Check
+	conn, passwordCheck = pkg.CheckPassword(conn)
+	checks = append(checks, settings, passwordCheck)
+	if settings.Status != pkg.CheckFail && passwordCheck.Status != pkg.CheckFail {
+		db, err := connect(config, conn)
+		checks = append(checks, pkg.CheckConnection(conn, err))
+		if err == nil {
+			checks = append(checks, pkg.NewSession(db).CheckPrivileges(context.Background(), conn.Database))
+			db.Close()
 		}
+	}
+	checks = append(checks, pkg.CheckWritableDir("history directory", pkg.DefaultHistoryDir()))
 
-		fmt.Printf("Databases: %s\n", pkg.ColorJSON(databases))
-	} else {
-		// MySQL-style tabular output
-		var databases []map[string]any
-		for rows.Next() {
-			var dbName string
-			if err := rows.Scan(&dbName); err != nil {
-				return err
-			}
-			databases = append(databases, map[string]any{"Database": dbName})
-		}
+	if pkg.PrintChecks(os.Stdout, checks) {
+		return 1
+	}
+	fmt.Println("No problems found")
+	return 0
+}
 
-		columns := []string{"Database"}
-		pkg.PrintTabularResults(columns, databases)
+// readPassword reads a password without echoing it when stdin is a
+// terminal, or a line of in otherwise
+func readPassword(in *bufio.Reader) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		input, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		return string(input), err
 	}
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
 
-	return nil
+// prompt asks question and returns the answer, or def when it is empty
+func prompt(in *bufio.Reader, question, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	line, _ := in.ReadString('\n')
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return def
 }
 
-// handleGetTables shows all tables in the current database
-func handleGetTables(db *sql.DB, line string) error {
-	if pkg.CurrentDB == "" {
-		return fmt.Errorf("no database selected. Use 'USE database_name' first")
+// runInit walks through the connection settings for `noqli init`, tests
+// the connection and saves it as a profile of the config and, if wanted,
+// as .env, and returns the exit status
+func runInit() int {
+	in := bufio.NewReader(os.Stdin)
+	godotenv.Load()
+	env := pkg.ConnectionFromEnv()
+	if env.Host == "" {
+		env.Host = "localhost:3306"
+	}
+	if env.User == "" {
+		env.User = "root"
 	}
 
-	rows, err := db.Query("SHOW TABLES")
+	fmt.Println("Setting up a MySQL connection. Press Enter to keep the value in brackets.")
+	conn := pkg.Connection{
+		Host: prompt(in, "Host", env.Host),
+		User: prompt(in, "User", env.User),
+	}
+	fmt.Print("Password: ")
+	password, err := readPassword(in)
 	if err != nil {
-		return err
+		fmt.Println(err)
+		return 1
 	}
-	defer rows.Close()
+	conn.Password = password
+	conn.Database = prompt(in, "Database", env.Database)
 
-	// Check if the command was in uppercase (for formatting choice)
-	useJsonOutput := false
-	for _, r := range line {
-		if r == 'g' || r == 'G' {
-			useJsonOutput = (r == 'g')
-			break
+	config, err := pkg.LoadConfig(pkg.DefaultConfigPath())
+	if err != nil {
+		fmt.Println("Warning: Could not load config:", err)
+		config = make(pkg.Config)
+	}
+	fmt.Printf("Connecting to %s... ", conn.Host)
+	if db, err := connect(config, conn); err != nil {
+		fmt.Println("failed")
+		fmt.Println(err)
+		if strings.ToLower(prompt(in, "Save the settings anyway? (y/N)", "")) != "y" {
+			return 1
+		}
+	} else {
+		health, err := pkg.NewSession(db).Health(context.Background())
+		db.Close()
+		if err == nil {
+			fmt.Printf("OK, MySQL %s\n", health.Version)
+		} else {
+			fmt.Println("OK")
 		}
 	}
 
-	if useJsonOutput {
-		// Colorized JSON output
-		var tables []string
-		for rows.Next() {
-			var tableName string
-			if err := rows.Scan(&tableName); err != nil {
-				return err
-			}
-			tables = append(tables, tableName)
+	name := prompt(in, "Profile name", "default")
+	if strings.ToLower(prompt(in, "Tag it as production, read-only until UNLOCK? (y/N)", "")) == "y" {
+		conn.Environment = pkg.EnvironmentProduction
+	}
+	saved := conn
+	if conn.Password != "" && strings.ToLower(prompt(in, "Keep the password in the OS keychain instead of the config? (y/N)", "")) == "y" {
+		if err := pkg.StoreKeychainPassword(name, conn.Password); err != nil {
+			fmt.Println("Could not store the password, keeping it in the config:", err)
+		} else {
+			saved.Password = "keychain:" + name
 		}
+	}
+	if err := pkg.AddProfile(pkg.DefaultConfigPath(), name, saved); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Printf("Saved profile %s to %s. Connect with noqli --profile %s\n", name, pkg.DefaultConfigPath(), name)
 
-		fmt.Printf("Tables in %s: %s\n", pkg.CurrentDB, pkg.ColorJSON(tables))
-	} else {
-		// MySQL-style tabular output
-		var tables []map[string]any
-		tableTitleColumn := fmt.Sprintf("Tables_in_%s", pkg.CurrentDB)
-
-		for rows.Next() {
-			var tableName string
-			if err := rows.Scan(&tableName); err != nil {
-				return err
-			}
-			tables = append(tables, map[string]any{tableTitleColumn: tableName})
-		}
+	if strings.ToLower(prompt(in, "Also write the settings to .env here? (y/N)", "")) != "y" {
+		return 0
+	}
+	if _, err := os.Stat(".env"); err == nil && strings.ToLower(prompt(in, ".env exists. Overwrite it? (y/N)", "")) != "y" {
+		return 0
+	}
+	if err := pkg.WriteEnvFile(".env", saved); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Println("Saved .env")
+	return 0
+}
 
-		columns := []string{tableTitleColumn}
-		pkg.PrintTabularResults(columns, tables)
+// storePassword reads a password and stores it under name in the OS
+// keychain for `noqli keychain name`, and returns the exit status
+func storePassword(name string) int {
+	if name == "" {
+		fmt.Println("Usage: noqli keychain NAME, then set password = keychain:NAME in the profile")
+		return 2
+	}
+	fmt.Printf("Password for %s: ", name)
+	password, err := readPassword(bufio.NewReader(os.Stdin))
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if err := pkg.StoreKeychainPassword(name, password); err != nil {
+		fmt.Println("Could not store the password:", err)
+		return 1
 	}
+	fmt.Printf("Stored. Use password = keychain:%s in the profile\n", name)
+	return 0
+}
 
-	return nil
+// commandContext derives the context for one command: Ctrl+C cancels a
+// running query and --timeout bounds its duration
+func commandContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt)
+	if *timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
 }
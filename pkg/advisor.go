@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultAdviseRows is the number of scanned rows from which the index
+// advisor makes a suggestion when the config does not set advise_rows
+const DefaultAdviseRows = 10000
+
+// whereColumnRegex finds the filtered columns and their operators in a WHERE clause
+var whereColumnRegex = regexp.MustCompile("`((?:[^`]|``)+)` (=|IN|>=|<=|>|<) ")
+
+// IndexAdvisorHook returns an after hook that runs EXPLAIN on the statement of
+// every successful GET and UPDATE. When MySQL plans a full table scan over at
+// least minRows rows, it suggests an index on the filtered columns and offers
+// to create it.
+func IndexAdvisorHook(minRows int64) AfterHook {
+	return func(ctx context.Context, s *Session, info *CommandInfo) {
+		if info.Err != nil || (info.Command != "GET" && info.Command != "UPDATE") {
+			return
+		}
+		suggestion, err := adviseIndex(ctx, s, info, minRows)
+		if err != nil || suggestion == nil || s.checkWritable() != nil {
+			return // advice is best effort
+		}
+
+		fmt.Fprintf(s.Out, "Hint: this %s scanned the whole table (about %d rows). An index would help:\n", info.Command, suggestion.Rows)
+		fmt.Fprintf(s.Out, "  %s\n", suggestion.Statement)
+		fmt.Fprintln(s.Out, "Create it now? (y/N)")
+		if strings.ToLower(s.confirm()) != "y" {
+			return
+		}
+		if _, err := s.DB.ExecContext(ctx, suggestion.Statement); err != nil {
+			fmt.Fprintln(s.Out, "Error:", err)
+			return
+		}
+		fmt.Fprintf(s.Out, "Index '%s' created\n", suggestion.Name)
+	}
+}
+
+// IndexAdvisorHookFromConfig builds the index advisor from the advise_rows
+// setting. It returns nil when advise_rows is 0.
+func IndexAdvisorHookFromConfig(config Config) (AfterHook, error) {
+	minRows := int64(DefaultAdviseRows)
+	if value := config.Get("advise_rows"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid advise_rows %q: must be a non-negative integer", value)
+		}
+		minRows = n
+	}
+	if minRows == 0 {
+		return nil, nil
+	}
+	return IndexAdvisorHook(minRows), nil
+}
+
+// IndexSuggestion is an index that would avoid a full table scan
+type IndexSuggestion struct {
+	Name      string
+	Columns   []string
+	Statement string
+	// Rows MySQL estimated to scan without the index
+	Rows int64
+}
+
+// adviseIndex explains the main statement of a command and returns an index
+// suggestion when it scans at least minRows rows, or nil
+func adviseIndex(ctx context.Context, s *Session, info *CommandInfo, minRows int64) (*IndexSuggestion, error) {
+	// The first SELECT or UPDATE is the statement the user asked for; later
+	// ones only fetch results for display
+	var stmt string
+	var params []any
+	for i, q := range info.SQL {
+		if strings.HasPrefix(q, "SELECT ") || strings.HasPrefix(q, "UPDATE ") {
+			stmt, params = q, info.Params[i]
+			break
+		}
+	}
+	if stmt == "" {
+		return nil, nil
+	}
+
+	columns := filterColumns(stmt)
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	// Explain outside the command's SQL record
+	rows, err := s.DB.QueryContext(ctx, "EXPLAIN "+stmt, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resultColumns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var scanned int64
+	for rows.Next() {
+		plan, err := scanRecord(rows, resultColumns)
+		if err != nil {
+			return nil, err
+		}
+		if fmt.Sprint(plan["type"]) != "ALL" {
+			continue
+		}
+		if n, err := strconv.ParseInt(fmt.Sprint(plan["rows"]), 10, 64); err == nil && n > scanned {
+			scanned = n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if scanned < minRows {
+		return nil, nil
+	}
+
+	name := "idx_" + strings.Join(columns, "_")
+	if len(name) > 64 {
+		name = name[:64] // MySQL identifier limit
+	}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col)
+	}
+	return &IndexSuggestion{
+		Name:      name,
+		Columns:   columns,
+		Statement: fmt.Sprintf("CREATE INDEX %s ON %s (%s)", quoteIdent(name), s.CurrentTable, strings.Join(quoted, ", ")),
+		Rows:      scanned,
+	}, nil
+}
+
+// filterColumns returns the columns filtered in the WHERE clause of stmt,
+// equality and IN filters first and range filters last, as an index would
+// best serve them
+func filterColumns(stmt string) []string {
+	where := strings.Index(stmt, " WHERE ")
+	if where < 0 {
+		return nil
+	}
+	clause := stmt[where:]
+
+	var equality, ranges []string
+	seen := make(map[string]bool)
+	for _, m := range whereColumnRegex.FindAllStringSubmatch(clause, -1) {
+		col := strings.ReplaceAll(m[1], "``", "`")
+		if seen[col] {
+			continue
+		}
+		seen[col] = true
+		if m[2] == "=" || m[2] == "IN" {
+			equality = append(equality, col)
+		} else {
+			ranges = append(ranges, col)
+		}
+	}
+	return append(equality, ranges...)
+}
@@ -0,0 +1,171 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GetAnonymizeCommandRegex returns the regex for ANONYMIZE commands
+func GetAnonymizeCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(ANONYMIZE)(?:\s+(.*))?$`)
+}
+
+// DefaultAnonymizeBatch is the number of rows ANONYMIZE rewrites per UPDATE
+const DefaultAnonymizeBatch = 1000
+
+// Word lists the fake values are picked from
+var (
+	fakeFirstNames = []string{"Alex", "Blake", "Casey", "Dana", "Eli", "Frankie", "Gray", "Harper", "Indy", "Jordan"}
+	fakeLastNames  = []string{"Smith", "Jones", "Brown", "Taylor", "Wilson", "Davies", "Evans", "Thomas", "Roberts", "Walker"}
+	fakeStreets    = []string{"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Lake", "Hill", "Park", "River"}
+	fakeCities     = []string{"Springfield", "Riverton", "Fairview", "Greenville", "Bristol", "Clinton", "Madison", "Georgetown", "Salem", "Franklin"}
+	fakeCompanies  = []string{"Acme", "Globex", "Initech", "Umbrella", "Hooli", "Vandelay", "Stark", "Wayne", "Wonka", "Tyrell"}
+)
+
+// fakers returns the SQL expression computing each fake.kind value from
+// the id of the row, so the same row is always given the same value and
+// values derived from the id, such as emails, stay unique
+var fakers = map[string]func(id string) string{
+	"email": func(id string) string {
+		return fmt.Sprintf("CONCAT('user', %s, '@example.com')", id)
+	},
+	"name": func(id string) string {
+		return fmt.Sprintf("CONCAT(%s, ' ', %s)", pick(fakeFirstNames, id, 1), pick(fakeLastNames, id, 10))
+	},
+	"first_name": func(id string) string { return pick(fakeFirstNames, id, 1) },
+	"last_name":  func(id string) string { return pick(fakeLastNames, id, 10) },
+	"phone": func(id string) string {
+		return fmt.Sprintf("CONCAT('+1-555-', LPAD(MOD(%s, 10000000), 7, '0'))", id)
+	},
+	"address": func(id string) string {
+		return fmt.Sprintf("CONCAT(1 + MOD(%s, 999), ' ', %s, ' St')", id, pick(fakeStreets, id, 7))
+	},
+	"city":    func(id string) string { return pick(fakeCities, id, 3) },
+	"company": func(id string) string { return fmt.Sprintf("CONCAT(%s, ' Inc')", pick(fakeCompanies, id, 1)) },
+	"ip": func(id string) string {
+		return fmt.Sprintf("CONCAT('10.', MOD(%[1]s DIV 65536, 256), '.', MOD(%[1]s DIV 256, 256), '.', MOD(%[1]s, 256))", id)
+	},
+	"uuid": func(id string) string { return "UUID()" },
+	"text": func(id string) string { return quoteString("Lorem ipsum dolor sit amet") },
+}
+
+// pick returns the SQL choosing a word of list by the id divided by step
+func pick(list []string, id string, step int) string {
+	quoted := make([]string, len(list))
+	for i, word := range list {
+		quoted[i] = quoteString(word)
+	}
+	return fmt.Sprintf("ELT(1 + MOD(%s DIV %d, %d), %s)", id, step, len(list), strings.Join(quoted, ", "))
+}
+
+// anonymizeSet returns the assignment of column to value: a fake.kind value
+// is computed by MySQL, anything else is stored as given
+func anonymizeSet(column string, value any) (string, []any, error) {
+	if name, ok := value.(string); ok && strings.HasPrefix(name, "fake.") {
+		faker, ok := fakers[strings.TrimPrefix(name, "fake.")]
+		if !ok {
+			kinds := make([]string, 0, len(fakers))
+			for kind := range fakers {
+				kinds = append(kinds, "fake."+kind)
+			}
+			sort.Strings(kinds)
+			return "", nil, fmt.Errorf("unknown fake value %s. Use one of %s", name, strings.Join(kinds, ", "))
+		}
+		return fmt.Sprintf("%s = %s", quoteIdent(column), faker("`id`")), nil, nil
+	}
+	expr, params, err := sqlValue(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid value for %s: %w", column, err)
+	}
+	return fmt.Sprintf("%s = %s", quoteIdent(column), expr), params, nil
+}
+
+// handleAnonymize rewrites columns of the matching rows, or of all rows
+// after confirmation, with fake values, NULL or constants. Rows are updated
+// in batches of ids so large tables are not locked by a single UPDATE. As
+// with UPDATE, id, conditions, lists, ranges and comparisons filter the rows.
+func handleAnonymize(ctx context.Context, s *Session, args map[string]any) error {
+	if s.CurrentTable == "" {
+		return fmt.Errorf("%w. Use 'USE table_name' to select a table", ErrNoTableSelected)
+	}
+
+	batch := DefaultAnonymizeBatch
+	for _, key := range []string{"batch", "BATCH"} {
+		if v, ok := args[key]; ok {
+			n, ok := toInt(v)
+			if !ok || n <= 0 {
+				return fmt.Errorf("batch must be a positive integer")
+			}
+			batch = n
+			delete(args, key)
+		}
+	}
+
+	columns, err := getColumns(ctx, s)
+	if err != nil {
+		return err
+	}
+	if !containsString(columns, "id") {
+		return fmt.Errorf("ANONYMIZE needs an id column to update the rows in batches")
+	}
+
+	filters := make(map[string]any)
+	var fields []string
+	for k, v := range args {
+		if k == "id" || k == WhereKey || isArrayOrRange(v) {
+			filters[k] = v
+			continue
+		}
+		if !containsString(columns, k) {
+			return fmt.Errorf("unknown column %q", k)
+		}
+		fields = append(fields, k)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("ANONYMIZE requires the columns to rewrite, e.g. ANONYMIZE {email: fake.email, phone: null}")
+	}
+	sort.Strings(fields)
+
+	var set []string
+	var setArgs []any
+	for _, field := range fields {
+		assignment, params, err := anonymizeSet(field, args[field])
+		if err != nil {
+			return err
+		}
+		set = append(set, assignment)
+		setArgs = append(setArgs, params...)
+	}
+
+	if len(filters) == 0 {
+		fmt.Fprintln(s.Out, "Warning: No filter conditions specified. This will rewrite ALL records in the table.")
+		fmt.Fprintln(s.Out, "Do you want to continue? (y/N)")
+		if strings.ToLower(s.confirm()) != "y" {
+			return ErrConfirmationDeclined
+		}
+	}
+
+	throttle, err := s.throttle()
+	if err != nil {
+		return err
+	}
+	total, err := s.writeInBatches(ctx, filters, throttle.batchSize(batch), throttle, func(clause string, whereArgs []any) (sql.Result, error) {
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", s.CurrentTable, strings.Join(set, ", "), clause)
+		return s.exec(ctx, query, append(append([]any{}, setArgs...), whereArgs...)...)
+	})
+	if err != nil {
+		return err
+	}
+	s.recordRows(total)
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Anonymized %d record(s)\n", total)
+	} else {
+		fmt.Fprintf(s.Out, "Query OK, %d rows affected%s\n", total, s.timing())
+	}
+	return nil
+}
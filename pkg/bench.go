@@ -0,0 +1,300 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GetBenchCommandRegex returns the regex for BENCH runs [concurrency] command
+func GetBenchCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(BENCH)\s+(\d+)(?:\s+(\d+))?\s+(\S+.*)$`)
+}
+
+// benchCommands are the commands BENCH can run
+var benchCommands = []string{"CREATE", "GET", "UPDATE"}
+
+// fakeValueRegex matches the fake.kind placeholders of a benchmarked command
+var fakeValueRegex = regexp.MustCompile(`\bfake\.(\w+)\b`)
+
+// fakeValue returns the n-th fake value of kind, picked from the same word
+// lists as ANONYMIZE. Values derived from n, such as emails, are unique.
+func fakeValue(kind string, n int64) (string, bool) {
+	word := func(list []string, step int64) string { return list[n/step%int64(len(list))] }
+	switch kind {
+	case "email":
+		return fmt.Sprintf("user%d@example.com", n), true
+	case "name":
+		return word(fakeFirstNames, 1) + " " + word(fakeLastNames, 10), true
+	case "first_name":
+		return word(fakeFirstNames, 1), true
+	case "last_name":
+		return word(fakeLastNames, 10), true
+	case "phone":
+		return fmt.Sprintf("+1-555-%07d", n%10000000), true
+	case "address":
+		return fmt.Sprintf("%d %s St", 1+n%999, word(fakeStreets, 7)), true
+	case "city":
+		return word(fakeCities, 3), true
+	case "company":
+		return word(fakeCompanies, 1) + " Inc", true
+	case "ip":
+		return fmt.Sprintf("10.%d.%d.%d", n/65536%256, n/256%256, n%256), true
+	case "uuid":
+		return newUUID(), true
+	case "text":
+		return "Lorem ipsum dolor sit amet", true
+	}
+	return "", false
+}
+
+// expandFakeValues replaces each fake.kind placeholder of command with the
+// n-th fake value of its kind, quoted
+func expandFakeValues(command string, n int64) (string, error) {
+	var unknown string
+	expanded := fakeValueRegex.ReplaceAllStringFunc(command, func(placeholder string) string {
+		kind := strings.TrimPrefix(placeholder, "fake.")
+		value, ok := fakeValue(kind, n)
+		if !ok {
+			unknown = placeholder
+			return placeholder
+		}
+		return "'" + value + "'"
+	})
+	if unknown != "" {
+		kinds := make([]string, 0, len(fakers))
+		for kind := range fakers {
+			kinds = append(kinds, "fake."+kind)
+		}
+		sort.Strings(kinds)
+		return "", fmt.Errorf("unknown fake value %s. Use %s", unknown, strings.Join(kinds, ", "))
+	}
+	return expanded, nil
+}
+
+// BenchResult summarizes a benchmark: how many runs completed in how long
+// and the percentiles of their latencies, in milliseconds
+type BenchResult struct {
+	Command     string  `json:"command"`
+	Runs        int     `json:"runs"`
+	Concurrency int     `json:"concurrency"`
+	ElapsedMS   float64 `json:"elapsed_ms"`
+	PerSecond   float64 `json:"per_second"`
+	MinMS       float64 `json:"min_ms"`
+	MeanMS      float64 `json:"mean_ms"`
+	P50MS       float64 `json:"p50_ms"`
+	P90MS       float64 `json:"p90_ms"`
+	P95MS       float64 `json:"p95_ms"`
+	P99MS       float64 `json:"p99_ms"`
+	MaxMS       float64 `json:"max_ms"`
+}
+
+// milliseconds converts d to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// percentile returns the p-th percentile of sorted latencies, using the
+// nearest rank
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(float64(len(sorted))*p/100)) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// summarizeBench computes the result of runs that took latencies
+func summarizeBench(command string, concurrency int, elapsed time.Duration, latencies []time.Duration) BenchResult {
+	result := BenchResult{Command: command, Runs: len(latencies), Concurrency: concurrency, ElapsedMS: milliseconds(elapsed)}
+	if len(latencies) == 0 {
+		return result
+	}
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	if elapsed > 0 {
+		result.PerSecond = float64(len(sorted)) / elapsed.Seconds()
+	}
+	result.MinMS = milliseconds(sorted[0])
+	result.MaxMS = milliseconds(sorted[len(sorted)-1])
+	result.MeanMS = milliseconds(total / time.Duration(len(sorted)))
+	result.P50MS = milliseconds(percentile(sorted, 50))
+	result.P90MS = milliseconds(percentile(sorted, 90))
+	result.P95MS = milliseconds(percentile(sorted, 95))
+	result.P99MS = milliseconds(percentile(sorted, 99))
+	return result
+}
+
+// connPool is implemented by *sql.DB, which can hand each benchmark
+// worker a connection of its own
+type connPool interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
+
+// benchWorker returns a session for one benchmark worker, discarding its
+// output and declining confirmations. Workers of a pool run on their own
+// connection switched to the current database; release returns it.
+func (s *Session) benchWorker(ctx context.Context, concurrency int) (worker *Session, release func(), err error) {
+	db, release := s.DB, func() {}
+	if pool, ok := s.DB.(connPool); ok && concurrency > 1 {
+		conn, err := pool.Conn(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if s.CurrentDB != "" {
+			if _, err := conn.ExecContext(ctx, "USE "+quoteIdent(s.CurrentDB)); err != nil {
+				conn.Close()
+				return nil, nil, err
+			}
+		}
+		db, release = conn, func() { conn.Close() }
+	} else if concurrency > 1 {
+		return nil, nil, fmt.Errorf("BENCH runs one command at a time on a single connection or transaction")
+	}
+
+	worker = NewSession(db)
+	worker.Config = s.Config
+	worker.CurrentDB = s.CurrentDB
+	worker.CurrentTable = s.CurrentTable
+	worker.Out = io.Discard
+	worker.Confirm = func() string { return "n" }
+	for name, value := range s.Vars {
+		worker.Vars[name] = value
+	}
+	return worker, release, nil
+}
+
+// Bench runs command runs times, spread over concurrency workers, and
+// summarizes their latencies. fake.kind placeholders are replaced by
+// a new fake value on every run. It stops at the first failing run.
+func (s *Session) Bench(ctx context.Context, runs, concurrency int, command string) (BenchResult, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 || !containsString(benchCommands, strings.ToUpper(fields[0])) {
+		return BenchResult{}, fmt.Errorf("BENCH runs %s commands, e.g. BENCH 1000 CREATE {name: fake.name}", strings.Join(benchCommands, ", "))
+	}
+	if runs <= 0 {
+		return BenchResult{}, fmt.Errorf("BENCH needs a positive number of runs")
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if concurrency > runs {
+		concurrency = runs
+	}
+	if _, err := expandFakeValues(command, 0); err != nil {
+		return BenchResult{}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Fake values start at a random number so repeated benchmarks do not
+	// insert the same unique values
+	base := rand.Int63n(1_000_000_000)
+	next := make(chan int, runs)
+	for i := 0; i < runs; i++ {
+		next <- i
+	}
+	close(next)
+
+	var mu sync.Mutex
+	var firstErr error
+	latencies := make([]time.Duration, 0, runs)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	var wg sync.WaitGroup
+	started := time.Now()
+	for w := 0; w < concurrency; w++ {
+		worker, release, err := s.benchWorker(ctx, concurrency)
+		if err != nil {
+			fail(err)
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+			for i := range next {
+				if ctx.Err() != nil {
+					return
+				}
+				line, _ := expandFakeValues(command, base+int64(i))
+				start := time.Now()
+				if err := ExecuteCommand(ctx, worker, line); err != nil {
+					fail(fmt.Errorf("run %d: %w", i+1, err))
+					return
+				}
+				elapsed := time.Since(start)
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	result := summarizeBench(command, concurrency, time.Since(started), latencies)
+	return result, firstErr
+}
+
+// handleBench runs BENCH and reports the throughput and latency percentiles
+func handleBench(ctx context.Context, s *Session, runs, concurrency, command string) error {
+	n, err := strconv.Atoi(runs)
+	if err != nil {
+		return fmt.Errorf("invalid number of runs %q", runs)
+	}
+	workers := 1
+	if concurrency != "" {
+		if workers, err = strconv.Atoi(concurrency); err != nil || workers <= 0 {
+			return fmt.Errorf("invalid concurrency %q: must be a positive integer", concurrency)
+		}
+	}
+
+	result, err := s.Bench(ctx, n, workers, strings.TrimSpace(command))
+	if err != nil {
+		return err
+	}
+	s.recordRows(int64(result.Runs))
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Bench: %s\n", ColorJSON(result))
+		return nil
+	}
+	var results []map[string]any
+	add := func(field string, value any) {
+		results = append(results, map[string]any{"Field": field, "Value": value})
+	}
+	add("Command", result.Command)
+	add("Runs", result.Runs)
+	add("Concurrency", result.Concurrency)
+	add("Elapsed", fmt.Sprintf("%.3f sec", result.ElapsedMS/1000))
+	add("Throughput", fmt.Sprintf("%.1f/sec", result.PerSecond))
+	for _, latency := range []struct {
+		name string
+		ms   float64
+	}{{"Min", result.MinMS}, {"Mean", result.MeanMS}, {"p50", result.P50MS}, {"p90", result.P90MS},
+		{"p95", result.P95MS}, {"p99", result.P99MS}, {"Max", result.MaxMS}} {
+		add(latency.name, fmt.Sprintf("%.3f ms", latency.ms))
+	}
+	s.printTable([]string{"Field", "Value"}, results)
+	return nil
+}
@@ -0,0 +1,52 @@
+package pkg_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBench(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	for i := 0; i < 3; i++ {
+		expectColumns(mock)
+		mock.ExpectExec("INSERT INTO users \\(`email`\\) VALUES \\(\\?\\)").WithArgs(sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+	}
+
+	// Each run inserts a new fake email
+	err := pkg.ExecuteCommand(ctx, session, "BENCH 3 CREATE {email: fake.email}")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "| Runs")
+	assert.Contains(t, buf.String(), "| p99")
+}
+
+func TestBenchConcurrency(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	mock.MatchExpectationsInOrder(false)
+	for i := 0; i < 2; i++ {
+		mock.ExpectExec("USE `shop`").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	for i := 0; i < 4; i++ {
+		expectColumns(mock)
+		expectColumns(mock)
+		mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Ann", "ann@example.com"))
+	}
+
+	result, err := session.Bench(ctx, 4, 2, "GET 1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Runs)
+	assert.Equal(t, 2, result.Concurrency)
+	assert.LessOrEqual(t, result.MinMS, result.P50MS)
+	assert.LessOrEqual(t, result.P50MS, result.MaxMS)
+}
+
+func TestBenchErrors(t *testing.T) {
+	session, _, _ := mockSession(t)
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "BENCH 10 DELETE 1"), "BENCH runs CREATE, GET, UPDATE commands")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "BENCH 10 CREATE {name: fake.nickname}"), "unknown fake value fake.nickname")
+}
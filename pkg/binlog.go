@@ -0,0 +1,411 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetBinlogCommandRegex returns the regex for BINLOG commands
+func GetBinlogCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(BINLOG)\s+(\S+)(?:\s+(.*))?$`)
+}
+
+// DefaultBinlogTail is the number of row changes BINLOG tail shows
+const DefaultBinlogTail = 10
+
+// binlogFilesRead is the number of binary logs, newest first, BINLOG tail
+// reads looking for changes
+const binlogFilesRead = 3
+
+// BinlogReader returns the events of a binary log of the server, decoded as
+// mysqlbinlog --base64-output=DECODE-ROWS --verbose prints them
+type BinlogReader func(ctx context.Context, file string) (io.ReadCloser, error)
+
+// MySQLBinlogReader reads the binary logs of the server at host, given as
+// host or host:port, with the mysqlbinlog program. The password is passed
+// in the environment so it does not show in the process list.
+func MySQLBinlogReader(host, user, password string) BinlogReader {
+	return func(ctx context.Context, file string) (io.ReadCloser, error) {
+		args := []string{"--read-from-remote-server", "--base64-output=DECODE-ROWS", "--verbose", "--user=" + user}
+		if h, port, err := net.SplitHostPort(host); err == nil {
+			args = append(args, "--host="+h, "--port="+port)
+		} else if host != "" {
+			args = append(args, "--host="+host)
+		}
+		cmd := exec.CommandContext(ctx, "mysqlbinlog", append(args, file)...)
+		cmd.Env = append(os.Environ(), "MYSQL_PWD="+password)
+		stderr := &bytes.Buffer{}
+		cmd.Stderr = stderr
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("cannot run mysqlbinlog: %w", err)
+		}
+		return &commandOutput{ReadCloser: out, cmd: cmd, stderr: stderr}, nil
+	}
+}
+
+// commandOutput is the output of a running program; closing it waits for
+// the program and reports its failure
+type commandOutput struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+func (c *commandOutput) Close() error {
+	c.ReadCloser.Close()
+	if err := c.cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(c.stderr.String()); msg != "" {
+			return fmt.Errorf("mysqlbinlog: %s", msg)
+		}
+		return fmt.Errorf("mysqlbinlog: %w", err)
+	}
+	return nil
+}
+
+// BinlogChange is a row change read from the binary log. Before holds the
+// row an UPDATE or DELETE changed and After the row an INSERT or UPDATE
+// wrote. Statement is the SQL that made the change when the server logs it
+// (binlog_rows_query_log_events).
+type BinlogChange struct {
+	Time      string         `json:"time"`
+	File      string         `json:"file"`
+	Position  int64          `json:"position"`
+	Thread    int64          `json:"thread_id"`
+	Operation string         `json:"operation"`
+	Before    map[string]any `json:"before,omitempty"`
+	After     map[string]any `json:"after,omitempty"`
+	Statement string         `json:"statement,omitempty"`
+}
+
+var (
+	binlogHeaderRegex = regexp.MustCompile(`^#(\d{6})\s+(\d{1,2}:\d{2}:\d{2})\s+server id`)
+	binlogThreadRegex = regexp.MustCompile(`thread_id=(\d+)`)
+	binlogRowRegex    = regexp.MustCompile("^### (INSERT INTO|UPDATE|DELETE FROM) `([^`]*)`\\.`([^`]*)`$")
+	binlogValueRegex  = regexp.MustCompile(`^###\s+@(\d+)=(.*)$`)
+)
+
+// binlogParser turns the output of mysqlbinlog --verbose into the changes
+// made to one table
+type binlogParser struct {
+	db, table, file string
+	columns         []string
+	emit            func(BinlogChange)
+
+	position  int64
+	time      string
+	thread    int64
+	statement string
+	inQuery   bool
+	current   *BinlogChange
+	after     bool
+}
+
+func (p *binlogParser) parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		p.line(scanner.Text())
+	}
+	p.flush()
+	return scanner.Err()
+}
+
+func (p *binlogParser) line(line string) {
+	if strings.HasPrefix(line, "# at ") {
+		p.flush()
+		p.inQuery = false
+		p.position, _ = strconv.ParseInt(strings.TrimSpace(line[5:]), 10, 64)
+		return
+	}
+	if m := binlogHeaderRegex.FindStringSubmatch(line); m != nil {
+		p.flush()
+		clock := m[2]
+		if len(clock) == 7 {
+			clock = "0" + clock
+		}
+		if t, err := time.Parse("060102 15:04:05", m[1]+" "+clock); err == nil {
+			p.time = t.Format("2006-01-02 15:04:05")
+		}
+		// The Query event starting a transaction names the connection
+		if t := binlogThreadRegex.FindStringSubmatch(line); t != nil {
+			p.thread, _ = strconv.ParseInt(t[1], 10, 64)
+			p.statement = ""
+		}
+		if strings.Contains(line, "Rows_query") {
+			p.statement, p.inQuery = "", true
+		}
+		return
+	}
+	if p.inQuery && strings.HasPrefix(line, "# ") {
+		p.statement = strings.TrimSpace(p.statement + " " + strings.TrimPrefix(line, "# "))
+		return
+	}
+	if m := binlogRowRegex.FindStringSubmatch(line); m != nil {
+		p.flush()
+		if m[2] == p.db && m[3] == p.table {
+			p.current = &BinlogChange{
+				Time: p.time, File: p.file, Position: p.position, Thread: p.thread,
+				Operation: strings.Fields(m[1])[0], Statement: p.statement,
+			}
+			p.after = m[1] == "INSERT INTO"
+		}
+		return
+	}
+	if p.current == nil {
+		return
+	}
+	switch {
+	case line == "### WHERE":
+		p.after = false
+	case line == "### SET":
+		p.after = true
+	case binlogValueRegex.MatchString(line):
+		m := binlogValueRegex.FindStringSubmatch(line)
+		n, _ := strconv.Atoi(m[1])
+		column := "@" + m[1]
+		if n >= 1 && n <= len(p.columns) {
+			column = p.columns[n-1]
+		}
+		row := &p.current.Before
+		if p.after {
+			row = &p.current.After
+		}
+		if *row == nil {
+			*row = make(map[string]any)
+		}
+		(*row)[column] = parseBinlogValue(m[2])
+	default:
+		p.flush()
+	}
+}
+
+// flush emits the change being read
+func (p *binlogParser) flush() {
+	if p.current != nil {
+		p.emit(*p.current)
+		p.current = nil
+	}
+}
+
+// parseBinlogValue reads a column value as mysqlbinlog --verbose prints it,
+// e.g. 'text', 42, 1.5 or NULL, followed by an optional type comment
+func parseBinlogValue(str string) any {
+	str = strings.TrimSpace(str)
+	if strings.HasPrefix(str, "'") {
+		var b strings.Builder
+		for i := 1; i < len(str); i++ {
+			c := str[i]
+			if c == '\\' && i+1 < len(str) {
+				i++
+				switch str[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				default:
+					b.WriteByte(str[i])
+				}
+				continue
+			}
+			if c == '\'' {
+				break
+			}
+			b.WriteByte(c)
+		}
+		return b.String()
+	}
+	if i := strings.IndexByte(str, ' '); i >= 0 {
+		str = str[:i]
+	}
+	if str == "NULL" {
+		return nil
+	}
+	if n, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(str, 64); err == nil {
+		return f
+	}
+	return str
+}
+
+// BinlogTail returns the last limit changes made to table, oldest first,
+// keeping only those for which match returns true when it is not nil
+func (s *Session) BinlogTail(ctx context.Context, table string, limit int, match func(BinlogChange) bool) ([]BinlogChange, error) {
+	if s.BinlogReader == nil {
+		return nil, fmt.Errorf("BINLOG needs the mysqlbinlog program to read the binary log, which this session cannot run")
+	}
+	columns, err := tableColumns(ctx, s, table)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.showRecords(ctx, "SHOW BINARY LOGS")
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []BinlogChange{}
+	for i := len(logs) - 1; i >= 0 && i >= len(logs)-binlogFilesRead && len(changes) < limit; i-- {
+		file := recordField(logs[i], "Log_name")
+		var found []BinlogChange
+		err := s.readBinlog(ctx, table, file, columns, func(change BinlogChange) {
+			if match != nil && !match(change) {
+				return
+			}
+			found = append(found, change)
+			if len(found) > limit {
+				found = found[1:]
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		changes = append(found, changes...)
+	}
+	if len(changes) > limit {
+		changes = changes[len(changes)-limit:]
+	}
+	return changes, nil
+}
+
+// readBinlog passes the changes to table recorded in the binary log file to
+// emit, naming the values after columns
+func (s *Session) readBinlog(ctx context.Context, table, file string, columns []string, emit func(BinlogChange)) error {
+	r, err := s.BinlogReader(ctx, file)
+	if err != nil {
+		return err
+	}
+	p := &binlogParser{db: s.CurrentDB, table: table, file: file, columns: columns, emit: emit}
+	err = p.parse(r)
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// summarizeChange describes a change in one line: the values an INSERT
+// wrote or a DELETE removed, or the columns an UPDATE changed
+func summarizeChange(change BinlogChange, columns []string) string {
+	var parts []string
+	switch change.Operation {
+	case "UPDATE":
+		if id, ok := change.Before["id"]; ok {
+			parts = append(parts, "id="+formatBinlogValue(id))
+		}
+		for _, col := range columns {
+			before, after := change.Before[col], change.After[col]
+			if fmt.Sprint(before) != fmt.Sprint(after) {
+				parts = append(parts, fmt.Sprintf("%s: %s -> %s", col, formatBinlogValue(before), formatBinlogValue(after)))
+			}
+		}
+	default:
+		row := change.After
+		if change.Operation == "DELETE" {
+			row = change.Before
+		}
+		for _, col := range columns {
+			if v, ok := row[col]; ok {
+				parts = append(parts, col+"="+formatBinlogValue(v))
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatBinlogValue renders a value the way SQL would write it
+func formatBinlogValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteString(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// handleBinlog runs BINLOG tail, showing the last changes to the rows of the
+// current table, or of the row with the given id, recorded in the binary
+// log: when they happened, which connection made them and what changed
+func handleBinlog(ctx context.Context, s *Session, action string, args map[string]any) error {
+	if !strings.EqualFold(action, "tail") {
+		return fmt.Errorf("unknown BINLOG action %q. Use BINLOG tail", action)
+	}
+	if s.CurrentTable == "" {
+		return fmt.Errorf("%w. Use 'USE table_name' to select a table", ErrNoTableSelected)
+	}
+
+	limit := DefaultBinlogTail
+	var match func(BinlogChange) bool
+	for key, v := range args {
+		switch strings.ToLower(key) {
+		case "limit":
+			n, ok := toInt(v)
+			if !ok || n <= 0 {
+				return fmt.Errorf("limit must be a positive integer")
+			}
+			limit = n
+		case "id":
+			id := fmt.Sprint(v)
+			match = func(change BinlogChange) bool {
+				return (change.Before != nil && fmt.Sprint(change.Before["id"]) == id) ||
+					(change.After != nil && fmt.Sprint(change.After["id"]) == id)
+			}
+		default:
+			return fmt.Errorf("unknown BINLOG tail option %q. Use id and limit", key)
+		}
+	}
+
+	changes, err := s.BinlogTail(ctx, s.CurrentTable, limit, match)
+	if err != nil {
+		return err
+	}
+	s.recordRows(int64(len(changes)))
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Changes: %s\n", ColorJSON(changes))
+		return nil
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(s.Out, "No changes found in the binary log")
+		return nil
+	}
+
+	columns, err := getColumns(ctx, s)
+	if err != nil {
+		return err
+	}
+	resultColumns := []string{"Time", "Position", "Thread", "Operation", "Changes"}
+	results := make([]map[string]any, len(changes))
+	for i, change := range changes {
+		results[i] = map[string]any{
+			"Time":      change.Time,
+			"Position":  fmt.Sprintf("%s:%d", change.File, change.Position),
+			"Thread":    change.Thread,
+			"Operation": change.Operation,
+			"Changes":   summarizeChange(change, columns),
+			"Statement": change.Statement,
+		}
+		if change.Statement != "" && len(resultColumns) == 5 {
+			resultColumns = append(resultColumns, "Statement")
+		}
+	}
+	s.printTable(resultColumns, results)
+	return nil
+}
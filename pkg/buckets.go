@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// bucketFormats are the periods a by: 'column/period' argument groups a
+// date column into, as the DATE_FORMAT layout naming each period
+var bucketFormats = map[string]string{
+	"hour":  "%Y-%m-%d %H:00",
+	"day":   "%Y-%m-%d",
+	"week":  "%Y-%m-%d",
+	"month": "%Y-%m",
+}
+
+// bucketExpression compiles a by argument to the expression rows are
+// grouped by and the name of its result column. A plain column groups by
+// its values; column/period, e.g. created_at/day, by the hour, day, week
+// (starting on Monday) or month it falls in.
+func bucketExpression(by string) (expr, name string, err error) {
+	column, period, bucketed := strings.Cut(strings.TrimSpace(by), "/")
+	if column == "" {
+		return "", "", fmt.Errorf("by needs a column, e.g. {COUNT: '*', by: 'created_at/day'}")
+	}
+	if !bucketed {
+		return quoteIdent(column), column, nil
+	}
+	period = strings.ToLower(strings.TrimSpace(period))
+	layout, ok := bucketFormats[period]
+	if !ok {
+		return "", "", fmt.Errorf("unknown period %q in by: use %s/hour, /day, /week or /month", period, column)
+	}
+	date := quoteIdent(column)
+	if period == "week" {
+		date = fmt.Sprintf("DATE_SUB(%s, INTERVAL WEEKDAY(%s) DAY)", date, date)
+	}
+	return fmt.Sprintf("DATE_FORMAT(%s, '%s')", date, layout), period, nil
+}
+
+// groupedAggregate runs the aggregate of builder once per group of by, in
+// group order, and prints a row per group with the aggregate in resultName.
+// A chartColumn adds bars for its values to the tabular output.
+func groupedAggregate(ctx context.Context, s *Session, builder *QueryBuilder, by, aggregateExpr, resultName, title, chartColumn string) error {
+	expr, name, err := bucketExpression(by)
+	if err != nil {
+		return err
+	}
+	builder.SelectExpr(fmt.Sprintf("%s AS %s, %s AS %s", expr, quoteIdent(name), aggregateExpr, quoteIdent(resultName)))
+	builder.GroupBy(expr)
+	builder.orderBy = []string{expr}
+
+	query, values, err := builder.Select()
+	if err != nil {
+		return err
+	}
+	rows, err := s.query(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	display, err := s.newColumnFormatter(rows, nil)
+	if err != nil {
+		return err
+	}
+	var results []map[string]any
+	for rows.Next() {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
+			return err
+		}
+		display.format(entry)
+		results = append(results, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.recordRows(int64(len(results)))
+	s.rememberResult(columns, results)
+	if len(results) == 0 {
+		fmt.Fprintln(s.Out, "No records found")
+		return nil
+	}
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "%s: %s\n", title, ColorJSON(results))
+		return nil
+	}
+	if chartColumn != "" {
+		if columns, err = addBarChart(columns, results, chartColumn); err != nil {
+			return err
+		}
+	}
+	s.printTable(columns, results)
+	return nil
+}
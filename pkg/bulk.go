@@ -0,0 +1,210 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBatchSize is the number of rows per INSERT when the config does not
+// set batch_size
+const DefaultBatchSize = 500
+
+// BulkInsertResult reports the outcome of a BulkInsert
+type BulkInsertResult struct {
+	Rows     int64
+	Duration time.Duration
+}
+
+// RowsPerSecond returns the insert throughput
+func (r BulkInsertResult) RowsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Rows) / r.Duration.Seconds()
+}
+
+// BulkInsert inserts records into the current table using multi-row INSERTs
+// of batch_size rows, all inside one transaction so a failure leaves the table
+// untouched. Missing columns are created first; fields absent from a record
+// are inserted as NULL.
+func BulkInsert(ctx context.Context, s *Session, records []map[string]any) (BulkInsertResult, error) {
+	if s.CurrentTable == "" {
+		return BulkInsertResult{}, ErrNoTableSelected
+	}
+	if len(records) == 0 {
+		return BulkInsertResult{}, fmt.Errorf("CREATE requires fields to insert")
+	}
+
+	batchSize, err := s.batchSize()
+	if err != nil {
+		return BulkInsertResult{}, err
+	}
+	throttle, err := s.throttle()
+	if err != nil {
+		return BulkInsertResult{}, err
+	}
+	batchSize = throttle.batchSize(batchSize)
+
+	// Collect the columns of all records
+	fieldSet := make(map[string]bool)
+	for _, record := range records {
+		for k := range record {
+			fieldSet[k] = true
+		}
+	}
+	if len(fieldSet) == 0 {
+		return BulkInsertResult{}, fmt.Errorf("CREATE requires fields to insert")
+	}
+	columns := make([]string, 0, len(fieldSet))
+	for k := range fieldSet {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+
+	// Schema changes commit implicitly in MySQL, so run them before the transaction
+	if err := ensureColumns(ctx, s, records...); err != nil {
+		return BulkInsertResult{}, err
+	}
+	if err := warnNarrowCharset(ctx, s, records...); err != nil {
+		return BulkInsertResult{}, err
+	}
+
+	start := time.Now()
+	var inserted int64
+	err = s.inTransaction(ctx, func() error {
+		inserted = 0
+		for i := 0; i < len(records); i += batchSize {
+			batchStart := time.Now()
+			end := i + batchSize
+			if end > len(records) {
+				end = len(records)
+			}
+			query, values, err := buildInsert(s.CurrentTable, columns, records[i:end])
+			if err != nil {
+				return err
+			}
+			result, err := s.exec(ctx, query, values...)
+			if err != nil {
+				return err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			inserted += affected
+			if end < len(records) {
+				if err := throttle.wait(ctx, affected, time.Since(batchStart)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return BulkInsertResult{}, err
+	}
+
+	return BulkInsertResult{Rows: inserted, Duration: time.Since(start)}, nil
+}
+
+// HandleCreateMany handles a batch CREATE with a list of records
+func HandleCreateMany(ctx context.Context, s *Session, records []map[string]any) error {
+	result, err := BulkInsert(ctx, s, records)
+	if err != nil {
+		return err
+	}
+	s.recordRows(result.Rows)
+
+	if s.JSONOutput {
+		// Colorized JSON output
+		fmt.Fprintf(s.Out, "Created: %s\n", ColorJSON(map[string]any{
+			"rows":         result.Rows,
+			"rows_per_sec": int64(result.RowsPerSecond()),
+		}))
+	} else {
+		// MySQL-style tabular output
+		fmt.Fprintf(s.Out, "Query OK, %d rows affected (%.2f sec, %.0f rows/sec)\n",
+			result.Rows, result.Duration.Seconds(), result.RowsPerSecond())
+	}
+	return nil
+}
+
+// buildInsert builds a multi-row INSERT for records over columns
+func buildInsert(table string, columns []string, records []map[string]any) (string, []any, error) {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col)
+	}
+	rows := make([]string, len(records))
+	values := make([]any, 0, len(records)*len(columns))
+	for i, record := range records {
+		placeholders := make([]string, len(columns))
+		for j, col := range columns {
+			expr, params, err := sqlValue(record[col]) // NULL for missing fields
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid value for %s: %w", col, err)
+			}
+			placeholders[j] = expr
+			values = append(values, params...)
+		}
+		rows[i] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		table,
+		strings.Join(quoted, ", "),
+		strings.Join(rows, ", "),
+	)
+	return query, values, nil
+}
+
+// batchSize returns the configured number of rows per INSERT
+func (s *Session) batchSize() (int, error) {
+	value := s.Config.Get("batch_size")
+	if value == "" {
+		return DefaultBatchSize, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid batch_size %q: must be a positive integer", value)
+	}
+	return size, nil
+}
+
+// txBeginner is implemented by *sql.DB and *sql.Conn
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// inTransaction runs fn with the session's statements inside a transaction,
+// committing when fn succeeds and rolling back otherwise. A transaction
+// rolled back by a transient error is run again from the start, so fn must
+// reset its state. When the session handle cannot start one, e.g. because it
+// already is a *sql.Tx, fn runs as is.
+func (s *Session) inTransaction(ctx context.Context, fn func() error) error {
+	beginner, ok := s.DB.(txBeginner)
+	if !ok {
+		return fn()
+	}
+
+	return s.withRetry(ctx, true, func() error {
+		tx, err := beginner.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		db := s.DB
+		s.DB = tx
+		defer func() { s.DB = db }()
+
+		if err := fn(); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+}
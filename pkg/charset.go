@@ -0,0 +1,184 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultCharset is the connection character set when the config does not
+// set charset
+const DefaultCharset = "utf8mb4"
+
+// GetDescribeCommandRegex returns the regex for DESCRIBE commands
+func GetDescribeCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(DESCRIBE|DESC)(?:\s+(\S+))?$`)
+}
+
+// Charset returns the configured connection character set
+func (c Config) Charset() string {
+	if charset := c.Get("charset"); charset != "" {
+		return charset
+	}
+	return DefaultCharset
+}
+
+// DSNCharsetParams returns the DSN parameters selecting the connection
+// character set
+func DSNCharsetParams(charset string) string {
+	params := url.Values{}
+	params.Set("charset", charset)
+	return params.Encode()
+}
+
+// ColumnInfo describes a table column as shown by DESCRIBE
+type ColumnInfo struct {
+	Field     string  `json:"field"`
+	Type      string  `json:"type"`
+	Null      string  `json:"null"`
+	Key       string  `json:"key"`
+	Default   *string `json:"default"`
+	Charset   *string `json:"charset"`
+	Collation *string `json:"collation"`
+}
+
+// TableInfo describes a table and its columns
+type TableInfo struct {
+	Table     string       `json:"table"`
+	Charset   string       `json:"charset"`
+	Collation string       `json:"collation"`
+	Columns   []ColumnInfo `json:"columns"`
+}
+
+// DescribeTable returns the columns of table with their character sets and
+// collations
+func (s *Session) DescribeTable(ctx context.Context, table string) (TableInfo, error) {
+	info := TableInfo{Table: table}
+	err := s.queryRow(ctx, `SELECT t.TABLE_COLLATION, c.CHARACTER_SET_NAME
+		FROM INFORMATION_SCHEMA.TABLES t
+		JOIN INFORMATION_SCHEMA.COLLATIONS c ON c.COLLATION_NAME = t.TABLE_COLLATION
+		WHERE t.TABLE_SCHEMA = DATABASE() AND t.TABLE_NAME = ?`, table).Scan(&info.Collation, &info.Charset)
+	if err == sql.ErrNoRows {
+		return info, fmt.Errorf("table '%s' does not exist", table)
+	} else if err != nil {
+		return info, err
+	}
+
+	rows, err := s.query(ctx, `SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, COLUMN_DEFAULT,
+			CHARACTER_SET_NAME, COLLATION_NAME
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, table)
+	if err != nil {
+		return info, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col ColumnInfo
+		if err := rows.Scan(&col.Field, &col.Type, &col.Null, &col.Key, &col.Default, &col.Charset, &col.Collation); err != nil {
+			return info, err
+		}
+		info.Columns = append(info.Columns, col)
+	}
+	return info, rows.Err()
+}
+
+// handleDescribe shows the columns of table, or of the current table when
+// table is empty
+func handleDescribe(ctx context.Context, s *Session, table string) error {
+	if table == "" {
+		table = s.CurrentTable
+	}
+	if table == "" {
+		return fmt.Errorf("%w. Use 'USE table_name' or 'DESCRIBE table_name'", ErrNoTableSelected)
+	}
+
+	info, err := s.DescribeTable(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Table: %s\n", ColorJSON(info))
+		return nil
+	}
+
+	fmt.Fprintf(s.Out, "Table '%s': charset %s, collation %s\n", info.Table, info.Charset, info.Collation)
+	columns := []string{"Field", "Type", "Null", "Key", "Default", "Charset", "Collation"}
+	results := make([]map[string]any, len(info.Columns))
+	for i, col := range info.Columns {
+		results[i] = map[string]any{
+			"Field":     col.Field,
+			"Type":      col.Type,
+			"Null":      col.Null,
+			"Key":       col.Key,
+			"Default":   nullString(col.Default),
+			"Charset":   nullString(col.Charset),
+			"Collation": nullString(col.Collation),
+		}
+	}
+	s.printTable(columns, results)
+	return nil
+}
+
+// nullString returns the value of s, or NULL when it is nil
+func nullString(s *string) string {
+	if s == nil {
+		return "NULL"
+	}
+	return *s
+}
+
+// warnNarrowCharset warns about string values with 4-byte characters, such
+// as emoji, bound for utf8mb3 columns that cannot store them
+func warnNarrowCharset(ctx context.Context, s *Session, records ...map[string]any) error {
+	fields := make(map[string]bool)
+	for _, record := range records {
+		for k, v := range record {
+			if str, ok := v.(string); ok && hasFourByteChars(str) {
+				fields[k] = true
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	rows, err := s.query(ctx, `SELECT COLUMN_NAME, CHARACTER_SET_NAME FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND CHARACTER_SET_NAME IN ('utf8', 'utf8mb3')`, s.CurrentTable)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var narrow []string
+	for rows.Next() {
+		var column, charset string
+		if err := rows.Scan(&column, &charset); err != nil {
+			return err
+		}
+		if fields[column] {
+			narrow = append(narrow, column)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sort.Strings(narrow)
+	for _, column := range narrow {
+		fmt.Fprintf(s.Out, "Warning: column '%s' uses utf8mb3 and cannot store 4-byte characters such as emoji. Convert it to utf8mb4 to keep them.\n", column)
+	}
+	return nil
+}
+
+// hasFourByteChars reports whether str has characters outside the Basic
+// Multilingual Plane, which take four bytes in UTF-8
+func hasFourByteChars(str string) bool {
+	return strings.IndexFunc(str, func(r rune) bool { return r > 0xFFFF }) >= 0
+}
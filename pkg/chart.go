@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// chartWidth is the length of the longest bar of a chart
+const chartWidth = 30
+
+// chartColumnName is the column the bars are shown in
+const chartColumnName = "chart"
+
+// addBarChart adds a column of ASCII bars proportional to the values of
+// column to results. Negative, NULL and non-numeric values get no bar.
+func addBarChart(columns []string, results []map[string]any, column string) ([]string, error) {
+	found := false
+	for _, col := range columns {
+		if col == column {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("chart column %q is not in the result", column)
+	}
+
+	values := make([]float64, len(results))
+	largest := 0.0
+	for i, row := range results {
+		values[i] = chartValue(row[column])
+		if values[i] > largest {
+			largest = values[i]
+		}
+	}
+
+	for i, row := range results {
+		bar := 0
+		if largest > 0 && values[i] > 0 {
+			bar = int(math.Round(values[i] / largest * chartWidth))
+		}
+		row[chartColumnName] = strings.Repeat("#", bar)
+	}
+	return append(columns, chartColumnName), nil
+}
+
+// chartValue returns the numeric value of v, or NaN when it has none
+func chartValue(v any) float64 {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case Decimal:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err == nil {
+			return f
+		}
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			return f
+		}
+	}
+	return math.NaN()
+}
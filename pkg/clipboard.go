@@ -0,0 +1,124 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// GetCopyCommandRegex returns the regex for COPY row n and COPY cell n column
+func GetCopyCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(COPY)\s+(row|cell)\s+(\d+)(?:\s+(\S+))?$`)
+}
+
+// clipboardCommands returns the programs tried in order to write the
+// clipboard on this system
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+	var commands [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-copy"})
+	}
+	return append(commands, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+}
+
+// WriteClipboard puts text on the system clipboard with pbcopy on macOS,
+// clip on Windows and wl-copy, xclip or xsel elsewhere
+func WriteClipboard(text string) error {
+	for _, command := range clipboardCommands() {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %v %s", command[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	names := []string{}
+	for _, command := range clipboardCommands() {
+		names = append(names, command[0])
+	}
+	return fmt.Errorf("no clipboard program found. Install %s", strings.Join(names, " or "))
+}
+
+// writeClipboard puts text on the clipboard of the session
+func (s *Session) writeClipboard(text string) error {
+	if s.Clipboard != nil {
+		return s.Clipboard(text)
+	}
+	return WriteClipboard(text)
+}
+
+// cellText returns the clipboard text of a value: strings as they are, NULL
+// as NULL and other values as in JSON
+func cellText(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return v, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// handleCopy copies row n of the last GET result, as JSON, or the value of
+// column in it to the clipboard. Rows are numbered from 1 as in the # column.
+func handleCopy(s *Session, what, n, column string) error {
+	index, row, err := s.resultRow(n)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	copied := map[string]any{"row": index}
+	if strings.EqualFold(what, "row") {
+		if column != "" {
+			return fmt.Errorf("COPY row takes no column. Use COPY cell %d %s", index, column)
+		}
+		data, err := json.MarshalIndent(Record(row), "", "  ")
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	} else {
+		if column == "" {
+			return fmt.Errorf("COPY cell needs a column, e.g. COPY cell %d %s", index, s.lastResult.Columns[0])
+		}
+		value, err := s.resultCell(row, column)
+		if err != nil {
+			return err
+		}
+		if text, err = cellText(value); err != nil {
+			return err
+		}
+		copied["column"] = column
+	}
+
+	if err := s.writeClipboard(text); err != nil {
+		return err
+	}
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Copied: %s\n", ColorJSON(copied))
+	} else if column != "" {
+		fmt.Fprintf(s.Out, "Copied %s of row %d to the clipboard\n", column, index)
+	} else {
+		fmt.Fprintf(s.Out, "Copied row %d to the clipboard\n", index)
+	}
+	return nil
+}
@@ -1,24 +1,250 @@
 package pkg
 
 import (
-	"github.com/hokaccha/go-prettyjson"
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Colors of the parts of JSON output
+var (
+	jsonKeyColor    = color.New(color.FgBlue, color.Bold)
+	jsonStringColor = color.New(color.FgGreen, color.Bold)
+	jsonBoolColor   = color.New(color.FgYellow, color.Bold)
+	jsonNumberColor = color.New(color.FgCyan, color.Bold)
+	jsonNullColor   = color.New(color.FgBlack, color.Bold)
 )
 
-// ColorJSON formats and colorizes JSON data
-var formatter = prettyjson.NewFormatter()
+// jsonIndent indents each level of nested JSON output
+const jsonIndent = "  "
+
+// JSONKeyOrder lists the keys JSON output puts first, in this order. The
+// other keys of a map follow alphabetically, and those of a struct in the
+// order of its fields. The json_key_order setting replaces it.
+var JSONKeyOrder = []string{"id"}
+
+// JSONKeyOrderFromConfig reads the json_key_order setting: the keys to put
+// first separated by commas, such as "id, name", or "alphabetical" to sort
+// every key. It returns the default order when the setting is unset.
+func JSONKeyOrderFromConfig(config Config) []string {
+	value := strings.TrimSpace(config.Get("json_key_order"))
+	switch {
+	case value == "":
+		return []string{"id"}
+	case strings.EqualFold(value, "alphabetical"):
+		return nil
+	}
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// orderKeys moves the keys listed in JSONKeyOrder to the front of keys,
+// keeping the others in their order
+func orderKeys(keys []string) []string {
+	if len(JSONKeyOrder) == 0 {
+		return keys
+	}
+	ordered := make([]string, 0, len(keys))
+	first := make(map[string]bool, len(JSONKeyOrder))
+	for _, key := range JSONKeyOrder {
+		for _, k := range keys {
+			if k == key && !first[k] {
+				ordered = append(ordered, k)
+				first[k] = true
+			}
+		}
+	}
+	for _, k := range keys {
+		if !first[k] {
+			ordered = append(ordered, k)
+		}
+	}
+	return ordered
+}
+
+// Record is a row whose JSON encoding lists its keys in JSONKeyOrder, then
+// alphabetically
+type Record map[string]any
+
+// MarshalJSON encodes the record with its keys in a stable order
+func (r Record) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(map[string]any(r))
+	if err != nil {
+		return nil, err
+	}
+	return reorderJSON(data)
+}
+
+// jsonObject is a decoded JSON object remembering the order of its keys
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// decodeOrdered decodes the next JSON value of dec, keeping the order of
+// object keys and numbers as written
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := &jsonObject{values: make(map[string]any)}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, seen := obj.values[key]; !seen {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		_, err := dec.Token() // }
+		return obj, err
+	default:
+		list := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token() // ]
+		return list, err
+	}
+}
 
-func init() {
-	// Configure the formatter
-	formatter.Indent = 2
-	formatter.DisabledColor = false
+// decodeJSON decodes data keeping the order of object keys
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeOrdered(dec)
 }
 
-// ColorJSON takes any data structure and returns a colorized JSON string
+// reorderJSON rewrites compact JSON with the keys of every object in
+// JSONKeyOrder
+func reorderJSON(data []byte) ([]byte, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	writeJSON(&b, v, -1, false)
+	return []byte(b.String()), nil
+}
+
+// writeJSON writes v to b, indented at depth unless depth is negative, and
+// colorized when colored is true
+func writeJSON(b *strings.Builder, v any, depth int, colored bool) {
+	paint := func(c *color.Color, s string) string {
+		if !colored {
+			return s
+		}
+		return c.Sprint(s)
+	}
+	newline := func(level int) {
+		if depth >= 0 {
+			b.WriteString("\n")
+			b.WriteString(strings.Repeat(jsonIndent, level))
+		}
+	}
+	next := depth
+	if depth >= 0 {
+		next = depth + 1
+	}
+
+	switch val := v.(type) {
+	case *jsonObject:
+		if len(val.keys) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{")
+		for i, key := range orderKeys(val.keys) {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			newline(next)
+			b.WriteString(paint(jsonKeyColor, encodeJSONString(key)))
+			b.WriteString(":")
+			if depth >= 0 {
+				b.WriteString(" ")
+			}
+			writeJSON(b, val.values[key], next, colored)
+		}
+		newline(depth)
+		b.WriteString("}")
+	case []any:
+		if len(val) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[")
+		for i, item := range val {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			newline(next)
+			writeJSON(b, item, next, colored)
+		}
+		newline(depth)
+		b.WriteString("]")
+	case string:
+		b.WriteString(paint(jsonStringColor, encodeJSONString(val)))
+	case json.Number:
+		b.WriteString(paint(jsonNumberColor, string(val)))
+	case bool:
+		if val {
+			b.WriteString(paint(jsonBoolColor, "true"))
+		} else {
+			b.WriteString(paint(jsonBoolColor, "false"))
+		}
+	case nil:
+		b.WriteString(paint(jsonNullColor, "null"))
+	}
+}
+
+// encodeJSONString quotes s as a JSON string without escaping HTML
+func encodeJSONString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// ColorJSON takes any data structure and returns a colorized JSON string.
+// Keys are listed in JSONKeyOrder, so the output is the same on every run.
 func ColorJSON(v any) string {
-	output, err := formatter.Marshal(v)
+	data, err := json.Marshal(v)
 	if err != nil {
 		// Fallback to non-colored string if there's an error
 		return "Error formatting JSON"
 	}
-	return string(output)
+	decoded, err := decodeJSON(data)
+	if err != nil {
+		return "Error formatting JSON"
+	}
+	var b strings.Builder
+	writeJSON(&b, decoded, 0, true)
+	return b.String()
 }
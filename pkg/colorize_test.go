@@ -0,0 +1,55 @@
+package pkg_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorJSONKeyOrder(t *testing.T) {
+	color.NoColor = true
+	record := map[string]any{"name": "Ann", "id": 1, "age": 30, "tags": []any{map[string]any{"z": 1, "id": 2}}}
+
+	expected := `{
+  "id": 1,
+  "age": 30,
+  "name": "Ann",
+  "tags": [
+    {
+      "id": 2,
+      "z": 1
+    }
+  ]
+}`
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, expected, pkg.ColorJSON(record))
+	}
+
+	// Struct fields keep their order
+	assert.Equal(t, "{\n  \"b\": 1,\n  \"a\": 2\n}", pkg.ColorJSON(struct {
+		B int `json:"b"`
+		A int `json:"a"`
+	}{1, 2}))
+	assert.Equal(t, "[]", pkg.ColorJSON([]any{}))
+}
+
+func TestRecordJSON(t *testing.T) {
+	data, err := json.Marshal(pkg.Record{"name": "Ann", "id": 1, "email": "ann@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"email":"ann@example.com","name":"Ann"}`, string(data))
+}
+
+func TestJSONKeyOrderFromConfig(t *testing.T) {
+	assert.Equal(t, []string{"id"}, pkg.JSONKeyOrderFromConfig(pkg.Config{}))
+	assert.Empty(t, pkg.JSONKeyOrderFromConfig(pkg.Config{"json_key_order": "alphabetical"}))
+	assert.Equal(t, []string{"name", "id"}, pkg.JSONKeyOrderFromConfig(pkg.Config{"json_key_order": "name, id"}))
+
+	defer func(order []string) { pkg.JSONKeyOrder = order }(pkg.JSONKeyOrder)
+	pkg.JSONKeyOrder = []string{"name", "id"}
+	data, err := json.Marshal(pkg.Record{"id": 1, "age": 3, "name": "Ann"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Ann","id":1,"age":3}`, string(data))
+}
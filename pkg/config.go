@@ -0,0 +1,124 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds user settings loaded from the noqli config file.
+// Keys declared inside a [section] are stored as "section.key".
+type Config map[string]string
+
+// DefaultConfigPath returns the location of the user config file (~/.noqli/config)
+func DefaultConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".noqli", "config")
+}
+
+// LoadConfig reads a config file made of "key = value" lines grouped in
+// optional [section] blocks. A missing file yields an empty config.
+func LoadConfig(path string) (Config, error) {
+	cfg := make(Config)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip blank lines and comments
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		// Section header
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNo)
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+		if section != "" {
+			key = section + "." + key
+		}
+		cfg[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Get returns the value stored under key, or an empty string
+func (c Config) Get(key string) string {
+	return c[key]
+}
+
+// Section returns the keys of a section with the section prefix stripped
+func (c Config) Section(name string) map[string]string {
+	prefix := strings.ToLower(name) + "."
+	result := make(map[string]string)
+	for k, v := range c {
+		if strings.HasPrefix(k, prefix) {
+			result[strings.TrimPrefix(k, prefix)] = v
+		}
+	}
+	return result
+}
+
+// Duration returns the value of key as a duration, accepting Go durations
+// such as "1.5s" or plain numbers of seconds. ok is false when the key is unset.
+func (c Config) Duration(key string) (d time.Duration, ok bool, err error) {
+	value := c.Get(key)
+	if value == "" {
+		return 0, false, nil
+	}
+	// Plain numbers are seconds
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), true, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, true, nil
+	}
+	return 0, false, fmt.Errorf("invalid %s value %q", key, value)
+}
+
+// Location returns the time zone named by the timezone setting, such as
+// "UTC" or "Europe/Berlin". It defaults to the local time zone.
+func (c Config) Location() (*time.Location, error) {
+	name := c.Get("timezone")
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
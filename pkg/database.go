@@ -1,79 +1,202 @@
 package pkg
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"strings"
+	"time"
 )
 
 // getColumns retrieves all column names from the current table
-func getColumns(db *sql.DB) ([]string, error) {
-	if CurrentTable == "" {
-		return nil, fmt.Errorf("no table selected")
+func getColumns(ctx context.Context, s *Session) ([]string, error) {
+	if s.CurrentTable == "" {
+		return nil, ErrNoTableSelected
 	}
+	return tableColumns(ctx, s, s.CurrentTable)
+}
+
+// tableColumns returns the column names of table
+func tableColumns(ctx context.Context, s *Session, table string) ([]string, error) {
+	schema, err := tableSchema(ctx, s, table)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(schema))
+	for i, col := range schema {
+		columns[i] = col.Name
+	}
+	return columns, nil
+}
+
+// ColumnSchema is a column of a table as shown by SHOW COLUMNS
+type ColumnSchema struct {
+	Name string
+	// Type is the column type, such as varchar(255) or int unsigned
+	Type          string
+	Nullable      bool
+	AutoIncrement bool
+}
 
-	rows, err := db.Query(fmt.Sprintf("SHOW COLUMNS FROM %s", CurrentTable))
+// tableSchema returns the columns of table with their types
+func tableSchema(ctx context.Context, s *Session, table string) ([]ColumnSchema, error) {
+	rows, err := s.query(ctx, fmt.Sprintf("SHOW COLUMNS FROM %s", table))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var columns []string
+	var columns []ColumnSchema
 	for rows.Next() {
 		var field, fieldType, null, key, defaultVal, extra sql.NullString
 		if err := rows.Scan(&field, &fieldType, &null, &key, &defaultVal, &extra); err != nil {
 			return nil, err
 		}
-		columns = append(columns, field.String)
+		columns = append(columns, ColumnSchema{
+			Name:          field.String,
+			Type:          fieldType.String,
+			Nullable:      !strings.EqualFold(null.String, "NO"),
+			AutoIncrement: strings.Contains(strings.ToLower(extra.String), "auto_increment"),
+		})
 	}
 
 	return columns, nil
 }
 
-// ensureColumns creates columns in the table if they don't exist
-func ensureColumns(db *sql.DB, fields map[string]any) error {
-	if CurrentTable == "" {
-		return fmt.Errorf("no table selected")
+// ensureColumns creates the columns of records missing from the table,
+// asking first when a name looks like a typo of an existing column, and
+// checks that the values of records fit their columns
+func ensureColumns(ctx context.Context, s *Session, records ...map[string]any) error {
+	if s.CurrentTable == "" {
+		return ErrNoTableSelected
 	}
 
-	existingCols, err := getColumns(db)
+	schema, err := tableSchema(ctx, s, s.CurrentTable)
 	if err != nil {
 		return err
 	}
 
 	// Create a map for faster lookup
-	colMap := make(map[string]bool)
-	for _, col := range existingCols {
-		colMap[col] = true
+	colMap := make(map[string]ColumnSchema)
+	existingCols := make([]string, len(schema))
+	for i, col := range schema {
+		colMap[col.Name] = col
+		existingCols[i] = col.Name
 	}
 
 	// Check if each field exists, create if not
-	for key := range fields {
-		if key == "id" {
-			continue // Skip id field
-		}
-
-		if !colMap[key] {
-			_, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN `%s` VARCHAR(255)", CurrentTable, key))
+	for _, record := range records {
+		for _, key := range sortedKeys(record) {
+			if _, ok := colMap[key]; ok || key == "id" {
+				continue // Skip id field
+			}
+			if err := confirmNewColumn(s, key, existingCols); err != nil {
+				return err
+			}
+			colType := columnType(record[key])
+			_, err := s.exec(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN `%s` %s", s.CurrentTable, key, colType))
 			if err != nil {
 				return err
 			}
+			colMap[key] = ColumnSchema{Name: key, Type: colType, Nullable: true}
 		}
+		binaryUUIDs(colMap, record)
 	}
 
-	return nil
+	return validateRecords(colMap, records)
 }
 
-// Helper function to determine if ID is an array or range
+// Helper function to determine if ID is an array, range, comparison or
+// operator object. Quoted comparisons such as '> 3' are values here.
 func isArrayOrRange(id any) bool {
 	_, isSlice := id.([]any)
-	_, isMap := id.(map[string]any)
-	return isSlice || isMap
+	_, isComparison := id.(Comparison)
+	_, isOperator := operatorObject(id)
+	_, isDistance := id.(Distance)
+	return isSlice || isComparison || isOperator || isDistance || isRange(id)
+}
+
+// isRange reports whether v is a {range: [start, end]} filter rather than a
+// nested object
+func isRange(v any) bool {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return false
+	}
+	_, ok = m["range"]
+	return ok
+}
+
+// columnType returns the type of a column created for value: JSON for nested
+// objects and lists, TINYINT(1) for booleans, DATETIME for dates, POINT for
+// points, BIGINT UNSIGNED for uuid_short(), VARCHAR(255) otherwise
+func columnType(value any) string {
+	switch value.(type) {
+	case map[string]any, []any:
+		return "JSON"
+	case bool:
+		return "TINYINT(1)"
+	case time.Time:
+		return "DATETIME"
+	case Point:
+		return "POINT"
+	case ServerFunc:
+		return "BIGINT UNSIGNED"
+	default:
+		return "VARCHAR(255)"
+	}
+}
+
+// columnValue converts a value for storage, encoding nested objects and
+// lists as JSON, dates in the DATETIME format and points as WKT
+func columnValue(value any) (any, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(dateTimeLayout), nil
+	case Point:
+		return v.String(), nil
+	case map[string]any, []any:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	default:
+		return value, nil
+	}
+}
+
+// sqlValue returns the SQL expression and parameters storing value. Points
+// are sent as WKT and UUIDs of BINARY(16) columns as text, both converted by
+// MySQL, and server functions such as uuid_short() are evaluated by MySQL.
+func sqlValue(value any) (string, []any, error) {
+	switch v := value.(type) {
+	case Point:
+		return "ST_GeomFromText(?)", []any{v.String()}, nil
+	case BinaryUUID:
+		return binaryUUIDExpr, []any{string(v)}, nil
+	case ServerFunc:
+		return string(v), nil, nil
+	default:
+		arg, err := columnValue(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return "?", []any{arg}, nil
+	}
 }
 
 // handleQueryAndDisplayResults executes a query and displays the results
-func handleQueryAndDisplayResults(db *sql.DB, query string, values []any, isMultiple bool, useJsonOutput bool) error {
-	rows, err := db.Query(query, values...)
+func handleQueryAndDisplayResults(ctx context.Context, s *Session, query string, values []any, isMultiple bool, useJsonOutput bool) error {
+	booleans, err := booleanColumns(ctx, s, s.CurrentTable)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.query(ctx, query, values...)
 	if err != nil {
 		return err
 	}
@@ -84,106 +207,51 @@ func handleQueryAndDisplayResults(db *sql.DB, query string, values []any, isMult
 		return err
 	}
 
+	display, err := s.newColumnFormatter(rows, booleans)
+	if err != nil {
+		return err
+	}
+
 	var results []map[string]any
 
 	for rows.Next() {
-		values := make([]any, len(columns))
-		valuePtrs := make([]any, len(columns))
-
-		for i := range columns {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
 			return err
 		}
-
-		entry := make(map[string]any)
-		for i, col := range columns {
-			var v any
-			val := values[i]
-
-			b, ok := val.([]byte)
-			if ok {
-				v = string(b)
-			} else {
-				v = val
-			}
-
-			entry[col] = v
-		}
-
+		display.format(entry)
 		results = append(results, entry)
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
 	if len(results) == 0 {
-		return fmt.Errorf("no records found")
+		return fmt.Errorf("%w found", ErrNoRecords)
 	}
 
 	if useJsonOutput {
 		// Colorized JSON output
 		if !isMultiple && len(results) == 1 {
-			fmt.Println(ColorJSON(results[0]))
+			fmt.Fprintln(s.Out, ColorJSON(results[0]))
 		} else {
-			fmt.Println(ColorJSON(results))
+			fmt.Fprintln(s.Out, ColorJSON(results))
 		}
 	} else {
 		// MySQL-style tabular output
-		PrintTabularResults(columns, results)
+		s.printTable(columns, results)
 	}
 
 	return nil
 }
 
-// printTabularResults prints results in a MySQL-like tabular format
-func PrintTabularResults(columns []string, results []map[string]any) {
-	if len(results) == 0 {
-		return
-	}
-
-	// Calculate column widths
-	colWidths := make(map[string]int)
-	for _, col := range columns {
-		colWidths[col] = len(col)
-	}
-
-	// Find the max width for each column
-	for _, row := range results {
-		for col, val := range row {
-			valStr := fmt.Sprintf("%v", val)
-			if len(valStr) > colWidths[col] {
-				colWidths[col] = len(valStr)
-			}
-		}
-	}
-
-	// Print header
-	fmt.Println()
-	for _, col := range columns {
-		fmt.Printf("| %-*s ", colWidths[col], col)
-	}
-	fmt.Println("|")
-
-	// Print separator
-	for _, col := range columns {
-		fmt.Print("+")
-		for i := 0; i < colWidths[col]+2; i++ {
-			fmt.Print("-")
-		}
-	}
-	fmt.Println("+")
-
-	// Print rows
+// FprintTabularResults prints results in a MySQL-like tabular format to w
+func FprintTabularResults(w io.Writer, columns []string, results []map[string]any) {
+	table := NewTableWriter(w, columns, 0)
 	for _, row := range results {
-		for _, col := range columns {
-			val := row[col]
-			fmt.Printf("| %-*v ", colWidths[col], val)
-		}
-		fmt.Println("|")
+		table.Write(row)
 	}
-
-	// Print row count
-	fmt.Printf("\n%d rows in set\n", len(results))
+	table.Close()
 }
 
 // Default function for user input confirmation
@@ -203,21 +271,21 @@ func toInt(v any) (int, bool) {
 	case int64:
 		return int(val), true
 	case float64:
-		return int(val), true
+		return int(val), val == math.Trunc(val)
 	case float32:
-		return int(val), true
+		return int(val), float64(val) == math.Trunc(float64(val))
 	default:
 		return 0, false
 	}
 }
 
-// getTextColumns returns only the text columns for the current table
-func getTextColumns(db *sql.DB) ([]string, error) {
-	if CurrentTable == "" {
-		return nil, fmt.Errorf("no table selected")
+// getTextColumns returns only the text columns of table
+func getTextColumns(ctx context.Context, s *Session, table string) ([]string, error) {
+	if table == "" {
+		return nil, ErrNoTableSelected
 	}
 
-	rows, err := db.Query(fmt.Sprintf("SHOW COLUMNS FROM %s", CurrentTable))
+	rows, err := s.query(ctx, fmt.Sprintf("SHOW COLUMNS FROM %s", table))
 	if err != nil {
 		return nil, err
 	}
@@ -237,3 +305,66 @@ func getTextColumns(db *sql.DB) ([]string, error) {
 	}
 	return textColumns, nil
 }
+
+// booleanColumns returns the TINYINT(1) columns of table, which are shown as
+// true and false unless the booleans setting is "numeric"
+func booleanColumns(ctx context.Context, s *Session, table string) (map[string]bool, error) {
+	if table == "" || s.Config.Get("booleans") == "numeric" {
+		return nil, nil
+	}
+
+	rows, err := s.query(ctx, fmt.Sprintf("SHOW COLUMNS FROM %s", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	booleans := make(map[string]bool)
+	for rows.Next() {
+		var field, fieldType, null, key, defaultVal, extra sql.NullString
+		if err := rows.Scan(&field, &fieldType, &null, &key, &defaultVal, &extra); err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(fieldType.String, "tinyint(1)") {
+			booleans[field.String] = true
+		}
+	}
+	return booleans, rows.Err()
+}
+
+// TableChecksum returns the MySQL checksum of the current table, which changes
+// whenever its contents change
+func (s *Session) TableChecksum(ctx context.Context) (int64, error) {
+	if s.CurrentTable == "" {
+		return 0, ErrNoTableSelected
+	}
+
+	var table string
+	var checksum sql.NullInt64
+	if err := s.queryRow(ctx, "CHECKSUM TABLE "+quoteIdent(s.CurrentTable)).Scan(&table, &checksum); err != nil {
+		return 0, err
+	}
+	if !checksum.Valid {
+		return 0, fmt.Errorf("table '%s' doesn't exist", s.CurrentTable)
+	}
+	return checksum.Int64, nil
+}
+
+// Tables lists the tables of the current database
+func (s *Session) Tables(ctx context.Context) ([]string, error) {
+	rows, err := s.query(ctx, "SHOW TABLES")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return nil, err
+		}
+		tables = append(tables, tableName)
+	}
+	return tables, rows.Err()
+}
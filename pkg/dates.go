@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateTimeLayout is the MySQL DATETIME format used for date parameters
+const dateTimeLayout = "2006-01-02 15:04:05.999999"
+
+// dateLayouts are the accepted date literals, e.g. 2024-06-01 or
+// 2024-06-01 14:30:00. Fractional seconds are accepted after the seconds.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+}
+
+// relativeDateRegex matches now() or today() followed by offsets such as -7d or +2h
+var relativeDateRegex = regexp.MustCompile(`(?i)^(now|today)\(\)((?:\s*[+-]\s*\d+\s*[smhdw])*)$`)
+
+// relativeOffsetRegex matches a single offset of a relative date
+var relativeOffsetRegex = regexp.MustCompile(`([+-])\s*(\d+)\s*([smhdw])`)
+
+// periodRegex matches a period such as 7d or 12h, as {last: '7d'} takes
+var periodRegex = regexp.MustCompile(`(?i)^\d+\s*[smhdw]$`)
+
+// timeNow is the clock relative dates are resolved against
+var timeNow = time.Now
+
+// periodStart returns the time period, such as 7d, before now
+func periodStart(period string) (time.Time, bool) {
+	period = strings.TrimSpace(period)
+	if !periodRegex.MatchString(period) {
+		return time.Time{}, false
+	}
+	return parseDateLiteral("now()-" + period)
+}
+
+// parseDateLiteral converts a date literal or a relative date expression to
+// a time. Literals are read as local wall-clock times; a literal with a zone
+// offset (RFC 3339) is converted to local time.
+func parseDateLiteral(text string) (time.Time, bool) {
+	if m := relativeDateRegex.FindStringSubmatch(text); m != nil {
+		t := timeNow()
+		if strings.EqualFold(m[1], "today") {
+			year, month, day := t.Date()
+			t = time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+		}
+		for _, offset := range relativeOffsetRegex.FindAllStringSubmatch(m[2], -1) {
+			n, err := strconv.Atoi(offset[2])
+			if err != nil {
+				return time.Time{}, false
+			}
+			if offset[1] == "-" {
+				n = -n
+			}
+			switch strings.ToLower(offset[3]) {
+			case "s":
+				t = t.Add(time.Duration(n) * time.Second)
+			case "m":
+				t = t.Add(time.Duration(n) * time.Minute)
+			case "h":
+				t = t.Add(time.Duration(n) * time.Hour)
+			case "d":
+				t = t.AddDate(0, 0, n)
+			case "w":
+				t = t.AddDate(0, 0, 7*n)
+			}
+		}
+		return t, true
+	}
+
+	// Only digits can start a date literal
+	if len(text) < len("2006-01-02") || text[0] < '0' || text[0] > '9' {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return t.In(time.Local), true
+	}
+	return time.Time{}, false
+}
+
+// DSNTimeParams returns the DSN parameters making the MySQL driver return
+// DATE, DATETIME and TIMESTAMP values as times in loc, and setting the MySQL
+// session time zone to the current offset of loc so TIMESTAMP values are
+// converted to it
+func DSNTimeParams(loc *time.Location) string {
+	_, offset := time.Now().In(loc).Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	timeZone := fmt.Sprintf("'%c%02d:%02d'", sign, offset/3600, offset%3600/60)
+
+	params := url.Values{}
+	params.Set("parseTime", "true")
+	params.Set("loc", loc.String())
+	params.Set("time_zone", timeZone)
+	return params.Encode()
+}
+
+// mysqlDateTimeLayouts are the layouts of date and time values the driver
+// returns as text when the DSN does not set parseTime
+var mysqlDateTimeLayouts = []string{"2006-01-02 15:04:05.999999", "2006-01-02"}
+
+// parseMySQLTime parses a date or time value returned as text
+func parseMySQLTime(value string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range mysqlDateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
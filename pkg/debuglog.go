@@ -0,0 +1,320 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel is the severity of a debug log entry
+type LogLevel int
+
+// Log levels, from the most to the least verbose
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l LogLevel) String() string {
+	if l < LevelDebug || l > LevelError {
+		return strconv.Itoa(int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLogLevel reads a level name such as debug or warn
+func ParseLogLevel(name string) (LogLevel, error) {
+	for i, level := range levelNames {
+		if strings.EqualFold(name, level) {
+			return LogLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid log level %q. Use debug, info, warn or error", name)
+}
+
+// DefaultDebugLogPath returns the location of the debug log (~/.noqli/debug.log)
+func DefaultDebugLogPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".noqli", "debug.log")
+}
+
+// DefaultDebugLogSize is the size in bytes past which the debug log is
+// rotated when the config does not set debug_log_size
+const DefaultDebugLogSize = 10 << 20
+
+// debugLogBackups is the number of rotated debug logs kept
+const debugLogBackups = 3
+
+// Logger writes leveled entries as logfmt lines, such as
+// time=... level=debug msg=statement sql="SELECT 1" duration=1.2ms
+type Logger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level LogLevel
+	// Path of the file written, empty for other writers
+	Path string
+}
+
+// NewLogger returns a logger writing entries of level and above to out
+func NewLogger(out io.Writer, level LogLevel) *Logger {
+	return &Logger{out: out, level: level}
+}
+
+// DebugLoggerFromConfig opens the debug log named by debug_log, or
+// ~/.noqli/debug.log, rotated past debug_log_size bytes and keeping entries
+// of debug_level and above
+func DebugLoggerFromConfig(config Config) (*Logger, error) {
+	path, size, level, err := debugLogSettings(config)
+	if err != nil {
+		return nil, err
+	}
+	file, err := OpenRotatingFile(path, size, debugLogBackups)
+	if err != nil {
+		return nil, err
+	}
+	logger := NewLogger(file, level)
+	logger.Path = path
+	return logger, nil
+}
+
+// debugLogSettings reads the path, rotation size and level of the debug log
+func debugLogSettings(config Config) (path string, size int64, level LogLevel, err error) {
+	level = LevelDebug
+	if name := config.Get("debug_level"); name != "" {
+		if level, err = ParseLogLevel(name); err != nil {
+			return "", 0, 0, err
+		}
+	}
+	size = DefaultDebugLogSize
+	if value := config.Get("debug_log_size"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			return "", 0, 0, fmt.Errorf("invalid debug_log_size %q: must be a positive number of bytes", value)
+		}
+		size = n
+	}
+	path = config.Get("debug_log")
+	if path == "" {
+		path = DefaultDebugLogPath()
+	}
+	return path, size, level, nil
+}
+
+// Enabled reports whether entries of level are written
+func (l *Logger) Enabled(level LogLevel) bool {
+	return l != nil && level >= l.level
+}
+
+// Log writes an entry with msg and the key-value pairs of fields
+func (l *Logger) Log(level LogLevel, msg string, fields ...any) {
+	if !l.Enabled(level) {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("time=" + time.Now().Format("2006-01-02T15:04:05.000Z07:00"))
+	b.WriteString(" level=" + level.String())
+	b.WriteString(" msg=" + logfmtValue(msg))
+	for i := 0; i+1 < len(fields); i += 2 {
+		fmt.Fprintf(&b, " %v=%s", fields[i], logfmtValue(fields[i+1]))
+	}
+	b.WriteString("\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.out, b.String())
+}
+
+// Debug writes an entry at debug level
+func (l *Logger) Debug(msg string, fields ...any) { l.Log(LevelDebug, msg, fields...) }
+
+// Info writes an entry at info level
+func (l *Logger) Info(msg string, fields ...any) { l.Log(LevelInfo, msg, fields...) }
+
+// Warn writes an entry at warn level
+func (l *Logger) Warn(msg string, fields ...any) { l.Log(LevelWarn, msg, fields...) }
+
+// Error writes an entry at error level
+func (l *Logger) Error(msg string, fields ...any) { l.Log(LevelError, msg, fields...) }
+
+// Close closes the file the logger writes to
+func (l *Logger) Close() error {
+	if closer, ok := l.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// logfmtBareRegex matches values written without quotes
+var logfmtBareRegex = regexp.MustCompile(`^[^\s"=]+$`)
+
+// logfmtValue renders v, quoting it when it has spaces, quotes or =
+func logfmtValue(v any) string {
+	var str string
+	switch val := v.(type) {
+	case nil:
+		return `""`
+	case string:
+		str = val
+	case error:
+		str = val.Error()
+	case time.Duration:
+		str = val.String()
+	default:
+		str = fmt.Sprintf("%v", val)
+	}
+	if logfmtBareRegex.MatchString(str) {
+		return str
+	}
+	return strconv.Quote(str)
+}
+
+// RotatingFile is a file that is renamed to path.1, path.1 to path.2 and
+// so on, once it grows past a size
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// OpenRotatingFile opens path for appending, rotating it past maxSize bytes
+// and keeping backups rotated files
+func OpenRotatingFile(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating the file first when p would take it past its
+// maximum size
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the rotated files and starts a new one
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	for i := r.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.backups > 0 {
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// Close closes the file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// SetDebug turns the debug log of the session on or off
+func (s *Session) SetDebug(on bool) error {
+	if !on {
+		if s.Logger == nil {
+			return nil
+		}
+		err := s.Logger.Close()
+		s.Logger = nil
+		return err
+	}
+	if s.Logger != nil {
+		return nil
+	}
+	logger, err := DebugLoggerFromConfig(s.Config)
+	if err != nil {
+		return err
+	}
+	s.Logger = logger
+	return nil
+}
+
+// logCommand writes a finished command, how long it took and the rows it
+// returned or changed to the debug log, at error level when it failed
+func (s *Session) logCommand(info *CommandInfo) {
+	if info.Err != nil {
+		s.Logger.Error("command", "command", info.Command, "line", info.Line, "duration", info.Duration, "error", info.Err)
+		return
+	}
+	s.Logger.Info("command", "command", info.Command, "line", info.Line, "duration", info.Duration,
+		"query_time", info.QueryDuration, "statements", len(info.SQL), "rows", info.Rows)
+}
+
+// handleSetDebug turns the debug log on or off for SET debug
+func handleSetDebug(s *Session, setting string) error {
+	on := strings.EqualFold(setting, "on")
+	if err := s.SetDebug(on); err != nil {
+		return err
+	}
+	path := ""
+	if s.Logger != nil {
+		path = s.Logger.Path
+	}
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Debug: %s\n", ColorJSON(map[string]any{"debug": on, "log": path}))
+	} else if on {
+		fmt.Fprintf(s.Out, "Debug logging on, writing to %s\n", path)
+	} else {
+		fmt.Fprintln(s.Out, "Debug logging off")
+	}
+	return nil
+}
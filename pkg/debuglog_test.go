@@ -0,0 +1,86 @@
+package pkg_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugLog(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	path := filepath.Join(t.TempDir(), "debug.log")
+	session.Config["debug_log"] = path
+
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET debug on"))
+	assert.Contains(t, buf.String(), "Debug logging on, writing to "+path)
+
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Ann", "ann@example.com"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET 1"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET debug off"))
+
+	// Commands run while the log is off are not written
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET 2"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	log := string(data)
+	assert.Contains(t, log, "level=debug msg=statement sql=\"SELECT * FROM users WHERE `id` = ?\" args=[1] duration=")
+	assert.Contains(t, log, `level=info msg=command command=GET line="GET 1"`)
+	assert.Contains(t, log, "statements=3 rows=1")
+	assert.NotContains(t, log, "GET 2")
+}
+
+func TestDebugLogLevel(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	path := filepath.Join(t.TempDir(), "debug.log")
+	session.Config["debug_log"] = path
+	session.Config["debug_level"] = "info"
+	require.NoError(t, session.SetDebug(true))
+
+	mock.ExpectExec("DELETE FROM users WHERE `id` = \\?").WithArgs(1).WillReturnError(assert.AnError)
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "DELETE 1"))
+	require.NoError(t, session.SetDebug(false))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	log := string(data)
+	assert.NotContains(t, log, "msg=statement")
+	assert.Contains(t, log, `level=error msg=command command=DELETE line="DELETE 1"`)
+
+	session.Config["debug_level"] = "verbose"
+	assert.ErrorContains(t, session.SetDebug(true), "invalid log level")
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.log")
+	file, err := pkg.OpenRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.Close())
+
+	read := func(name string) string {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(data))
+	}
+	assert.Equal(t, "fourth", read(path))
+	assert.Equal(t, "third", read(path+".1"))
+	assert.Equal(t, "second", read(path+".2"))
+	assert.NoFileExists(t, path+".3")
+}
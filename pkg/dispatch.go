@@ -0,0 +1,543 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExecuteCommand parses and runs a single command line against the session.
+// The session's before hooks run once the command is parsed and may abort it;
+// after hooks run once it finished, including when it failed.
+func ExecuteCommand(ctx context.Context, s *Session, line string) error {
+	info := &CommandInfo{Line: strings.TrimSpace(line)}
+	previous := s.current
+	s.current = info
+	defer func() { s.current = previous }()
+
+	// Record the commands entered while RECORD is on
+	if s.recorder != nil && previous == nil {
+		defer s.captureCommand(info)()
+	}
+
+	info.Err = executeCommand(ctx, s, info)
+	if info.Command != "" {
+		s.commandsRun++
+		if info.Err != nil {
+			s.commandsFailed++
+		}
+		s.logCommand(info)
+	}
+	s.runAfterHooks(ctx, info)
+	return info.Err
+}
+
+// executeCommand parses info.Line and dispatches it to its handler
+func executeCommand(ctx context.Context, s *Session, info *CommandInfo) error {
+	trimmed := info.Line
+
+	// run executes a parsed command after the before hooks allowed it
+	run := func(handler func() error) error {
+		if err := s.runBeforeHooks(ctx, info); err != nil {
+			return err
+		}
+		info.Started = time.Now()
+		err := handler()
+		info.Duration = time.Since(info.Started)
+		return s.suggestColumn(ctx, err)
+	}
+
+	// ASK takes free text, so match it before parsing arguments
+	if askMatches := GetAskCommandRegex().FindStringSubmatch(trimmed); askMatches != nil {
+		info.Command = "ASK"
+		prompt := strings.Trim(strings.TrimSpace(askMatches[1]), `"'`)
+		return run(func() error { return handleAsk(ctx, s, prompt) })
+	}
+
+	// Check for USE command first
+	useCommandRegex := GetUseCommandRegex()
+	useMatches := useCommandRegex.FindStringSubmatch(trimmed)
+
+	if useMatches != nil {
+		// Handle USE command
+		info.Command = "USE"
+		return run(func() error { return handleUse(ctx, s, useMatches[1]) })
+	}
+
+	// DESCRIBE shows the columns of a table
+	if describeMatches := GetDescribeCommandRegex().FindStringSubmatch(trimmed); describeMatches != nil {
+		info.Command = "DESCRIBE"
+		s.JSONOutput = describeMatches[1] != strings.ToUpper(describeMatches[1])
+		return run(func() error { return handleDescribe(ctx, s, describeMatches[2]) })
+	}
+
+	// WATCH TABLE prints the changes made to a table until interrupted
+	if watchTableMatches := GetWatchTableCommandRegex().FindStringSubmatch(trimmed); watchTableMatches != nil {
+		info.Command = "WATCH TABLE"
+		s.JSONOutput = watchTableMatches[1] != strings.ToUpper(watchTableMatches[1])
+		return run(func() error { return handleWatchTable(ctx, s, watchTableMatches[2], watchTableMatches[3]) })
+	}
+
+	// WATCH reruns a command until interrupted. The hooks see each run of the
+	// watched command rather than the whole watch.
+	if watchMatches := GetWatchCommandRegex().FindStringSubmatch(trimmed); watchMatches != nil {
+		return handleWatch(ctx, s, watchMatches[1], watchMatches[2])
+	}
+
+	// RECORD writes the session to a file and REPLAY runs it again
+	if recordMatches := GetRecordCommandRegex().FindStringSubmatch(trimmed); recordMatches != nil {
+		info.Command = "RECORD"
+		s.JSONOutput = recordMatches[1] != info.Command
+		return run(func() error { return handleRecord(s, recordMatches[2], recordMatches[3]) })
+	}
+	if replayMatches := GetReplayCommandRegex().FindStringSubmatch(trimmed); replayMatches != nil {
+		info.Command = "REPLAY"
+		s.JSONOutput = replayMatches[1] != info.Command
+		return run(func() error { return handleReplay(ctx, s, replayMatches[2]) })
+	}
+
+	// BENCH times a command run many times. The hooks see the whole
+	// benchmark rather than each run.
+	if benchMatches := GetBenchCommandRegex().FindStringSubmatch(trimmed); benchMatches != nil {
+		info.Command = "BENCH"
+		s.JSONOutput = benchMatches[1] != info.Command
+		return run(func() error { return handleBench(ctx, s, benchMatches[2], benchMatches[3], benchMatches[4]) })
+	}
+
+	// SAVE stores a command template, RUN expands one and runs it in place
+	if saveMatches := GetSaveCommandRegex().FindStringSubmatch(trimmed); saveMatches != nil {
+		info.Command = "SAVE"
+		s.JSONOutput = saveMatches[1] != strings.ToUpper(saveMatches[1])
+		return run(func() error { return handleSave(s, saveMatches[2], saveMatches[3]) })
+	}
+	if runMatches := GetRunCommandRegex().FindStringSubmatch(trimmed); runMatches != nil {
+		params, err := splitTemplateParams(runMatches[2])
+		if err != nil {
+			return locateParseError(err, trimmed, len(trimmed)-len(runMatches[2]))
+		}
+		expanded, err := s.ExpandTemplate(runMatches[1], params)
+		if err != nil {
+			return err
+		}
+		info.Line = expanded
+		return executeCommand(ctx, s, info)
+	}
+
+	// SNAPSHOT saves, shows and compares GET results
+	if snapshotMatches := GetSnapshotCommandRegex().FindStringSubmatch(trimmed); snapshotMatches != nil {
+		info.Command = "SNAPSHOT"
+		s.JSONOutput = snapshotMatches[1] != strings.ToUpper(snapshotMatches[1])
+		return run(func() error { return handleSnapshot(ctx, s, snapshotMatches[2], snapshotMatches[3]) })
+	}
+
+	// COPY puts a row or cell of the last GET result on the clipboard
+	if copyMatches := GetCopyCommandRegex().FindStringSubmatch(trimmed); copyMatches != nil {
+		info.Command = "COPY"
+		s.JSONOutput = copyMatches[1] != strings.ToUpper(copyMatches[1])
+		return run(func() error { return handleCopy(s, copyMatches[2], copyMatches[3], copyMatches[4]) })
+	}
+
+	// SHOW cell prints or saves a full value of the last GET result
+	if showMatches := GetShowCellCommandRegex().FindStringSubmatch(trimmed); showMatches != nil {
+		info.Command = "SHOW"
+		s.JSONOutput = showMatches[1] != strings.ToUpper(showMatches[1])
+		return run(func() error { return handleShowCell(s, showMatches[2], showMatches[3], showMatches[4]) })
+	}
+
+	// SEARCH looks for a value in every table of the current database
+	if searchMatches := GetSearchCommandRegex().FindStringSubmatch(trimmed); searchMatches != nil {
+		info.Command = "SEARCH"
+		s.JSONOutput = searchMatches[1] != strings.ToUpper(searchMatches[1])
+		return run(func() error { return handleSearch(ctx, s, searchMatches[2], searchMatches[3]) })
+	}
+
+	// FIND lists the tables or columns of the server matching a name
+	if findMatches := GetFindCommandRegex().FindStringSubmatch(trimmed); findMatches != nil {
+		info.Command = "FIND"
+		s.JSONOutput = findMatches[1] != strings.ToUpper(findMatches[1])
+		return run(func() error { return handleFind(ctx, s, findMatches[2], findMatches[3]) })
+	}
+
+	// EXPLAIN GET shows the plan of a GET's query as a tree
+	if explainMatches := GetExplainCommandRegex().FindStringSubmatch(trimmed); explainMatches != nil {
+		info.Command = "EXPLAIN"
+		s.JSONOutput = explainMatches[1] != strings.ToUpper(explainMatches[1])
+		var argObj map[string]any
+		if args := strings.TrimSpace(explainMatches[2]); args != "" {
+			parsed, err := ParseArg(args)
+			if err != nil {
+				return locateParseError(err, trimmed, strings.LastIndex(trimmed, args))
+			}
+			if argObj, err = s.resolveArgs(parsed); err != nil {
+				return err
+			}
+		}
+		info.Args = argObj
+		return run(func() error { return handleExplain(ctx, s, argObj) })
+	}
+
+	// EDIT changes a row in the editor of the user
+	if editMatches := GetEditCommandRegex().FindStringSubmatch(trimmed); editMatches != nil {
+		info.Command = "EDIT"
+		s.JSONOutput = editMatches[1] != strings.ToUpper(editMatches[1])
+		return run(func() error { return handleEdit(ctx, s, editMatches[2]) })
+	}
+
+	// UNLOCK allows writes on a production connection for a while
+	if unlockMatches := GetUnlockCommandRegex().FindStringSubmatch(trimmed); unlockMatches != nil {
+		info.Command = "UNLOCK"
+		s.JSONOutput = unlockMatches[1] != strings.ToUpper(unlockMatches[1])
+		return run(func() error { return handleUnlock(s) })
+	}
+
+	// DUPES lists rows sharing the values of some columns
+	if dupesMatches := GetDupesCommandRegex().FindStringSubmatch(trimmed); dupesMatches != nil {
+		info.Command = "DUPES"
+		s.JSONOutput = dupesMatches[1] != strings.ToUpper(dupesMatches[1])
+		argObj, err := ParseArg(dupesMatches[2])
+		if err != nil {
+			return locateParseError(err, trimmed, len(trimmed)-len(dupesMatches[2]))
+		}
+		if argObj, err = s.resolveArgs(argObj); err != nil {
+			return err
+		}
+		info.Args = argObj
+		return run(func() error { return handleDupes(ctx, s, argObj) })
+	}
+
+	// ANONYMIZE rewrites sensitive columns with fake values
+	if anonymizeMatches := GetAnonymizeCommandRegex().FindStringSubmatch(trimmed); anonymizeMatches != nil {
+		info.Command = "ANONYMIZE"
+		s.JSONOutput = anonymizeMatches[1] != strings.ToUpper(anonymizeMatches[1])
+		argObj, err := ParseArg(anonymizeMatches[2])
+		if err != nil {
+			return locateParseError(err, trimmed, len(trimmed)-len(anonymizeMatches[2]))
+		}
+		if argObj, err = s.resolveArgs(argObj); err != nil {
+			return err
+		}
+		info.Args = argObj
+		return run(func() error { return handleAnonymize(ctx, s, argObj) })
+	}
+
+	// BINLOG tail shows the latest changes to the current table
+	if binlogMatches := GetBinlogCommandRegex().FindStringSubmatch(trimmed); binlogMatches != nil {
+		info.Command = "BINLOG"
+		s.JSONOutput = binlogMatches[1] != strings.ToUpper(binlogMatches[1])
+		argObj, err := ParseArg(binlogMatches[3])
+		if err != nil {
+			return locateParseError(err, trimmed, len(trimmed)-len(binlogMatches[3]))
+		}
+		if argObj, err = s.resolveArgs(argObj); err != nil {
+			return err
+		}
+		info.Args = argObj
+		return run(func() error { return handleBinlog(ctx, s, binlogMatches[2], argObj) })
+	}
+
+	// FIXTURES load replaces table rows with the rows of fixture files
+	if fixturesMatches := GetFixturesCommandRegex().FindStringSubmatch(trimmed); fixturesMatches != nil {
+		info.Command = "FIXTURES"
+		s.JSONOutput = fixturesMatches[1] != strings.ToUpper(fixturesMatches[1])
+		return run(func() error { return handleFixtures(ctx, s, fixturesMatches[2], fixturesMatches[3]) })
+	}
+
+	// CHECK fks looks for references to missing rows
+	if checkMatches := GetCheckCommandRegex().FindStringSubmatch(trimmed); checkMatches != nil {
+		info.Command = "CHECK"
+		s.JSONOutput = checkMatches[1] != strings.ToUpper(checkMatches[1])
+		return run(func() error { return handleCheck(ctx, s, checkMatches[2], checkMatches[3]) })
+	}
+
+	// REPORT orphans finds orphaned and unused rows in the whole database
+	if reportMatches := GetReportCommandRegex().FindStringSubmatch(trimmed); reportMatches != nil {
+		info.Command = "REPORT"
+		s.JSONOutput = reportMatches[1] != strings.ToUpper(reportMatches[1])
+		return run(func() error { return handleReport(ctx, s, reportMatches[2], reportMatches[3]) })
+	}
+
+	// KILL terminates a server connection or its running statement
+	if killMatches := GetKillCommandRegex().FindStringSubmatch(trimmed); killMatches != nil {
+		info.Command = "KILL"
+		s.JSONOutput = killMatches[1] != strings.ToUpper(killMatches[1])
+		return run(func() error { return handleKill(ctx, s, killMatches[2] != "", killMatches[3]) })
+	}
+
+	// OPTIMIZE and ANALYZE maintain the current table or all tables
+	if maintenanceMatches := GetMaintenanceCommandRegex().FindStringSubmatch(trimmed); maintenanceMatches != nil {
+		info.Command = strings.ToUpper(maintenanceMatches[1])
+		s.JSONOutput = maintenanceMatches[1] != info.Command
+		return run(func() error { return handleMaintenance(ctx, s, maintenanceMatches[1], maintenanceMatches[2]) })
+	}
+
+	// STATUS summarizes the health of the connection
+	if statusMatches := GetStatusCommandRegex().FindStringSubmatch(trimmed); statusMatches != nil {
+		info.Command = "STATUS"
+		s.JSONOutput = statusMatches[1] != info.Command
+		return run(func() error { return handleStatus(ctx, s) })
+	}
+
+	// VERSION prints the build information
+	if versionMatches := GetVersionCommandRegex().FindStringSubmatch(trimmed); versionMatches != nil {
+		info.Command = "VERSION"
+		s.JSONOutput = versionMatches[1] != info.Command
+		return run(func() error { return handleVersion(ctx, s, versionMatches[2] != "") })
+	}
+
+	// STATS profiles the current table
+	if statsMatches := GetStatsCommandRegex().FindStringSubmatch(trimmed); statsMatches != nil {
+		info.Command = "STATS"
+		s.JSONOutput = statsMatches[1] != strings.ToUpper(statsMatches[1])
+		return run(func() error { return handleStats(ctx, s) })
+	}
+
+	// SET assigns or lists session variables
+	if setMatches := GetSetCommandRegex().FindStringSubmatch(trimmed); setMatches != nil {
+		info.Command = "SET"
+		s.JSONOutput = setMatches[1] != strings.ToUpper(setMatches[1])
+		offset := len(trimmed) - len(setMatches[2])
+		return run(func() error {
+			return locateParseError(handleSet(s, setMatches[2]), trimmed, offset)
+		})
+	}
+
+	// Handle other commands
+	re := GetCommandRegex()
+	matches := re.FindStringSubmatch(trimmed)
+
+	if matches == nil {
+		return fmt.Errorf("invalid command. Use CREATE, GET, UPDATE, DELETE, USE, DESCRIBE, SET, SAVE, RUN, WATCH, BENCH, RECORD, REPLAY, STATUS, STATS, SNAPSHOT, COPY, SHOW, SEARCH, FIND, EXPLAIN, EDIT, UNLOCK, DUPES, ANONYMIZE, BINLOG, FIXTURES, CHECK, REPORT, KILL, OPTIMIZE, ANALYZE, VERSION, ASK, or EXIT")
+	}
+
+	originalCommand := matches[1]
+	command := strings.ToUpper(originalCommand)
+	args := matches[2]
+	argsOffset := len(trimmed) - len(args)
+	info.Command = command
+
+	// Check if command was originally uppercase (for formatting choice)
+	s.JSONOutput = originalCommand != command
+
+	// Special handling for GET dbs and GET tables
+	if IsGetDbsCommand(command, args) {
+		return run(func() error { return handleGetDatabases(ctx, s) })
+	} else if IsGetTablesCommand(command, args) {
+		return run(func() error { return handleGetTables(ctx, s) })
+	} else if IsGetViewsCommand(command, args) {
+		return run(func() error { return handleGetViews(ctx, s) })
+	} else if IsGetStatsCommand(command, args) {
+		return run(func() error { return handleStats(ctx, s) })
+	} else if IsGetRelationsCommand(command, args) {
+		return run(func() error { return handleGetRelations(ctx, s) })
+	} else if IsGetProcessesCommand(command, args) {
+		return run(func() error { return handleGetProcesses(ctx, s) })
+	} else if IsGetSizesCommand(command, args) {
+		return run(func() error { return handleGetSizes(ctx, s) })
+	} else if IsGetReplicationCommand(command, args) {
+		return run(func() error { return handleGetReplication(ctx, s) })
+	} else if kind, pattern, ok := cutServerInfo(command, args); ok {
+		return run(func() error { return handleServerInfo(ctx, s, kind, pattern) })
+	} else if rest, ok := cutSlowlog(command, args); ok {
+		argObj, err := ParseArg(rest)
+		if err != nil {
+			return locateParseError(err, trimmed, len(trimmed)-len(rest))
+		}
+		info.Args = argObj
+		return run(func() error { return handleGetSlowlog(ctx, s, argObj) })
+	}
+
+	// GET ... AS VIEW name creates a view from the query, GET ... -> table
+	// follows a relation and GET FROM [...] reads from several tables
+	var viewName, relatedTable string
+	var unionTables []string
+	if command == "GET" {
+		args, viewName, _ = cutViewSuffix(args)
+		args, relatedTable, _ = cutRelationSuffix(args)
+		tables, rest, ok, err := cutUnionTables(args)
+		if err != nil {
+			return err
+		}
+		if ok && relatedTable != "" {
+			return fmt.Errorf("cannot follow a relation from several tables")
+		}
+		if ok {
+			unionTables = tables
+			argsOffset += strings.Index(trimmed[argsOffset:], rest)
+			args = rest
+		}
+	}
+
+	// Batch CREATE takes a list of records
+	if command == "CREATE" && strings.HasPrefix(strings.TrimSpace(args), "[") {
+		records, err := ParseArgList(args)
+		if err != nil {
+			return locateParseError(err, trimmed, argsOffset)
+		}
+		for i, record := range records {
+			if records[i], err = s.resolveArgs(record); err != nil {
+				return err
+			}
+		}
+		if s.CurrentTable == "" {
+			return fmt.Errorf("%w. Use 'USE table_name' to select a table", ErrNoTableSelected)
+		}
+		return run(func() error { return HandleCreateMany(ctx, s, records) })
+	}
+
+	// Handle regular CRUD operations
+	var argObj map[string]any
+	var err error
+
+	if args != "" {
+		argObj, err = ParseArg(args)
+		if err != nil {
+			return locateParseError(err, trimmed, argsOffset)
+		}
+		if argObj, err = s.resolveArgs(argObj); err != nil {
+			return err
+		}
+	}
+	if unionTables != nil {
+		if argObj == nil {
+			argObj = make(map[string]any)
+		}
+		argObj["_from"] = unionTables
+	}
+	info.Args = argObj
+
+	// Ensure a table is selected before executing CRUD operations
+	if s.CurrentTable == "" && unionTables == nil && (command == "CREATE" || command == "GET" || command == "UPDATE" || command == "DELETE") {
+		return fmt.Errorf("%w. Use 'USE table_name' to select a table", ErrNoTableSelected)
+	}
+	if command == "CREATE" || command == "UPDATE" || command == "DELETE" {
+		if err := s.checkWritable(); err != nil {
+			return err
+		}
+	}
+
+	switch command {
+	case "CREATE":
+		return run(func() error { return HandleCreate(ctx, s, argObj) })
+	case "GET":
+		if viewName != "" {
+			return run(func() error { return handleCreateView(ctx, s, argObj, viewName) })
+		}
+		if relatedTable != "" {
+			return run(func() error { return handleRelated(ctx, s, argObj, relatedTable) })
+		}
+		return run(func() error {
+			return s.onReplica(ctx, argObj, func() error { return HandleGet(ctx, s, argObj) })
+		})
+	case "UPDATE":
+		return run(func() error { return HandleUpdate(ctx, s, argObj) })
+	case "DELETE":
+		return run(func() error { return HandleDelete(ctx, s, argObj) })
+	default:
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// handleUse handles the USE command to select database or table
+func handleUse(ctx context.Context, s *Session, name string) error {
+	// Check if name is a database
+	var exists int
+	err := s.queryRow(ctx, "SELECT 1 FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?", name).Scan(&exists)
+	if err == nil {
+		// It's a database, switch to it
+		_, err = s.exec(ctx, "USE "+name)
+		if err != nil {
+			return fmt.Errorf("failed to switch to database %s: %v", name, err)
+		}
+		s.CurrentDB = name
+		s.CurrentTable = "" // Reset table selection when changing database
+		fmt.Fprintf(s.Out, "Switched to database '%s'\n", name)
+		return nil
+	}
+
+	// Not a database, check if it's a table in the current database
+	if s.CurrentDB == "" {
+		return fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+
+	err = s.queryRow(ctx, "SELECT 1 FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+		s.CurrentDB, name).Scan(&exists)
+	if err == nil {
+		// It's a table, select it
+		s.CurrentTable = name
+		fmt.Fprintf(s.Out, "Using table '%s'\n", name)
+		return nil
+	} else if err == sql.ErrNoRows {
+		return fmt.Errorf("table '%s' does not exist in database '%s'", name, s.CurrentDB)
+	} else {
+		return err
+	}
+}
+
+// handleGetDatabases shows all available databases
+func handleGetDatabases(ctx context.Context, s *Session) error {
+	rows, err := s.query(ctx, "SHOW DATABASES")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if s.JSONOutput {
+		// Colorized JSON output
+		var databases []string
+		for rows.Next() {
+			var dbName string
+			if err := rows.Scan(&dbName); err != nil {
+				return err
+			}
+			databases = append(databases, dbName)
+		}
+
+		fmt.Fprintf(s.Out, "Databases: %s\n", ColorJSON(databases))
+	} else {
+		// MySQL-style tabular output
+		var databases []map[string]any
+		for rows.Next() {
+			var dbName string
+			if err := rows.Scan(&dbName); err != nil {
+				return err
+			}
+			databases = append(databases, map[string]any{"Database": dbName})
+		}
+
+		columns := []string{"Database"}
+		s.printTable(columns, databases)
+	}
+
+	return nil
+}
+
+// handleGetTables shows all tables in the current database
+func handleGetTables(ctx context.Context, s *Session) error {
+	if s.CurrentDB == "" {
+		return fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+
+	tables, err := s.Tables(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		// Colorized JSON output
+		fmt.Fprintf(s.Out, "Tables in %s: %s\n", s.CurrentDB, ColorJSON(tables))
+	} else {
+		// MySQL-style tabular output
+		tableTitleColumn := fmt.Sprintf("Tables_in_%s", s.CurrentDB)
+		results := make([]map[string]any, len(tables))
+		for i, tableName := range tables {
+			results[i] = map[string]any{tableTitleColumn: tableName}
+		}
+
+		columns := []string{tableTitleColumn}
+		s.printTable(columns, results)
+	}
+
+	return nil
+}
@@ -0,0 +1,860 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bogwi/noqli/pkg"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ctx = context.Background()
+
+func TestMockGet(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Ann", "ann@example.com"))
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET 1"))
+	assert.Contains(t, buf.String(), "ann@example.com")
+	assert.Contains(t, buf.String(), "1 rows in set")
+}
+
+func TestMockCreate(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	expectColumns(mock)
+	mock.ExpectExec("INSERT INTO users \\(`name`\\) VALUES \\(\\?\\)").WithArgs("Ann").
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "CREATE {name: 'Ann'}"))
+	assert.Contains(t, buf.String(), "1 row affected")
+}
+
+func TestMockCreateOperatorShapedObject(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	// Objects such as {not: 'x'} are filters only in filter position
+	expectColumns(mock)
+	mock.ExpectExec("ALTER TABLE users ADD COLUMN `preferences` JSON").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO users \\(`preferences`\\) VALUES \\(\\?\\)").WithArgs(`{"not":"x"}`).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "CREATE {preferences: {not: 'x'}}"))
+}
+
+func TestMockUpdate(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectExec("UPDATE users SET `name` = \\? WHERE `id` = \\?").WithArgs("Bob", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {id: 1, name: 'Bob'}"))
+	assert.Contains(t, buf.String(), "1 rows affected")
+}
+
+func TestMockDelete(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectExec("DELETE FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE 1"))
+	assert.Contains(t, buf.String(), "1 rows affected")
+
+	// DELETE needs the rows to delete
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "DELETE"), "requires an id")
+}
+
+func TestMockBinaryUUIDs(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	// The users of this test are keyed by UUIDs stored in 16 bytes
+	uuidColumns := func() {
+		mock.ExpectQuery("SHOW COLUMNS FROM users").WillReturnRows(
+			sqlmock.NewRows([]string{"Field", "Type", "Null", "Key", "Default", "Extra"}).
+				AddRow("id", "binary(16)", "NO", "PRI", nil, "").
+				AddRow("ref", "BINARY(16)", "YES", "", nil, "").
+				AddRow("name", "varchar(255)", "YES", "", nil, ""))
+	}
+	const (
+		ann = "3f2504e0-4f89-41d3-9a0c-0305e82c3301"
+		bob = "9a0c0305e82c33013f2504e04f8941d3"
+	)
+	toBin := "UNHEX(REPLACE(?, '-', ''))"
+
+	uuidColumns()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (`id`) VALUES (" + toBin + ")")).WithArgs(ann).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "CREATE {id: '"+ann+"'}"))
+
+	uuidColumns()
+	uuidColumns()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET `ref` = "+toBin+" WHERE `id` = "+toBin)).WithArgs(bob, ann).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {id: '"+ann+"', ref: '"+bob+"'}"))
+
+	uuidColumns()
+	uuidColumns()
+	uuidColumns()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM users WHERE `id` IN ("+toBin+","+toBin+")")).WithArgs(ann, bob).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {id: ['"+ann+"', '"+bob+"']}"))
+
+	uuidColumns()
+	uuidColumns()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `name` FROM users WHERE `id` != "+toBin+" AND `name` = ?")).WithArgs(ann, bob).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {_columns: [name], id: {not: '"+ann+"'}, name: '"+bob+"'}"))
+
+	uuidColumns()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE `id` = " + toBin)).WithArgs(ann).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {id: '"+ann+"'}"))
+}
+
+func TestMockUse(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	mock.ExpectQuery("SELECT 1 FROM INFORMATION_SCHEMA.TABLES").WithArgs("shop", "orders").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "USE orders"))
+	assert.Equal(t, "shop", session.CurrentDB)
+	assert.Equal(t, "orders", session.CurrentTable)
+}
+
+func TestMockGetTables(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectQuery("SHOW TABLES").WillReturnRows(sqlmock.NewRows([]string{"Tables_in_shop"}).AddRow("orders").AddRow("users"))
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET tables"))
+	assert.Contains(t, buf.String(), "orders")
+	assert.Contains(t, buf.String(), "users")
+}
+
+func TestMockQueryError(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	mock.ExpectQuery("SHOW TABLES").WillReturnError(errors.New("connection lost"))
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET tables"), "connection lost")
+}
+
+func TestCommandsWithoutDatabase(t *testing.T) {
+	session, _, _ := mockSession(t)
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "FETCH 1"), "invalid command")
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "GET {id: (1, x)}"))
+
+	session.CurrentTable = ""
+	assert.True(t, errors.Is(pkg.ExecuteCommand(ctx, session, "GET 1"), pkg.ErrNoTableSelected))
+}
+
+func TestMockRowNumbers(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET row_numbers on"))
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
+			AddRow(7, "Ann", "ann@example.com").
+			AddRow(9, "Bob", "bob@example.com"))
+
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET"))
+	assert.Contains(t, buf.String(), "| # | id | name |")
+	assert.Contains(t, buf.String(), "| 2 | 9  | Bob  |")
+
+	// SET row_numbers overrides the row_numbers setting
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET @uid = 7"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET row_numbers off"))
+	session.Config["row_numbers"] = "on"
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET"))
+	assert.NotContains(t, buf.String(), "| # ")
+}
+
+func TestMockRelativeTimes(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	session.Config["relative_times"] = "on"
+	seen := time.Now().Add(-3*time.Hour - time.Minute)
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRowsWithColumnDefinition(
+			sqlmock.NewColumn("id").OfType("INT", int64(0)),
+			sqlmock.NewColumn("seen_at").OfType("DATETIME", time.Time{})).
+			AddRow(7, seen)
+	}
+	for i := 0; i < 2; i++ {
+		expectColumns(mock)
+		expectColumns(mock)
+		mock.ExpectQuery("SELECT \\* FROM users").WillReturnRows(rows())
+	}
+
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET"))
+	assert.Contains(t, buf.String(), "| 7  | 3 hours ago |")
+
+	// JSON output keeps the raw value
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "get"))
+	assert.NotContains(t, buf.String(), "ago")
+	assert.Contains(t, buf.String(), seen.Format("2006-01-02T15:04:05"))
+
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET relative_times off"))
+	assert.Contains(t, buf.String(), "Relative times off")
+}
+
+func TestMockPageSize(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	var answers []string
+	session.Confirm = func() string {
+		answers = append(answers, "")
+		if len(answers) > 1 {
+			return "q"
+		}
+		return ""
+	}
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET pagesize 2"))
+	expectColumns(mock)
+	expectColumns(mock)
+	page := func(ids ...int) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id", "name"})
+		for _, id := range ids {
+			rows.AddRow(id, fmt.Sprintf("user%d", id))
+		}
+		return rows
+	}
+	mock.ExpectQuery("SELECT \\* FROM users LIMIT \\? OFFSET \\?").WithArgs(3, 0).WillReturnRows(page(1, 2, 3))
+	mock.ExpectQuery("SELECT \\* FROM users LIMIT \\? OFFSET \\?").WithArgs(3, 2).WillReturnRows(page(3, 4, 5))
+
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET"))
+	out := buf.String()
+	assert.Len(t, answers, 2)
+	assert.Equal(t, 2, strings.Count(out, "-- More (Enter/q) --"))
+	assert.Contains(t, out, "| 4  | user4 |")
+	assert.NotContains(t, out, "user5")
+	assert.Contains(t, out, "4 rows in set")
+
+	// An explicit limit is not paged
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users LIMIT \\?").WithArgs(5).WillReturnRows(page(1, 2, 3))
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {lim: 5}"))
+	assert.NotContains(t, buf.String(), "More")
+}
+
+func TestMockCopy(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	var clipboard string
+	session.Clipboard = func(text string) error {
+		clipboard = text
+		return nil
+	}
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "COPY row 1"), "no result. Run a GET first")
+
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
+			AddRow(7, "Ann", "ann@example.com").
+			AddRow(9, "Bob", nil))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET"))
+
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "COPY cell 1 email"))
+	assert.Equal(t, "ann@example.com", clipboard)
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "copy cell 2 email"))
+	assert.Equal(t, "NULL", clipboard)
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "COPY row 2"))
+	assert.JSONEq(t, `{"id": 9, "name": "Bob", "email": null}`, clipboard)
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "COPY row 3"), "rows 1 to 2")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "COPY cell 1 phone"), "unknown column")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "COPY cell 1"), "needs a column")
+}
+
+func TestMockShowCell(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	body := strings.Repeat("lorem ipsum ", 50)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
+			AddRow(7, body, []byte{0xff, 0xd8, 0xff}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET"))
+	assert.Contains(t, buf.String(), `<text, 600 B> "lorem ipsum lore…"`)
+
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SHOW cell 1 name"))
+	assert.Equal(t, body+"\n", buf.String())
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "SHOW cell 1 email"), "is binary")
+	path := filepath.Join(t.TempDir(), "email.bin")
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SHOW cell 1 email > "+path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xd8, 0xff}, data)
+}
+
+func TestMockSampleSeed(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT MIN\\(`id`\\), MAX\\(`id`\\) FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(1, 3))
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users ORDER BY RAND\\(42\\) LIMIT \\?").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {sample: 2, seed: 42}"))
+	assert.Contains(t, buf.String(), "2 rows in set")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {seed: 42}"), "seed requires sample")
+}
+
+func TestMockCountByPeriod(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DATE_FORMAT(`created_at`, '%Y-%m-%d') AS `day`, COUNT(*) AS `count` FROM users " +
+		"WHERE `status` = ? GROUP BY DATE_FORMAT(`created_at`, '%Y-%m-%d') ORDER BY DATE_FORMAT(`created_at`, '%Y-%m-%d')")).
+		WithArgs("active").
+		WillReturnRows(sqlmock.NewRows([]string{"day", "count"}).AddRow("2026-10-14", 3).AddRow("2026-10-15", 12))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {COUNT: '*', by: 'created_at/day', status: 'active'}"))
+	assert.Contains(t, buf.String(), "| 2026-10-15 | 12    |")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DATE_FORMAT(DATE_SUB(`created_at`, INTERVAL WEEKDAY(`created_at`) DAY), '%Y-%m-%d') AS `week`, MAX(`total`) AS `max`")).
+		WillReturnRows(sqlmock.NewRows([]string{"week", "max"}).AddRow("2026-10-12", 40))
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "get {max: total, by: 'created_at/week'}"))
+	assert.Contains(t, buf.String(), `"week": "2026-10-12"`)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DATE_FORMAT(`created_at`, '%Y-%m-%d') AS `day`, COUNT(*) AS `count` FROM users " +
+		"GROUP BY DATE_FORMAT(`created_at`, '%Y-%m-%d') ORDER BY DATE_FORMAT(`created_at`, '%Y-%m-%d')")).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "count"}).AddRow("2026-10-14", 3).AddRow("2026-10-15", 12))
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {COUNT: '*', by: 'created_at/day', chart: count}"))
+	assert.Contains(t, buf.String(), "| 2026-10-14 | 3     | "+strings.Repeat("#", 8)+" ")
+	assert.Contains(t, buf.String(), "| 2026-10-15 | 12    | "+strings.Repeat("#", 30)+" |")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {COUNT: '*', by: 'created_at/year'}"), "unknown period")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {by: 'created_at/day'}"), "by requires COUNT")
+}
+
+func TestMockHaving(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `category` AS `category`, COUNT(*) AS `count` FROM users "+
+		"WHERE `status` = ? GROUP BY `category` HAVING `count` > ? ORDER BY `category`")).
+		WithArgs("active", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "count"}).AddRow("books", 12))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {COUNT: '*', by: 'category', having: {count: {gt: 10}}, status: 'active'}"))
+	assert.Contains(t, buf.String(), "| books    | 12    |")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `category` AS `category`, AVG(`price`) AS `avg` FROM users "+
+		"GROUP BY `category` HAVING `avg` >= ? AND `category` NOT IN (?) ORDER BY `category`")).
+		WithArgs(20, "misc").
+		WillReturnRows(sqlmock.NewRows([]string{"category", "avg"}).AddRow("books", 25.5))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {AVG: price, by: 'category', HAVING: {avg: '>= 20', category: {not: [misc]}}}"))
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {COUNT: '*', having: {count: {gt: 10}}}"), "having requires by")
+}
+
+func TestMockGroupedChart(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `category` AS `category`, COUNT(*) AS `count` FROM users GROUP BY `category` ORDER BY `category`")).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "count"}).AddRow("books", 20).AddRow("games", 10))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {COUNT: '*', by: 'category', chart: count}"))
+	assert.Contains(t, buf.String(), "| books    | 20    | "+strings.Repeat("#", 30)+" |")
+	assert.Contains(t, buf.String(), "| games    | 10    | "+strings.Repeat("#", 15)+" ")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `category` AS `category`, SUM(`total`) AS `sum`")).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "sum"}).AddRow("books", 5))
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {SUM: total, by: 'category', chart: total}"), "not in the result")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {COUNT: '*', chart: count}"), "chart requires by with COUNT")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {AVG: price, chart: avg}"), "chart requires by with AVG")
+}
+
+func TestMockList(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `status` AS `status`, GROUP_CONCAT(`email` ORDER BY `email` SEPARATOR ', ') AS `list` " +
+		"FROM users GROUP BY `status` ORDER BY `status`")).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "list"}).AddRow("active", "a@example.com, b@example.com"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {LIST: 'email', by: 'status'}"))
+	assert.Contains(t, buf.String(), "| active | a@example.com, b@example.com |")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GROUP_CONCAT(DISTINCT `city` ORDER BY `city` SEPARATOR ' | ') AS list FROM users WHERE `country` = ?")).
+		WithArgs("NO").
+		WillReturnRows(sqlmock.NewRows([]string{"list"}).AddRow("Bergen | Oslo"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {list: 'city', distinct: true, separator: ' | ', country: 'NO'}"))
+	assert.Contains(t, buf.String(), "Bergen | Oslo")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {SUM: 'total', separator: ';'}"), "separator requires LIST")
+}
+
+func TestMockMatchCase(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM users WHERE CAST(`name` AS BINARY) = ?")).WithArgs("Ann").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Ann"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: 'Ann', case: 'sensitive'}"))
+	assert.Contains(t, buf.String(), "| 7  | Ann  |")
+
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET case insensitive"))
+	assert.Contains(t, buf.String(), "Matching case-insensitive")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) AS count FROM users WHERE CONVERT(`name` USING utf8mb4) COLLATE utf8mb4_general_ci = ?")).
+		WithArgs("ann").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {count: '*', name: 'ann'}"))
+
+	// SET case applies to DELETE filters too
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE `id` = ? AND CONVERT(`name` USING utf8mb4) COLLATE utf8mb4_general_ci = ?")).
+		WithArgs(3, "ann").WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {id: 3, name: 'ann'}"))
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {count: '*', case: 'upper'}"), "case must be")
+}
+
+func TestMockSearch(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectQuery("SELECT c.TABLE_NAME, c.COLUMN_NAME\\s+FROM INFORMATION_SCHEMA.COLUMNS").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME"}).
+			AddRow("notes", "body").
+			AddRow("users", "name").
+			AddRow("users", "email"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT *, CONCAT_WS(',', IF(`body` LIKE ?, 'body', NULL)) AS `noqli_matched` FROM `notes` WHERE (`body` LIKE ?) LIMIT ?")).
+		WithArgs("%ann@%", "%ann@%", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "body", "noqli_matched"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT *, CONCAT_WS(',', IF(`name` LIKE ?, 'name', NULL), IF(`email` LIKE ?, 'email', NULL)) AS `noqli_matched` FROM `users`")).
+		WithArgs("%ann@%", "%ann@%", "%ann@%", "%ann@%", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "noqli_matched"}).
+			AddRow(7, "Ann", "ann@example.com", "email"))
+
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SEARCH 'ann@' LIM 3"))
+	assert.Contains(t, buf.String(), "users: 1 rows matching in email")
+	assert.Contains(t, buf.String(), "| 7  | Ann  | ann@example.com |")
+	assert.NotContains(t, buf.String(), "notes")
+	assert.NotContains(t, buf.String(), "noqli_matched")
+	assert.Contains(t, buf.String(), "1 rows in 1 tables")
+
+	session.CurrentDB = ""
+	assert.True(t, errors.Is(pkg.ExecuteCommand(ctx, session, "SEARCH ann"), pkg.ErrNoDatabaseSelected))
+}
+
+func TestMockFind(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectQuery("SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME, COLUMN_TYPE\\s+FROM INFORMATION_SCHEMA.COLUMNS\\s+WHERE COLUMN_NAME LIKE \\?").
+		WithArgs("%email%", "information_schema", "mysql", "performance_schema", "sys").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE"}).
+			AddRow("shop", "users", "email", "varchar(255)").
+			AddRow("crm", "contacts", "work_email", "varchar(100)"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "FIND column 'email'"))
+	assert.Contains(t, buf.String(), "| crm      | contacts | work_email | varchar(100) |")
+
+	mock.ExpectQuery("SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE, TABLE_ROWS\\s+FROM INFORMATION_SCHEMA.TABLES").
+		WithArgs("order%", "information_schema", "mysql", "performance_schema", "sys").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "TABLE_TYPE", "TABLE_ROWS"}))
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "FIND table 'order%'"))
+	assert.Contains(t, buf.String(), `No table matches "order%"`)
+}
+
+func TestMockUnknownColumn(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	session.Confirm = func() string { return "n" }
+	expectColumns(mock)
+	err := pkg.ExecuteCommand(ctx, session, "CREATE {name: 'Ann', emial: 'ann@example.com'}")
+	assert.True(t, errors.Is(err, pkg.ErrConfirmationDeclined))
+	assert.Contains(t, buf.String(), "Column 'emial' does not exist, did you mean 'email'?")
+
+	// Filters on a mistyped column name the column meant
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `emial` = \\?").
+		WillReturnError(&mysql.MySQLError{Number: 1054, Message: "Unknown column 'emial' in 'where clause'"})
+	expectColumns(mock)
+	err = pkg.ExecuteCommand(ctx, session, "GET {emial: 'ann@example.com'}")
+	assert.ErrorContains(t, err, "(did you mean 'email'?)")
+}
+
+func TestMockInvalidValues(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectTypes := func() {
+		mock.ExpectQuery("SHOW COLUMNS FROM users").WillReturnRows(
+			sqlmock.NewRows([]string{"Field", "Type", "Null", "Key", "Default", "Extra"}).
+				AddRow("id", "int", "NO", "PRI", nil, "auto_increment").
+				AddRow("name", "varchar(5)", "NO", "", nil, "").
+				AddRow("age", "tinyint unsigned", "YES", "", nil, "").
+				AddRow("price", "decimal(5,2)", "YES", "", nil, "").
+				AddRow("status", "enum('open','closed')", "YES", "", nil, "").
+				AddRow("born", "date", "YES", "", nil, ""))
+	}
+	for command, message := range map[string]string{
+		"CREATE {name: 'Annabel'}":                      "invalid value for column 'name': 7 characters is longer than varchar(5) allows",
+		"CREATE {name: null}":                           "invalid value for column 'name': the column cannot be NULL",
+		"CREATE {name: 'Ann', age: 300}":                "invalid value for column 'age': 300 is out of range for tinyint unsigned (0 to 255)",
+		"CREATE {name: 'Ann', age: 'old'}":              `invalid value for column 'age': "old" is not a number`,
+		"CREATE {name: 'Ann', price: 1000}":             "invalid value for column 'price': 1000 has more than 3 digits before the decimal point for decimal(5,2)",
+		"CREATE {name: 'Ann', status: 'pending'}":       `invalid value for column 'status': "pending" is not one of 'open', 'closed'`,
+		"CREATE {name: 'Ann', born: '2024-02-30'}":      `invalid value for column 'born': "2024-02-30" is not a valid date`,
+		"CREATE [{name: 'Ann'}, {name: 'Bartholomew'}]": "record 2: invalid value for column 'name'",
+	} {
+		expectTypes()
+		err := pkg.ExecuteCommand(ctx, session, command)
+		assert.ErrorIs(t, err, pkg.ErrInvalidValue, command)
+		assert.ErrorContains(t, err, message, command)
+	}
+
+	// Values MySQL accepts are written
+	expectTypes()
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(1, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "CREATE {name: 'Ann', age: 42, price: 999.99, status: 'OPEN', born: '2000-01-31'}"))
+}
+
+func TestMockExplain(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	expectColumns(mock)
+	mock.ExpectQuery("EXPLAIN SELECT \\* FROM users WHERE `status` = \\?").WithArgs("open").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "select_type", "table", "type", "possible_keys", "key", "rows", "filtered", "Extra"}).
+			AddRow(1, "SIMPLE", "users", "ALL", nil, nil, 12000, 10.0, "Using where"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "EXPLAIN GET {status: 'open', chart: 'name'}"))
+	assert.Equal(t, "SIMPLE #1\n└── users: full table scan (ALL), no usable index, ~12000 rows, 10% kept [Using where]\n", buf.String())
+}
+
+func TestMockProductionUnlock(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	session.Environment = pkg.EnvironmentProduction
+	assert.True(t, errors.Is(pkg.ExecuteCommand(ctx, session, "CREATE {name: 'Ann'}"), pkg.ErrReadOnly))
+
+	session.Confirm = func() string { return "y" }
+	assert.True(t, errors.Is(pkg.ExecuteCommand(ctx, session, "UNLOCK"), pkg.ErrConfirmationDeclined))
+	assert.Contains(t, buf.String(), "Type 'shop' to allow writes for 5 minutes")
+
+	session.Confirm = func() string { return "shop" }
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "UNLOCK"))
+	assert.Contains(t, buf.String(), "Writes allowed until")
+	expectColumns(mock)
+	mock.ExpectExec("INSERT INTO users \\(`name`\\) VALUES \\(\\?\\)").WithArgs("Ann").
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "CREATE {name: 'Ann'}"))
+}
+
+func TestMockReadReplicas(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	lagging, laggingMock, _ := mockSession(t)
+	current, currentMock, _ := mockSession(t)
+	session.Replicas = pkg.NewReplicas([]pkg.DBTX{lagging.DB, current.DB}, []string{"r1", "r2"}, 5*time.Second)
+	replicaStatus := func(seconds any) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"Channel_Name", "Seconds_Behind_Source"}).AddRow("", seconds)
+	}
+
+	// The lagging replica is skipped
+	laggingMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(replicaStatus(30))
+	currentMock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(replicaStatus(0))
+	expectColumns(currentMock)
+	expectColumns(currentMock)
+	currentMock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Ann", "ann@example.com"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET 1"))
+	assert.Contains(t, buf.String(), "ann@example.com")
+
+	// primary: true reads a write just made from the primary
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Bob", "bob@example.com"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {id: 1, primary: true}"))
+	assert.Contains(t, buf.String(), "bob@example.com")
+}
+
+func TestMockThrottle(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET throttle 2"))
+	assert.Contains(t, buf.String(), "Throttling writes to 2 rows/sec")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "SET throttle fast"), "invalid throttle")
+
+	// Throttled deletes run in batches of ids
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT MAX\\(`id`\\) FROM \\(SELECT `id` FROM users WHERE `id` IN \\(\\?,\\?,\\?\\) ORDER BY `id` ASC LIMIT \\?\\) AS batch").WithArgs(1, 2, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"MAX(`id`)"}).AddRow(2))
+	mock.ExpectExec("DELETE FROM users WHERE `id` IN \\(\\?,\\?,\\?\\) AND `id` <= \\?").WithArgs(1, 2, 3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	// Waiting a second before the next batch is cut short by cancelling
+	cancelled, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := pkg.ExecuteCommand(cancelled, session, "DELETE {id: [1, 2, 3]}")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "stopped after 2 rows")
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Tables without an id are deleted from at once, as UPDATE does
+	session.Confirm = func() string { return "y" }
+	mock.ExpectQuery("SHOW COLUMNS FROM users").WillReturnRows(
+		sqlmock.NewRows([]string{"Field", "Type", "Null", "Key", "Default", "Extra"}).
+			AddRow("name", "varchar(255)", "YES", "", nil, ""))
+	mock.ExpectExec("DELETE FROM users WHERE `name` = \\?").WithArgs("Ann").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {name: 'Ann'}"))
+}
+
+func TestMockRetry(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	mock.ExpectExec("DELETE FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+	mock.ExpectExec("DELETE FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE 1"))
+	assert.Contains(t, buf.String(), "1 rows affected")
+
+	// A write whose connection dropped may have been applied, so it fails
+	mock.ExpectExec("DELETE FROM users WHERE `id` = \\?").WithArgs(2).WillReturnError(mysql.ErrInvalidConn)
+	assert.ErrorIs(t, pkg.ExecuteCommand(ctx, session, "DELETE 2"), mysql.ErrInvalidConn)
+
+	// retries = 0 turns retrying off
+	session.Config["retries"] = "0"
+	mock.ExpectExec("DELETE FROM users WHERE `id` = \\?").WithArgs(3).
+		WillReturnError(&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"})
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "DELETE 3"), "Lock wait timeout")
+}
+
+func TestMockEdit(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	var edited string
+	session.Editor = func(text string) (string, error) {
+		edited = text
+		return strings.Replace(text, `"ann@example.com"`, `"ann@example.org"`, 1), nil
+	}
+	session.Confirm = func() string { return "y" }
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(42, "Ann", "ann@example.com"))
+	expectColumns(mock)
+	mock.ExpectExec("UPDATE users SET `email` = \\? WHERE `id` = \\?").WithArgs("ann@example.org", 42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "EDIT 42"))
+	assert.Equal(t, "{\n  \"id\": 42,\n  \"email\": \"ann@example.com\",\n  \"name\": \"Ann\"\n}\n", edited)
+	assert.Contains(t, buf.String(), "Changes to id 42:\n  email: \"ann@example.com\" -> \"ann@example.org\"\n")
+	assert.Contains(t, buf.String(), "1 rows affected")
+
+	// Changing the id is refused
+	session.Editor = func(text string) (string, error) { return strings.Replace(text, "42", "43", 1), nil }
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(42, "Ann", "ann@example.org"))
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "EDIT 42"), "cannot change the id")
+}
+
+func TestMockWatchTable(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
+		AddRow(1, "Ann", "ann@example.com").
+		AddRow(2, "Bob", "bob@example.com"))
+	mock.ExpectQuery("SELECT \\* FROM users").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
+		AddRow(1, "Ann", "ann@example.org").
+		AddRow(3, "Cy", nil))
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		for mock.ExpectationsWereMet() != nil {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+	require.NoError(t, pkg.ExecuteCommand(watchCtx, session, "WATCH TABLE users 5ms"))
+	output := buf.String()
+	assert.Contains(t, output, "Watching users for changes from reading it every 5ms.")
+	assert.Regexp(t, `INSERT id=3, name='Cy', email=NULL\n`, output)
+	assert.Regexp(t, `UPDATE id=1, email: 'ann@example.com' -> 'ann@example.org'\n`, output)
+	assert.Regexp(t, `DELETE id=2, name='Bob', email='bob@example.com'\n`, output)
+}
+
+func TestMockTableChecksum(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	session.CurrentTable = "odd`name"
+	mock.ExpectQuery(regexp.QuoteMeta("CHECKSUM TABLE `odd``name`")).
+		WillReturnRows(sqlmock.NewRows([]string{"Table", "Checksum"}).AddRow("shop.odd`name", 42))
+	checksum, err := session.TableChecksum(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), checksum)
+}
+
+func TestMockOrObjects(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM users WHERE (`status` = ? OR (`priority` = ? AND (`owner` IS NULL OR `due` < ?)))")).
+		WithArgs("active", "high", "2024-06-01 00:00:00").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Ann", "ann@example.com"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session,
+		"GET {or: [{status: 'active'}, {priority: 'high', or: [{owner: null}, {due: < 2024-06-01}]}]}"))
+}
+
+func TestMockComparisons(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectExec("UPDATE users SET `email` = \\? WHERE `id` > \\?").WithArgs(nil, 100).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {id: {gt: 100}, email: null}"))
+
+	mock.ExpectExec("DELETE FROM users WHERE `id` <= \\?").WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {id: '<= 5'}"))
+
+	// Quoted comparisons are values to set in UPDATE, except on the id
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectExec("UPDATE users SET `name` = \\? WHERE `id` = \\?").WithArgs("> 3", 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {id: 5, name: '> 3'}"))
+
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectExec("UPDATE users SET `name` = \\? WHERE `id` > \\?").WithArgs("Ann", 3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {id: '> 3', name: 'Ann'}"))
+}
+
+func TestMockExclusions(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectExec("UPDATE users SET `email` = \\? WHERE `id` NOT IN \\(\\?,\\?\\) AND `name` != \\?").
+		WithArgs(nil, 1, 2, "Ann").WillReturnResult(sqlmock.NewResult(0, 3))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {id: {notin: [1, 2]}, name: {not: 'Ann'}, email: null}"))
+
+	mock.ExpectExec("DELETE FROM users WHERE `id` > \\? AND `name` != \\?").WithArgs(0, "Ann").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {id: {gt: 0}, name: {not: 'Ann'}}"))
+
+	// Exclusions of strings match case as equality does
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM users WHERE CAST(`name` AS BINARY) != ?")).WithArgs("Ann").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: {not: 'Ann'}, case: 'sensitive'}"))
+
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "SET case sensitive"))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM users WHERE `id` = ? AND CAST(`name` AS BINARY) != ?")).WithArgs(3, "Ann").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {id: 3, name: {not: 'Ann'}}"))
+}
+
+func TestMockLike(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `name` LIKE \\?").WithArgs("Smi%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Smith", "smith@example.com"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: {like: 'Smi%'}}"))
+
+	// search keeps matching every column
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE \\(`id` LIKE \\? OR `name` LIKE \\? OR `email` LIKE \\?\\)").
+		WithArgs("%smi%", "%smi%", "%smi%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Smith", "smith@example.com"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {search: smi}"))
+}
+
+func TestMockNullFilters(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectExec("UPDATE users SET `name` = \\? WHERE `email` IS NULL").WithArgs("Unknown").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {email: {notnull: false}, name: 'Unknown'}"))
+
+	mock.ExpectExec("DELETE FROM users WHERE `email` IS NULL AND `id` IN \\(\\?,\\?\\) AND `name` IS NOT NULL").WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {id: [1, 2], email: null, name: {notnull: true}}"))
+
+	// Without an id DELETE asks before removing every matching row
+	session.Confirm = func() string { return "y" }
+	mock.ExpectExec("DELETE FROM users WHERE `email` IS NULL").WillReturnResult(sqlmock.NewResult(0, 4))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {email: {notnull: false}}"))
+
+	session.Confirm = func() string { return "n" }
+	assert.ErrorIs(t, pkg.ExecuteCommand(ctx, session, "DELETE {email: null}"), pkg.ErrConfirmationDeclined)
+}
+
+func TestMockRegexp(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE REGEXP_LIKE\\(`name`, \\?, \\?\\)").WithArgs("^a.*son$", "c").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: {regexp: '^a.*son$'}, case: 'sensitive'}"))
+}
+
+func TestMockDateRanges(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `created_at` >= \\? AND `created_at` <= \\?").
+		WithArgs("2024-01-01 00:00:00", "2024-03-31 23:59:59.999999").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {created_at: ('2024-01-01', '2024-03-31')}"))
+
+	// An end bound with a time is kept as it is
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `created_at` >= \\? AND `created_at` <= \\?").
+		WithArgs("2024-01-01 00:00:00", "2024-03-31 12:00:00").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {created_at: ('2024-01-01', '2024-03-31 12:00')}"))
+
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `created_at` >= \\?").WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {created_at: {last: '7d'}}"))
+
+	expectColumns(mock)
+	err := pkg.ExecuteCommand(ctx, session, "GET {created_at: {last: 'week'}}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid filter for field created_at")
+}
+
+func TestMockSortKeys(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM users ORDER BY `status` ASC, `name` ASC")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {up: ['status', 'name']}"))
+
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM users ORDER BY `status` ASC, `score` DESC, `id` ASC LIMIT ?")).WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {order: ['status asc', 'score DESC', id], lim: 10}"))
+
+	expectColumns(mock)
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {order: ['score downwards']}"), "invalid order")
+}
+
+func TestMockDistinct(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	expectColumns(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `name` FROM users ORDER BY `name` ASC")).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Ann").AddRow("Bob"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name, distinct: true, up: name}"))
+	assert.Contains(t, buf.String(), "2 rows in set")
+
+	expectColumns(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `name`, `email` FROM users WHERE `id` > ?")).WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "email"}))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {_columns: [name, email], DISTINCT: true, id: > 10}"))
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {name, distinct: 'yes'}"), "distinct requires true or false")
+}
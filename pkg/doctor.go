@@ -0,0 +1,331 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/joho/godotenv"
+)
+
+// CheckStatus is the outcome of a doctor check
+type CheckStatus string
+
+// Check outcomes, from healthy to broken
+const (
+	CheckOK   CheckStatus = "OK"
+	CheckWarn CheckStatus = "WARN"
+	CheckFail CheckStatus = "FAIL"
+)
+
+// Check is the result of one doctor check, with a fix to apply when it did
+// not pass
+type Check struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+	Fix    string
+}
+
+// CheckEnvFile checks that the .env file at path exists, parses and sets
+// the connection variables
+func CheckEnvFile(path string) Check {
+	check := Check{Name: ".env file"}
+	env, err := godotenv.Read(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		check.Status, check.Detail = CheckWarn, path+" not found"
+		check.Fix = "copy env.example to .env and fill in DB_HOST, DB_USER, DB_PASSWORD and DB_NAME, or connect with --profile"
+		return check
+	case err != nil:
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("%s: %v", path, err)
+		check.Fix = "write one KEY=value setting per line"
+		return check
+	}
+
+	var missing []string
+	for _, key := range []string{"DB_HOST", "DB_USER", "DB_PASSWORD"} {
+		if _, ok := env[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		check.Status, check.Detail = CheckWarn, fmt.Sprintf("%s does not set %s", path, strings.Join(missing, ", "))
+		check.Fix = "add " + strings.Join(missing, "=..., ") + "=... to " + path
+		return check
+	}
+	check.Status, check.Detail = CheckOK, path
+	return check
+}
+
+// CheckConfigFile loads the config file at path and validates its settings.
+// It returns the config, empty when it could not be read.
+func CheckConfigFile(path string) (Config, Check) {
+	check := Check{Name: "config file"}
+	config, err := LoadConfig(path)
+	if err != nil {
+		check.Status, check.Detail = CheckFail, err.Error()
+		check.Fix = "fix or remove the line; settings are written as key = value, grouped in [section] blocks"
+		return make(Config), check
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		check.Status, check.Detail = CheckOK, path+" not found, using the defaults"
+		return config, check
+	}
+
+	var problems []string
+	validate := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	_, err = config.Location()
+	validate(err)
+	_, err = SlowLogHookFromConfig(config)
+	validate(err)
+	_, err = NotifyHookFromConfig(config)
+	validate(err)
+	_, err = IndexAdvisorHookFromConfig(config)
+	validate(err)
+	_, _, _, err = debugLogSettings(config)
+	validate(err)
+	_, err = (&Session{Config: config}).batchSize()
+	validate(err)
+	if len(problems) > 0 {
+		check.Status, check.Detail = CheckFail, strings.Join(problems, "; ")
+		check.Fix = "correct these settings in " + path
+		return config, check
+	}
+	check.Status, check.Detail = CheckOK, path
+	return config, check
+}
+
+// CheckProfile checks that the [profile.name] section of config is usable,
+// returning the connection settings it gives over base
+func CheckProfile(config Config, name string, base Connection) (Connection, Check) {
+	check := Check{Name: "profile " + name}
+	conn, err := config.Profile(name, base)
+	if err != nil {
+		check.Status, check.Detail = CheckFail, err.Error()
+		check.Fix = fmt.Sprintf("add host, user, password and database to a [profile.%s] section of %s", name, DefaultConfigPath())
+		return conn, check
+	}
+	check.Status, check.Detail = CheckOK, fmt.Sprintf("%s@%s", conn.User, conn.Host)
+	return conn, check
+}
+
+// CheckPassword checks that the password of conn can be read when it names
+// a keychain entry or secret, returning conn with the password read
+func CheckPassword(conn Connection) (Connection, Check) {
+	check := Check{Name: "password"}
+	if _, _, ok, _ := secretLookup(conn.Password); !ok {
+		check.Status, check.Detail = CheckOK, "plain text"
+		return conn, check
+	}
+	password, err := ResolvePassword(conn.Password)
+	if err != nil {
+		check.Status, check.Detail = CheckFail, err.Error()
+		check.Fix = "store the password with noqli keychain NAME, or check the secret and that its command line tool is installed and signed in"
+		return conn, check
+	}
+	check.Status, check.Detail = CheckOK, "read from "+conn.Password
+	conn.Password = password
+	return conn, check
+}
+
+// CheckConnectionSettings checks that conn names a server and a user
+func CheckConnectionSettings(conn Connection) Check {
+	check := Check{Name: "connection settings"}
+	var missing []string
+	if conn.Host == "" {
+		missing = append(missing, "DB_HOST")
+	}
+	if conn.User == "" {
+		missing = append(missing, "DB_USER")
+	}
+	if len(missing) > 0 {
+		check.Status, check.Detail = CheckFail, strings.Join(missing, " and ")+" not set"
+		check.Fix = "set " + strings.Join(missing, " and ") + " in .env or the selected profile"
+		return check
+	}
+	check.Status, check.Detail = CheckOK, fmt.Sprintf("%s@%s", conn.User, conn.Host)
+	if conn.Database == "" {
+		check.Status = CheckWarn
+		check.Detail += ", no database"
+		check.Fix = "set DB_NAME to start in a database, or pick one with USE"
+	}
+	return check
+}
+
+// CheckConnection turns the error of connecting to conn into a check,
+// suggesting a fix for the usual causes
+func CheckConnection(conn Connection, err error) Check {
+	check := Check{Name: "connection"}
+	if err == nil {
+		check.Status, check.Detail = CheckOK, "connected to "+conn.Host
+		return check
+	}
+	check.Status, check.Detail = CheckFail, err.Error()
+
+	var mysqlErr *mysql.MySQLError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &mysqlErr) && mysqlErr.Number == 1045:
+		check.Fix = fmt.Sprintf("check DB_USER and DB_PASSWORD; the server refused %s", conn.User)
+	case errors.As(err, &mysqlErr) && mysqlErr.Number == 1049:
+		check.Fix = fmt.Sprintf("create the database with CREATE DATABASE %s, or correct DB_NAME", quoteIdent(conn.Database))
+	case errors.As(err, &mysqlErr) && (mysqlErr.Number == 1044 || mysqlErr.Number == 1130):
+		check.Fix = fmt.Sprintf("ask an administrator to grant %s access from this host", conn.User)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		check.Fix = fmt.Sprintf("the server at %s did not answer; check DB_HOST and the firewall", conn.Host)
+	case errors.As(err, &netErr), strings.Contains(err.Error(), "connection refused"):
+		check.Fix = fmt.Sprintf("check that MySQL runs and listens at %s (host:port in DB_HOST)", conn.Host)
+	default:
+		check.Fix = "check the connection settings in .env or the selected profile"
+	}
+	return check
+}
+
+// requiredPrivileges are the privileges NoQLi's commands need on a database
+var requiredPrivileges = []string{"SELECT", "INSERT", "UPDATE", "DELETE"}
+
+// grantRegex matches a GRANT line of SHOW GRANTS on a database or table
+var grantRegex = regexp.MustCompile("(?i)^GRANT (.+?) ON (\\S+) TO ")
+
+// grantScopeMatches reports whether the scope of a grant, such as *.* or
+// `shop`.*, covers every table of database
+func grantScopeMatches(scope, database string) bool {
+	if scope == "*.*" {
+		return true
+	}
+	if !strings.HasSuffix(scope, ".*") {
+		return false
+	}
+	name := strings.Trim(strings.TrimSuffix(scope, ".*"), "`")
+	if name == database {
+		return true
+	}
+	// Database names in grants may use the LIKE wildcards % and _
+	pattern := regexp.QuoteMeta(name)
+	pattern = strings.NewReplacer(`\\_`, "_", "%", ".*", "_", ".").Replace(pattern)
+	matched, _ := regexp.MatchString("^"+pattern+"$", database)
+	return matched
+}
+
+// CheckPrivileges checks that the current user may read and write every
+// table of database, reading SHOW GRANTS
+func (s *Session) CheckPrivileges(ctx context.Context, database string) Check {
+	check := Check{Name: "privileges"}
+	if database == "" {
+		check.Status, check.Detail = CheckWarn, "no database selected, skipped"
+		check.Fix = "set DB_NAME to check the privileges on it"
+		return check
+	}
+	rows, err := s.query(ctx, "SHOW GRANTS")
+	if err != nil {
+		check.Status, check.Detail = CheckWarn, err.Error()
+		return check
+	}
+	defer rows.Close()
+
+	granted := make(map[string]bool)
+	tableGrants := false
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			check.Status, check.Detail = CheckWarn, err.Error()
+			return check
+		}
+		m := grantRegex.FindStringSubmatch(grant)
+		if m == nil {
+			continue
+		}
+		if !grantScopeMatches(m[2], database) {
+			if strings.HasPrefix(strings.Trim(m[2], "`"), database+"`.") {
+				tableGrants = true
+			}
+			continue
+		}
+		for _, privilege := range strings.Split(m[1], ",") {
+			privilege = strings.ToUpper(strings.TrimSpace(privilege))
+			if privilege == "ALL" || privilege == "ALL PRIVILEGES" {
+				for _, p := range requiredPrivileges {
+					granted[p] = true
+				}
+			}
+			granted[privilege] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		check.Status, check.Detail = CheckWarn, err.Error()
+		return check
+	}
+
+	var missing []string
+	for _, p := range requiredPrivileges {
+		if !granted[p] {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		check.Status, check.Detail = CheckOK, strings.Join(requiredPrivileges, ", ")+" on "+database
+		return check
+	}
+	check.Status = CheckWarn
+	check.Detail = fmt.Sprintf("missing %s on %s", strings.Join(missing, ", "), database)
+	if tableGrants {
+		check.Detail += " (some tables have their own grants)"
+	}
+	check.Fix = fmt.Sprintf("GRANT %s ON %s.* TO CURRENT_USER", strings.Join(missing, ", "), quoteIdent(database))
+	return check
+}
+
+// CheckWritableDir checks that files can be created in dir, where the
+// history, templates and logs are kept
+func CheckWritableDir(name, dir string) Check {
+	check := Check{Name: name}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		check.Status, check.Detail = CheckFail, err.Error()
+		check.Fix = fmt.Sprintf("create %s and make it writable: mkdir -p %s && chmod u+rwx %s", dir, dir, dir)
+		return check
+	}
+	file, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		check.Status, check.Detail = CheckFail, err.Error()
+		check.Fix = fmt.Sprintf("make %s writable: chmod u+rwx %s", dir, dir)
+		return check
+	}
+	file.Close()
+	os.Remove(file.Name())
+	check.Status, check.Detail = CheckOK, dir
+	return check
+}
+
+// DefaultHistoryDir returns the directory of the command history and the
+// other files NoQLi keeps (~/.noqli)
+func DefaultHistoryDir() string {
+	return filepath.Dir(DefaultConfigPath())
+}
+
+// PrintChecks writes one line per check, followed by its fix when it did
+// not pass, and reports whether any check failed
+func PrintChecks(w io.Writer, checks []Check) bool {
+	failed := false
+	for _, check := range checks {
+		fmt.Fprintf(w, "[%-4s] %s: %s\n", check.Status, check.Name, check.Detail)
+		if check.Fix != "" && check.Status != CheckOK {
+			fmt.Fprintf(w, "       fix: %s\n", check.Fix)
+		}
+		if check.Status == CheckFail {
+			failed = true
+		}
+	}
+	return failed
+}
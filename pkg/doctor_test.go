@@ -0,0 +1,124 @@
+package pkg_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bogwi/noqli/pkg"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPrivileges(t *testing.T) {
+	session, mock, _ := mockSession(t)
+	mock.ExpectQuery("SHOW GRANTS").WillReturnRows(sqlmock.NewRows([]string{"Grants"}).
+		AddRow("GRANT USAGE ON *.* TO `app`@`%`").
+		AddRow("GRANT SELECT, INSERT ON `shop`.* TO `app`@`%`").
+		AddRow("GRANT UPDATE ON `shop`.`users` TO `app`@`%`"))
+
+	check := session.CheckPrivileges(ctx, "shop")
+	assert.Equal(t, pkg.CheckWarn, check.Status)
+	assert.Equal(t, "missing UPDATE, DELETE on shop (some tables have their own grants)", check.Detail)
+	assert.Equal(t, "GRANT UPDATE, DELETE ON `shop`.* TO CURRENT_USER", check.Fix)
+
+	mock.ExpectQuery("SHOW GRANTS").WillReturnRows(sqlmock.NewRows([]string{"Grants"}).
+		AddRow("GRANT ALL PRIVILEGES ON `sho%`.* TO `app`@`%`"))
+	assert.Equal(t, pkg.CheckOK, session.CheckPrivileges(ctx, "shop").Status)
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("timezone = Mars/Olympus\nbatch_size = 0\n"), 0644))
+
+	_, check := pkg.CheckConfigFile(path)
+	assert.Equal(t, pkg.CheckFail, check.Status)
+	assert.Contains(t, check.Detail, `invalid timezone "Mars/Olympus"`)
+	assert.Contains(t, check.Detail, `invalid batch_size "0"`)
+
+	require.NoError(t, os.WriteFile(path, []byte("timezone = UTC\n"), 0644))
+	config, check := pkg.CheckConfigFile(path)
+	assert.Equal(t, pkg.CheckOK, check.Status)
+	assert.Equal(t, "UTC", config.Get("timezone"))
+}
+
+func TestCheckEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	assert.Equal(t, pkg.CheckWarn, pkg.CheckEnvFile(path).Status)
+
+	require.NoError(t, os.WriteFile(path, []byte("DB_HOST=localhost\nDB_USER=app\n"), 0644))
+	check := pkg.CheckEnvFile(path)
+	assert.Equal(t, pkg.CheckWarn, check.Status)
+	assert.Contains(t, check.Detail, "does not set DB_PASSWORD")
+}
+
+func TestCheckConnection(t *testing.T) {
+	conn := pkg.Connection{Host: "localhost:3306", User: "app", Database: "shop"}
+	assert.Equal(t, pkg.CheckOK, pkg.CheckConnection(conn, nil).Status)
+
+	check := pkg.CheckConnection(conn, &mysql.MySQLError{Number: 1049, Message: "Unknown database 'shop'"})
+	assert.Equal(t, pkg.CheckFail, check.Status)
+	assert.Contains(t, check.Fix, "CREATE DATABASE `shop`")
+}
+
+func TestResolvePassword(t *testing.T) {
+	// Fake vault and aws tools echoing what they were asked for
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vault"), []byte("#!/bin/sh\necho \"$4:$3\"\n"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "aws"), []byte("#!/bin/sh\necho '{\"username\": \"app\", \"password\": \"s3cret\"}'\n"), 0755))
+	t.Setenv("PATH", dir)
+
+	password, err := pkg.ResolvePassword("plain:text")
+	require.NoError(t, err)
+	assert.Equal(t, "plain:text", password)
+
+	password, err = pkg.ResolvePassword("vault:secret/noqli")
+	require.NoError(t, err)
+	assert.Equal(t, "secret/noqli:-field=password", password)
+
+	password, err = pkg.ResolvePassword("aws:prod/noqli")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", password)
+	_, err = pkg.ResolvePassword("aws:prod/noqli#token")
+	assert.ErrorContains(t, err, "has no token field")
+
+	conn, check := pkg.CheckPassword(pkg.Connection{Password: "keychain:"})
+	assert.Equal(t, pkg.CheckFail, check.Status)
+	assert.Equal(t, "keychain:", conn.Password)
+}
+
+func TestStoreKeychainPasswordKeepsSecretOffCommandLine(t *testing.T) {
+	const secret = `s3cret "pass"`
+	for _, goos := range []string{"darwin", "windows", "linux"} {
+		cmd := pkg.KeychainStoreCommand(goos, "prod", secret)
+		for _, arg := range cmd.Args {
+			assert.NotContains(t, arg, "s3cret", goos)
+		}
+		input, err := io.ReadAll(cmd.Stdin)
+		require.NoError(t, err)
+		assert.Contains(t, string(input), "s3cret", goos)
+	}
+	darwin := pkg.KeychainStoreCommand("darwin", "prod", secret)
+	input, _ := io.ReadAll(darwin.Stdin)
+	assert.Equal(t, `add-generic-password -U -s noqli -a "prod" -w "s3cret \"pass\""`+"\n", string(input))
+
+	if runtime.GOOS != "linux" {
+		t.Skip("secret-tool is only used on Linux")
+	}
+	// A fake secret-tool records the arguments and input it was run with
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > \"$0.args\"\nread -r line\nprintf '%s' \"$line\" > \"$0.stdin\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0755))
+	t.Setenv("PATH", dir)
+	require.NoError(t, pkg.StoreKeychainPassword("prod", secret))
+	args, err := os.ReadFile(filepath.Join(dir, "secret-tool.args"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(args), "s3cret")
+	stdin, err := os.ReadFile(filepath.Join(dir, "secret-tool.stdin"))
+	require.NoError(t, err)
+	assert.Equal(t, secret, string(stdin))
+}
@@ -0,0 +1,182 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GetDupesCommandRegex returns the regex for DUPES commands
+func GetDupesCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(DUPES)(?:\s+(.*))?$`)
+}
+
+// dupesOptions reads the by and keep arguments of a DUPES command, removing
+// them from args and leaving only the filters
+func dupesOptions(args map[string]any) (by []string, keep string, err error) {
+	var byValue, keepValue any
+	for _, key := range []string{"by", "BY"} {
+		if v, ok := args[key]; ok {
+			byValue = v
+			delete(args, key)
+		}
+	}
+	for _, key := range []string{"keep", "KEEP"} {
+		if v, ok := args[key]; ok {
+			keepValue = v
+			delete(args, key)
+		}
+	}
+
+	switch v := byValue.(type) {
+	case string:
+		by = []string{v}
+	case []any:
+		for _, item := range v {
+			col, ok := item.(string)
+			if !ok {
+				return nil, "", fmt.Errorf("by must list column names")
+			}
+			by = append(by, col)
+		}
+	}
+	if len(by) == 0 {
+		return nil, "", fmt.Errorf("DUPES requires the columns to compare, e.g. DUPES {by: [email]}")
+	}
+
+	if keepValue != nil {
+		keep, _ = keepValue.(string)
+		keep = strings.ToLower(strings.Join(strings.Fields(keep), " "))
+		if keep != "lowest id" && keep != "highest id" {
+			return nil, "", fmt.Errorf("keep must be 'lowest id' or 'highest id'")
+		}
+	}
+	return by, keep, nil
+}
+
+// handleDupes lists the groups of rows sharing the values of the by columns.
+// With keep, it offers to delete every row of a group but the one with the
+// lowest or highest id. Rows with a NULL in a by column are not duplicates.
+func handleDupes(ctx context.Context, s *Session, args map[string]any) error {
+	if s.CurrentTable == "" {
+		return fmt.Errorf("%w. Use 'USE table_name' to select a table", ErrNoTableSelected)
+	}
+	if args == nil {
+		args = make(map[string]any)
+	}
+	by, keep, err := dupesOptions(args)
+	if err != nil {
+		return err
+	}
+
+	columns, err := getColumns(ctx, s)
+	if err != nil {
+		return err
+	}
+	hasID := containsString(columns, "id")
+	if keep != "" && !hasID {
+		return fmt.Errorf("keep needs an id column")
+	}
+
+	quoted := make([]string, len(by))
+	builder := NewQueryBuilder(s.CurrentTable).Where(args)
+	for i, col := range by {
+		if !containsString(columns, col) {
+			return fmt.Errorf("unknown column %q", col)
+		}
+		quoted[i] = quoteIdent(col)
+		builder.WhereRaw(quoted[i] + " IS NOT NULL")
+	}
+	clause, values := builder.WhereClause()
+	if builder.err != nil {
+		return builder.err
+	}
+
+	exprs := append(append([]string{}, quoted...), "COUNT(*) AS count")
+	if hasID {
+		exprs = append(exprs, "GROUP_CONCAT(`id` ORDER BY `id`) AS ids")
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s GROUP BY %s HAVING COUNT(*) > 1 ORDER BY count DESC, %s",
+		strings.Join(exprs, ", "), s.CurrentTable, clause, strings.Join(quoted, ", "), strings.Join(quoted, ", "))
+
+	rows, err := s.query(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	resultColumns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return err
+	}
+	display, err := s.newColumnFormatter(rows, nil)
+	if err != nil {
+		rows.Close()
+		return err
+	}
+	var groups []map[string]any
+	var extra int64
+	for rows.Next() {
+		entry, err := scanRecord(rows, resultColumns)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		display.format(entry)
+		count, _ := toInt64(entry["count"])
+		extra += count - 1
+		groups = append(groups, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.recordRows(int64(len(groups)))
+
+	if len(groups) == 0 {
+		fmt.Fprintln(s.Out, "No duplicates found")
+		return nil
+	}
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Duplicates: %s\n", ColorJSON(groups))
+	} else {
+		s.printTable(resultColumns, groups)
+	}
+	if keep == "" {
+		return nil
+	}
+
+	// Number the rows of each group from the one kept, and delete the others.
+	// The derived table lets MySQL read the table it deletes from.
+	order := "`id`"
+	if keep == "highest id" {
+		order = "`id` DESC"
+	}
+	statement := fmt.Sprintf("DELETE FROM %s WHERE `id` IN (SELECT `id` FROM (SELECT `id`, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS n FROM %s WHERE %s) AS numbered WHERE n > 1)",
+		s.CurrentTable, strings.Join(quoted, ", "), order, s.CurrentTable, clause)
+	shown, err := inlineParams(statement, values)
+	if err != nil {
+		shown = statement
+	}
+	fmt.Fprintf(s.Out, "To keep the row with the %s of each group:\n  %s\n", keep, shown)
+	fmt.Fprintf(s.Out, "Delete %d duplicate rows? (y/N)\n", extra)
+	if strings.ToLower(s.confirm()) != "y" {
+		return ErrConfirmationDeclined
+	}
+
+	result, err := s.exec(ctx, statement, values...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	s.recordRows(affected)
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Deleted %d record(s)\n", affected)
+	} else {
+		fmt.Fprintf(s.Out, "Query OK, %d rows affected%s\n", affected, s.timing())
+	}
+	return nil
+}
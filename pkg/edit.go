@@ -0,0 +1,222 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEditCommandRegex returns the regex for EDIT id
+func GetEditCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(EDIT)\s+(\S+)$`)
+}
+
+// editorCommand returns the editor of the user: $VISUAL, $EDITOR, or else
+// notepad on Windows and vi elsewhere
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(name)); len(editor) > 0 {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// EditInEditor opens text in the editor of the user, as a temporary .json
+// file, and returns the text saved when the editor exits
+func EditInEditor(text string) (string, error) {
+	file, err := os.CreateTemp("", "noqli-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w", editor[0], err)
+	}
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// edit lets the user change text with the editor of the session
+func (s *Session) edit(text string) (string, error) {
+	if s.Editor != nil {
+		return s.Editor(text)
+	}
+	return EditInEditor(text)
+}
+
+// editableValue returns value as it is shown for editing: times in the
+// layout MySQL reads back
+func editableValue(value any) any {
+	if t, ok := value.(time.Time); ok {
+		return t.Format("2006-01-02 15:04:05.999999")
+	}
+	return value
+}
+
+// decodeEdited decodes an edited record keeping numbers as written
+func decodeEdited(text string) (map[string]any, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var record map[string]any
+	if err := decoder.Decode(&record); err != nil {
+		return nil, fmt.Errorf("the edited record is not a JSON object: %w", err)
+	}
+	return record, nil
+}
+
+// updateValue converts an edited JSON value for UPDATE: whole numbers to
+// integers, other numbers as written so decimals keep their precision
+func updateValue(value any) any {
+	if n, ok := value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		return n.String()
+	}
+	return value
+}
+
+// handleEdit opens the row with id in the editor as JSON and, after showing
+// the changed fields and asking, updates them. Removing a field leaves it
+// unchanged; set it to null to clear it.
+func handleEdit(ctx context.Context, s *Session, idText string) error {
+	if s.CurrentTable == "" {
+		return fmt.Errorf("%w. Use 'USE table_name' to select a table", ErrNoTableSelected)
+	}
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	var id any = strings.Trim(idText, `"'`)
+	if n, err := strconv.ParseInt(idText, 10, 64); err == nil {
+		id = n
+	}
+
+	query, values, err := NewQueryBuilder(s.CurrentTable).Where(map[string]any{"id": id}).Select()
+	if err != nil {
+		return err
+	}
+	rows, err := s.query(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return err
+	}
+	if !rows.Next() {
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w with id %v", ErrNoRecords, id)
+	}
+	row, err := scanRecord(rows, columns)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	for col, value := range row {
+		row[col] = editableValue(value)
+	}
+
+	original, err := json.MarshalIndent(Record(row), "", "  ")
+	if err != nil {
+		return err
+	}
+	before, err := decodeEdited(string(original))
+	if err != nil {
+		return err
+	}
+	text, err := s.edit(string(original) + "\n")
+	if err != nil {
+		return err
+	}
+	after, err := decodeEdited(text)
+	if err != nil {
+		return err
+	}
+
+	changes := make(map[string]any)
+	var lines []string
+	order := append([]string{}, columns...)
+	for _, col := range sortedKeys(after) {
+		if _, ok := before[col]; !ok {
+			order = append(order, col)
+		}
+	}
+	for _, col := range order {
+		value, ok := after[col]
+		if !ok || encodeValue(value) == encodeValue(before[col]) {
+			continue
+		}
+		if col == "id" {
+			return fmt.Errorf("EDIT cannot change the id")
+		}
+		old := "(new)"
+		if _, existed := before[col]; existed {
+			old = encodeValue(before[col])
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s -> %s", col, old, encodeValue(value)))
+		changes[col] = updateValue(value)
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(s.Out, "No changes")
+		return nil
+	}
+
+	fmt.Fprintf(s.Out, "Changes to id %v:\n%s\n", id, strings.Join(lines, "\n"))
+	fmt.Fprintln(s.Out, "Apply them? (y/N)")
+	if strings.ToLower(s.confirm()) != "y" {
+		return ErrConfirmationDeclined
+	}
+
+	if err := ensureColumns(ctx, s, changes); err != nil {
+		return err
+	}
+	query, values, err = NewQueryBuilder(s.CurrentTable).Set(changes).Where(map[string]any{"id": id}).Update()
+	if err != nil {
+		return err
+	}
+	result, err := s.exec(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	s.recordRows(affected)
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Updated: %s\n", ColorJSON(map[string]any{"id": id, "changes": changes}))
+	} else {
+		fmt.Fprintf(s.Out, "Query OK, %d rows affected%s\n", affected, s.timing())
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sentinel errors returned by handlers. Use errors.Is to test for them, as
+// handlers usually wrap them with extra context.
+var (
+	// ErrNoTableSelected is returned when a command needs a table and none is selected
+	ErrNoTableSelected = errors.New("no table selected")
+	// ErrNoDatabaseSelected is returned when a command needs a database and none is selected
+	ErrNoDatabaseSelected = errors.New("no database selected")
+	// ErrNoRecords is returned when a command matched no records
+	ErrNoRecords = errors.New("no records")
+	// ErrConfirmationDeclined is returned when the user declines a confirmation prompt
+	ErrConfirmationDeclined = errors.New("operation cancelled")
+	// ErrInvalidValue is returned when a value to write does not fit the type of its column
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrParse is matched by every *ParseError
+	ErrParse = errors.New("parse error")
+)
+
+// ParseError reports a command argument that could not be parsed, with the
+// byte offset of the offending segment within Input
+type ParseError struct {
+	Input string
+	Pos   int
+	// Len is the length of the offending segment; 0 marks a single position
+	Len  int
+	Msg  string
+	Hint string
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// Snippet returns Input with the offending segment underlined by a caret on
+// the next line, followed by the hint when there is one:
+//
+//	GET {id: (1, x)}
+//	              ^
+//	hint: range bounds are numbers, dates or variables, e.g. (1, 10)
+func (e *ParseError) Snippet() string {
+	pos := e.Pos
+	if pos > len(e.Input) {
+		pos = len(e.Input)
+	}
+
+	// Keep tabs so the caret lines up with the input
+	var sb strings.Builder
+	sb.WriteString(e.Input)
+	sb.WriteString("\n")
+	for _, r := range e.Input[:pos] {
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	sb.WriteString("^")
+	if end := pos + e.Len; e.Len > 1 && end <= len(e.Input) {
+		sb.WriteString(strings.Repeat("~", utf8.RuneCountInString(e.Input[pos:end])-1))
+	}
+	if e.Hint != "" {
+		sb.WriteString("\nhint: " + e.Hint)
+	}
+	return sb.String()
+}
+
+// Is makes errors.Is(err, ErrParse) true for any *ParseError
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// newParseError creates a ParseError for the segment of input starting at pos
+func newParseError(input string, pos int, format string, args ...any) *ParseError {
+	if pos < 0 {
+		pos = 0
+	}
+	return &ParseError{Input: input, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// withHint sets the hint shown below the caret
+func (e *ParseError) withHint(hint string) *ParseError {
+	e.Hint = hint
+	return e
+}
+
+// withLen sets the length of the offending segment
+func (e *ParseError) withLen(n int) *ParseError {
+	e.Len = n
+	return e
+}
+
+// locateParseError moves a parse error of an argument into the full command
+// line input, in which the argument starts at offset. Other errors are
+// returned unchanged.
+func locateParseError(err error, input string, offset int) error {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		return err
+	}
+	located := *parseErr
+	located.Input = input
+	located.Pos += offset
+	return &located
+}
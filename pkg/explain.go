@@ -0,0 +1,187 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GetExplainCommandRegex returns the regex for EXPLAIN GET {...}
+func GetExplainCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?is)^(EXPLAIN)\s+GET\b\s*(.*)$`)
+}
+
+// accessTypes describe the access types of EXPLAIN in plain words
+var accessTypes = map[string]string{
+	"system":          "single row table",
+	"const":           "single row by primary or unique key",
+	"eq_ref":          "one row per join by unique key",
+	"ref":             "index lookup",
+	"fulltext":        "full-text index search",
+	"ref_or_null":     "index lookup including NULLs",
+	"index_merge":     "several indexes merged",
+	"unique_subquery": "unique key lookup per subquery row",
+	"index_subquery":  "index lookup per subquery row",
+	"range":           "index range scan",
+	"index":           "full index scan",
+	"ALL":             "full table scan",
+}
+
+// explainDisplayKeys are the GET arguments changing how rows are shown
+// rather than which rows are read, left out of the explained query
+var explainDisplayKeys = []string{"chart", "sample", "seed", "by", "having"}
+
+// PlanStep is one table access of a query plan, as a row of EXPLAIN
+type PlanStep struct {
+	ID         string  `json:"id"`
+	SelectType string  `json:"select_type"`
+	Table      string  `json:"table"`
+	Type       string  `json:"type"`
+	Key        string  `json:"key"`
+	Possible   string  `json:"possible_keys"`
+	Rows       int64   `json:"rows"`
+	Filtered   float64 `json:"filtered"`
+	Extra      string  `json:"extra"`
+}
+
+// ExplainGet returns the plan MySQL chooses for the query a GET with args
+// would run, without running it
+func (s *Session) ExplainGet(ctx context.Context, args map[string]any) ([]PlanStep, error) {
+	if args == nil {
+		args = make(map[string]any)
+	}
+	for key := range args {
+		if containsString(explainDisplayKeys, strings.ToLower(key)) {
+			delete(args, key)
+		}
+	}
+	source, err := sourceFromArgs(ctx, s, args)
+	if err != nil {
+		return nil, err
+	}
+	builder, err := buildGetQuery(ctx, s, source, args)
+	if err != nil {
+		return nil, err
+	}
+	query, values, err := builder.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.query(ctx, "EXPLAIN "+query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []PlanStep
+	for rows.Next() {
+		plan, err := scanRecord(rows, columns)
+		if err != nil {
+			return nil, err
+		}
+		text := func(key string) string {
+			if plan[key] == nil {
+				return ""
+			}
+			return fmt.Sprint(plan[key])
+		}
+		step := PlanStep{
+			ID:         text("id"),
+			SelectType: text("select_type"),
+			Table:      text("table"),
+			Type:       text("type"),
+			Key:        text("key"),
+			Possible:   text("possible_keys"),
+			Extra:      text("Extra"),
+			Filtered:   100,
+		}
+		step.Rows, _ = strconv.ParseInt(text("rows"), 10, 64)
+		if f, err := strconv.ParseFloat(text("filtered"), 64); err == nil {
+			step.Filtered = f
+		}
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
+
+// describeStep renders a plan step in plain words, e.g.
+// "users: index lookup (ref) on idx_status, ~120 rows"
+func describeStep(step PlanStep) string {
+	table := step.Table
+	if table == "" {
+		table = "(no table)"
+	}
+	if step.Type == "" {
+		if step.Extra != "" {
+			return fmt.Sprintf("%s: %s", table, step.Extra)
+		}
+		return table + ": no table access"
+	}
+
+	access, ok := accessTypes[step.Type]
+	if !ok {
+		access = step.Type
+	}
+	line := fmt.Sprintf("%s: %s (%s)", table, access, step.Type)
+	switch {
+	case step.Key != "":
+		line += " on " + step.Key
+	case step.Possible != "":
+		line += ", unused indexes " + step.Possible
+	case step.Type == "ALL":
+		line += ", no usable index"
+	}
+	line += fmt.Sprintf(", ~%d rows", step.Rows)
+	if step.Filtered < 100 {
+		line += fmt.Sprintf(", %.0f%% kept", step.Filtered)
+	}
+	if step.Extra != "" {
+		line += " [" + step.Extra + "]"
+	}
+	return line
+}
+
+// renderPlan draws the steps as a tree: one root per SELECT of the query,
+// each joined table nested under the one it is looked up from
+func renderPlan(steps []PlanStep) string {
+	var b strings.Builder
+	var lastID string
+	depth := 0
+	for i, step := range steps {
+		if i == 0 || step.ID != lastID {
+			label := step.SelectType
+			if label == "" {
+				label = "SELECT"
+			}
+			if step.ID != "" {
+				label += " #" + step.ID
+			}
+			b.WriteString(label + "\n")
+			lastID, depth = step.ID, 0
+		}
+		b.WriteString(strings.Repeat("    ", depth) + "└── " + describeStep(step) + "\n")
+		depth++
+	}
+	return b.String()
+}
+
+// handleExplain prints the plan of the query a GET with args would run
+func handleExplain(ctx context.Context, s *Session, args map[string]any) error {
+	steps, err := s.ExplainGet(ctx, args)
+	if err != nil {
+		return err
+	}
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Plan: %s\n", ColorJSON(steps))
+		return nil
+	}
+	fmt.Fprint(s.Out, renderPlan(steps))
+	return nil
+}
@@ -0,0 +1,4 @@
+package pkg
+
+// KeychainStoreCommand exposes keychainStoreCommand to the tests
+var KeychainStoreCommand = keychainStoreCommand
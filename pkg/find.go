@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GetFindCommandRegex returns the regex for FIND column 'pattern' and
+// FIND table 'pattern'
+func GetFindCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(FIND)\s+(column|table)s?\s+(.+)$`)
+}
+
+// systemSchemas are the databases of the server itself, left out of FIND
+var systemSchemas = []any{"information_schema", "mysql", "performance_schema", "sys"}
+
+// FindSchemaObjects lists the tables or columns of every user database
+// whose name matches pattern with LIKE. Patterns without a % wildcard match
+// anywhere in the name.
+func (s *Session) FindSchemaObjects(ctx context.Context, kind, pattern string) ([]string, []map[string]any, error) {
+	if !strings.Contains(pattern, "%") {
+		pattern = "%" + pattern + "%"
+	}
+	exclude := strings.TrimSuffix(strings.Repeat("?, ", len(systemSchemas)), ", ")
+	args := append([]any{pattern}, systemSchemas...)
+
+	var query string
+	var columns []string
+	if strings.EqualFold(kind, "column") {
+		columns = []string{"Database", "Table", "Column", "Type"}
+		query = `SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME, COLUMN_TYPE
+			FROM INFORMATION_SCHEMA.COLUMNS
+			WHERE COLUMN_NAME LIKE ? AND TABLE_SCHEMA NOT IN (` + exclude + `)
+			ORDER BY TABLE_SCHEMA, TABLE_NAME, ORDINAL_POSITION`
+	} else {
+		columns = []string{"Database", "Table", "Type", "Rows"}
+		query = `SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE, TABLE_ROWS
+			FROM INFORMATION_SCHEMA.TABLES
+			WHERE TABLE_NAME LIKE ? AND TABLE_SCHEMA NOT IN (` + exclude + `)
+			ORDER BY TABLE_SCHEMA, TABLE_NAME`
+	}
+
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var results []map[string]any
+	for rows.Next() {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = append(results, entry)
+	}
+	return columns, results, rows.Err()
+}
+
+// handleFind lists the tables or columns whose name matches pattern
+func handleFind(ctx context.Context, s *Session, kind, pattern string) error {
+	pattern = strings.Trim(strings.TrimSpace(pattern), `"'`)
+	if pattern == "" {
+		return fmt.Errorf("FIND needs a name pattern, e.g. FIND column '%%email%%'")
+	}
+	columns, results, err := s.FindSchemaObjects(ctx, kind, pattern)
+	if err != nil {
+		return err
+	}
+	s.recordRows(int64(len(results)))
+
+	if len(results) == 0 {
+		fmt.Fprintf(s.Out, "No %s matches %q\n", strings.ToLower(kind), pattern)
+		return nil
+	}
+	if s.JSONOutput {
+		title := "Columns"
+		if strings.EqualFold(kind, "table") {
+			title = "Tables"
+		}
+		fmt.Fprintf(s.Out, "%s: %s\n", title, ColorJSON(results))
+		return nil
+	}
+	s.printTable(columns, results)
+	return nil
+}
@@ -0,0 +1,292 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GetFixturesCommandRegex returns the regex for FIXTURES commands
+func GetFixturesCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(FIXTURES)\s+(\S+)\s+(.+)$`)
+}
+
+// Fixtures are rows to load, by table name
+type Fixtures map[string][]map[string]any
+
+// ReadFixtures reads the fixture files at path, a .yaml, .yml or .json file
+// or a directory of them. A file holds either a list of rows for the table
+// it is named after, e.g. users.yaml, or a map of table names to rows.
+func ReadFixtures(path string) (Fixtures, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, entry := range entries {
+			if !entry.IsDir() && isFixtureFile(entry.Name()) {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no .yaml, .yml or .json fixture files in %s", path)
+		}
+	} else if !isFixtureFile(path) {
+		return nil, fmt.Errorf("%s is not a .yaml, .yml or .json fixture file", path)
+	}
+
+	fixtures := make(Fixtures)
+	for _, file := range files {
+		if err := readFixtureFile(file, fixtures); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return fixtures, nil
+}
+
+// isFixtureFile reports whether name has a fixture file extension
+func isFixtureFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// readFixtureFile adds the rows of one fixture file to fixtures
+func readFixtureFile(file string, fixtures Fixtures) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var content any
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		err = json.Unmarshal(data, &content)
+	} else {
+		err = yaml.Unmarshal(data, &content)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch v := content.(type) {
+	case []any:
+		table := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		return addFixtureRows(fixtures, table, v)
+	case map[string]any:
+		for table, rows := range v {
+			list, ok := rows.([]any)
+			if !ok {
+				return fmt.Errorf("the rows of %s must be a list", table)
+			}
+			if err := addFixtureRows(fixtures, table, list); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("expected a list of rows or a map of tables to rows")
+	}
+}
+
+// addFixtureRows appends rows to the fixtures of table
+func addFixtureRows(fixtures Fixtures, table string, rows []any) error {
+	for i, row := range rows {
+		record, ok := row.(map[string]any)
+		if !ok {
+			return fmt.Errorf("row %d of %s is not a map of columns to values", i+1, table)
+		}
+		fixtures[table] = append(fixtures[table], record)
+	}
+	if _, ok := fixtures[table]; !ok {
+		fixtures[table] = []map[string]any{}
+	}
+	return nil
+}
+
+// fixtureOrder sorts tables so that a table comes after the tables it
+// references. Tables referencing each other keep alphabetical order.
+func fixtureOrder(tables []string, refs []ForeignKey) []string {
+	pending := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		pending[table] = true
+	}
+	sorted := append([]string{}, tables...)
+	sort.Strings(sorted)
+
+	var order []string
+	for len(order) < len(sorted) {
+		progressed := false
+		for _, table := range sorted {
+			if !pending[table] {
+				continue
+			}
+			ready := true
+			for _, fk := range refs {
+				if fk.Table == table && fk.RefTable != table && pending[fk.RefTable] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, table)
+				pending[table] = false
+				progressed = true
+			}
+		}
+		if !progressed {
+			// A cycle: load the rest as they come
+			for _, table := range sorted {
+				if pending[table] {
+					order = append(order, table)
+					pending[table] = false
+				}
+			}
+		}
+	}
+	return order
+}
+
+// LoadFixtures replaces the rows of each table of fixtures with its fixture
+// rows. Tables are emptied from the referencing to the referenced ones and
+// filled in the opposite order, so foreign keys between them hold, all in
+// one transaction: a failure leaves every table untouched. It returns the
+// number of rows loaded per table.
+func (s *Session) LoadFixtures(ctx context.Context, fixtures Fixtures) (map[string]int64, error) {
+	if s.CurrentDB == "" {
+		return nil, fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+	schema, err := databaseColumns(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]string, 0, len(fixtures))
+	rowColumns := make(map[string][]string, len(fixtures))
+	for table, rows := range fixtures {
+		columns, ok := schema[table]
+		if !ok {
+			return nil, fmt.Errorf("table %s does not exist", table)
+		}
+		set := make(map[string]bool)
+		for _, row := range rows {
+			for col := range row {
+				if !containsString(columns, col) {
+					return nil, fmt.Errorf("unknown column %q in the fixtures of %s", col, table)
+				}
+				set[col] = true
+			}
+		}
+		for col := range set {
+			rowColumns[table] = append(rowColumns[table], col)
+		}
+		sort.Strings(rowColumns[table])
+		tables = append(tables, table)
+	}
+
+	refs, err := s.References(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	order := fixtureOrder(tables, refs)
+	batchSize, err := s.batchSize()
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]int64, len(order))
+	err = s.inTransaction(ctx, func() error {
+		for i := len(order) - 1; i >= 0; i-- {
+			if _, err := s.exec(ctx, "DELETE FROM "+quoteIdent(order[i])); err != nil {
+				return fmt.Errorf("emptying %s: %w", order[i], err)
+			}
+		}
+		for _, table := range order {
+			rows := fixtures[table]
+			loaded[table] = 0
+			for i := 0; i < len(rows); i += batchSize {
+				end := i + batchSize
+				if end > len(rows) {
+					end = len(rows)
+				}
+				query, values, err := buildInsert(quoteIdent(table), rowColumns[table], rows[i:end])
+				if err != nil {
+					return fmt.Errorf("loading %s: %w", table, err)
+				}
+				result, err := s.exec(ctx, query, values...)
+				if err != nil {
+					return fmt.Errorf("loading %s: %w", table, err)
+				}
+				affected, err := result.RowsAffected()
+				if err != nil {
+					return err
+				}
+				loaded[table] += affected
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loaded, nil
+}
+
+// handleFixtures runs FIXTURES load path, replacing the rows of the tables
+// named by the fixture files after confirmation
+func handleFixtures(ctx context.Context, s *Session, action, path string) error {
+	if !strings.EqualFold(action, "load") {
+		return fmt.Errorf("unknown FIXTURES action %q. Use FIXTURES load path", action)
+	}
+	path = strings.Trim(strings.TrimSpace(path), `"'`)
+	fixtures, err := ReadFixtures(path)
+	if err != nil {
+		return err
+	}
+
+	tables := make([]string, 0, len(fixtures))
+	for table := range fixtures {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	fmt.Fprintf(s.Out, "Replace all rows of %s with the fixtures? (y/N)\n", strings.Join(tables, ", "))
+	if strings.ToLower(s.confirm()) != "y" {
+		return ErrConfirmationDeclined
+	}
+
+	loaded, err := s.LoadFixtures(ctx, fixtures)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, n := range loaded {
+		total += n
+	}
+	s.recordRows(total)
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Loaded: %s\n", ColorJSON(loaded))
+		return nil
+	}
+	results := make([]map[string]any, len(tables))
+	for i, table := range tables {
+		results[i] = map[string]any{"Table": table, "Rows": loaded[table]}
+	}
+	s.printTable([]string{"Table", "Rows"}, results)
+	return nil
+}
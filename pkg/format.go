@@ -0,0 +1,187 @@
+package pkg
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decimal is a DECIMAL value in the exact text form MySQL returns. It is
+// written as a JSON number and right-aligned in tables, without the
+// rounding of a float64.
+type Decimal string
+
+// MarshalJSON writes the decimal as a number literal
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if !json.Valid([]byte(d)) {
+		return nil, fmt.Errorf("invalid decimal %q", string(d))
+	}
+	return []byte(d), nil
+}
+
+// columnFormatter prepares the values of result columns for display: DATE,
+// DATETIME and TIMESTAMP values are formatted, DECIMAL values become Decimal,
+// spatial values are shown as WKT, BINARY(16) values as UUIDs and boolean
+// columns as true and false
+type columnFormatter struct {
+	// MySQL type of each formatted column by name
+	kinds      map[string]string
+	loc        *time.Location
+	dateLayout string
+	timeLayout string
+	// DATETIME and TIMESTAMP values are shown relative to now, e.g.
+	// "3 hours ago"
+	relative bool
+}
+
+// newColumnFormatter prepares the formatting of the columns of rows. The
+// date_format setting selects "iso" (ISO 8601, the default), "mysql"
+// (2006-01-02 15:04:05) or a Go time layout for DATETIME and TIMESTAMP
+// values; the timezone setting selects the zone they are shown in. TINYINT
+// columns named in booleans are shown as true and false. With relative times
+// on, tabular output shows DATETIME and TIMESTAMP values as "3 hours ago".
+func (s *Session) newColumnFormatter(rows *sql.Rows, booleans map[string]bool) (*columnFormatter, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]string)
+	for _, t := range types {
+		switch name := t.DatabaseTypeName(); name {
+		case "DATE", "DATETIME", "TIMESTAMP", "DECIMAL", "GEOMETRY", "BINARY":
+			kinds[t.Name()] = name
+		case "TINYINT":
+			if booleans[t.Name()] {
+				kinds[t.Name()] = "BOOLEAN"
+			}
+		}
+	}
+
+	loc, err := s.Config.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &columnFormatter{kinds: kinds, loc: loc, dateLayout: "2006-01-02"}
+	switch format := s.Config.Get("date_format"); format {
+	case "", "iso":
+		f.timeLayout = "2006-01-02T15:04:05.999999Z07:00"
+	case "mysql":
+		f.timeLayout = "2006-01-02 15:04:05.999999"
+	default:
+		f.timeLayout = format
+	}
+	f.relative = !s.JSONOutput && s.showRelativeTimes()
+	return f, nil
+}
+
+// format replaces the values of entry with their display form. NULL and
+// values that do not parse, such as zero dates, are left unchanged.
+func (f *columnFormatter) format(entry map[string]any) {
+	for col, kind := range f.kinds {
+		if kind == "DECIMAL" {
+			if v, ok := entry[col].(string); ok {
+				entry[col] = Decimal(v)
+			}
+			continue
+		}
+		if kind == "BOOLEAN" {
+			switch v := entry[col].(type) {
+			case int64:
+				entry[col] = v != 0
+			case string:
+				if n, err := strconv.Atoi(v); err == nil {
+					entry[col] = n != 0
+				}
+			}
+			continue
+		}
+		if kind == "BINARY" {
+			if v, ok := entry[col].(string); ok && len(v) == 16 {
+				entry[col] = formatUUID([]byte(v))
+			}
+			continue
+		}
+		if kind == "GEOMETRY" {
+			if v, ok := entry[col].(string); ok {
+				if wkt, err := geometryToWKT([]byte(v)); err == nil {
+					entry[col] = wkt
+				}
+			}
+			continue
+		}
+
+		var t time.Time
+		switch v := entry[col].(type) {
+		case time.Time:
+			t = v
+		case string:
+			parsed, ok := parseMySQLTime(v, f.loc)
+			if !ok {
+				continue
+			}
+			t = parsed
+		default:
+			continue
+		}
+
+		if kind == "DATE" {
+			entry[col] = t.Format(f.dateLayout)
+		} else if f.relative {
+			entry[col] = relativeTime(t, timeNow())
+		} else {
+			entry[col] = t.In(f.loc).Format(f.timeLayout)
+		}
+	}
+}
+
+// relativeUnits are the units relative times are counted in, largest first
+var relativeUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+}
+
+// relativeTime describes t relative to now in its largest whole unit, e.g.
+// "3 hours ago" or "in 2 days"
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	for _, unit := range relativeUnits {
+		n := int64(d / unit.size)
+		if n == 0 {
+			continue
+		}
+		text := fmt.Sprintf("%d %s", n, unit.name)
+		if n > 1 {
+			text += "s"
+		}
+		if future {
+			return "in " + text
+		}
+		return text + " ago"
+	}
+	return "just now"
+}
+
+// showRelativeTimes reports whether tabular output shows DATETIME and
+// TIMESTAMP values relative to now: SET relative_times on|off, or else the
+// relative_times setting
+func (s *Session) showRelativeTimes() bool {
+	value := s.relativeTimes
+	if value == "" {
+		value = s.Config.Get("relative_times")
+	}
+	return strings.EqualFold(value, "on")
+}
@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// likeEscaper escapes the LIKE wildcards of a literal
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// trigrams returns the distinct three-character sequences of term, lowercased
+func trigrams(term string) []string {
+	runes := []rune(strings.ToLower(term))
+	seen := make(map[string]bool)
+	var grams []string
+	for i := 0; i+3 <= len(runes); i++ {
+		gram := string(runes[i : i+3])
+		if !seen[gram] {
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+// fuzzyCondition compiles {fuzzy: 'term'} on col to a condition tolerating
+// typos: the value sounds like term (SOUNDEX), or contains at least half of
+// its trigrams, so "Jonson" finds "Johnson". Terms shorter than three
+// characters match where they appear in the value.
+func fuzzyCondition(col string, value any) (string, []any, error) {
+	term, ok := value.(string)
+	if !ok || strings.TrimSpace(term) == "" {
+		return "", nil, fmt.Errorf("fuzzy requires text, e.g. {fuzzy: 'Jonson'}")
+	}
+	term = strings.TrimSpace(term)
+	grams := trigrams(term)
+	if len(grams) == 0 {
+		return fmt.Sprintf("(SOUNDEX(%s) = SOUNDEX(?) OR %s LIKE ?)", col, col),
+			[]any{term, "%" + likeEscaper.Replace(term) + "%"}, nil
+	}
+
+	matches := make([]string, len(grams))
+	args := []any{term}
+	for i, gram := range grams {
+		matches[i] = fmt.Sprintf("(LOWER(%s) LIKE ?)", col)
+		args = append(args, "%"+likeEscaper.Replace(gram)+"%")
+	}
+	needed := (len(grams) + 1) / 2
+	args = append(args, needed)
+	return fmt.Sprintf("(SOUNDEX(%s) = SOUNDEX(?) OR %s >= ?)", col, strings.Join(matches, " + ")), args, nil
+}
@@ -0,0 +1,172 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DefaultAskEndpoint is the chat completions endpoint used when the config
+// does not set ask.endpoint
+const DefaultAskEndpoint = "https://api.openai.com/v1/chat/completions"
+
+// DefaultAskModel is the model requested when the config does not set ask.model
+const DefaultAskModel = "gpt-4o-mini"
+
+// AskCompletion sends the system and user prompts to the LLM endpoint
+// configured in the [ask] section and returns the reply text.
+// It is a variable so it can be replaced, e.g. in tests.
+var AskCompletion = func(ctx context.Context, config Config, system, prompt string) (string, error) {
+	endpoint := config.Get("ask.endpoint")
+	if endpoint == "" {
+		endpoint = DefaultAskEndpoint
+	}
+	model := config.Get("ask.model")
+	if model == "" {
+		model = DefaultAskModel
+	}
+	apiKey := config.Get("ask.api_key")
+	if apiKey == "" {
+		apiKey = os.Getenv("NOQLI_ASK_API_KEY")
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"model": model,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": prompt},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("invalid response from %s: %w", endpoint, err)
+	}
+	if reply.Error != nil {
+		return "", fmt.Errorf("assistant error: %s", reply.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK || len(reply.Choices) == 0 {
+		return "", fmt.Errorf("assistant request failed: %s", resp.Status)
+	}
+	return reply.Choices[0].Message.Content, nil
+}
+
+// handleAsk turns a natural-language request into a NoQLi command using the
+// configured assistant and runs it after confirmation
+func handleAsk(ctx context.Context, s *Session, prompt string) error {
+	if s.CurrentTable == "" {
+		return fmt.Errorf("%w. Use 'USE table_name' to select a table", ErrNoTableSelected)
+	}
+
+	schema, err := describeTable(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	reply, err := AskCompletion(ctx, s.Config, askSystemPrompt(s.CurrentTable, schema), prompt)
+	if err != nil {
+		return err
+	}
+
+	command, ok := extractCommand(reply)
+	if !ok {
+		return fmt.Errorf("could not find a NoQLi command in the reply: %q", strings.TrimSpace(reply))
+	}
+
+	fmt.Fprintf(s.Out, "Generated: %s\n", command)
+	fmt.Fprintln(s.Out, "Run this command? (y/N)")
+	response := s.confirm()
+	if strings.ToLower(response) != "y" {
+		return ErrConfirmationDeclined
+	}
+
+	return ExecuteCommand(ctx, s, command)
+}
+
+// describeTable lists the columns of the current table as "name type" lines
+func describeTable(ctx context.Context, s *Session) (string, error) {
+	rows, err := s.query(ctx, "SHOW COLUMNS FROM "+s.CurrentTable)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var schema strings.Builder
+	for rows.Next() {
+		record, err := scanRecord(rows, columns)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&schema, "%v %v\n", record["Field"], record["Type"])
+	}
+	return schema.String(), rows.Err()
+}
+
+// askSystemPrompt explains the NoQLi syntax and the table schema to the assistant
+func askSystemPrompt(table, schema string) string {
+	return fmt.Sprintf(`You translate requests into a single NoQLi command for the MySQL table %s with columns:
+%s
+NoQLi syntax:
+GET {field: value}                 filter by equality; several fields are combined with AND
+GET {field: [a, b]}                field IN (a, b)
+GET {id: (1, 10)}                  inclusive id range
+GET {name, email}                  select columns
+GET {like: text}                   text columns containing text
+GET {up: field} / GET {down: field} order ascending / descending
+GET {lim: 10, off: 20}             LIMIT and OFFSET
+GET {count: *} / GET {max: field}  COUNT, MAX, MIN, AVG, SUM
+CREATE {field: value}
+UPDATE {id: 1, field: value}
+DELETE {id: 1}
+Reply with the command only, on one line.`, table, schema)
+}
+
+// extractCommand returns the first line of reply that is a NoQLi command,
+// ignoring Markdown code fences
+func extractCommand(reply string) (string, bool) {
+	re := regexp.MustCompile(`(?i)^(CREATE|GET|UPDATE|DELETE)\b`)
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "`")
+		if re.MatchString(line) {
+			return line, true
+		}
+	}
+	return "", false
+}
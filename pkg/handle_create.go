@@ -1,15 +1,15 @@
 package pkg
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"strings"
 )
 
 // HandleCreate handles the CREATE command
-func HandleCreate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
-	if CurrentTable == "" {
-		return fmt.Errorf("no table selected")
+func HandleCreate(ctx context.Context, s *Session, args map[string]any) error {
+	if s.CurrentTable == "" {
+		return ErrNoTableSelected
 	}
 
 	if len(args) == 0 {
@@ -17,7 +17,10 @@ func HandleCreate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 	}
 
 	// Ensure columns exist
-	if err := ensureColumns(db, args); err != nil {
+	if err := ensureColumns(ctx, s, args); err != nil {
+		return err
+	}
+	if err := warnNarrowCharset(ctx, s, args); err != nil {
 		return err
 	}
 
@@ -27,19 +30,23 @@ func HandleCreate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 	var values []any
 
 	for k, v := range args {
+		expr, params, err := sqlValue(v)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", k, err)
+		}
 		fields = append(fields, fmt.Sprintf("`%s`", k))
-		placeholders = append(placeholders, "?")
-		values = append(values, v)
+		placeholders = append(placeholders, expr)
+		values = append(values, params...)
 	}
 
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-		CurrentTable,
+		s.CurrentTable,
 		strings.Join(fields, ", "),
 		strings.Join(placeholders, ", "),
 	)
 
 	// Execute query
-	result, err := db.Exec(query, values...)
+	result, err := s.exec(ctx, query, values...)
 	if err != nil {
 		return err
 	}
@@ -50,16 +57,22 @@ func HandleCreate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 		return err
 	}
 
-	// Output result
-	args["id"] = id
+	s.recordRows(1)
+
+	// Output result. Tables keyed by UUIDs have no insert ID.
+	if _, ok := args["id"]; !ok {
+		args["id"] = id
+	}
 
-	if useJsonOutput {
+	if s.JSONOutput {
 		// Colorized JSON output
-		fmt.Printf("Created: %s\n", ColorJSON(args))
+		fmt.Fprintf(s.Out, "Created: %s\n", ColorJSON(args))
 	} else {
 		// MySQL-style tabular output
-		fmt.Println("Query OK, 1 row affected")
-		fmt.Printf("Last insert ID: %d\n", id)
+		fmt.Fprintf(s.Out, "Query OK, 1 row affected%s\n", s.timing())
+		if id != 0 {
+			fmt.Fprintf(s.Out, "Last insert ID: %d\n", id)
+		}
 	}
 
 	return nil
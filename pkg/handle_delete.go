@@ -1,72 +1,100 @@
 package pkg
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 )
 
 // HandleDelete handles the DELETE command
-func HandleDelete(db *sql.DB, args map[string]any, useJsonOutput bool) error {
-	if CurrentTable == "" {
-		return fmt.Errorf("no table selected")
+func HandleDelete(ctx context.Context, s *Session, args map[string]any) error {
+	if s.CurrentTable == "" {
+		return ErrNoTableSelected
 	}
 
-	if args == nil || args["id"] == nil {
-		return fmt.Errorf("DELETE requires an id field")
+	// Other fields narrow down the rows with the id, or select the rows
+	// without one
+	filters := make(map[string]any, len(args))
+	for k, v := range args {
+		if k != "_columns" {
+			filters[k] = v
+		}
+	}
+	if len(filters) == 0 {
+		return fmt.Errorf("DELETE requires an id field or filter conditions")
+	}
+	if err := binaryUUIDFilters(ctx, s, s.CurrentTable, filters); err != nil {
+		return err
+	}
+	throttle, err := s.throttle()
+	if err != nil {
+		return err
+	}
+	// Batches are split by id, so tables without one are deleted from at once
+	batched := false
+	if throttle.active() {
+		existingCols, err := getColumns(ctx, s)
+		if err != nil {
+			return err
+		}
+		batched = containsString(existingCols, "id")
 	}
 
-	id := args["id"]
-
-	var whereClause string
-	var values []any
+	if _, ok := filters["id"]; !ok {
+		fmt.Fprintln(s.Out, "Warning: No id specified. This will delete ALL records matching the filter conditions.")
+		fmt.Fprintln(s.Out, "Do you want to continue? (y/N)")
+		if strings.ToLower(s.confirm()) != "y" {
+			return ErrConfirmationDeclined
+		}
+	}
 
-	// Handle different ID types
-	if idSlice, ok := id.([]any); ok {
-		// Multiple IDs
-		placeholders := make([]string, len(idSlice))
-		for i, v := range idSlice {
-			placeholders[i] = "?"
-			values = append(values, v)
+	var affected int64
+	if batched {
+		// Throttled deletes run in batches of ids
+		batchSize, err := s.batchSize()
+		if err != nil {
+			return err
 		}
-		whereClause = fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ","))
-	} else if idMap, ok := id.(map[string]any); ok {
-		// Range query
-		if rangeSlice, ok := idMap["range"].([]int); ok && len(rangeSlice) == 2 {
-			whereClause = "id >= ? AND id <= ?"
-			values = append(values, rangeSlice[0], rangeSlice[1])
-		} else {
-			return fmt.Errorf("invalid range format")
+		affected, err = s.writeInBatches(ctx, filters, throttle.batchSize(batchSize), throttle, func(clause string, whereArgs []any) (sql.Result, error) {
+			return s.exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", s.CurrentTable, clause), whereArgs...)
+		})
+		if err != nil {
+			return err
 		}
 	} else {
-		// Single ID
-		whereClause = "id = ?"
-		values = append(values, id)
-	}
-
-	query := fmt.Sprintf("DELETE FROM %s WHERE %s", CurrentTable, whereClause)
+		// Build query filtering on id: a single value, an array, a range or a
+		// comparison, and on the other fields
+		query, values, err := NewQueryBuilder(s.CurrentTable).
+			MatchCase(s.matchCase).
+			Where(filters).
+			Delete()
+		if err != nil {
+			return err
+		}
 
-	// Execute query
-	result, err := db.Exec(query, values...)
-	if err != nil {
-		return err
-	}
+		// Execute query
+		result, err := s.exec(ctx, query, values...)
+		if err != nil {
+			return err
+		}
 
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return err
+		if affected, err = result.RowsAffected(); err != nil {
+			return err
+		}
 	}
+	s.recordRows(affected)
 
 	if affected == 0 {
-		return fmt.Errorf("record(s) not found")
+		return fmt.Errorf("%w found", ErrNoRecords)
 	}
 
-	if useJsonOutput {
+	if s.JSONOutput {
 		// JSON output (original)
-		fmt.Printf("Deleted %d record(s)\n", affected)
+		fmt.Fprintf(s.Out, "Deleted %d record(s)\n", affected)
 	} else {
 		// MySQL-style tabular output
-		fmt.Printf("Query OK, %d rows affected\n", affected)
+		fmt.Fprintf(s.Out, "Query OK, %d rows affected%s\n", affected, s.timing())
 	}
 
 	return nil
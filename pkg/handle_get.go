@@ -1,17 +1,103 @@
 package pkg
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
 )
 
 // HandleGet handles the GET command
-func HandleGet(db *sql.DB, args map[string]any, useJsonOutput bool) error {
-	if CurrentTable == "" {
-		return fmt.Errorf("no table selected")
+func HandleGet(ctx context.Context, s *Session, args map[string]any) error {
+	source, err := sourceFromArgs(ctx, s, args)
+	if err != nil {
+		return err
+	}
+	if err := binaryUUIDFilters(ctx, s, source.table, args); err != nil {
+		return err
+	}
+
+	// chart draws bars for the values of a result column
+	var chartColumn string
+	if args != nil {
+		var chartValue any
+		var hasChart bool
+		if v, ok := args["CHART"]; ok {
+			chartValue, hasChart = v, true
+			delete(args, "CHART")
+		} else if v, ok := args["chart"]; ok {
+			chartValue, hasChart = v, true
+			delete(args, "chart")
+		}
+		if hasChart {
+			name, ok := chartValue.(string)
+			if !ok {
+				return fmt.Errorf("chart requires a column name")
+			}
+			chartColumn = name
+		}
+	}
+
+	// --- Random sample support ---
+	var sample int
+	var seed *int64
+	if args != nil {
+		for _, key := range []string{"SAMPLE", "sample"} {
+			if v, ok := args[key]; ok {
+				if sample, err = sampleSize(v); err != nil {
+					return err
+				}
+				delete(args, key)
+				break
+			}
+		}
+		for _, key := range []string{"SEED", "seed"} {
+			if v, ok := args[key]; ok {
+				if sample == 0 {
+					return fmt.Errorf("seed requires sample, e.g. {sample: 50, seed: 42}")
+				}
+				n, err := sampleSeed(v)
+				if err != nil {
+					return err
+				}
+				seed = &n
+				delete(args, key)
+				break
+			}
+		}
+	}
+
+	// --- Grouping support for COUNT and the aggregates ---
+	var by string
+	if args != nil {
+		for _, key := range []string{"BY", "by"} {
+			if v, ok := args[key]; ok {
+				name, ok := v.(string)
+				if !ok {
+					return fmt.Errorf("by requires a column name, e.g. by: 'created_at/day'")
+				}
+				by = name
+				delete(args, key)
+				break
+			}
+		}
+	}
+	var having map[string]any
+	if args != nil {
+		for _, key := range []string{"HAVING", "having"} {
+			if v, ok := args[key]; ok {
+				filters, ok := v.(map[string]any)
+				if !ok {
+					return fmt.Errorf("having requires filters on the groups, e.g. having: {count: {gt: 10}}")
+				}
+				if by == "" {
+					return fmt.Errorf("having requires by, e.g. {COUNT: '*', by: 'category', having: {count: {gt: 10}}}")
+				}
+				having = filters
+				delete(args, key)
+				break
+			}
+		}
 	}
 
 	// --- COUNT support ---
@@ -110,6 +196,20 @@ func HandleGet(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 				hasAggregate = true
 			}
 		}
+		// Check for LIST
+		if !hasAggregate {
+			if v, ok := args["LIST"]; ok {
+				aggregateKey = "LIST"
+				aggregateTarget = v
+				aggregateFunc = "LIST"
+				hasAggregate = true
+			} else if v, ok := args["list"]; ok {
+				aggregateKey = "list"
+				aggregateTarget = v
+				aggregateFunc = "LIST"
+				hasAggregate = true
+			}
+		}
 
 		// Handle distinct for aggregate functions
 		if hasAggregate {
@@ -129,364 +229,337 @@ func HandleGet(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 			delete(args, aggregateKey)
 		}
 	}
-
-	if hasCount {
-		// --- LIKE support for COUNT ---
-		var likeValue any
-		if args != nil {
-			if v, ok := args["LIKE"]; ok {
-				likeValue = v
-				delete(args, "LIKE")
-			} else if v, ok := args["like"]; ok {
-				likeValue = v
-				delete(args, "like")
+	separator := DefaultListSeparator
+	for _, key := range []string{"SEPARATOR", "separator"} {
+		if v, ok := args[key]; ok {
+			if aggregateFunc != "LIST" {
+				return fmt.Errorf("separator requires LIST, e.g. {LIST: 'email', separator: '; '}")
+			}
+			text, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("separator requires a string, e.g. separator: '; '")
 			}
+			separator = text
+			delete(args, key)
+			break
 		}
+	}
+
+	if hasCount {
+		// --- LIKE search support for COUNT ---
+		likeValue := searchValue(args)
 
 		// Build COUNT query
 		var countExpr string
-		if s, ok := countTarget.(string); ok {
-			if distinct && s != "*" {
-				countExpr = fmt.Sprintf("COUNT(DISTINCT `%s`)", s)
-			} else if s == "*" {
+		if target, ok := countTarget.(string); ok {
+			if distinct && target != "*" {
+				countExpr = fmt.Sprintf("COUNT(DISTINCT `%s`)", target)
+			} else if target == "*" {
 				countExpr = "COUNT(*)"
 			} else {
-				countExpr = fmt.Sprintf("COUNT(`%s`)", s)
+				countExpr = fmt.Sprintf("COUNT(`%s`)", target)
 			}
 		} else {
 			// Fallback to COUNT(*)
 			countExpr = "COUNT(*)"
 		}
 
-		// Build WHERE clause from remaining args
-		var whereConditions []string
-		var values []any
-		for field, value := range args {
-			if sliceValue, ok := value.([]any); ok {
-				if len(sliceValue) == 0 {
-					whereConditions = append(whereConditions, "0=1")
-				} else {
-					placeholders := make([]string, len(sliceValue))
-					for i, v := range sliceValue {
-						placeholders[i] = "?"
-						values = append(values, v)
-					}
-					whereConditions = append(whereConditions, fmt.Sprintf("`%s` IN (%s)", field, strings.Join(placeholders, ",")))
-				}
-			} else if mapValue, ok := value.(map[string]any); ok {
-				// Support both []int and []any for range
-				if rangeVal, ok := mapValue["range"]; ok {
-					switch rangeSlice := rangeVal.(type) {
-					case []int:
-						if len(rangeSlice) == 2 {
-							whereConditions = append(whereConditions, fmt.Sprintf("`%s` >= ? AND `%s` <= ?", field, field))
-							values = append(values, rangeSlice[0], rangeSlice[1])
-						} else {
-							return fmt.Errorf("invalid range format for field %s", field)
-						}
-					case []any:
-						if len(rangeSlice) == 2 {
-							valuesToAdd := make([]any, 2)
-							for i := 0; i < 2; i++ {
-								switch v := rangeSlice[i].(type) {
-								case int:
-									valuesToAdd[i] = v
-								case float64:
-									valuesToAdd[i] = int(v)
-								case json.Number:
-									if intVal, err := v.Int64(); err == nil {
-										valuesToAdd[i] = int(intVal)
-									} else {
-										return fmt.Errorf("invalid range value type for field %s", field)
-									}
-								default:
-									return fmt.Errorf("invalid range value type for field %s", field)
-								}
-							}
-							whereConditions = append(whereConditions, fmt.Sprintf("`%s` >= ? AND `%s` <= ?", field, field))
-							values = append(values, valuesToAdd[0], valuesToAdd[1])
-						} else {
-							return fmt.Errorf("invalid range format for field %s", field)
-						}
-					default:
-						return fmt.Errorf("invalid range type for field %s", field)
-					}
-					continue // After handling range, do not process this field further
-				} else {
-					return fmt.Errorf("invalid range format for field %s", field)
-				}
-			} else {
-				whereConditions = append(whereConditions, fmt.Sprintf("`%s` = ?", field))
-				values = append(values, value)
-			}
+		matchCase, err := matchCaseOption(s, args)
+		if err != nil {
+			return err
 		}
 
+		// Build COUNT query with WHERE clause from remaining args
+		builder := NewQueryBuilder(source.from).
+			SelectExpr(countExpr + " AS count").
+			MatchCase(matchCase).
+			Where(args)
+
 		// Add LIKE clause if present
 		if likeValue != nil {
-			likeStr := fmt.Sprintf("%v", likeValue)
-			if !strings.Contains(likeStr, "%") {
-				likeStr = "%" + likeStr + "%"
-			}
-			textColumns, err := getTextColumns(db)
+			textColumns, err := getTextColumns(ctx, s, source.table)
 			if err != nil {
 				return err
 			}
 			if len(textColumns) == 0 {
 				return fmt.Errorf("no text columns available for LIKE query")
 			}
-			var likeConds []string
-			for _, col := range textColumns {
-				likeConds = append(likeConds, fmt.Sprintf("`%s` LIKE ?", col))
-				values = append(values, likeStr)
-			}
-			likeClause := "(" + strings.Join(likeConds, " OR ") + ")"
-			whereConditions = append(whereConditions, likeClause)
+			builder.WhereLike(likeValue, textColumns)
+		}
+
+		if by != "" {
+			builder.Having(having)
+			return groupedAggregate(ctx, s, builder, by, countExpr, "count", "Counts", chartColumn)
+		}
+		if chartColumn != "" {
+			return fmt.Errorf("chart requires by with COUNT, e.g. {COUNT: '*', by: 'category', chart: count}")
 		}
 
-		query := fmt.Sprintf("SELECT %s AS count FROM %s", countExpr, CurrentTable)
-		if len(whereConditions) > 0 {
-			query += " WHERE " + strings.Join(whereConditions, " AND ")
+		query, values, err := builder.Select()
+		if err != nil {
+			return err
 		}
-		// DEBUG: Print the final query and values for troubleshooting
-		// log.Printf("[DEBUG] COUNT query: %s\n", query)
-		// log.Printf("[DEBUG] COUNT values: %#v\n", values)
 		// Execute COUNT query
-		row := db.QueryRow(query, values...)
+		row := s.queryRow(ctx, query, values...)
 		var countResult int64
 		if err := row.Scan(&countResult); err != nil {
 			return err
 		}
-		if useJsonOutput {
-			fmt.Printf("Count: %s\n", ColorJSON(map[string]any{"count": countResult}))
+		if s.JSONOutput {
+			fmt.Fprintf(s.Out, "Count: %s\n", ColorJSON(map[string]any{"count": countResult}))
 		} else {
-			fmt.Println()
-			fmt.Printf("| %-5s |", "count")
-			fmt.Println("+-------+")
-			fmt.Printf("| %-5d |", countResult)
-			fmt.Println("+-------+")
-			fmt.Printf("\n1 row in set\n")
+			fmt.Fprintln(s.Out)
+			fmt.Fprintf(s.Out, "| %-5s |", "count")
+			fmt.Fprintln(s.Out, "+-------+")
+			fmt.Fprintf(s.Out, "| %-5d |", countResult)
+			fmt.Fprintln(s.Out, "+-------+")
+			fmt.Fprintf(s.Out, "\n1 row in set%s\n", s.timing())
 		}
 		return nil
 	} else if hasAggregate {
-		// --- LIKE support for aggregate functions ---
-		var likeValue any
-		if args != nil {
-			if v, ok := args["LIKE"]; ok {
-				likeValue = v
-				delete(args, "LIKE")
-			} else if v, ok := args["like"]; ok {
-				likeValue = v
-				delete(args, "like")
-			}
-		}
+		// --- LIKE search support for aggregate functions ---
+		likeValue := searchValue(args)
 
 		// Build aggregate function query
 		var aggregateExpr string
-		if s, ok := aggregateTarget.(string); ok {
-			if distinct {
-				aggregateExpr = fmt.Sprintf("%s(DISTINCT `%s`)", aggregateFunc, s)
+		if target, ok := aggregateTarget.(string); ok {
+			if aggregateFunc == "LIST" {
+				aggregateExpr = listExpression(target, distinct, separator)
+			} else if distinct {
+				aggregateExpr = fmt.Sprintf("%s(DISTINCT `%s`)", aggregateFunc, target)
 			} else {
-				aggregateExpr = fmt.Sprintf("%s(`%s`)", aggregateFunc, s)
+				aggregateExpr = fmt.Sprintf("%s(`%s`)", aggregateFunc, target)
 			}
 		} else {
 			return fmt.Errorf("aggregate function requires a column name")
 		}
 
-		// Build WHERE clause from remaining args
-		var whereConditions []string
-		var values []any
-		for field, value := range args {
-			if sliceValue, ok := value.([]any); ok {
-				if len(sliceValue) == 0 {
-					whereConditions = append(whereConditions, "0=1")
-				} else {
-					placeholders := make([]string, len(sliceValue))
-					for i, v := range sliceValue {
-						placeholders[i] = "?"
-						values = append(values, v)
-					}
-					whereConditions = append(whereConditions, fmt.Sprintf("`%s` IN (%s)", field, strings.Join(placeholders, ",")))
-				}
-			} else if mapValue, ok := value.(map[string]any); ok {
-				// Support both []int and []any for range
-				if rangeVal, ok := mapValue["range"]; ok {
-					switch rangeSlice := rangeVal.(type) {
-					case []int:
-						if len(rangeSlice) == 2 {
-							whereConditions = append(whereConditions, fmt.Sprintf("`%s` >= ? AND `%s` <= ?", field, field))
-							values = append(values, rangeSlice[0], rangeSlice[1])
-						} else {
-							return fmt.Errorf("invalid range format for field %s", field)
-						}
-					case []any:
-						if len(rangeSlice) == 2 {
-							valuesToAdd := make([]any, 2)
-							for i := 0; i < 2; i++ {
-								switch v := rangeSlice[i].(type) {
-								case int:
-									valuesToAdd[i] = v
-								case float64:
-									valuesToAdd[i] = int(v)
-								case json.Number:
-									if intVal, err := v.Int64(); err == nil {
-										valuesToAdd[i] = int(intVal)
-									} else {
-										return fmt.Errorf("invalid range value type for field %s", field)
-									}
-								default:
-									return fmt.Errorf("invalid range value type for field %s", field)
-								}
-							}
-							whereConditions = append(whereConditions, fmt.Sprintf("`%s` >= ? AND `%s` <= ?", field, field))
-							values = append(values, valuesToAdd[0], valuesToAdd[1])
-						} else {
-							return fmt.Errorf("invalid range format for field %s", field)
-						}
-					default:
-						return fmt.Errorf("invalid range type for field %s", field)
-					}
-					continue // After handling range, do not process this field further
-				} else {
-					return fmt.Errorf("invalid range format for field %s", field)
-				}
-			} else {
-				whereConditions = append(whereConditions, fmt.Sprintf("`%s` = ?", field))
-				values = append(values, value)
-			}
+		// Use aggregateFunc to name the result column
+		resultColumnName := strings.ToLower(aggregateFunc)
+
+		matchCase, err := matchCaseOption(s, args)
+		if err != nil {
+			return err
 		}
 
+		// Build aggregate query with WHERE clause from remaining args
+		builder := NewQueryBuilder(source.from).
+			SelectExpr(fmt.Sprintf("%s AS %s", aggregateExpr, resultColumnName)).
+			MatchCase(matchCase).
+			Where(args)
+
 		// Add LIKE clause if present
 		if likeValue != nil {
-			likeStr := fmt.Sprintf("%v", likeValue)
-			if !strings.Contains(likeStr, "%") {
-				likeStr = "%" + likeStr + "%"
-			}
-			textColumns, err := getTextColumns(db)
+			textColumns, err := getTextColumns(ctx, s, source.table)
 			if err != nil {
 				return err
 			}
 			if len(textColumns) == 0 {
 				return fmt.Errorf("no text columns available for LIKE query")
 			}
-			var likeConds []string
-			for _, col := range textColumns {
-				likeConds = append(likeConds, fmt.Sprintf("`%s` LIKE ?", col))
-				values = append(values, likeStr)
-			}
-			likeClause := "(" + strings.Join(likeConds, " OR ") + ")"
-			whereConditions = append(whereConditions, likeClause)
+			builder.WhereLike(likeValue, textColumns)
 		}
 
-		// Use aggregateFunc to name the result column
-		resultColumnName := strings.ToLower(aggregateFunc)
-		query := fmt.Sprintf("SELECT %s AS %s FROM %s", aggregateExpr, resultColumnName, CurrentTable)
-		if len(whereConditions) > 0 {
-			query += " WHERE " + strings.Join(whereConditions, " AND ")
+		if by != "" {
+			builder.Having(having)
+			return groupedAggregate(ctx, s, builder, by, aggregateExpr, resultColumnName, aggregateFunc, chartColumn)
+		}
+		if chartColumn != "" {
+			return fmt.Errorf("chart requires by with %s, e.g. {%s: total, by: 'category', chart: %s}", aggregateFunc, aggregateFunc, resultColumnName)
 		}
 
-		// DEBUG: Print the final query and values for troubleshooting
-		log.Printf("[DEBUG] %s query: %s\n", aggregateFunc, query)
-		log.Printf("[DEBUG] %s values: %#v\n", aggregateFunc, values)
+		query, values, err := builder.Select()
+		if err != nil {
+			return err
+		}
 
 		// Execute aggregate query
-		row := db.QueryRow(query, values...)
-		var result any
-		if err := row.Scan(&result); err != nil {
+		result, err := queryAggregate(ctx, s, query, values)
+		if err != nil {
 			return err
 		}
-		// Convert []byte to string for string columns
-		if b, ok := result.([]byte); ok {
-			result = string(b)
-		}
 
-		if useJsonOutput {
-			fmt.Printf("%s: %s\n", aggregateFunc, ColorJSON(map[string]any{resultColumnName: result}))
+		if s.JSONOutput {
+			fmt.Fprintf(s.Out, "%s: %s\n", aggregateFunc, ColorJSON(map[string]any{resultColumnName: result}))
 		} else {
-			fmt.Println()
-			fmt.Printf("| %-10s |", resultColumnName)
-			fmt.Println("+-----------+")
-			fmt.Printf("| %-10v |", result)
-			fmt.Println("+-----------+")
-			fmt.Printf("\n1 row in set\n")
+			fmt.Fprintln(s.Out)
+			fmt.Fprintf(s.Out, "| %-10s |", resultColumnName)
+			fmt.Fprintln(s.Out, "+-----------+")
+			if _, ok := result.(Decimal); ok {
+				fmt.Fprintf(s.Out, "| %10v |", result)
+			} else {
+				fmt.Fprintf(s.Out, "| %-10v |", result)
+			}
+			fmt.Fprintln(s.Out, "+-----------+")
+			fmt.Fprintf(s.Out, "\n1 row in set%s\n", s.timing())
 		}
 		return nil
 	}
 
+	if by != "" {
+		return fmt.Errorf("by requires COUNT or an aggregate, e.g. {COUNT: '*', by: 'created_at/day'}")
+	}
+
+	builder, err := buildGetQuery(ctx, s, source, args)
+	if err != nil {
+		return err
+	}
+	if sample > 0 {
+		if builder, err = sampleQuery(ctx, s, source, builder, sample, seed); err != nil {
+			return err
+		}
+	}
+
+	query, values, err := builder.Select()
+	if err != nil {
+		return err
+	}
+
+	booleans, err := booleanColumns(ctx, s, source.table)
+	if err != nil {
+		return err
+	}
+
+	// Page tabular output with SET pagesize, unless the GET limits itself
+	if !s.JSONOutput && chartColumn == "" && sample == 0 && s.pageSize > 0 && builder.limit == nil && builder.offset == nil {
+		return pageTabular(ctx, s, builder, s.pageSize, booleans)
+	}
+
+	rows, err := s.query(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Get column names
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	display, err := s.newColumnFormatter(rows, booleans)
+	if err != nil {
+		return err
+	}
+
+	// Stream tabular output when a sample size is configured. Charts need
+	// every value first.
+	if !s.JSONOutput && chartColumn == "" {
+		sampleRows, err := s.sampleRows()
+		if err != nil {
+			return err
+		}
+		if sampleRows > 0 {
+			return streamTabular(s, rows, columns, sampleRows, display)
+		}
+	}
+
+	// Prepare results
+	var results []map[string]any
+
+	for rows.Next() {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
+			return err
+		}
+		display.format(entry)
+		results = append(results, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.recordRows(int64(len(results)))
+	s.rememberResult(columns, results)
+
+	// Output results
+	if len(results) == 0 {
+		fmt.Fprintln(s.Out, "No records found")
+		return nil
+	}
+
+	if s.JSONOutput {
+		// Colorized JSON output
+		// Special case for single ID lookup for backward compatibility
+		_, isComparisonText := comparisonText(args["id"])
+		if id, ok := args["id"]; ok && len(args) == 1 && !isArrayOrRange(id) && !isComparisonText && len(results) == 1 {
+			// Single result by ID
+			fmt.Fprintf(s.Out, "Record: %s\n", ColorJSON(results[0]))
+		} else {
+			// Multiple results or non-ID query
+			fmt.Fprintf(s.Out, "Records: %s\n", ColorJSON(results))
+		}
+	} else {
+		// MySQL-style tabular output
+		if chartColumn != "" {
+			if columns, err = addBarChart(columns, results, chartColumn); err != nil {
+				return err
+			}
+		}
+		s.printTable(columns, results)
+	}
+
+	return nil
+}
+
+// buildGetQuery builds the SELECT from source for a GET from its column
+// selection, ordering, LIMIT/OFFSET, LIKE, case and filter arguments. The special
+// keys are removed from args, leaving only the filters.
+func buildGetQuery(ctx context.Context, s *Session, source getSource, args map[string]any) (*QueryBuilder, error) {
 	// --- Column selection support ---
-	var selectColumns string = "*"
 	var selectedCols []string
 	if args != nil {
 		if colsRaw, ok := args["_columns"]; ok {
 			if cols, ok := colsRaw.([]string); ok && len(cols) > 0 {
-				var quoted []string
-				for _, c := range cols {
-					quoted = append(quoted, fmt.Sprintf("`%s`", c))
-					selectedCols = append(selectedCols, c)
-				}
-				selectColumns = strings.Join(quoted, ", ")
+				selectedCols = append(selectedCols, cols...)
 				delete(args, "_columns")
 			} else if colsIface, ok := colsRaw.([]any); ok && len(colsIface) > 0 {
-				var quoted []string
 				for _, c := range colsIface {
-					if s, ok := c.(string); ok {
-						quoted = append(quoted, fmt.Sprintf("`%s`", s))
-						selectedCols = append(selectedCols, s)
+					if col, ok := c.(string); ok {
+						selectedCols = append(selectedCols, col)
 					}
 				}
-				if len(quoted) > 0 {
-					selectColumns = strings.Join(quoted, ", ")
+				if len(selectedCols) > 0 {
 					delete(args, "_columns")
 				}
 			}
 		}
 	}
-	if len(selectedCols) == 0 {
+	builder := NewQueryBuilder(source.from)
+	if len(selectedCols) > 0 {
+		if source.union && !containsString(selectedCols, sourceTableColumn) {
+			selectedCols = append([]string{sourceTableColumn}, selectedCols...)
+		}
+		builder.Columns(selectedCols...)
+	} else {
 		// No explicit columns requested, use all columns
-		allCols, err := getColumns(db)
+		allCols, err := tableColumns(ctx, s, source.table)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		selectedCols = allCols
 	}
 
-	// Build query based on args
-	var query string
-	var values []any
-	var orderByClause string
-
-	// Check for ordering parameters
-	if args != nil {
-		if upValue, ok := args["up"]; ok {
-			// Order ascending
-			if colName, ok := upValue.(string); ok {
-				orderByClause = fmt.Sprintf(" ORDER BY `%s` ASC", colName)
+	// Check for DISTINCT (case-insensitive)
+	for _, key := range []string{"DISTINCT", "distinct"} {
+		if v, ok := args[key]; ok {
+			distinct, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("distinct requires true or false, e.g. {_columns: [status], distinct: true}")
 			}
-			delete(args, "up")
-		} else if upValue, ok := args["UP"]; ok {
-			// Same for uppercase variant
-			if colName, ok := upValue.(string); ok {
-				orderByClause = fmt.Sprintf(" ORDER BY `%s` ASC", colName)
-			}
-			delete(args, "UP")
+			builder.Distinct(distinct)
+			delete(args, key)
+			break
 		}
+	}
 
-		if downValue, ok := args["down"]; ok {
-			// Order descending
-			if colName, ok := downValue.(string); ok {
-				orderByClause = fmt.Sprintf(" ORDER BY `%s` DESC", colName)
-			}
-			delete(args, "down")
-		} else if downValue, ok := args["DOWN"]; ok {
-			// Same for uppercase variant
-			if colName, ok := downValue.(string); ok {
-				orderByClause = fmt.Sprintf(" ORDER BY `%s` DESC", colName)
-			}
-			delete(args, "DOWN")
-		}
+	// Check for ordering parameters
+	order, err := sortKeys(args)
+	if err != nil {
+		return nil, err
 	}
 
 	// --- LIMIT/OFFSET support ---
-	var limitClause string
 	var limValue any
 	var offValue any
 	if args != nil {
@@ -504,213 +577,155 @@ func HandleGet(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 			offValue = v
 			delete(args, "off")
 		}
-		// Validate limit and offset are non-negative integers
-		if limValue != nil {
-			if limInt, ok := toInt(limValue); ok {
-				if limInt < 0 {
-					return fmt.Errorf("LIMIT must be non-negative")
-				}
-			} else {
-				return fmt.Errorf("LIMIT must be an integer")
-			}
-		}
-		if offValue != nil {
-			if offInt, ok := toInt(offValue); ok {
-				if offInt < 0 {
-					return fmt.Errorf("OFFSET must be non-negative")
-				}
-			} else {
-				return fmt.Errorf("OFFSET must be an integer")
-			}
-		}
-		if limValue != nil {
-			limitClause = " LIMIT ?"
-			if offValue != nil {
-				limitClause += " OFFSET ?"
-			}
-		}
 	}
 
-	// --- LIKE support ---
-	var likeValue any
-	if args != nil {
-		if v, ok := args["LIKE"]; ok {
-			likeValue = v
-			delete(args, "LIKE")
-		} else if v, ok := args["like"]; ok {
-			likeValue = v
-			delete(args, "like")
-		}
+	// --- LIKE search support ---
+	likeValue := searchValue(args)
+
+	matchCase, err := matchCaseOption(s, args)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(args) == 0 {
-		// Get all records
-		query = fmt.Sprintf("SELECT %s FROM %s", selectColumns, CurrentTable)
-	} else {
-		// Build WHERE clause
-		var whereConditions []string
-
-		for field, value := range args {
-			if sliceValue, ok := value.([]any); ok {
-				// Handle array of values (IN clause)
-				if len(sliceValue) == 0 {
-					// Handle empty array
-					whereConditions = append(whereConditions, "0=1") // No results should match
-				} else {
-					placeholders := make([]string, len(sliceValue))
-					for i, v := range sliceValue {
-						placeholders[i] = "?"
-						// Convert numbers or other types to appropriate string representation if needed
-						switch val := v.(type) {
-						case int, int32, int64, float32, float64:
-							// Keep numeric values as they are
-							values = append(values, val)
-						default:
-							// Convert other types to string
-							values = append(values, fmt.Sprintf("%v", val))
-						}
-					}
-					whereConditions = append(whereConditions,
-						fmt.Sprintf("`%s` IN (%s)", field, strings.Join(placeholders, ",")))
+	// Build query from the remaining filters
+	builder.MatchCase(matchCase).Where(args)
+	if likeValue != nil {
+		builder.WhereLike(likeValue, selectedCols)
+	}
+	for _, key := range order {
+		builder.OrderBy(key.column, key.desc)
+	}
+	builder.Limit(limValue, offValue)
+
+	return builder, nil
+}
+
+// sortKey is a column a GET sorts by
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+// sortKeys removes up, down and order from args and returns the sort keys
+// they give. up and down take a column or a list of columns, and order a
+// list of 'column asc' or 'column desc'. The keys of order come first, then
+// those of up and down.
+func sortKeys(args map[string]any) ([]sortKey, error) {
+	var keys []sortKey
+	for _, key := range []string{"ORDER", "order"} {
+		v, ok := args[key]
+		if !ok {
+			continue
+		}
+		for _, entry := range sortList(v) {
+			text, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("order requires 'column asc' or 'column desc', e.g. order: ['status asc', 'score desc']")
+			}
+			fields := strings.Fields(text)
+			if len(fields) == 0 || len(fields) > 2 {
+				return nil, fmt.Errorf("invalid order %q: use 'column asc' or 'column desc'", text)
+			}
+			desc := false
+			if len(fields) == 2 {
+				switch strings.ToLower(fields[1]) {
+				case "asc":
+				case "desc":
+					desc = true
+				default:
+					return nil, fmt.Errorf("invalid order %q: use 'column asc' or 'column desc'", text)
 				}
-			} else if mapValue, ok := value.(map[string]any); ok {
-				// Handle range
-				if rangeSlice, ok := mapValue["range"].([]int); ok && len(rangeSlice) == 2 {
-					whereConditions = append(whereConditions,
-						fmt.Sprintf("`%s` >= ? AND `%s` <= ?", field, field))
-					values = append(values, rangeSlice[0], rangeSlice[1])
-				} else {
-					return fmt.Errorf("invalid range format for field %s", field)
+			}
+			keys = append(keys, sortKey{column: fields[0], desc: desc})
+		}
+		delete(args, key)
+		break
+	}
+	for _, direction := range []struct {
+		keys []string
+		desc bool
+	}{{[]string{"up", "UP"}, false}, {[]string{"down", "DOWN"}, true}} {
+		for _, key := range direction.keys {
+			v, ok := args[key]
+			if !ok {
+				continue
+			}
+			for _, entry := range sortList(v) {
+				column, ok := entry.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s requires a column or a list of columns, e.g. %s: ['status', 'name']", direction.keys[0], direction.keys[0])
 				}
-			} else {
-				// Single value
-				whereConditions = append(whereConditions, fmt.Sprintf("`%s` = ?", field))
-				values = append(values, value)
+				keys = append(keys, sortKey{column: column, desc: direction.desc})
 			}
+			delete(args, key)
+			break
 		}
+	}
+	return keys, nil
+}
 
-		// Build the WHERE clause
-		if len(whereConditions) > 0 {
-			query = fmt.Sprintf("SELECT %s FROM %s WHERE %s",
-				selectColumns, CurrentTable, strings.Join(whereConditions, " AND "))
-		} else {
-			// No conditions, get all
-			query = fmt.Sprintf("SELECT %s FROM %s", selectColumns, CurrentTable)
-		}
+// sortList returns the entries of a sort argument, a single value or a list
+func sortList(v any) []any {
+	if list, ok := v.([]any); ok {
+		return list
 	}
+	return []any{v}
+}
 
-	// Add LIKE condition if present
-	if likeValue != nil {
-		if len(selectedCols) == 0 {
-			return fmt.Errorf("no columns found for LIKE clause")
-		}
-		var likeConditions []string
-		likeStr := fmt.Sprintf("%v", likeValue)
-		if !strings.Contains(likeStr, "%") {
-			likeStr = "%" + likeStr + "%"
-		}
-		for _, col := range selectedCols {
-			likeConditions = append(likeConditions, fmt.Sprintf("`%s` LIKE ?", col))
-			values = append(values, likeStr)
-		}
-		likeClause := fmt.Sprintf("(%s)", strings.Join(likeConditions, " OR "))
-		if strings.Contains(query, "WHERE") {
-			query = fmt.Sprintf("%s AND %s", query, likeClause)
-		} else {
-			query = fmt.Sprintf("%s WHERE %s", query, likeClause)
+// searchValue removes the pattern searched for in every text column from
+// args, given as search or like, and returns it
+func searchValue(args map[string]any) any {
+	for _, key := range []string{"SEARCH", "search", "LIKE", "like"} {
+		if v, ok := args[key]; ok {
+			delete(args, key)
+			return v
 		}
 	}
+	return nil
+}
 
-	// Add ORDER BY clause if present
-	if orderByClause != "" {
-		query += orderByClause
-	}
-	// Add LIMIT/OFFSET clause if present
-	if limitClause != "" {
-		query += limitClause
-	}
+// DefaultListSeparator separates the values of a LIST unless separator
+// gives another
+const DefaultListSeparator = ", "
 
-	// Execute query
-	if limValue != nil && offValue != nil {
-		values = append(values, limValue, offValue)
-	} else if limValue != nil {
-		values = append(values, limValue)
+// listExpression returns the GROUP_CONCAT of column for LIST, in order of
+// the values and without repeats when distinct is set
+func listExpression(column string, distinct bool, separator string) string {
+	expr := quoteIdent(column)
+	if distinct {
+		expr = "DISTINCT " + expr
 	}
+	return fmt.Sprintf("GROUP_CONCAT(%s ORDER BY %s SEPARATOR %s)", expr, quoteIdent(column), quoteString(separator))
+}
 
-	// DEBUG: Print the final query and values
-	log.Printf("[DEBUG] Executing query: %s\n", query)
-	log.Printf("[DEBUG] With values: %#v\n", values)
-
-	rows, err := db.Query(query, values...)
+// queryAggregate runs a query returning a single value, such as MIN(col),
+// converting []byte to string and formatting dates for display
+func queryAggregate(ctx context.Context, s *Session, query string, values []any) (any, error) {
+	rows, err := s.query(ctx, query, values...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer rows.Close()
 
-	// Get column names
 	columns, err := rows.Columns()
 	if err != nil {
-		return err
-	}
-	// DEBUG: Print the columns returned
-	// log.Printf("[DEBUG] Columns returned: %#v\n", columns)
-
-	// Prepare results
-	var results []map[string]any
-
-	for rows.Next() {
-		// Create a slice of any to hold the values
-		values := make([]any, len(columns))
-		valuePtrs := make([]any, len(columns))
-
-		for i := range columns {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return err
-		}
-
-		// Create a map for this row
-		entry := make(map[string]any)
-		for i, col := range columns {
-			var v any
-			val := values[i]
-
-			// Convert to appropriate Go type
-			b, ok := val.([]byte)
-			if ok {
-				v = string(b)
-			} else {
-				v = val
-			}
-
-			entry[col] = v
-		}
-
-		results = append(results, entry)
+		return nil, err
 	}
-
-	// Output results
-	if len(results) == 0 {
-		fmt.Println("No records found")
-		return nil
+	display, err := s.newColumnFormatter(rows, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if useJsonOutput {
-		// Colorized JSON output
-		// Special case for single ID lookup for backward compatibility
-		if id, ok := args["id"]; ok && len(args) == 1 && !isArrayOrRange(id) && len(results) == 1 {
-			// Single result by ID
-			fmt.Printf("Record: %s\n", ColorJSON(results[0]))
-		} else {
-			// Multiple results or non-ID query
-			fmt.Printf("Records: %s\n", ColorJSON(results))
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
 		}
-	} else {
-		// MySQL-style tabular output
-		PrintTabularResults(columns, results)
+		return nil, sql.ErrNoRows
 	}
-
-	return nil
+	entry, err := scanRecord(rows, columns)
+	if err != nil {
+		return nil, err
+	}
+	display.format(entry)
+	return entry[columns[0]], rows.Err()
 }
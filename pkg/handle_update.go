@@ -1,15 +1,16 @@
 package pkg
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 )
 
 // HandleUpdate handles the UPDATE command
-func HandleUpdate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
-	if CurrentTable == "" {
-		return fmt.Errorf("no table selected")
+func HandleUpdate(ctx context.Context, s *Session, args map[string]any) error {
+	if s.CurrentTable == "" {
+		return ErrNoTableSelected
 	}
 
 	if len(args) == 0 {
@@ -17,10 +18,16 @@ func HandleUpdate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 	}
 
 	// Get existing columns to differentiate between filter and update columns
-	existingCols, err := getColumns(db)
+	schema, err := tableSchema(ctx, s, s.CurrentTable)
 	if err != nil {
 		return err
 	}
+	existingCols := make([]string, len(schema))
+	columns := make(map[string]ColumnSchema, len(schema))
+	for i, col := range schema {
+		existingCols[i] = col.Name
+		columns[col.Name] = col
+	}
 
 	// Create maps for filter fields and update fields
 	filterFields := make(map[string]any)
@@ -41,8 +48,8 @@ func HandleUpdate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 
 	// Determine which fields are for filtering and which are for updating
 	for k, v := range args {
-		// Special handling for id field - always a filter
-		if k == "id" {
+		// Special handling for id field and conditions - always a filter
+		if k == "id" || k == WhereKey {
 			filterFields[k] = v
 			continue
 		}
@@ -68,137 +75,90 @@ func HandleUpdate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 	if len(updateFields) == 0 {
 		return fmt.Errorf("UPDATE requires fields to update")
 	}
+	binaryUUIDs(columns, filterFields)
 
 	// If no filter fields, use all records (with warning)
 	if len(filterFields) == 0 {
-		fmt.Println("Warning: No filter conditions specified. This will update ALL records in the table.")
-		fmt.Println("Do you want to continue? (y/N)")
-		response := ScanForConfirmation()
+		fmt.Fprintln(s.Out, "Warning: No filter conditions specified. This will update ALL records in the table.")
+		fmt.Fprintln(s.Out, "Do you want to continue? (y/N)")
+		response := s.confirm()
 		if strings.ToLower(response) != "y" {
-			return fmt.Errorf("operation cancelled")
+			return ErrConfirmationDeclined
 		}
 	}
 
 	// Ensure columns exist for update fields
-	if err := ensureColumns(db, updateFields); err != nil {
+	if err := ensureColumns(ctx, s, updateFields); err != nil {
+		return err
+	}
+	if err := warnNarrowCharset(ctx, s, updateFields); err != nil {
 		return err
 	}
 
-	// Build SET clause
-	var setStatements []string
-	var setValues []any
-
-	for k, v := range updateFields {
-		setStatements = append(setStatements, fmt.Sprintf("`%s` = ?", k))
-		setValues = append(setValues, v)
+	throttle, err := s.throttle()
+	if err != nil {
+		return err
 	}
 
-	// Build WHERE clause based on filter fields
-	var whereClause string
-	var whereValues []any
-
-	if len(filterFields) > 0 {
-		var whereConditions []string
-
-		for field, value := range filterFields {
-			if sliceValue, ok := value.([]any); ok {
-				// Handle array of values (IN clause)
-				if len(sliceValue) == 0 {
-					// Handle empty array
-					whereConditions = append(whereConditions, "0=1") // No results should match
-				} else {
-					placeholders := make([]string, len(sliceValue))
-					for i, v := range sliceValue {
-						placeholders[i] = "?"
-						// Convert numbers or other types to appropriate string representation if needed
-						switch val := v.(type) {
-						case int, int32, int64, float32, float64:
-							// Keep numeric values as they are
-							whereValues = append(whereValues, val)
-						default:
-							// Convert other types to string
-							whereValues = append(whereValues, fmt.Sprintf("%v", val))
-						}
-					}
-					whereConditions = append(whereConditions,
-						fmt.Sprintf("`%s` IN (%s)", field, strings.Join(placeholders, ",")))
-				}
-			} else if mapValue, ok := value.(map[string]any); ok {
-				// Handle range
-				if rangeSlice, ok := mapValue["range"].([]int); ok && len(rangeSlice) == 2 {
-					whereConditions = append(whereConditions,
-						fmt.Sprintf("`%s` >= ? AND `%s` <= ?", field, field))
-					whereValues = append(whereValues, rangeSlice[0], rangeSlice[1])
-				} else {
-					return fmt.Errorf("invalid range format for field %s", field)
-				}
-			} else {
-				// Single value
-				whereConditions = append(whereConditions, fmt.Sprintf("`%s` = ?", field))
-				whereValues = append(whereValues, value)
+	var affected int64
+	if throttle.active() && containsString(existingCols, "id") {
+		// Throttled updates run in batches of ids
+		batchSize, err := s.batchSize()
+		if err != nil {
+			return err
+		}
+		affected, err = s.writeInBatches(ctx, filterFields, throttle.batchSize(batchSize), throttle, func(clause string, whereArgs []any) (sql.Result, error) {
+			query, values, err := NewQueryBuilder(s.CurrentTable).Set(updateFields).WhereRaw(clause, whereArgs...).Update()
+			if err != nil {
+				return nil, err
 			}
+			return s.exec(ctx, query, values...)
+		})
+		if err != nil {
+			return err
 		}
-
-		whereClause = strings.Join(whereConditions, " AND ")
-	}
-
-	// Build query
-	var query string
-	var allValues []any
-
-	// Add SET values
-	allValues = append(allValues, setValues...)
-
-	if whereClause != "" {
-		query = fmt.Sprintf("UPDATE %s SET %s WHERE %s",
-			CurrentTable,
-			strings.Join(setStatements, ", "),
-			whereClause)
-
-		// Add WHERE values
-		allValues = append(allValues, whereValues...)
 	} else {
-		query = fmt.Sprintf("UPDATE %s SET %s",
-			CurrentTable,
-			strings.Join(setStatements, ", "))
-	}
+		// Build query: SET clause from update fields, WHERE clause from filter fields
+		query, allValues, err := NewQueryBuilder(s.CurrentTable).
+			Set(updateFields).
+			MatchCase(s.matchCase).
+			Where(filterFields).
+			Update()
+		if err != nil {
+			return err
+		}
 
-	// Execute query
-	result, err := db.Exec(query, allValues...)
-	if err != nil {
-		return err
-	}
+		// Execute query
+		result, err := s.exec(ctx, query, allValues...)
+		if err != nil {
+			return err
+		}
 
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return err
+		if affected, err = result.RowsAffected(); err != nil {
+			return err
+		}
 	}
+	s.recordRows(affected)
 
 	if affected == 0 {
-		return fmt.Errorf("no records matched the filter criteria")
+		return fmt.Errorf("%w matched the filter criteria", ErrNoRecords)
 	}
 
-	if useJsonOutput {
+	if s.JSONOutput {
 		// Select the updated records for JSON output
-		var selectQuery string
-		if whereClause != "" {
-			// The issue is here - when we update fields that are also used in the filter,
-			// running the same query again won't find any matches
-
-			// Original code - using the same whereClause as filter
-			// selectQuery = fmt.Sprintf("SELECT * FROM %s WHERE %s", CurrentTable, whereClause)
-			// return handleQueryAndDisplayResults(db, selectQuery, whereValues, len(filterFields) > 0, true)
-
-			// Modified code - to fix the issue, we need to select rows by their IDs
-			// First get the IDs of the affected rows
-			var idQuery string
-			if whereClause != "" {
-				idQuery = fmt.Sprintf("SELECT id FROM %s WHERE %s", CurrentTable, whereClause)
-			} else {
-				idQuery = fmt.Sprintf("SELECT id FROM %s", CurrentTable)
+		if len(filterFields) > 0 {
+			// Select rows by their IDs: running the filter again after the update
+			// may not match when the updated fields overlap the filter
+			idQuery, whereValues, err := NewQueryBuilder(s.CurrentTable).
+				Columns("id").
+				MatchCase(s.matchCase).
+				Where(filterFields).
+				Select()
+			if err != nil {
+				return err
 			}
 
-			rows, err := db.Query(idQuery, whereValues...)
+			rows, err := s.query(ctx, idQuery, whereValues...)
 			if err != nil {
 				return err
 			}
@@ -214,27 +174,29 @@ func HandleUpdate(db *sql.DB, args map[string]any, useJsonOutput bool) error {
 			}
 
 			// If we found matching rows, display them
-			if len(ids) > 0 {
-				placeholders := make([]string, len(ids))
-				for i := range placeholders {
-					placeholders[i] = "?"
-				}
-				selectQuery = fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)",
-					CurrentTable, strings.Join(placeholders, ","))
-
-				// Use these IDs to display the updated records
-				return handleQueryAndDisplayResults(db, selectQuery, ids, true, true)
-			} else {
-				return fmt.Errorf("no records matched the filter criteria")
+			if len(ids) == 0 {
+				return fmt.Errorf("%w matched the filter criteria", ErrNoRecords)
 			}
-		} else {
-			selectQuery = fmt.Sprintf("SELECT * FROM %s LIMIT 10", CurrentTable)
-			fmt.Printf("Updated %d record(s). Showing first 10:\n", affected)
-			return handleQueryAndDisplayResults(db, selectQuery, nil, true, true)
+			selectQuery, selectValues, err := NewQueryBuilder(s.CurrentTable).
+				Where(map[string]any{"id": ids}).
+				Select()
+			if err != nil {
+				return err
+			}
+
+			// Use these IDs to display the updated records
+			return handleQueryAndDisplayResults(ctx, s, selectQuery, selectValues, true, true)
+		}
+
+		selectQuery, selectValues, err := NewQueryBuilder(s.CurrentTable).Limit(10, nil).Select()
+		if err != nil {
+			return err
 		}
+		fmt.Fprintf(s.Out, "Updated %d record(s). Showing first 10:\n", affected)
+		return handleQueryAndDisplayResults(ctx, s, selectQuery, selectValues, true, true)
 	} else {
 		// MySQL-style tabular output
-		fmt.Printf("Query OK, %d rows affected\n", affected)
+		fmt.Fprintf(s.Out, "Query OK, %d rows affected%s\n", affected, s.timing())
 		return nil
 	}
 }
@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// GetStatusCommandRegex returns the regex for the STATUS command
+func GetStatusCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(STATUS)$`)
+}
+
+// Health summarizes the state of the connection: how fast the server
+// answers, which server it is, the session settings and, for a *sql.DB,
+// the connection pool
+type Health struct {
+	Latency       time.Duration `json:"-"`
+	LatencyMS     float64       `json:"latency_ms"`
+	Version       string        `json:"version"`
+	Server        string        `json:"server"`
+	User          string        `json:"user"`
+	ConnectionID  int64         `json:"connection_id"`
+	Uptime        int64         `json:"uptime_seconds"`
+	SSLCipher     string        `json:"ssl_cipher"`
+	Charset       string        `json:"charset"`
+	Collation     string        `json:"collation"`
+	Autocommit    bool          `json:"autocommit"`
+	InTransaction bool          `json:"in_transaction"`
+	Pool          *sql.DBStats  `json:"pool,omitempty"`
+}
+
+// Ping runs SELECT 1 and returns how long the server took to answer
+func (s *Session) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	var one int
+	if err := s.queryRow(ctx, "SELECT 1").Scan(&one); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// Health checks the connection and describes the server
+func (s *Session) Health(ctx context.Context) (Health, error) {
+	var health Health
+	latency, err := s.Ping(ctx)
+	if err != nil {
+		return health, err
+	}
+	health.Latency = latency
+	health.LatencyMS = float64(latency.Microseconds()) / 1000
+
+	var hostname string
+	var port int
+	if err := s.queryRow(ctx, `SELECT CONCAT(VERSION(), ' ', @@version_comment), @@hostname, @@port, CURRENT_USER(), CONNECTION_ID(),
+		@@character_set_connection, @@collation_connection, @@autocommit`).
+		Scan(&health.Version, &hostname, &port, &health.User, &health.ConnectionID,
+			&health.Charset, &health.Collation, &health.Autocommit); err != nil {
+		return health, err
+	}
+	// Commands only share a transaction when the session runs in one
+	_, health.InTransaction = s.DB.(*sql.Tx)
+	health.Server = fmt.Sprintf("%s:%d", hostname, port)
+
+	// Uptime is global, the cipher belongs to this connection
+	var name, value string
+	if err := s.queryRow(ctx, "SHOW GLOBAL STATUS LIKE 'Uptime'").Scan(&name, &value); err != nil {
+		return health, err
+	}
+	health.Uptime, _ = strconv.ParseInt(value, 10, 64)
+	if err := s.queryRow(ctx, "SHOW SESSION STATUS LIKE 'Ssl_cipher'").Scan(&name, &health.SSLCipher); err != nil {
+		return health, err
+	}
+
+	if db, ok := s.DB.(interface{ Stats() sql.DBStats }); ok {
+		stats := db.Stats()
+		health.Pool = &stats
+	}
+	return health, nil
+}
+
+// handleStatus shows the health of the connection, like the mysql client's
+// STATUS command
+func handleStatus(ctx context.Context, s *Session) error {
+	health, err := s.Health(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		status := map[string]any{
+			"connection":      health,
+			"database":        s.CurrentDB,
+			"table":           s.CurrentTable,
+			"commands_run":    s.commandsRun,
+			"commands_failed": s.commandsFailed,
+		}
+		if !s.started.IsZero() {
+			status["session_uptime_seconds"] = int64(time.Since(s.started).Seconds())
+		}
+		fmt.Fprintf(s.Out, "Status: %s\n", ColorJSON(status))
+		return nil
+	}
+
+	var results []map[string]any
+	add := func(field string, value any) {
+		results = append(results, map[string]any{"Field": field, "Value": value})
+	}
+	ssl := health.SSLCipher
+	if ssl == "" {
+		ssl = "Not in use"
+	}
+	add("Latency", fmt.Sprintf("%.3f ms", health.LatencyMS))
+	add("Server version", health.Version)
+	add("Server", health.Server)
+	add("User", health.User)
+	add("Connection id", health.ConnectionID)
+	add("SSL", ssl)
+	transaction := "None (autocommit)"
+	if health.InTransaction {
+		transaction = "Active"
+	} else if !health.Autocommit {
+		transaction = "None (autocommit off)"
+	}
+	add("Charset", fmt.Sprintf("%s (%s)", health.Charset, health.Collation))
+	add("Transaction", transaction)
+	add("Server uptime", formatUptime(time.Duration(health.Uptime)*time.Second))
+	add("Database", s.CurrentDB)
+	add("Table", s.CurrentTable)
+	if !s.started.IsZero() {
+		add("Session uptime", formatUptime(time.Since(s.started)))
+	}
+	add("Commands run", fmt.Sprintf("%d (%d failed)", s.commandsRun, s.commandsFailed))
+	if pool := health.Pool; pool != nil {
+		maxOpen := strconv.Itoa(pool.MaxOpenConnections)
+		if pool.MaxOpenConnections == 0 {
+			maxOpen = "unlimited"
+		}
+		add("Pool connections", fmt.Sprintf("%d open (%d in use, %d idle), max %s", pool.OpenConnections, pool.InUse, pool.Idle, maxOpen))
+		add("Pool waits", fmt.Sprintf("%d (%s)", pool.WaitCount, pool.WaitDuration))
+	}
+	s.printTable([]string{"Field", "Value"}, results)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/peterh/liner"
@@ -20,6 +21,8 @@ type CommandHistory struct {
 	maxHistoryEntries int
 	// History file path
 	historyFile string
+	// Key shortcuts mapped to the commands they run
+	bindings map[string]string
 }
 
 // NewCommandHistory creates a new command history manager
@@ -38,6 +41,7 @@ func NewCommandHistory(maxEntries int) *CommandHistory {
 
 	return &CommandHistory{
 		histories:         make(map[string][]string),
+		bindings:          make(map[string]string),
 		maxHistoryEntries: maxEntries,
 		historyFile:       filepath.Join(historyDir, "history.txt"),
 	}
@@ -167,19 +171,81 @@ func (h *CommandHistory) SaveHistory() {
 	line.WriteHistory(file)
 }
 
+// SetBindings registers key shortcuts, typically the [bind] section of the config.
+// Key names are case-insensitive and accept forms like "F5", "Ctrl-T", "C-t" or "^T".
+func (h *CommandHistory) SetBindings(bindings map[string]string) {
+	h.bindings = make(map[string]string)
+	for key, cmd := range bindings {
+		h.bindings[normalizeKeyName(key)] = strings.TrimSpace(cmd)
+	}
+}
+
+// BindingNames returns the bound key names in their canonical form, such as
+// "F5" or "CTRL-T"
+func (h *CommandHistory) BindingNames() []string {
+	names := make([]string, 0, len(h.bindings))
+	for name := range h.bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandBinding returns the command bound to the given shortcut. A bound command
+// of the form "!PREFIX" re-runs the most recent history entry starting with PREFIX.
+func (h *CommandHistory) ExpandBinding(input string) (string, bool) {
+	cmd, ok := h.bindings[normalizeKeyName(input)]
+	if !ok || cmd == "" {
+		return "", false
+	}
+
+	if !strings.HasPrefix(cmd, "!") {
+		return cmd, true
+	}
+
+	prefix := strings.ToUpper(strings.TrimSpace(cmd[1:]))
+	history := h.GetHistory()
+	for i := len(history) - 1; i >= 0; i-- {
+		if strings.HasPrefix(strings.ToUpper(history[i]), prefix) {
+			return history[i], true
+		}
+	}
+	return "", false
+}
+
+// normalizeKeyName converts the different spellings of a key to one canonical form
+func normalizeKeyName(key string) string {
+	k := strings.ToUpper(strings.TrimSpace(key))
+	k = strings.ReplaceAll(k, "+", "-")
+	switch {
+	case strings.HasPrefix(k, "^") && len(k) == 2:
+		k = "CTRL-" + k[1:]
+	case strings.HasPrefix(k, "C-"):
+		k = "CTRL-" + k[2:]
+	case strings.HasPrefix(k, "CONTROL-"):
+		k = "CTRL-" + k[len("CONTROL-"):]
+	}
+	return k
+}
+
 // SetupLiner configures a liner instance with the command history
 func (h *CommandHistory) SetupLiner() *liner.State {
 	line := liner.NewLiner()
 
 	// Enable tab completion for common commands
 	line.SetCompleter(func(line string) (c []string) {
-		commands := []string{"USE", "CREATE", "GET", "UPDATE", "DELETE", "EXIT"}
+		commands := []string{"USE", "CREATE", "GET", "UPDATE", "DELETE", "DESCRIBE", "SET", "SAVE", "RUN", "WATCH", "BENCH", "RECORD", "REPLAY", "STATUS", "STATS", "SNAPSHOT", "COPY", "SHOW", "SEARCH", "FIND", "EXPLAIN", "EDIT", "UNLOCK", "DUPES", "ANONYMIZE", "BINLOG", "FIXTURES", "CHECK", "REPORT", "KILL", "OPTIMIZE", "ANALYZE", "VERSION", "EXIT"}
 
 		for _, cmd := range commands {
 			if strings.HasPrefix(strings.ToUpper(cmd), strings.ToUpper(line)) {
 				c = append(c, cmd)
 			}
 		}
+		for key := range h.bindings {
+			if strings.HasPrefix(key, strings.ToUpper(line)) {
+				c = append(c, key)
+			}
+		}
 		return
 	})
 
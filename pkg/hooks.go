@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CommandInfo describes a command as it passes through the hooks
+type CommandInfo struct {
+	// Upper-cased command name, e.g. "GET"
+	Command string
+	// Command line as entered
+	Line string
+	// Parsed arguments; nil for commands without arguments
+	Args map[string]any
+	// SQL statements executed by the command, in order
+	SQL []string
+	// Parameters bound to each statement in SQL
+	Params [][]any
+	// Rows returned to the user or affected by writes
+	Rows int64
+	// When the command started executing
+	Started time.Time
+	// Time spent executing the command
+	Duration time.Duration
+	// Part of Duration spent waiting for MySQL to answer the statements,
+	// the rest being spent fetching and rendering results
+	QueryDuration time.Duration
+	// Error returned by the command, if any
+	Err error
+}
+
+// BeforeHook runs before a command executes. Returning an error aborts the
+// command with that error, which makes it usable for policy enforcement.
+type BeforeHook func(ctx context.Context, s *Session, info *CommandInfo) error
+
+// AfterHook runs after a command executed, whether or not it failed
+type AfterHook func(ctx context.Context, s *Session, info *CommandInfo)
+
+// Hooks holds the middleware run around every command of a session
+type Hooks struct {
+	Before []BeforeHook
+	After  []AfterHook
+}
+
+// AddBeforeHook registers a hook run before each command
+func (s *Session) AddBeforeHook(hook BeforeHook) {
+	s.Hooks.Before = append(s.Hooks.Before, hook)
+}
+
+// AddAfterHook registers a hook run after each command
+func (s *Session) AddAfterHook(hook AfterHook) {
+	s.Hooks.After = append(s.Hooks.After, hook)
+}
+
+// runBeforeHooks runs the before hooks, stopping at the first error
+func (s *Session) runBeforeHooks(ctx context.Context, info *CommandInfo) error {
+	for _, hook := range s.Hooks.Before {
+		if err := hook(ctx, s, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAfterHooks runs all after hooks
+func (s *Session) runAfterHooks(ctx context.Context, info *CommandInfo) {
+	for _, hook := range s.Hooks.After {
+		hook(ctx, s, info)
+	}
+}
+
+// recordSQL adds a statement to the command being executed
+func (s *Session) recordSQL(query string, args []any) {
+	if s.current == nil {
+		return
+	}
+	s.current.SQL = append(s.current.SQL, query)
+	s.current.Params = append(s.current.Params, args)
+}
+
+// recordRows sets the number of rows returned or affected by the current command
+func (s *Session) recordRows(n int64) {
+	if s.current != nil {
+		s.current.Rows = n
+	}
+}
+
+// recordQueryTime adds the time since start to the current command's query time
+func (s *Session) recordQueryTime(start time.Time) {
+	if s.current != nil {
+		s.current.QueryDuration += time.Since(start)
+	}
+}
+
+// logStatement writes a statement, its parameters and how long it took to
+// the debug log
+func (s *Session) logStatement(query string, args []any, start time.Time, err error) {
+	if !s.Logger.Enabled(LevelDebug) {
+		return
+	}
+	fields := []any{"sql", strings.Join(strings.Fields(query), " "), "args", fmt.Sprintf("%v", args), "duration", time.Since(start)}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+	s.Logger.Debug("statement", fields...)
+}
+
+// query runs a statement returning rows and records it for the hooks.
+// Outside a transaction, transient errors are retried.
+func (s *Session) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	s.recordSQL(query, args)
+	start := time.Now()
+	defer s.recordQueryTime(start)
+	var result *sql.Rows
+	run := func() error {
+		attempt := time.Now()
+		var err error
+		result, err = s.DB.QueryContext(ctx, query, args...)
+		s.logStatement(query, args, attempt, err)
+		return err
+	}
+	if s.inTx() {
+		return result, run()
+	}
+	return result, s.withRetry(ctx, true, run)
+}
+
+// queryRow runs a statement returning one row and records it for the hooks
+func (s *Session) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	s.recordSQL(query, args)
+	start := time.Now()
+	defer s.recordQueryTime(start)
+	row := s.DB.QueryRowContext(ctx, query, args...)
+	s.logStatement(query, args, start, nil)
+	return row
+}
+
+// exec runs a statement without rows and records it for the hooks.
+// Outside a transaction, deadlocks and lock wait timeouts are retried.
+func (s *Session) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	// USE only switches the database
+	if !strings.HasPrefix(query, "USE ") {
+		if err := s.checkWritable(); err != nil {
+			return nil, err
+		}
+	}
+	s.recordSQL(query, args)
+	start := time.Now()
+	defer s.recordQueryTime(start)
+	var result sql.Result
+	run := func() error {
+		attempt := time.Now()
+		var err error
+		result, err = s.DB.ExecContext(ctx, query, args...)
+		s.logStatement(query, args, attempt, err)
+		return err
+	}
+	if s.inTx() {
+		return result, run()
+	}
+	return result, s.withRetry(ctx, false, run)
+}
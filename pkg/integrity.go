@@ -0,0 +1,141 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GetCheckCommandRegex returns the regex for CHECK commands
+func GetCheckCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(CHECK)\s+(\S+)(?:\s+(\S+))?$`)
+}
+
+// orphanValuesShown is the number of missing values reported per reference
+const orphanValuesShown = 5
+
+// OrphanCheck is the result of checking one reference: the number of rows
+// whose value is missing from the referenced table, and the most frequent
+// missing values
+type OrphanCheck struct {
+	ForeignKey
+	Orphans int64 `json:"orphans"`
+	Missing []any `json:"missing"`
+}
+
+// CheckReferences looks for rows of table, or of every table when table is
+// empty, referencing rows that do not exist. NULL references are ignored.
+func (s *Session) CheckReferences(ctx context.Context, table string) ([]OrphanCheck, error) {
+	keys, err := s.References(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []OrphanCheck{}
+	for _, fk := range keys {
+		check := OrphanCheck{ForeignKey: fk, Missing: []any{}}
+		rows, err := s.query(ctx, orphanQuery(fk, fmt.Sprintf("c.%s AS value, COUNT(*) AS count", quoteIdent(fk.Column)))+
+			fmt.Sprintf(" GROUP BY c.%s ORDER BY count DESC, value", quoteIdent(fk.Column)))
+		if err != nil {
+			return nil, err
+		}
+		display, err := s.newColumnFormatter(rows, nil)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		for rows.Next() {
+			entry, err := scanRecord(rows, []string{"value", "count"})
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			display.format(entry)
+			count, _ := toInt64(entry["count"])
+			check.Orphans += count
+			if len(check.Missing) < orphanValuesShown {
+				check.Missing = append(check.Missing, entry["value"])
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// orphanQuery selects exprs from the rows of the referencing table, aliased
+// c, whose value is missing from the referenced table
+func orphanQuery(fk ForeignKey, exprs string) string {
+	return fmt.Sprintf("SELECT %s %s", exprs, orphanJoin(fk))
+}
+
+// orphanJoin returns the FROM and WHERE clauses matching the rows of the
+// referencing table, aliased c, whose value is missing from the referenced
+// table
+func orphanJoin(fk ForeignKey) string {
+	return fmt.Sprintf("FROM %s AS c LEFT JOIN %s AS p ON p.%s = c.%s WHERE c.%s IS NOT NULL AND p.%s IS NULL",
+		quoteIdent(fk.Table), quoteIdent(fk.RefTable), quoteIdent(fk.RefColumn), quoteIdent(fk.Column),
+		quoteIdent(fk.Column), quoteIdent(fk.RefColumn))
+}
+
+// handleCheck runs CHECK fks on the current table, or on the whole database
+// with CHECK fks all or when no table is selected
+func handleCheck(ctx context.Context, s *Session, target, scope string) error {
+	if !strings.EqualFold(target, "fks") {
+		return fmt.Errorf("unknown check %q. Use CHECK fks", target)
+	}
+	if s.CurrentDB == "" {
+		return fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+	table := s.CurrentTable
+	if strings.EqualFold(scope, "all") {
+		table = ""
+	} else if scope != "" {
+		return fmt.Errorf("usage: CHECK fks [all]")
+	}
+
+	checks, err := s.CheckReferences(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Orphans: %s\n", ColorJSON(checks))
+		return nil
+	}
+	if len(checks) == 0 {
+		fmt.Fprintln(s.Out, "No references found to check")
+		return nil
+	}
+
+	var total int64
+	results := make([]map[string]any, len(checks))
+	for i, check := range checks {
+		missing := make([]string, len(check.Missing))
+		for j, value := range check.Missing {
+			missing[j] = fmt.Sprintf("%v", value)
+		}
+		if int64(len(check.Missing)) < check.Orphans && len(check.Missing) == orphanValuesShown {
+			missing = append(missing, "...")
+		}
+		results[i] = map[string]any{
+			"Table":          check.Table,
+			"Column":         check.Column,
+			"References":     check.RefTable + "." + check.RefColumn,
+			"Orphans":        check.Orphans,
+			"Missing values": strings.Join(missing, ", "),
+		}
+		total += check.Orphans
+	}
+	s.printTable([]string{"Table", "Column", "References", "Orphans", "Missing values"}, results)
+	if total == 0 {
+		fmt.Fprintln(s.Out, "No orphaned rows found")
+	} else {
+		fmt.Fprintf(s.Out, "%d orphaned rows found\n", total)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GetMaintenanceCommandRegex returns the regex for OPTIMIZE and ANALYZE
+// commands
+func GetMaintenanceCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(OPTIMIZE|ANALYZE)(?:\s+(\S+))?$`)
+}
+
+// handleMaintenance runs OPTIMIZE TABLE or ANALYZE TABLE on the current
+// table, or on every table of the database with "all", printing each table as
+// it is processed and the messages MySQL returned at the end
+func handleMaintenance(ctx context.Context, s *Session, operation, scope string) error {
+	operation = strings.ToUpper(operation)
+	// OPTIMIZE rebuilds the tables
+	if operation == "OPTIMIZE" {
+		if err := s.checkWritable(); err != nil {
+			return err
+		}
+	}
+	var tables []string
+	switch {
+	case strings.EqualFold(scope, "all"):
+		if s.CurrentDB == "" {
+			return fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+		}
+		columns, err := databaseColumns(ctx, s)
+		if err != nil {
+			return err
+		}
+		for table := range columns {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+	case scope != "":
+		return fmt.Errorf("usage: %s [all]", operation)
+	case s.CurrentTable == "":
+		return fmt.Errorf("%w. Use 'USE table_name' to select a table or %s all", ErrNoTableSelected, operation)
+	default:
+		tables = []string{s.CurrentTable}
+	}
+
+	verb := "Optimizing"
+	if operation == "ANALYZE" {
+		verb = "Analyzing"
+	}
+	columns := []string{"Table", "Op", "Msg_type", "Msg_text"}
+	var results []map[string]any
+	for i, table := range tables {
+		fmt.Fprintf(s.Out, "%s %s (%d/%d)...\n", verb, table, i+1, len(tables))
+		rows, err := s.query(ctx, fmt.Sprintf("%s TABLE %s", operation, quoteIdent(table)))
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			entry, err := scanRecord(rows, columns)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			results = append(results, entry)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Results: %s\n", ColorJSON(results))
+		return nil
+	}
+	s.printTable(columns, results)
+	return nil
+}
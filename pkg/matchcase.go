@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// matchCaseRegex matches SET case sensitive|insensitive|default
+var matchCaseRegex = regexp.MustCompile(`(?i)^case\s+(sensitive|insensitive|default)$`)
+
+// handleSetMatchCase sets how filters and LIKE compare strings for
+// SET case: sensitive, insensitive, or by the collation of each column
+func handleSetMatchCase(s *Session, mode string) error {
+	mode = strings.ToLower(mode)
+	if mode == "default" {
+		mode = ""
+	}
+	s.matchCase = mode
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Case: %s\n", ColorJSON(map[string]any{"case": caseName(mode)}))
+	} else {
+		fmt.Fprintf(s.Out, "Matching %s\n", caseName(mode))
+	}
+	return nil
+}
+
+// caseName describes a match case for output
+func caseName(mode string) string {
+	if mode == "" {
+		return "by collation"
+	}
+	return "case-" + mode
+}
+
+// matchCaseOption removes the case argument of a command from args and
+// returns the match case it asks for, or else the one SET case chose
+func matchCaseOption(s *Session, args map[string]any) (string, error) {
+	for _, key := range []string{"CASE", "case"} {
+		v, ok := args[key]
+		if !ok {
+			continue
+		}
+		delete(args, key)
+		mode, _ := v.(string)
+		switch mode = strings.ToLower(mode); mode {
+		case CaseSensitive, CaseInsensitive:
+			return mode, nil
+		case "default":
+			return "", nil
+		}
+		return "", fmt.Errorf("case must be 'sensitive', 'insensitive' or 'default'")
+	}
+	return s.matchCase, nil
+}
@@ -0,0 +1,43 @@
+package pkg_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bogwi/noqli/pkg"
+)
+
+// mockSession returns a session on a sqlmock database with users selected
+// in shop, and checks once the test ends that every expected statement ran
+func mockSession(t *testing.T) (*pkg.Session, sqlmock.Sqlmock, *bytes.Buffer) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	var buf bytes.Buffer
+	session := pkg.NewSession(db)
+	session.CurrentDB = "shop"
+	session.CurrentTable = "users"
+	session.Out = &buf
+	session.Config["timing"] = "off"
+	return session, mock, &buf
+}
+
+// expectColumns expects SHOW COLUMNS FROM users, answering id, name and
+// email
+func expectColumns(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SHOW COLUMNS FROM users").WillReturnRows(
+		sqlmock.NewRows([]string{"Field", "Type", "Null", "Key", "Default", "Extra"}).
+			AddRow("id", "int", "NO", "PRI", nil, "auto_increment").
+			AddRow("name", "varchar(255)", "YES", "", nil, "").
+			AddRow("email", "varchar(255)", "YES", "", nil, ""))
+}
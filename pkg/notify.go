@@ -0,0 +1,89 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultNotifyAfter is how long a command must run before a notification is
+// sent when the config does not set notify.after
+const DefaultNotifyAfter = 30 * time.Second
+
+// notifyTimeout bounds the webhook request so a slow endpoint does not block the shell
+const notifyTimeout = 5 * time.Second
+
+// NotifyHook returns an after hook posting a message to webhookURL for every
+// command that ran for at least threshold. The payload is Slack-compatible:
+// {"text": "..."}.
+func NotifyHook(webhookURL string, threshold time.Duration) AfterHook {
+	return func(ctx context.Context, s *Session, info *CommandInfo) {
+		if info.Command == "" || info.Duration < threshold {
+			return
+		}
+		if err := postNotification(webhookURL, notificationText(s, info)); err != nil {
+			fmt.Fprintln(s.Out, "Warning: Could not send notification:", err)
+		}
+	}
+}
+
+// NotifyHookFromConfig builds the notification hook from the [notify] section
+// of the config. It returns nil when no webhook is configured.
+func NotifyHookFromConfig(config Config) (AfterHook, error) {
+	webhookURL := config.Get("notify.webhook")
+	if webhookURL == "" {
+		return nil, nil
+	}
+
+	threshold, ok, err := config.Duration("notify.after")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		threshold = DefaultNotifyAfter
+	}
+	return NotifyHook(webhookURL, threshold), nil
+}
+
+// notificationText describes the outcome of a finished command
+func notificationText(s *Session, info *CommandInfo) string {
+	location := s.CurrentDB
+	if s.CurrentTable != "" {
+		location += "." + s.CurrentTable
+	}
+	duration := info.Duration.Round(time.Millisecond)
+
+	if info.Err != nil {
+		return fmt.Sprintf("noqli: `%s` on %s failed after %s: %v", info.Line, location, duration, info.Err)
+	}
+	return fmt.Sprintf("noqli: `%s` on %s finished in %s (%d rows)", info.Line, location, duration, info.Rows)
+}
+
+// postNotification sends text to a webhook
+func postNotification(webhookURL, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pageSizeRegex matches SET pagesize n
+var pageSizeRegex = regexp.MustCompile(`(?i)^pagesize\s+(\d+)$`)
+
+// morePrompt is shown between pages; Enter shows the next page, q stops
+const morePrompt = "-- More (Enter/q) --"
+
+// handleSetPageSize sets how many rows GET shows at a time for
+// SET pagesize; 0 turns paging off
+func handleSetPageSize(s *Session, size string) error {
+	n, err := strconv.Atoi(size)
+	if err != nil {
+		return fmt.Errorf("invalid pagesize %q: must be a non-negative integer", size)
+	}
+	s.pageSize = n
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Page size: %s\n", ColorJSON(map[string]any{"pagesize": n}))
+	} else if n == 0 {
+		fmt.Fprintln(s.Out, "Paging off")
+	} else {
+		fmt.Fprintf(s.Out, "Page size %d\n", n)
+	}
+	return nil
+}
+
+// pageTabular shows the rows of builder pageSize at a time, asking before
+// each further page. Pages are fetched lazily with LIMIT and OFFSET, one row
+// more than shown telling whether another page follows, so stopping early
+// neither reads nor holds the rest of the result.
+func pageTabular(ctx context.Context, s *Session, builder *QueryBuilder, pageSize int, booleans map[string]bool) error {
+	var table *TableWriter
+	var columns []string
+	var shown []map[string]any
+	for offset := 0; ; offset += pageSize {
+		query, values, err := builder.Limit(pageSize+1, offset).Select()
+		if err != nil {
+			return err
+		}
+		page, pageColumns, err := fetchPage(ctx, s, query, values, booleans)
+		if err != nil {
+			return err
+		}
+		if table == nil {
+			if len(page) == 0 {
+				s.recordRows(0)
+				fmt.Fprintln(s.Out, "No records found")
+				return nil
+			}
+			columns = pageColumns
+			table = s.newTableWriter(columns, pageSize)
+		}
+
+		more := len(page) > pageSize
+		if more {
+			page = page[:pageSize]
+		}
+		for _, entry := range page {
+			table.Write(entry)
+		}
+		shown = append(shown, page...)
+		if !more {
+			break
+		}
+		fmt.Fprint(s.Out, morePrompt)
+		if strings.EqualFold(strings.TrimSpace(s.confirm()), "q") {
+			break
+		}
+	}
+
+	s.recordRows(int64(len(shown)))
+	s.rememberResult(columns, shown)
+	table.Suffix = s.timing()
+	table.Close()
+	return nil
+}
+
+// fetchPage runs the query of one page and returns its formatted rows and
+// columns
+func fetchPage(ctx context.Context, s *Session, query string, values []any, booleans map[string]bool) ([]map[string]any, []string, error) {
+	rows, err := s.query(ctx, query, values...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	display, err := s.newColumnFormatter(rows, booleans)
+	if err != nil {
+		return nil, nil, err
+	}
+	var page []map[string]any
+	for rows.Next() {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
+			return nil, nil, err
+		}
+		display.format(entry)
+		page = append(page, entry)
+	}
+	return page, columns, rows.Err()
+}
@@ -1,18 +1,15 @@
 package pkg
 
 import (
-	"encoding/json"
 	"fmt"
-	// "log"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
-// Global variables to track current database and table
-var CurrentDB string
-var CurrentTable string
-
 // GetCommandRegex returns the regex used to parse NoQLi commands
 func GetCommandRegex() *regexp.Regexp {
 	return regexp.MustCompile(`(?i)^(CREATE|GET|UPDATE|DELETE|USE)\s*(.*)$`)
@@ -23,6 +20,11 @@ func GetUseCommandRegex() *regexp.Regexp {
 	return regexp.MustCompile(`(?i)^USE\s+(.+)$`)
 }
 
+// GetAskCommandRegex returns the regex for ASK commands
+func GetAskCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^ASK\s+(.+)$`)
+}
+
 // IsGetDbsCommand checks if the command is GET dbs
 func IsGetDbsCommand(command string, args string) bool {
 	return strings.ToUpper(command) == "GET" && strings.ToLower(strings.TrimSpace(args)) == "dbs"
@@ -33,6 +35,42 @@ func IsGetTablesCommand(command string, args string) bool {
 	return strings.ToUpper(command) == "GET" && strings.ToLower(strings.TrimSpace(args)) == "tables"
 }
 
+// SplitStatements splits a line into the commands separated by ';', ignoring
+// separators inside quotes, braces, brackets and parentheses. Empty commands
+// are dropped.
+func SplitStatements(line string) []string {
+	var statements []string
+	depth := 0
+	quote := byte(0)
+	start := 0
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++ // skip the escaped character
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '{' || c == '[' || c == '(':
+			depth++
+		case (c == '}' || c == ']' || c == ')') && depth > 0:
+			depth--
+		case c == ';' && depth == 0:
+			if stmt := strings.TrimSpace(line[start:i]); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			start = i + 1
+		}
+	}
+	if stmt := strings.TrimSpace(line[start:]); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
+
 // ParseArg parses the argument string into a map
 func ParseArg(str string) (map[string]any, error) {
 	if str == "" {
@@ -48,245 +86,657 @@ func ParseArg(str string) (map[string]any, error) {
 	}
 
 	// Handle object notation
-	if strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
-		return parseObjectNotation(trimmed)
+	if strings.HasPrefix(trimmed, "{") {
+		p := &argParser{src: str, pos: strings.Index(str, "{")}
+		obj, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectEnd(); err != nil {
+			return nil, err
+		}
+		return obj, nil
 	}
 
-	return nil, fmt.Errorf("invalid argument format")
+	return nil, newParseError(str, len(str)-len(strings.TrimLeft(str, " \t")), "invalid argument format").
+		withHint("arguments are an id such as 5 or an object such as {name: 'John'}")
 }
 
-// DisplayPrompt shows the appropriate prompt based on current selections
-func DisplayPrompt() string {
-	prompt := "noqli"
-	if CurrentDB != "" {
-		prompt += ":" + CurrentDB
-		if CurrentTable != "" {
-			prompt += ":" + CurrentTable
+// ParseArgList parses a list of objects such as '[{name: a}, {name: b}]'
+// used by batch CREATE
+func ParseArgList(str string) ([]map[string]any, error) {
+	p := &argParser{src: str}
+	p.skipSpace()
+	if !p.consume('[') {
+		return nil, newParseError(str, p.pos, "expected a list of objects").
+			withHint("a batch looks like [{name: 'a'}, {name: 'b'}]")
+	}
+
+	var list []map[string]any
+	p.skipSpace()
+	if p.consume(']') {
+		return nil, newParseError(str, p.pos-1, "empty list")
+	}
+	for {
+		p.skipSpace()
+		if p.peek() != '{' {
+			return nil, newParseError(str, p.pos, "expected an object")
+		}
+		obj, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, obj)
+
+		p.skipSpace()
+		if p.consume(']') {
+			break
+		}
+		if !p.consume(',') {
+			return nil, p.unexpected("',' or ']'")
 		}
 	}
-	prompt += "> "
-	return prompt
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+	return list, nil
 }
 
-// parseObjectNotation handles the '{field1: value, field2: value}' syntax
-func parseObjectNotation(str string) (map[string]any, error) {
-	// Remove surrounding braces
-	trimmed := strings.TrimSpace(str[1 : len(str)-1])
-
-	// Result map
-	result := make(map[string]any)
+// argParser is a recursive-descent parser for the object notation:
+//
+//	{name, email}              columns, stored under _columns
+//	{name: 'John', age: 30}    fields with quoted or bare values
+//	{id: (1, 10)}              inclusive ranges
+//	{created_at: > now()-7d}   comparisons with >, >=, <, <= or !=
+//	{status: [a, b]}           lists
+//	{[name, title] = 'Test'}   one value assigned to several fields
+//	{meta: {source: 'api'}}    nested objects, to any depth
+//	{(a: 1 or b: 2) and not c: 3}  conditions with and, or, not and grouping
+type argParser struct {
+	src string
+	pos int
+	// groups is the number of open condition groups
+	groups int
+}
 
-	// Handle array assignments like [field1,field2] = value
-	arrayFieldRegex := regexp.MustCompile(`\[([^\]]+)\]\s*=\s*([^,}]+)`)
-	arrayMatches := arrayFieldRegex.FindAllStringSubmatch(trimmed, -1)
+// WhereKey is the argument holding the conditions combined with and, or and
+// not, as a BoolExpr
+const WhereKey = "_where"
 
-	// Process array field assignments
-	for _, match := range arrayMatches {
-		fullMatch := match[0]
-		fields := strings.Split(match[1], ",")
-		valueStr := strings.TrimSpace(match[2])
+// keywordAheadRegex matches 'and' or 'or' followed by the start of another
+// condition, which ends an unquoted value. 'rock and roll' stays one value.
+var keywordAheadRegex = regexp.MustCompile(`^\s+(?i:and|or)\s+(?:(?i:not)\s|\(|[^\s:,{}()\[\]'"]+\s*:)`)
 
-		// Replace in the original string
-		trimmed = strings.Replace(trimmed, fullMatch, "", 1)
+// addCondition adds expr to the conditions of result, combined with AND
+func addCondition(result map[string]any, expr BoolExpr) {
+	if existing, ok := result[WhereKey].(BoolExpr); ok {
+		expr = BoolExpr{Op: "and", Args: []BoolExpr{existing, expr}}
+	}
+	result[WhereKey] = expr
+}
 
-		// Parse the value
-		var value any
+// objectList returns the objects of value when it is a list of objects
+func objectList(value any) ([]map[string]any, bool) {
+	list, ok := value.([]any)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	objects := make([]map[string]any, len(list))
+	for i, elem := range list {
+		if objects[i], ok = elem.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return objects, true
+}
 
-		// Try as JSON
-		if err := json.Unmarshal([]byte(valueStr), &value); err != nil {
-			// If not JSON, use string with quotes removed
-			value = strings.Trim(valueStr, `'\"`)
+// objectsCondition combines objects with op, "and" or "or". The fields of
+// each object must all match, as in a filter, and its own conditions, such
+// as a nested {or: [...]}, too.
+func objectsCondition(op string, objects []map[string]any) (BoolExpr, error) {
+	expr := BoolExpr{Op: op}
+	for _, object := range objects {
+		if _, ok := object["_columns"]; ok {
+			return BoolExpr{}, fmt.Errorf("%s conditions cannot select columns; give each field a value", op)
+		}
+		fields := make([]string, 0, len(object))
+		for field := range object {
+			if field != WhereKey {
+				fields = append(fields, field)
+			}
 		}
+		sort.Strings(fields)
 
-		// Assign to all fields
+		and := BoolExpr{Op: "and"}
 		for _, field := range fields {
-			result[strings.TrimSpace(field)] = value
+			and.Args = append(and.Args, BoolExpr{Field: field, Value: object[field]})
 		}
-	}
-
-	// --- Extract leading columns ---
-	var colList []string
-	parts := splitRespectingQuotes(trimmed, ',')
-	for i, part := range parts {
-		p := strings.TrimSpace(part)
-		if p == "" || strings.HasPrefix(p, "[") {
-			continue
+		if where, ok := object[WhereKey].(BoolExpr); ok {
+			and.Args = append(and.Args, where)
 		}
-		// If it looks like a key-value pair, stop collecting columns
-		if strings.Contains(p, ":") || strings.Contains(p, "=") {
-			// The rest will be handled by key-value logic below
-			break
+		if len(and.Args) == 0 {
+			return BoolExpr{}, fmt.Errorf("%s conditions cannot be empty objects", op)
 		}
-		colList = append(colList, p)
-		// Remove from trimmed string
-		parts[i] = ""
+		expr.Args = append(expr.Args, and)
 	}
-	if len(colList) > 0 {
-		result["_columns"] = colList
+	return expr, nil
+}
+
+// parseOr parses conditions joined by 'or'. When first is set it is the
+// already parsed first condition.
+func (p *argParser) parseOr(first *BoolExpr) (BoolExpr, error) {
+	expr, err := p.parseAnd(first)
+	if err != nil {
+		return BoolExpr{}, err
 	}
-	// Rebuild trimmed string with remaining parts
-	var remaining []string
-	for _, p := range parts {
-		if strings.TrimSpace(p) != "" {
-			remaining = append(remaining, p)
+	args := []BoolExpr{expr}
+	for p.skipSpace(); p.consumeKeyword("or"); p.skipSpace() {
+		next, err := p.parseAnd(nil)
+		if err != nil {
+			return BoolExpr{}, err
 		}
+		args = append(args, next)
+	}
+	if len(args) == 1 {
+		return expr, nil
 	}
-	trimmed = strings.Join(remaining, ",")
+	return BoolExpr{Op: "or", Args: args}, nil
+}
 
-	// Process ID range syntax: id: (start, stop)
-	rangeRegex := regexp.MustCompile(`id\s*:\s*\(([^,]+),([^)]+)\)`)
-	if rangeMatches := rangeRegex.FindStringSubmatch(trimmed); len(rangeMatches) > 0 {
-		fullMatch := rangeMatches[0]
-		start, err := strconv.Atoi(strings.TrimSpace(rangeMatches[1]))
+// parseAnd parses conditions joined by 'and', which binds tighter than 'or'
+func (p *argParser) parseAnd(first *BoolExpr) (BoolExpr, error) {
+	var expr BoolExpr
+	if first != nil {
+		expr = *first
+	} else {
+		var err error
+		if expr, err = p.parseCondition(); err != nil {
+			return BoolExpr{}, err
+		}
+	}
+	args := []BoolExpr{expr}
+	for p.skipSpace(); p.consumeKeyword("and"); p.skipSpace() {
+		next, err := p.parseCondition()
 		if err != nil {
-			return nil, fmt.Errorf("invalid range start: %v", err)
+			return BoolExpr{}, err
 		}
+		args = append(args, next)
+	}
+	if len(args) == 1 {
+		return expr, nil
+	}
+	return BoolExpr{Op: "and", Args: args}, nil
+}
 
-		end, err := strconv.Atoi(strings.TrimSpace(rangeMatches[2]))
+// parseCondition parses 'not' followed by a condition, a parenthesized group
+// or a single 'field: value' filter
+func (p *argParser) parseCondition() (BoolExpr, error) {
+	p.skipSpace()
+	if p.consumeKeyword("not") {
+		expr, err := p.parseCondition()
 		if err != nil {
-			return nil, fmt.Errorf("invalid range end: %v", err)
+			return BoolExpr{}, err
 		}
+		return BoolExpr{Op: "not", Args: []BoolExpr{expr}}, nil
+	}
 
-		result["id"] = map[string]any{
-			"range": []int{start, end},
+	if start := p.pos; p.consume('(') {
+		p.groups++
+		expr, err := p.parseOr(nil)
+		if err != nil {
+			return BoolExpr{}, err
 		}
-
-		// Replace in the original string
-		trimmed = strings.Replace(trimmed, fullMatch, "", 1)
+		p.groups--
+		p.skipSpace()
+		if p.atEnd() {
+			return BoolExpr{}, newParseError(p.src, start, "unterminated group").withHint("add the missing ')'")
+		}
+		if !p.consume(')') {
+			return BoolExpr{}, p.unexpected("')'")
+		}
+		return expr, nil
 	}
 
-	// Clean up the remaining string
-	trimmed = strings.TrimSpace(trimmed)
-	trimmed = regexp.MustCompile(`,\s*,`).ReplaceAllString(trimmed, ",")
-	trimmed = regexp.MustCompile(`^,|,$`).ReplaceAllString(trimmed, "")
-
-	// Improved array parsing
-	// Find all KEY: [ARRAY] patterns
-	arrayRegex := regexp.MustCompile(`(\w+)\s*:\s*\[(.*?)\]`)
-	arrayMatches = arrayRegex.FindAllStringSubmatch(trimmed, -1)
+	key, err := p.parseKey()
+	if err != nil {
+		return BoolExpr{}, err
+	}
+	p.skipSpace()
+	if !p.consume(':') {
+		return BoolExpr{}, p.unexpected("':'")
+	}
+	value, err := p.parseFieldValue()
+	if err != nil {
+		return BoolExpr{}, err
+	}
+	return BoolExpr{Field: key, Value: value}, nil
+}
 
-	for _, match := range arrayMatches {
-		if len(match) >= 3 {
-			key := match[1]
-			arrayContent := match[2]
+// atKeyword reports whether the keyword word, in any case, starts at the
+// current position
+func (p *argParser) atKeyword(word string) bool {
+	end := p.pos + len(word)
+	if end >= len(p.src) || !strings.EqualFold(p.src[p.pos:end], word) {
+		return false
+	}
+	return unicode.IsSpace(rune(p.src[end])) || p.src[end] == '('
+}
 
-			// Remove the array pattern from the string
-			fullMatch := match[0]
-			trimmed = strings.Replace(trimmed, fullMatch, "", 1)
+// consumeKeyword advances past the keyword word when it is next
+func (p *argParser) consumeKeyword(word string) bool {
+	if !p.atKeyword(word) {
+		return false
+	}
+	p.pos += len(word)
+	return true
+}
 
-			// Split the array content by commas (respecting quotes)
-			var arrayElements []any
-			elements := splitRespectingQuotes(arrayContent, ',')
+// parseObject parses a '{...}' object starting at the current position
+func (p *argParser) parseObject() (map[string]any, error) {
+	start := p.pos
+	if !p.consume('{') {
+		return nil, p.unexpected("'{'")
+	}
 
-			for _, elem := range elements {
-				elemTrimmed := strings.TrimSpace(elem)
+	result := make(map[string]any)
+	var columns []string
+	for {
+		p.skipSpace()
+		if p.atEnd() {
+			return nil, newParseError(p.src, start, "unterminated object").withHint("add the missing '}'")
+		}
+		if p.consume('}') {
+			break
+		}
 
-				// Handle quoted strings
-				if (strings.HasPrefix(elemTrimmed, "\"") && strings.HasSuffix(elemTrimmed, "\"")) ||
-					(strings.HasPrefix(elemTrimmed, "'") && strings.HasSuffix(elemTrimmed, "'")) {
-					// Remove quotes
-					value := strings.Trim(elemTrimmed, `'"`)
-					arrayElements = append(arrayElements, value)
-				} else if num, err := strconv.Atoi(elemTrimmed); err == nil {
-					// It's a number
-					arrayElements = append(arrayElements, num)
+		if p.peek() == '[' {
+			// Handle array assignments like [field1, field2] = value
+			if err := p.parseMultiAssignment(result); err != nil {
+				return nil, err
+			}
+		} else if p.peek() == '(' || p.atKeyword("not") {
+			// A grouped or negated condition
+			expr, err := p.parseOr(nil)
+			if err != nil {
+				return nil, err
+			}
+			addCondition(result, expr)
+		} else {
+			keyStart := p.pos
+			key, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			if p.consume(':') {
+				value, err := p.parseFieldValue()
+				if err != nil {
+					return nil, err
+				}
+				p.skipSpace()
+				if p.atKeyword("and") || p.atKeyword("or") {
+					// The field starts a condition like 'a: 1 or b: 2'
+					expr, err := p.parseOr(&BoolExpr{Field: key, Value: value})
+					if err != nil {
+						return nil, err
+					}
+					addCondition(result, expr)
+				} else if objects, ok := objectList(value); ok && (strings.EqualFold(key, "or") || strings.EqualFold(key, "and")) {
+					// {or: [{...}, {...}]} combines the conditions of each object
+					expr, err := objectsCondition(strings.ToLower(key), objects)
+					if err != nil {
+						return nil, newParseError(p.src, keyStart, "%s", err).withLen(len(key))
+					}
+					addCondition(result, expr)
 				} else {
-					// It's an unquoted string or identifier
-					arrayElements = append(arrayElements, elemTrimmed)
+					result[key] = value
 				}
+			} else {
+				// A bare name selects a column
+				columns = append(columns, key)
 			}
+		}
 
-			// Add the array to the result map
-			result[key] = arrayElements
+		p.skipSpace()
+		if p.consume('}') {
+			break
+		}
+		if p.atEnd() {
+			return nil, newParseError(p.src, start, "unterminated object").withHint("add the missing '}'")
+		}
+		if !p.consume(',') {
+			return nil, p.unexpected("',' or '}'").
+				withHint("separate fields with ',' and quote values containing ':' or braces")
 		}
 	}
 
-	// Process remaining key-value pairs
-	if trimmed != "" {
-		// Try to parse as JSON
-		jsonStr := "{" + strings.Replace(trimmed, "'", "\"", -1) + "}"
-		var jsonObj map[string]any
+	if len(columns) > 0 {
+		result["_columns"] = columns
+	}
+	return result, nil
+}
 
-		if err := json.Unmarshal([]byte(jsonStr), &jsonObj); err != nil {
-			// If JSON parsing fails, try a more manual approach
-			keyValuePairs := strings.Split(trimmed, ",")
-			for _, pair := range keyValuePairs {
-				parts := strings.SplitN(pair, ":", 2)
-				if len(parts) != 2 {
-					continue
-				}
+// parseMultiAssignment parses '[field1, field2] = value' into result
+func (p *argParser) parseMultiAssignment(result map[string]any) error {
+	p.consume('[')
+	var fields []string
+	for {
+		p.skipSpace()
+		field, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		fields = append(fields, field)
+		p.skipSpace()
+		if p.consume(']') {
+			break
+		}
+		if !p.consume(',') {
+			return p.unexpected("',' or ']'")
+		}
+	}
 
-				key := strings.TrimSpace(parts[0])
-				valueStr := strings.TrimSpace(parts[1])
+	p.skipSpace()
+	if !p.consume('=') {
+		return p.unexpected("'='")
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		result[field] = value
+	}
+	return nil
+}
 
-				// Skip array values we already processed
-				if strings.HasPrefix(valueStr, "[") && strings.HasSuffix(valueStr, "]") {
-					continue
-				}
-				// log.Printf("[DEBUG] key: %s, value: %s\n", key, valueStr)
-
-				// Handle simple values
-				valueStr = strings.Trim(valueStr, `'"`)
-				if num, err := strconv.Atoi(valueStr); err == nil {
-					result[key] = num
-				} else if strings.EqualFold(valueStr, "true") {
-					result[key] = true
-				} else if strings.EqualFold(valueStr, "false") {
-					result[key] = false
-				} else {
-					// If not a number, use as string
-					result[key] = valueStr
-				}
-				// log.Printf("[DEBUG] err: %#v\n", err)
+// parseKey parses a field name, quoted or bare
+func (p *argParser) parseKey() (string, error) {
+	if c := p.peek(); c == '\'' || c == '"' {
+		return p.parseQuoted()
+	}
+	start := p.pos
+	for !p.atEnd() && !strings.ContainsRune(":,{}[]()='\"", rune(p.peek())) && !unicode.IsSpace(rune(p.peek())) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.unexpected("a field name").withHint("field names are words such as name or quoted strings such as 'full name'")
+	}
+	return p.src[start:p.pos], nil
+}
+
+// comparisonOperators are the operators a field value may start with,
+// longest first
+var comparisonOperators = []string{">=", "<=", "!=", ">", "<"}
+
+// parseFieldValue parses the value of a field, which may also be a range or
+// a comparison
+func (p *argParser) parseFieldValue() (any, error) {
+	p.skipSpace()
+	if p.peek() == '(' {
+		return p.parseRange()
+	}
+	for _, op := range comparisonOperators {
+		if strings.HasPrefix(p.src[p.pos:], op) {
+			p.pos += len(op)
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
 			}
-		} else {
-			// If JSON parsing succeeds, merge the results
-			// Post-process: convert string 'true'/'false' to boolean
-			for k, v := range jsonObj {
-				// Skip array values we already processed
-				if _, exists := result[k]; !exists {
-					result[k] = v
-				}
+			return Comparison{Op: op, Value: value}, nil
+		}
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// parseRange parses '(start, end)' into {range: [start, end]}. The bounds
+// are numbers, dates, quoted or not, or variables. An end date without a
+// time runs to the end of that day.
+func (p *argParser) parseRange() (any, error) {
+	p.consume('(')
+	bounds := make([]any, 2)
+	for i, name := range []string{"start", "end"} {
+		start := p.pos
+		for depth := 0; !p.atEnd() && (depth > 0 || (p.peek() != ',' && p.peek() != ')')); p.pos++ {
+			if p.peek() == '(' {
+				depth++
+			} else if p.peek() == ')' {
+				depth--
 			}
 		}
+		text := strings.TrimSpace(p.src[start:p.pos])
+		// Dates may be quoted, e.g. ('2024-01-01', '2024-03-31')
+		date := text
+		if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') && text[len(text)-1] == text[0] {
+			date = text[1 : len(text)-1]
+		}
+		if n, ok := parseNumber(text); ok {
+			bounds[i] = n
+		} else if t, ok := parseDateLiteral(date); ok {
+			if i == 1 && len(date) == len("2006-01-02") {
+				// An end date without a time covers that whole day
+				t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+			}
+			bounds[i] = t
+		} else if m := variableRegex.FindStringSubmatch(text); m != nil {
+			bounds[i] = Variable{Name: m[1]}
+		} else {
+			return nil, newParseError(p.src, start, "invalid range %s %q", name, text).
+				withLen(p.pos - start).
+				withHint("range bounds are numbers, dates or variables, e.g. (1, 10) or ('2024-01-01', '2024-03-31')")
+		}
+
+		want := byte(',')
+		if i == 1 {
+			want = ')'
+		}
+		if !p.consume(want) {
+			return nil, p.unexpected("'" + string(want) + "'")
+		}
 	}
+	if start, ok := bounds[0].(int); ok {
+		if end, ok := bounds[1].(int); ok {
+			return map[string]any{"range": []int{start, end}}, nil
+		}
+	}
+	return map[string]any{"range": bounds}, nil
+}
 
-	return result, nil
+// parseValue parses a nested object, a list, a quoted string or a bare value
+func (p *argParser) parseValue() (any, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case p.atEnd():
+		return nil, p.unexpected("a value")
+	case c == '{':
+		return p.parseObject()
+	case c == '[':
+		return p.parseList()
+	case c == '\'' || c == '"':
+		return p.parseQuoted()
+	default:
+		return p.parseBare()
+	}
 }
 
-// Helper function to split a string by a delimiter respecting quotes
-func splitRespectingQuotes(str string, delimiter rune) []string {
-	var result []string
-	var current strings.Builder
-	inQuotes := false
-	quoteChar := rune(0)
+// parseList parses '[a, b, ...]' into a []any
+func (p *argParser) parseList() (any, error) {
+	start := p.pos
+	p.consume('[')
+	list := []any{}
+	p.skipSpace()
+	if p.consume(']') {
+		return list, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
 
-	for _, char := range str {
+		p.skipSpace()
+		if p.consume(']') {
+			return list, nil
+		}
+		if p.atEnd() {
+			return nil, newParseError(p.src, start, "unterminated list").withHint("add the missing ']'")
+		}
+		if !p.consume(',') {
+			return nil, p.unexpected("',' or ']'")
+		}
+	}
+}
+
+// parseQuoted parses a string in single or double quotes. A backslash
+// escapes the quote character or another backslash.
+func (p *argParser) parseQuoted() (string, error) {
+	start := p.pos
+	quote := p.src[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+	for !p.atEnd() {
+		c := p.src[p.pos]
 		switch {
-		case char == '"' || char == '\'':
-			if inQuotes && char == quoteChar {
-				// Closing quote
-				inQuotes = false
-				quoteChar = rune(0)
-			} else if !inQuotes {
-				// Opening quote
-				inQuotes = true
-				quoteChar = char
-			}
-			current.WriteRune(char)
-		case char == delimiter && !inQuotes:
-			// Found delimiter outside quotes
-			result = append(result, current.String())
-			current.Reset()
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src) && (p.src[p.pos+1] == quote || p.src[p.pos+1] == '\\'):
+			sb.WriteByte(p.src[p.pos+1])
+			p.pos += 2
 		default:
-			current.WriteRune(char)
+			sb.WriteByte(c)
+			p.pos++
 		}
 	}
+	return "", newParseError(p.src, start, "unterminated string").
+		withLen(len(p.src) - start).
+		withHint("close the string with the quote it starts with; escape quotes inside it with a backslash")
+}
 
-	// Add the last part
-	if current.Len() > 0 {
-		result = append(result, current.String())
+// parseBare parses an unquoted value up to the next separator or the 'and'
+// or 'or' of a condition. Parentheses may appear inside, e.g. now().
+func (p *argParser) parseBare() (any, error) {
+	start := p.pos
+	depth := 0
+	for ; !p.atEnd(); p.pos++ {
+		c := p.src[p.pos]
+		if c == '(' {
+			depth++
+		} else if c == ')' && depth > 0 {
+			depth--
+		} else if depth == 0 && (c == ',' || c == '}' || c == ']' || (c == ')' && p.groups > 0)) {
+			break
+		} else if depth == 0 && unicode.IsSpace(rune(c)) && keywordAheadRegex.MatchString(p.src[p.pos:]) {
+			break
+		}
 	}
+	text := strings.TrimSpace(p.src[start:p.pos])
+	if text == "" {
+		return nil, newParseError(p.src, start, "expected a value")
+	}
+	return bareValue(text), nil
+}
+
+// bareValue converts an unquoted value to a number, bool, nil, date or
+// variable reference when it is one. true, false and null are
+// case-insensitive.
+func bareValue(text string) any {
+	if num, ok := parseNumber(text); ok {
+		return num
+	}
+	if t, ok := parseDateLiteral(text); ok {
+		return t
+	}
+	if v, ok := parseSpatialLiteral(text); ok {
+		return v
+	}
+	if v, ok := parseUUIDFunc(text); ok {
+		return v
+	}
+	if strings.EqualFold(text, "true") {
+		return true
+	}
+	if strings.EqualFold(text, "false") {
+		return false
+	}
+	if strings.EqualFold(text, "null") {
+		return nil
+	}
+	if m := variableRegex.FindStringSubmatch(text); m != nil {
+		return Variable{Name: m[1]}
+	}
+	return text
+}
 
-	return result
+// intLiteralRegex and floatLiteralRegex match numbers with an optional sign,
+// underscores between digits, a fraction and an exponent
+var (
+	intLiteralRegex   = regexp.MustCompile(`^[+-]?\d+(_\d+)*$`)
+	floatLiteralRegex = regexp.MustCompile(`^[+-]?(\d+(_\d+)*)?(\.\d+(_\d+)*)?([eE][+-]?\d+)?$`)
+)
+
+// parseNumber converts a numeric literal such as -42, 1_000, 3.5 or 2.5e-3
+// to an int or a float64. Integers too large for an int stay unconverted, so
+// long digit strings such as phone numbers are not rounded.
+func parseNumber(text string) (any, bool) {
+	digits := strings.ReplaceAll(text, "_", "")
+	if intLiteralRegex.MatchString(text) {
+		n, err := strconv.Atoi(digits)
+		return n, err == nil
+	}
+	if !floatLiteralRegex.MatchString(text) || !strings.ContainsAny(text, "0123456789") {
+		return nil, false
+	}
+	f, err := strconv.ParseFloat(digits, 64)
+	return f, err == nil
+}
+
+// expectEnd fails unless only whitespace is left
+func (p *argParser) expectEnd() error {
+	p.skipSpace()
+	if !p.atEnd() {
+		return newParseError(p.src, p.pos, "unexpected %q after the argument", p.src[p.pos:]).
+			withLen(len(p.src) - p.pos).
+			withHint("commands take a single argument; separate several commands with ';'")
+	}
+	return nil
+}
+
+// unexpected reports the character at the current position
+func (p *argParser) unexpected(want string) *ParseError {
+	if p.atEnd() {
+		return newParseError(p.src, p.pos, "expected %s, got end of input", want)
+	}
+	return newParseError(p.src, p.pos, "expected %s, got %q", want, p.src[p.pos])
+}
+
+func (p *argParser) atEnd() bool { return p.pos >= len(p.src) }
+
+// peek returns the current character, or 0 at the end
+func (p *argParser) peek() byte {
+	if p.atEnd() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// consume advances past c when it is the current character
+func (p *argParser) consume(c byte) bool {
+	if p.peek() == c && !p.atEnd() {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *argParser) skipSpace() {
+	for !p.atEnd() && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
 }
@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultPreviewSize is the length in bytes above which tables show a
+// preview of a value instead of all of it
+const DefaultPreviewSize = 256
+
+// previewLength is the number of characters, or bytes of binary values,
+// a preview shows
+const previewLength = 16
+
+// base64Regex matches values that look like base64 encoded data
+var base64Regex = regexp.MustCompile(`^[A-Za-z0-9+/\r\n]+={0,2}$`)
+
+// previewEscaper keeps a preview on one line
+var previewEscaper = strings.NewReplacer("\r", `\r`, "\n", `\n`, "\t", `\t`)
+
+// GetShowCellCommandRegex returns the regex for SHOW cell n column [> file]
+func GetShowCellCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(SHOW)\s+cell\s+(\d+)\s+([^\s>]+)(?:\s*>\s*(.+))?$`)
+}
+
+// valueKind names the kind of a string value for previews: binary when it
+// is not valid UTF-8, base64 when it looks encoded, text otherwise
+func valueKind(v string) string {
+	switch {
+	case !utf8.ValidString(v):
+		return "binary"
+	case len(v) >= 4*previewLength && base64Regex.MatchString(v):
+		return "base64"
+	}
+	return "text"
+}
+
+// previewValue shortens a value longer than size bytes, or binary, to its
+// kind, total length and first characters, e.g.
+// <text, 14.2 KiB> "Lorem ipsum dolo…". Binary values are shown in hex.
+func previewValue(v string, size int) string {
+	kind := valueKind(v)
+	if kind != "binary" && len(v) <= size {
+		return v
+	}
+	label := fmt.Sprintf("<%s, %s>", kind, formatBytes(int64(len(v))))
+	if kind == "binary" {
+		if len(v) <= previewLength {
+			return label + " " + hex.EncodeToString([]byte(v))
+		}
+		return label + " " + hex.EncodeToString([]byte(v[:previewLength])) + "…"
+	}
+	head := v
+	if runes := []rune(v); len(runes) > previewLength {
+		head = string(runes[:previewLength]) + "…"
+	}
+	return label + ` "` + previewEscaper.Replace(head) + `"`
+}
+
+// handleShowCell prints the full value of column in row n of the last GET
+// result, or writes it to path. Binary values can only be written to a file.
+func handleShowCell(s *Session, n, column, path string) error {
+	index, row, err := s.resultRow(n)
+	if err != nil {
+		return err
+	}
+	value, err := s.resultCell(row, column)
+	if err != nil {
+		return err
+	}
+	text, err := cellText(value)
+	if err != nil {
+		return err
+	}
+
+	path = strings.Trim(strings.TrimSpace(path), `"'`)
+	if path != "" {
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			return err
+		}
+		s.recordRows(1)
+		if s.JSONOutput {
+			fmt.Fprintf(s.Out, "Written: %s\n", ColorJSON(map[string]any{"row": index, "column": column, "file": path, "bytes": len(text)}))
+		} else {
+			fmt.Fprintf(s.Out, "Wrote %s of row %d (%s) to %s\n", column, index, formatBytes(int64(len(text))), path)
+		}
+		return nil
+	}
+
+	if valueKind(text) == "binary" {
+		return fmt.Errorf("%s of row %d is binary. Write it to a file with SHOW cell %d %s > file", column, index, index, column)
+	}
+	s.recordRows(1)
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Cell: %s\n", ColorJSON(map[string]any{"row": index, "column": column, "value": value}))
+	} else {
+		fmt.Fprintln(s.Out, text)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GetKillCommandRegex returns the regex for KILL commands
+func GetKillCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(KILL)(\s+QUERY)?\s+(\S+)$`)
+}
+
+// IsGetProcessesCommand checks if the command is GET processes
+func IsGetProcessesCommand(command string, args string) bool {
+	return strings.ToUpper(command) == "GET" && strings.ToLower(strings.TrimSpace(args)) == "processes"
+}
+
+// processColumns are the columns of SHOW FULL PROCESSLIST
+var processColumns = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"}
+
+// handleGetProcesses shows the connections to the server and the statements
+// they are running
+func handleGetProcesses(ctx context.Context, s *Session) error {
+	rows, err := s.query(ctx, "SHOW FULL PROCESSLIST")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	var processes []map[string]any
+	for rows.Next() {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
+			return err
+		}
+		processes = append(processes, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.recordRows(int64(len(processes)))
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Processes: %s\n", ColorJSON(processes))
+		return nil
+	}
+	s.printTable(processColumns, processes)
+	return nil
+}
+
+// handleKill terminates the connection id after confirmation, or only the
+// statement it is running with KILL QUERY
+func handleKill(ctx context.Context, s *Session, queryOnly bool, id string) error {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid process id %q. Use GET processes to list them", id)
+	}
+
+	statement, what := fmt.Sprintf("KILL %d", n), fmt.Sprintf("connection %d", n)
+	if queryOnly {
+		statement, what = fmt.Sprintf("KILL QUERY %d", n), fmt.Sprintf("the statement running on connection %d", n)
+	}
+	fmt.Fprintf(s.Out, "Kill %s? (y/N)\n", what)
+	if strings.ToLower(s.confirm()) != "y" {
+		return ErrConfirmationDeclined
+	}
+
+	if _, err := s.exec(ctx, statement); err != nil {
+		return err
+	}
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Killed: %s\n", ColorJSON(map[string]any{"id": n, "query_only": queryOnly}))
+	} else {
+		fmt.Fprintf(s.Out, "Query OK, 0 rows affected%s\n", s.timing())
+	}
+	return nil
+}
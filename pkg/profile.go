@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Connection holds the settings to connect to a MySQL server. Host may
+// include the port, as in localhost:3306.
+type Connection struct {
+	Host     string
+	User     string
+	Password string
+	Database string
+	// Environment tag, e.g. production, which makes sessions read-only
+	// until UNLOCK
+	Environment string
+	// Hosts of read replicas GET reads from, and how far they may lag
+	Replicas []string
+	MaxLag   time.Duration
+}
+
+// ConnectionFromEnv reads the connection settings from DB_HOST, DB_USER,
+// DB_PASSWORD and DB_NAME
+func ConnectionFromEnv() Connection {
+	return Connection{
+		Host:     os.Getenv("DB_HOST"),
+		User:     os.Getenv("DB_USER"),
+		Password: os.Getenv("DB_PASSWORD"),
+		Database: os.Getenv("DB_NAME"),
+	}
+}
+
+// Profile returns the connection settings of the [profile.name] section of
+// the config, taking the settings it leaves out from base
+func (c Config) Profile(name string, base Connection) (Connection, error) {
+	section := c.Section("profile." + strings.ToLower(name))
+	if len(section) == 0 {
+		return base, fmt.Errorf("unknown profile %q: add a [profile.%s] section to %s", name, name, DefaultConfigPath())
+	}
+	conn := base
+	for key, value := range section {
+		switch strings.ToLower(key) {
+		case "host":
+			conn.Host = value
+		case "user":
+			conn.User = value
+		case "password":
+			conn.Password = value
+		case "database":
+			conn.Database = value
+		case "environment":
+			conn.Environment = strings.ToLower(value)
+		case "replicas":
+			conn.Replicas = nil
+			for _, host := range strings.Split(value, ",") {
+				if host = strings.TrimSpace(host); host != "" {
+					conn.Replicas = append(conn.Replicas, host)
+				}
+			}
+		case "max_lag":
+			lag, _, err := Config{"max_lag": value}.Duration("max_lag")
+			if err != nil || lag < 0 {
+				return base, fmt.Errorf("invalid max_lag %q in profile %s: use seconds or a duration such as 10s", value, name)
+			}
+			conn.MaxLag = lag
+		default:
+			return base, fmt.Errorf("unknown setting %q in profile %s. Use host, user, password, database, environment, replicas and max_lag", key, name)
+		}
+	}
+	if _, ok := section["max_lag"]; !ok && len(conn.Replicas) > 0 {
+		conn.MaxLag = DefaultMaxReplicaLag
+	}
+	return conn, nil
+}
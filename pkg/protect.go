@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// EnvironmentProduction tags a connection profile whose sessions are
+// read-only until UNLOCK
+const EnvironmentProduction = "production"
+
+// unlockDuration is how long UNLOCK allows writes on production
+const unlockDuration = 5 * time.Minute
+
+// ErrReadOnly is returned when a command would write on a locked production
+// session
+var ErrReadOnly = errors.New("read-only production connection")
+
+// GetUnlockCommandRegex returns the regex for UNLOCK
+func GetUnlockCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(UNLOCK)$`)
+}
+
+// isProduction tells whether the session is connected to production
+func (s *Session) isProduction() bool {
+	return strings.EqualFold(s.Environment, EnvironmentProduction)
+}
+
+// checkWritable returns ErrReadOnly when the session is connected to
+// production and writes are not unlocked
+func (s *Session) checkWritable() error {
+	if !s.isProduction() || timeNow().Before(s.writesUntil) {
+		return nil
+	}
+	return fmt.Errorf("%w. Use UNLOCK to allow writes for %d minutes", ErrReadOnly, int(unlockDuration.Minutes()))
+}
+
+// unlockWord is what the user types to unlock writes: the current database,
+// or the environment when none is selected
+func (s *Session) unlockWord() string {
+	if s.CurrentDB != "" {
+		return s.CurrentDB
+	}
+	return EnvironmentProduction
+}
+
+// handleUnlock allows writes on a production session for unlockDuration
+// once the user types the name of the database
+func handleUnlock(s *Session) error {
+	if !s.isProduction() {
+		fmt.Fprintln(s.Out, "Writes are not locked on this connection")
+		return nil
+	}
+	word := s.unlockWord()
+	fmt.Fprintf(s.Out, "This is a production connection. Type '%s' to allow writes for %d minutes: ", word, int(unlockDuration.Minutes()))
+	if strings.TrimSpace(s.confirm()) != word {
+		return ErrConfirmationDeclined
+	}
+	s.writesUntil = timeNow().Add(unlockDuration)
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Unlocked: %s\n", ColorJSON(map[string]any{"until": s.writesUntil.Format(time.RFC3339)}))
+	} else {
+		fmt.Fprintf(s.Out, "Writes allowed until %s\n", s.writesUntil.Format("15:04:05"))
+	}
+	return nil
+}
@@ -0,0 +1,747 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QueryBuilder compiles NoQLi argument maps into parameterized SQL statements.
+// Builder methods can be chained; the first error encountered is reported by
+// the terminal Select, Update or Delete call.
+type QueryBuilder struct {
+	table      string
+	selectExpr string
+	distinct   bool
+	where      []string
+	whereArgs  []any
+	set        []string
+	setArgs    []any
+	matchCase  string
+	groupBy    []string
+	having     []string
+	havingArgs []any
+	orderBy    []string
+	limit      any
+	offset     any
+	err        error
+}
+
+// NewQueryBuilder creates a builder for statements against table
+func NewQueryBuilder(table string) *QueryBuilder {
+	return &QueryBuilder{
+		table:      table,
+		selectExpr: "*",
+	}
+}
+
+// Columns restricts a SELECT to the given columns
+func (b *QueryBuilder) Columns(columns ...string) *QueryBuilder {
+	if len(columns) == 0 {
+		b.selectExpr = "*"
+		return b
+	}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col)
+	}
+	b.selectExpr = strings.Join(quoted, ", ")
+	return b
+}
+
+// SelectExpr sets a raw select expression such as "COUNT(*) AS count"
+func (b *QueryBuilder) SelectExpr(expr string) *QueryBuilder {
+	b.selectExpr = expr
+	return b
+}
+
+// Distinct makes a SELECT return each combination of values once
+func (b *QueryBuilder) Distinct(distinct bool) *QueryBuilder {
+	b.distinct = distinct
+	return b
+}
+
+// Match cases of MatchCase: string equality, IN and LIKE follow the
+// collation of the column unless CaseSensitive or CaseInsensitive is asked
+const (
+	CaseSensitive   = "sensitive"
+	CaseInsensitive = "insensitive"
+)
+
+// MatchCase makes the string equality, IN and LIKE conditions added after it
+// case-sensitive (CaseSensitive), case-insensitive (CaseInsensitive) or
+// follow the collation of their column (an empty mode)
+func (b *QueryBuilder) MatchCase(mode string) *QueryBuilder {
+	switch mode {
+	case "", CaseSensitive, CaseInsensitive:
+		b.matchCase = mode
+	default:
+		b.fail(fmt.Errorf("invalid case %q: use sensitive or insensitive", mode))
+	}
+	return b
+}
+
+// caseColumn returns col as it is compared with a string under matchCase:
+// as binary to tell case apart, or in a case-insensitive collation
+func caseColumn(col, matchCase string) string {
+	switch matchCase {
+	case CaseSensitive:
+		return fmt.Sprintf("CAST(%s AS BINARY)", col)
+	case CaseInsensitive:
+		return fmt.Sprintf("CONVERT(%s USING utf8mb4) COLLATE utf8mb4_general_ci", col)
+	}
+	return col
+}
+
+// Where adds one condition per field of filters. Scalars compile to equality,
+// nil to IS NULL, arrays to IN, {range: [start, end]} maps to an inclusive
+// range and {fuzzy: 'text'} maps to a typo-tolerant match. A BoolExpr
+// compiles to its own condition whatever its field name. Conditions are
+// combined with AND in field name order.
+func (b *QueryBuilder) Where(filters map[string]any) *QueryBuilder {
+	fields := make([]string, 0, len(filters))
+	for field := range filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		condition, args, err := buildCondition(field, filters[field], b.matchCase)
+		if err != nil {
+			b.fail(err)
+			return b
+		}
+		b.WhereRaw(condition, args...)
+	}
+	return b
+}
+
+// WhereRaw adds a literal condition with its placeholder arguments
+func (b *QueryBuilder) WhereRaw(condition string, args ...any) *QueryBuilder {
+	b.where = append(b.where, condition)
+	b.whereArgs = append(b.whereArgs, args...)
+	return b
+}
+
+// WhereLike adds a condition matching pattern against any of the given columns.
+// Patterns without a % wildcard match anywhere in the value.
+func (b *QueryBuilder) WhereLike(pattern any, columns []string) *QueryBuilder {
+	if len(columns) == 0 {
+		b.fail(fmt.Errorf("no columns found for LIKE clause"))
+		return b
+	}
+
+	likeStr := likePattern(pattern)
+
+	conditions := make([]string, len(columns))
+	args := make([]any, len(columns))
+	for i, col := range columns {
+		conditions[i] = fmt.Sprintf("%s LIKE ?", caseColumn(quoteIdent(col), b.matchCase))
+		args[i] = likeStr
+	}
+	return b.WhereRaw("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// likePattern returns pattern for LIKE; patterns without a % wildcard match
+// anywhere in the value
+func likePattern(pattern any) string {
+	likeStr := fmt.Sprintf("%v", pattern)
+	if !strings.Contains(likeStr, "%") {
+		likeStr = "%" + likeStr + "%"
+	}
+	return likeStr
+}
+
+// Set adds column assignments for an UPDATE in field name order. Nested
+// objects and lists are stored as JSON.
+func (b *QueryBuilder) Set(fields map[string]any) *QueryBuilder {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		expr, params, err := sqlValue(fields[k])
+		if err != nil {
+			b.fail(fmt.Errorf("invalid value for %s: %w", k, err))
+			return b
+		}
+		b.set = append(b.set, fmt.Sprintf("%s = %s", quoteIdent(k), expr))
+		b.setArgs = append(b.setArgs, params...)
+	}
+	return b
+}
+
+// GroupBy adds raw grouping expressions such as DATE(`created_at`)
+func (b *QueryBuilder) GroupBy(exprs ...string) *QueryBuilder {
+	b.groupBy = append(b.groupBy, exprs...)
+	return b
+}
+
+// Having adds one condition on the groups per field of filters, compiled
+// like the filters of Where. Fields name the grouped or aggregated result
+// columns, e.g. {count: {gt: 10}}.
+func (b *QueryBuilder) Having(filters map[string]any) *QueryBuilder {
+	for _, field := range sortedKeys(filters) {
+		condition, args, err := buildCondition(field, filters[field], b.matchCase)
+		if err != nil {
+			b.fail(err)
+			return b
+		}
+		b.having = append(b.having, condition)
+		b.havingArgs = append(b.havingArgs, args...)
+	}
+	return b
+}
+
+// OrderBy adds a sort key
+func (b *QueryBuilder) OrderBy(column string, desc bool) *QueryBuilder {
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	b.orderBy = append(b.orderBy, fmt.Sprintf("%s %s", quoteIdent(column), direction))
+	return b
+}
+
+// Limit sets LIMIT and OFFSET; nil leaves either unset. Both must be
+// non-negative integers, and OFFSET is only applied together with LIMIT.
+func (b *QueryBuilder) Limit(limit, offset any) *QueryBuilder {
+	if limit != nil {
+		if limInt, ok := toInt(limit); ok {
+			if limInt < 0 {
+				b.fail(fmt.Errorf("LIMIT must be non-negative"))
+				return b
+			}
+		} else {
+			b.fail(fmt.Errorf("LIMIT must be an integer"))
+			return b
+		}
+	}
+	if offset != nil {
+		if offInt, ok := toInt(offset); ok {
+			if offInt < 0 {
+				b.fail(fmt.Errorf("OFFSET must be non-negative"))
+				return b
+			}
+		} else {
+			b.fail(fmt.Errorf("OFFSET must be an integer"))
+			return b
+		}
+	}
+	b.limit = limit
+	b.offset = offset
+	return b
+}
+
+// WhereClause returns the combined WHERE conditions (without the WHERE keyword)
+// and their arguments. The clause is empty when no conditions were added.
+func (b *QueryBuilder) WhereClause() (string, []any) {
+	return strings.Join(b.where, " AND "), b.whereArgs
+}
+
+// Select builds a SELECT statement
+func (b *QueryBuilder) Select() (string, []any, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	query := "SELECT "
+	if b.distinct {
+		query += "DISTINCT "
+	}
+	query += fmt.Sprintf("%s FROM %s", b.selectExpr, b.table)
+	var args []any
+
+	if clause, whereArgs := b.WhereClause(); clause != "" {
+		query += " WHERE " + clause
+		args = append(args, whereArgs...)
+	}
+	if len(b.groupBy) > 0 {
+		query += " GROUP BY " + strings.Join(b.groupBy, ", ")
+	}
+	if len(b.having) > 0 {
+		query += " HAVING " + strings.Join(b.having, " AND ")
+		args = append(args, b.havingArgs...)
+	}
+	if len(b.orderBy) > 0 {
+		query += " ORDER BY " + strings.Join(b.orderBy, ", ")
+	}
+	if b.limit != nil {
+		query += " LIMIT ?"
+		args = append(args, b.limit)
+		if b.offset != nil {
+			query += " OFFSET ?"
+			args = append(args, b.offset)
+		}
+	}
+
+	return query, args, nil
+}
+
+// Update builds an UPDATE statement from the Set assignments
+func (b *QueryBuilder) Update() (string, []any, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	if len(b.set) == 0 {
+		return "", nil, fmt.Errorf("UPDATE requires fields to update")
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s", b.table, strings.Join(b.set, ", "))
+	args := append([]any{}, b.setArgs...)
+
+	if clause, whereArgs := b.WhereClause(); clause != "" {
+		query += " WHERE " + clause
+		args = append(args, whereArgs...)
+	}
+
+	return query, args, nil
+}
+
+// Delete builds a DELETE statement. A filter is required so a builder
+// without conditions never deletes the whole table.
+func (b *QueryBuilder) Delete() (string, []any, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	clause, whereArgs := b.WhereClause()
+	if clause == "" {
+		return "", nil, fmt.Errorf("DELETE requires a filter")
+	}
+
+	return fmt.Sprintf("DELETE FROM %s WHERE %s", b.table, clause), whereArgs, nil
+}
+
+// clone returns a copy of the builder that can be changed independently
+func (b *QueryBuilder) clone() *QueryBuilder {
+	c := *b
+	c.where = append([]string(nil), b.where...)
+	c.whereArgs = append([]any(nil), b.whereArgs...)
+	c.groupBy = append([]string(nil), b.groupBy...)
+	c.having = append([]string(nil), b.having...)
+	c.havingArgs = append([]any(nil), b.havingArgs...)
+	c.orderBy = append([]string(nil), b.orderBy...)
+	return &c
+}
+
+// fail records the first error raised while building
+func (b *QueryBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Comparison filters a field with an operator other than equality, e.g.
+// {created_at: > now()-7d}, with NOT IN excluding the values of a list, or
+// with LIKE or REGEXP matching a pattern
+type Comparison struct {
+	Op    string
+	Value any
+	// Flags are the match types of REGEXP, such as i for case-insensitive
+	Flags string
+}
+
+// comparisonTextRegex matches a quoted comparison such as '>= 18'
+var comparisonTextRegex = regexp.MustCompile(`^\s*(>=|<=|!=|>|<)\s*(\S.*?)\s*$`)
+
+// comparisonText returns the comparison a filter value such as '>= 18' or
+// '< 2024-06-01' stands for. Only numbers and dates are compared this way,
+// so other text keeps matching as it is.
+func comparisonText(value any) (Comparison, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return Comparison{}, false
+	}
+	m := comparisonTextRegex.FindStringSubmatch(text)
+	if m == nil {
+		return Comparison{}, false
+	}
+	if n, ok := parseNumber(m[2]); ok {
+		if _, isText := n.(string); !isText {
+			return Comparison{Op: m[1], Value: n}, true
+		}
+	}
+	if t, ok := parseDateLiteral(m[2]); ok {
+		return Comparison{Op: m[1], Value: t}, true
+	}
+	return Comparison{}, false
+}
+
+// comparisonKeys are the operators of operator objects such as {gt: 50}.
+// {not: value} excludes a value, or the values of a list as {notin: list}
+// does, and {like: 'Smi%'} matches a pattern.
+var comparisonKeys = map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<=", "ne": "!=", "not": "!=", "notin": "NOT IN", "like": "LIKE"}
+
+// operatorObject returns the filter an object with a single operator key,
+// such as {gt: 50}, {ne: null}, {notin: [1, 2]} or {notnull: true}, stands
+// for in filter position; elsewhere such objects are values. {last: '7d'}
+// matches times within the last period. {regexp: pattern} may also have
+// flags, e.g. {regexp: '^a', flags: 'i'}.
+func operatorObject(value any) (any, bool) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return Comparison{}, false
+	}
+	if c, ok := regexpObject(m); ok {
+		return c, true
+	}
+	if len(m) != 1 {
+		return Comparison{}, false
+	}
+	for key, operand := range m {
+		if strings.EqualFold(key, "notnull") {
+			// {notnull: true} is IS NOT NULL and {notnull: false} IS NULL
+			notNull, ok := operand.(bool)
+			if !ok {
+				return Comparison{}, false
+			}
+			if !notNull {
+				return nil, true
+			}
+			return Comparison{Op: "!=", Value: nil}, true
+		}
+		if strings.EqualFold(key, "last") {
+			period, ok := operand.(string)
+			if !ok {
+				return Comparison{}, false
+			}
+			t, ok := periodStart(period)
+			if !ok {
+				return Comparison{}, false
+			}
+			return Comparison{Op: ">=", Value: t}, true
+		}
+		op, ok := comparisonKeys[strings.ToLower(key)]
+		if !ok {
+			return Comparison{}, false
+		}
+		switch operand.(type) {
+		case map[string]any, Comparison:
+			return Comparison{}, false
+		case []any:
+			if op != "!=" && op != "NOT IN" {
+				return Comparison{}, false
+			}
+			op = "NOT IN"
+		}
+		return Comparison{Op: op, Value: operand}, true
+	}
+	return Comparison{}, false
+}
+
+// regexpObject returns the REGEXP filter of {regexp: pattern} or
+// {regexp: pattern, flags: 'i'}
+func regexpObject(m map[string]any) (Comparison, bool) {
+	var c Comparison
+	for key, operand := range m {
+		switch strings.ToLower(key) {
+		case "regexp":
+			switch operand.(type) {
+			case map[string]any, []any, Comparison:
+				return Comparison{}, false
+			}
+			c.Op, c.Value = "REGEXP", operand
+		case "flags":
+			flags, ok := operand.(string)
+			if !ok {
+				return Comparison{}, false
+			}
+			c.Flags = flags
+		default:
+			return Comparison{}, false
+		}
+	}
+	return c, c.Op != ""
+}
+
+// MarshalJSON renders a comparison as its operator and value, e.g. "> 5"
+func (c Comparison) MarshalJSON() ([]byte, error) {
+	value, err := columnValue(c.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fmt.Sprintf("%s %v", c.Op, value))
+}
+
+// BoolExpr is a condition combining field filters with and, or and not, e.g.
+// {(status: 'new' or status: 'open') and priority: 'high'}
+type BoolExpr struct {
+	// Op is "and", "or" or "not"; it is empty for a single field filter
+	Op string
+	// Field and Value are the filter when Op is empty
+	Field string
+	Value any
+	// Args are the combined conditions; "not" has exactly one
+	Args []BoolExpr
+}
+
+// build compiles the expression into a condition that can be combined with
+// others without changing its meaning, matching strings as matchCase asks
+func (e BoolExpr) build(matchCase string) (string, []any, error) {
+	switch e.Op {
+	case "":
+		condition, args, err := buildCondition(e.Field, e.Value, matchCase)
+		if err != nil {
+			return "", nil, err
+		}
+		if strings.Contains(condition, " AND ") {
+			condition = "(" + condition + ")" // a range
+		}
+		return condition, args, nil
+	case "not":
+		if len(e.Args) != 1 {
+			return "", nil, fmt.Errorf("NOT takes exactly one condition")
+		}
+		inner := e.Args[0]
+		condition, args, err := inner.build(matchCase)
+		if err != nil {
+			return "", nil, err
+		}
+		if (inner.Op == "and" || inner.Op == "or") && len(inner.Args) > 1 {
+			return "NOT " + condition, args, nil // already parenthesized
+		}
+		return "NOT (" + condition + ")", args, nil
+	case "and", "or":
+		if len(e.Args) == 0 {
+			return "", nil, fmt.Errorf("%s requires conditions", strings.ToUpper(e.Op))
+		}
+		conditions := make([]string, len(e.Args))
+		var args []any
+		for i, arg := range e.Args {
+			condition, condArgs, err := arg.build(matchCase)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions[i] = condition
+			args = append(args, condArgs...)
+		}
+		if len(conditions) == 1 {
+			return conditions[0], args, nil
+		}
+		return "(" + strings.Join(conditions, " "+strings.ToUpper(e.Op)+" ") + ")", args, nil
+	default:
+		return "", nil, fmt.Errorf("invalid operator %q", e.Op)
+	}
+}
+
+// regexpCondition compiles a REGEXP filter on field. Flags, or else the
+// match case, pick case-sensitive (c) or case-insensitive (i) matching;
+// without either the collation of the column decides.
+func regexpCondition(field string, c Comparison, matchCase string) (string, []any, error) {
+	pattern, ok := c.Value.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("regexp for field %s requires a pattern, e.g. {regexp: '^A.*son$'}", field)
+	}
+	flags := strings.ToLower(c.Flags)
+	for _, flag := range flags {
+		if !strings.ContainsRune("icmnu", flag) {
+			return "", nil, fmt.Errorf("invalid regexp flag %q: use i (case-insensitive), c (case-sensitive), m, n or u", flag)
+		}
+	}
+	if flags == "" {
+		switch matchCase {
+		case CaseSensitive:
+			flags = "c"
+		case CaseInsensitive:
+			flags = "i"
+		}
+	}
+	col := quoteIdent(field)
+	if flags == "" {
+		return fmt.Sprintf("%s REGEXP ?", col), []any{pattern}, nil
+	}
+	return fmt.Sprintf("REGEXP_LIKE(%s, ?, ?)", col), []any{pattern, flags}, nil
+}
+
+// notInCondition compiles a filter excluding the values of list, or the
+// single value list. Excluding null leaves out NULL values too.
+func notInCondition(field string, list any, matchCase string) (string, []any, error) {
+	values, ok := list.([]any)
+	if !ok {
+		values = []any{list}
+	}
+	if len(values) == 0 {
+		return "1=1", nil, nil // Nothing is excluded
+	}
+	condition, args, err := buildCondition(field, values, matchCase)
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case strings.HasPrefix(condition, "("):
+		return "NOT " + condition, args, nil // values and null
+	case strings.HasSuffix(condition, " IS NULL"):
+		return strings.TrimSuffix(condition, " IS NULL") + " IS NOT NULL", args, nil
+	default:
+		return strings.Replace(condition, " IN (", " NOT IN (", 1), args, nil
+	}
+}
+
+// buildCondition compiles a single field filter into a SQL condition. nil
+// compiles to IS NULL, and to IS NOT NULL when compared with !=. Strings are
+// compared as matchCase asks.
+func buildCondition(field string, value any, matchCase string) (string, []any, error) {
+	col := quoteIdent(field)
+	if c, ok := comparisonText(value); ok {
+		value = c
+	}
+
+	switch v := value.(type) {
+	case BoolExpr:
+		return v.build(matchCase)
+	case nil:
+		return fmt.Sprintf("%s IS NULL", col), nil, nil
+	case []any:
+		// Handle array of values (IN clause)
+		if len(v) == 0 {
+			return "0=1", nil, nil // No results should match
+		}
+		var placeholders []string
+		var args []any
+		hasNull, hasString := false, false
+		for _, elem := range v {
+			// Keep numbers and booleans as they are, convert other types to string
+			placeholder := "?"
+			switch elem := elem.(type) {
+			case nil:
+				hasNull = true
+				continue
+			case int, int32, int64, float32, float64, bool:
+				args = append(args, elem)
+			case time.Time:
+				arg, _ := columnValue(elem)
+				args = append(args, arg)
+			case BinaryUUID:
+				args = append(args, string(elem))
+				placeholder = binaryUUIDExpr
+			default:
+				args = append(args, fmt.Sprintf("%v", elem))
+				hasString = true
+			}
+			placeholders = append(placeholders, placeholder)
+		}
+		in := col
+		if hasString {
+			in = caseColumn(col, matchCase)
+		}
+		switch {
+		case !hasNull:
+			return fmt.Sprintf("%s IN (%s)", in, strings.Join(placeholders, ",")), args, nil
+		case len(args) == 0:
+			return fmt.Sprintf("%s IS NULL", col), nil, nil
+		default:
+			return fmt.Sprintf("(%s IN (%s) OR %s IS NULL)", in, strings.Join(placeholders, ","), col), args, nil
+		}
+	case map[string]any:
+		if filter, ok := operatorObject(v); ok {
+			return buildCondition(field, filter, matchCase)
+		}
+		if term, ok := v["fuzzy"]; ok {
+			return fuzzyCondition(col, term)
+		}
+		// Handle range
+		rangeVal, ok := v["range"]
+		if !ok {
+			return "", nil, fmt.Errorf("invalid filter for field %s: use an operator such as {gt: 5} or {notnull: true}, {range: [start, end]} or {fuzzy: 'text'}", field)
+		}
+		start, end, err := rangeBounds(field, rangeVal)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s >= ? AND %s <= ?", col, col), []any{start, end}, nil
+	case Comparison:
+		switch v.Op {
+		case ">", ">=", "<", "<=", "!=":
+		case "NOT IN":
+			return notInCondition(field, v.Value, matchCase)
+		case "REGEXP":
+			return regexpCondition(field, v, matchCase)
+		case "LIKE":
+			if v.Value == nil {
+				return "", nil, fmt.Errorf("cannot match field %s with null using LIKE", field)
+			}
+			return fmt.Sprintf("%s LIKE ?", caseColumn(col, matchCase)), []any{likePattern(v.Value)}, nil
+		default:
+			return "", nil, fmt.Errorf("invalid operator %q for field %s", v.Op, field)
+		}
+		if v.Value == nil {
+			if v.Op != "!=" {
+				return "", nil, fmt.Errorf("cannot compare field %s with null using %s", field, v.Op)
+			}
+			return fmt.Sprintf("%s IS NOT NULL", col), nil, nil
+		}
+		expr, args, err := sqlValue(v.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, ok := v.Value.(string); ok {
+			col = caseColumn(col, matchCase)
+		}
+		return fmt.Sprintf("%s %s %s", col, v.Op, expr), args, nil
+	case Point:
+		return fmt.Sprintf("ST_Equals(%s, ST_GeomFromText(?))", col), []any{v.String()}, nil
+	case Distance:
+		return fmt.Sprintf("ST_Distance_Sphere(%s, ST_GeomFromText(?)) <= ?", col), []any{v.From.String(), v.Meters}, nil
+	default:
+		// Single value
+		expr, args, err := sqlValue(value)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, ok := value.(string); ok {
+			col = caseColumn(col, matchCase)
+		}
+		return fmt.Sprintf("%s = %s", col, expr), args, nil
+	}
+}
+
+// rangeBounds extracts the two bounds of a range given as []int or []any.
+// Integer and float bounds are kept as they are.
+func rangeBounds(field string, rangeVal any) (any, any, error) {
+	switch rangeSlice := rangeVal.(type) {
+	case []int:
+		if len(rangeSlice) != 2 {
+			return nil, nil, fmt.Errorf("invalid range format for field %s", field)
+		}
+		return rangeSlice[0], rangeSlice[1], nil
+	case []any:
+		if len(rangeSlice) != 2 {
+			return nil, nil, fmt.Errorf("invalid range format for field %s", field)
+		}
+		bounds := make([]any, 2)
+		for i := 0; i < 2; i++ {
+			switch v := rangeSlice[i].(type) {
+			case int:
+				bounds[i] = v
+			case time.Time:
+				bounds[i], _ = columnValue(v)
+			case int64, float64:
+				bounds[i] = v
+			case json.Number:
+				if intVal, err := v.Int64(); err == nil {
+					bounds[i] = intVal
+				} else if floatVal, err := v.Float64(); err == nil {
+					bounds[i] = floatVal
+				} else {
+					return nil, nil, fmt.Errorf("invalid range value type for field %s", field)
+				}
+			default:
+				return nil, nil, fmt.Errorf("invalid range value type for field %s", field)
+			}
+		}
+		return bounds[0], bounds[1], nil
+	default:
+		return nil, nil, fmt.Errorf("invalid range type for field %s", field)
+	}
+}
+
+// quoteIdent wraps a column name in backticks
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
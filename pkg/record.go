@@ -0,0 +1,312 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GetRecordCommandRegex returns the regex for RECORD start file and RECORD stop
+func GetRecordCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(RECORD)\s+(start|stop)(?:\s+(.+))?$`)
+}
+
+// GetReplayCommandRegex returns the regex for REPLAY file
+func GetReplayCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(REPLAY)\s+(.+)$`)
+}
+
+// RecordHeader is the first line of a session recording
+type RecordHeader struct {
+	Version  string    `json:"noqli"`
+	Started  time.Time `json:"started"`
+	Database string    `json:"database"`
+	Table    string    `json:"table"`
+}
+
+// RecordedCommand is a command of a session recording: the line entered,
+// the SQL it ran and its sanitized output
+type RecordedCommand struct {
+	Line    string   `json:"line"`
+	Command string   `json:"command,omitempty"`
+	SQL     []string `json:"sql,omitempty"`
+	Params  [][]any  `json:"params,omitempty"`
+	// Answers given to confirmation prompts, replayed in order
+	Confirm    []string `json:"confirm,omitempty"`
+	Rows       int64    `json:"rows"`
+	DurationMS float64  `json:"duration_ms"`
+	Error      string   `json:"error,omitempty"`
+	Output     string   `json:"output"`
+}
+
+// Recorder writes the commands of a session to a recording file, one JSON
+// object per line after the header
+type Recorder struct {
+	Path     string
+	file     *os.File
+	enc      *json.Encoder
+	commands int
+	// Answers to the confirmation prompts of the command being recorded
+	answers []string
+}
+
+// StartRecording creates the recording file at path for the commands of s
+func StartRecording(path string, s *Session) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{Path: path, file: file, enc: json.NewEncoder(file)}
+	header := RecordHeader{Version: CurrentBuild().Version, Started: time.Now(), Database: s.CurrentDB, Table: s.CurrentTable}
+	if err := r.enc.Encode(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close closes the recording file
+func (r *Recorder) Close() error {
+	return r.file.Close()
+}
+
+// notRecorded lists the commands left out of recordings
+var notRecorded = []string{"RECORD", "REPLAY"}
+
+// captureCommand starts recording the command of info, copying its output,
+// and returns the function that writes it once it finished
+func (s *Session) captureCommand(info *CommandInfo) func() {
+	r := s.recorder
+	r.answers = nil
+	var output bytes.Buffer
+	out := s.Out
+	s.Out = io.MultiWriter(out, &output)
+
+	return func() {
+		s.Out = out
+		if containsString(notRecorded, info.Command) {
+			return
+		}
+		entry := RecordedCommand{
+			Line:       info.Line,
+			Command:    info.Command,
+			SQL:        info.SQL,
+			Params:     info.Params,
+			Confirm:    r.answers,
+			Rows:       info.Rows,
+			DurationMS: milliseconds(info.Duration),
+			Output:     sanitizeOutput(output.String()),
+		}
+		if info.Err != nil {
+			entry.Error = info.Err.Error()
+		}
+		if err := r.enc.Encode(entry); err != nil {
+			fmt.Fprintln(out, "Warning: could not record the command:", err)
+			return
+		}
+		r.commands++
+	}
+}
+
+var (
+	ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	timingRegex     = regexp.MustCompile(` \(\d+\.\d+ sec(?:: [^)]*)?\)`)
+	emailRegex      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// sanitizeOutput removes what changes between runs or should not leave the
+// machine from command output: colors, timings and email addresses
+func sanitizeOutput(output string) string {
+	output = ansiEscapeRegex.ReplaceAllString(output, "")
+	output = timingRegex.ReplaceAllString(output, "")
+	return emailRegex.ReplaceAllString(output, "<email>")
+}
+
+// ReadRecording reads the header and the commands of a recording file
+func ReadRecording(path string) (RecordHeader, []RecordedCommand, error) {
+	var header RecordHeader
+	file, err := os.Open(path)
+	if err != nil {
+		return header, nil, err
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(file))
+	if err := dec.Decode(&header); err != nil {
+		return header, nil, fmt.Errorf("%s is not a noqli recording: %w", path, err)
+	}
+	var commands []RecordedCommand
+	for dec.More() {
+		var entry RecordedCommand
+		if err := dec.Decode(&entry); err != nil {
+			return header, nil, fmt.Errorf("%s: command %d: %w", path, len(commands)+1, err)
+		}
+		commands = append(commands, entry)
+	}
+	return header, commands, nil
+}
+
+// ReplayResult compares a replayed command with its recording
+type ReplayResult struct {
+	Line     string `json:"line"`
+	Matched  bool   `json:"matched"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// Replay runs the commands of a recording against the session, answering
+// confirmations as recorded, and compares their errors and sanitized output
+// with the recorded ones. The recorded table is selected first.
+func (s *Session) Replay(ctx context.Context, header RecordHeader, commands []RecordedCommand) ([]ReplayResult, error) {
+	out, confirm := s.Out, s.Confirm
+	defer func() { s.Out, s.Confirm = out, confirm }()
+
+	if header.Table != "" && s.CurrentDB != "" && s.CurrentTable != header.Table {
+		s.Out = io.Discard
+		if err := ExecuteCommand(ctx, s, "USE "+header.Table); err != nil {
+			return nil, fmt.Errorf("selecting the recorded table: %w", err)
+		}
+	}
+
+	results := make([]ReplayResult, 0, len(commands))
+	for _, entry := range commands {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		answers := entry.Confirm
+		s.Confirm = func() string {
+			if len(answers) == 0 {
+				return "n"
+			}
+			answer := answers[0]
+			answers = answers[1:]
+			return answer
+		}
+		var output bytes.Buffer
+		s.Out = &output
+		err := ExecuteCommand(ctx, s, entry.Line)
+
+		result := ReplayResult{Line: entry.Line, Matched: true}
+		actualErr := ""
+		if err != nil {
+			actualErr = err.Error()
+		}
+		actual := sanitizeOutput(output.String())
+		switch {
+		case actualErr != entry.Error:
+			result.Matched = false
+			result.Expected, result.Actual = "error: "+entry.Error, "error: "+actualErr
+		case actual != entry.Output:
+			result.Matched = false
+			result.Expected, result.Actual = firstDifference(entry.Output, actual)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// firstDifference returns the first line that differs between expected and
+// actual output
+func firstDifference(expected, actual string) (string, string) {
+	want := strings.Split(expected, "\n")
+	got := strings.Split(actual, "\n")
+	for i := 0; i < len(want) || i < len(got); i++ {
+		var w, g string
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d: %s", i+1, w), fmt.Sprintf("line %d: %s", i+1, g)
+		}
+	}
+	return "", ""
+}
+
+// handleRecord starts or stops recording the session to a file
+func handleRecord(s *Session, action, path string) error {
+	path = strings.Trim(strings.TrimSpace(path), `"'`)
+	switch strings.ToLower(action) {
+	case "start":
+		if path == "" {
+			return fmt.Errorf("RECORD start needs a file, e.g. RECORD start bug.session")
+		}
+		if s.recorder != nil {
+			return fmt.Errorf("already recording to %s. Use RECORD stop first", s.recorder.Path)
+		}
+		r, err := StartRecording(path, s)
+		if err != nil {
+			return err
+		}
+		s.recorder = r
+		if s.JSONOutput {
+			fmt.Fprintf(s.Out, "Recording: %s\n", ColorJSON(map[string]any{"file": path}))
+		} else {
+			fmt.Fprintf(s.Out, "Recording to %s. Use RECORD stop to finish\n", path)
+		}
+		return nil
+	default:
+		if s.recorder == nil {
+			return fmt.Errorf("not recording. Use RECORD start file first")
+		}
+		r := s.recorder
+		s.recorder = nil
+		if err := r.Close(); err != nil {
+			return err
+		}
+		if s.JSONOutput {
+			fmt.Fprintf(s.Out, "Recorded: %s\n", ColorJSON(map[string]any{"file": r.Path, "commands": r.commands}))
+		} else {
+			fmt.Fprintf(s.Out, "Recorded %d commands to %s\n", r.commands, r.Path)
+		}
+		return nil
+	}
+}
+
+// handleReplay replays a recording and reports the commands whose output
+// or error changed
+func handleReplay(ctx context.Context, s *Session, path string) error {
+	path = strings.Trim(strings.TrimSpace(path), `"'`)
+	header, commands, err := ReadRecording(path)
+	if err != nil {
+		return err
+	}
+	results, err := s.Replay(ctx, header, commands)
+	if err != nil {
+		return err
+	}
+	s.recordRows(int64(len(results)))
+
+	differed := 0
+	for _, result := range results {
+		if !result.Matched {
+			differed++
+		}
+	}
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Replay: %s\n", ColorJSON(results))
+	} else {
+		rows := make([]map[string]any, len(results))
+		for i, result := range results {
+			status := "matched"
+			if !result.Matched {
+				status = "differed"
+			}
+			rows[i] = map[string]any{"#": i + 1, "Command": result.Line, "Result": status, "Recorded": result.Expected, "Replayed": result.Actual}
+		}
+		s.printTable([]string{"#", "Command", "Result", "Recorded", "Replayed"}, rows)
+	}
+	if differed > 0 {
+		return fmt.Errorf("%d of %d replayed commands differed from the recording", differed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package pkg_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectGet expects GET 1, answering a user with email
+func expectGet(mock sqlmock.Sqlmock, email string) {
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Ann", email))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	session, mock, buf := mockSession(t)
+	path := filepath.Join(t.TempDir(), "bug.session")
+
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "RECORD start "+path))
+	expectGet(mock, "ann@example.com")
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "GET 1"))
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "GET {id: }"))
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "RECORD stop"))
+	assert.Contains(t, buf.String(), "Recorded 2 commands to "+path)
+
+	header, commands, err := pkg.ReadRecording(path)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", header.Database)
+	assert.Equal(t, "users", header.Table)
+	require.Len(t, commands, 2)
+	assert.Equal(t, "GET 1", commands[0].Line)
+	assert.Contains(t, commands[0].SQL, "SELECT * FROM users WHERE `id` = ?")
+	assert.Contains(t, commands[0].Output, "<email>")
+	assert.NotContains(t, commands[0].Output, "ann@example.com")
+	assert.NotEmpty(t, commands[1].Error)
+
+	// Replaying against the same data matches, other data differs
+	expectGet(mock, "bob@example.com")
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "REPLAY "+path))
+	assert.Contains(t, buf.String(), "matched")
+
+	expectColumns(mock)
+	expectColumns(mock)
+	mock.ExpectQuery("SELECT \\* FROM users WHERE `id` = \\?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "Bob", "bob@example.com"))
+	results, err := session.Replay(ctx, header, commands)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.False(t, results[0].Matched)
+	assert.Contains(t, results[0].Actual, "Bob")
+	assert.True(t, results[1].Matched)
+}
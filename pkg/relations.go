@@ -0,0 +1,324 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// relationSuffixRegex matches the "-> table" ending of a GET that follows a
+// relation to another table, e.g. GET 42 -> orders
+var relationSuffixRegex = regexp.MustCompile(`(?s)^(.*?)\s*->\s*([A-Za-z_][A-Za-z0-9_$]*)\s*$`)
+
+// IsGetRelationsCommand checks if the command is GET relations
+func IsGetRelationsCommand(command string, args string) bool {
+	return strings.ToUpper(command) == "GET" && strings.ToLower(strings.TrimSpace(args)) == "relations"
+}
+
+// DefaultForeignKeyPattern is the naming convention for foreign key columns
+// used when no foreign key constraint links two tables
+const DefaultForeignKeyPattern = "{singular}_id"
+
+// cutRelationSuffix splits the arguments of a GET into the filter and the
+// table to follow the relation to
+func cutRelationSuffix(args string) (string, string, bool) {
+	m := relationSuffixRegex.FindStringSubmatch(args)
+	if m == nil {
+		return args, "", false
+	}
+	return m[1], m[2], true
+}
+
+// ForeignKey is a column of Table referencing RefColumn of RefTable.
+// Constraint is empty for relations found by the naming convention.
+type ForeignKey struct {
+	Constraint string `json:"constraint,omitempty"`
+	Table      string `json:"table"`
+	Column     string `json:"column"`
+	RefTable   string `json:"referenced_table"`
+	RefColumn  string `json:"referenced_column"`
+}
+
+// ForeignKeys returns the foreign keys of the current database that
+// reference table or are defined on it
+func (s *Session) ForeignKeys(ctx context.Context, table string) ([]ForeignKey, error) {
+	rows, err := s.query(ctx, `SELECT CONSTRAINT_NAME, TABLE_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL
+		AND (TABLE_NAME = ? OR REFERENCED_TABLE_NAME = ?)
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`, table, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []ForeignKey
+	for rows.Next() {
+		var fk ForeignKey
+		if err := rows.Scan(&fk.Constraint, &fk.Table, &fk.Column, &fk.RefTable, &fk.RefColumn); err != nil {
+			return nil, err
+		}
+		keys = append(keys, fk)
+	}
+	return keys, rows.Err()
+}
+
+// findRelation returns the foreign key linking the tables from and to in
+// either direction. Without a constraint, a column of one table named after
+// the other by the foreign_key setting, e.g. user_id for users, is assumed
+// to reference its id.
+func findRelation(ctx context.Context, s *Session, from, to string) (ForeignKey, error) {
+	keys, err := s.ForeignKeys(ctx, from)
+	if err != nil {
+		return ForeignKey{}, err
+	}
+	for _, fk := range keys {
+		if (fk.Table == to && fk.RefTable == from) || (fk.Table == from && fk.RefTable == to) {
+			return fk, nil
+		}
+	}
+
+	pattern := s.Config.Get("foreign_key")
+	if pattern == "" {
+		pattern = DefaultForeignKeyPattern
+	}
+	toColumns, err := tableColumns(ctx, s, to)
+	if err != nil {
+		return ForeignKey{}, err
+	}
+	if column := foreignKeyColumn(pattern, from); containsString(toColumns, column) {
+		return ForeignKey{Table: to, Column: column, RefTable: from, RefColumn: "id"}, nil
+	}
+	fromColumns, err := tableColumns(ctx, s, from)
+	if err != nil {
+		return ForeignKey{}, err
+	}
+	if column := foreignKeyColumn(pattern, to); containsString(fromColumns, column) {
+		return ForeignKey{Table: from, Column: column, RefTable: to, RefColumn: "id"}, nil
+	}
+	return ForeignKey{}, fmt.Errorf("no foreign key links %s and %s. Add a constraint or a %s or %s column",
+		from, to, foreignKeyColumn(pattern, from), foreignKeyColumn(pattern, to))
+}
+
+// foreignKeyColumn names the column referencing table by pattern, where
+// {table} is the table name and {singular} its singular, e.g. user for users
+func foreignKeyColumn(pattern, table string) string {
+	return strings.NewReplacer("{table}", table, "{singular}", singular(table)).Replace(pattern)
+}
+
+// singular returns the singular of an English plural table name
+func singular(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss") && !strings.HasSuffix(lower, "us"):
+		return name[:len(name)-1]
+	default:
+		return name
+	}
+}
+
+// handleRelated shows the rows of table related to the rows of the current
+// table matching filters
+func handleRelated(ctx context.Context, s *Session, filters map[string]any, table string) error {
+	fk, err := findRelation(ctx, s, s.CurrentTable, table)
+	if err != nil {
+		return err
+	}
+
+	// Match the key column of table against the keys of the selected rows
+	column, key := fk.Column, fk.RefColumn
+	if fk.Table != table || fk.RefTable != s.CurrentTable {
+		column, key = fk.RefColumn, fk.Column
+	}
+	keys, keyValues, err := NewQueryBuilder(s.CurrentTable).Columns(key).Where(filters).Select()
+	if err != nil {
+		return err
+	}
+	query, values, err := NewQueryBuilder(table).
+		WhereRaw(fmt.Sprintf("%s IN (%s)", quoteIdent(column), keys), keyValues...).
+		Select()
+	if err != nil {
+		return err
+	}
+
+	booleans, err := booleanColumns(ctx, s, table)
+	if err != nil {
+		return err
+	}
+	rows, err := s.query(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	display, err := s.newColumnFormatter(rows, booleans)
+	if err != nil {
+		return err
+	}
+	var results []map[string]any
+	for rows.Next() {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
+			return err
+		}
+		display.format(entry)
+		results = append(results, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.recordRows(int64(len(results)))
+	s.rememberResult(columns, results)
+
+	if len(results) == 0 {
+		fmt.Fprintln(s.Out, "No records found")
+	} else if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Records: %s\n", ColorJSON(results))
+	} else {
+		s.printTable(columns, results)
+	}
+	return nil
+}
+
+// handleGetRelations shows the foreign keys of the current table and those
+// of other tables referencing it
+func handleGetRelations(ctx context.Context, s *Session) error {
+	if s.CurrentTable == "" {
+		return fmt.Errorf("%w. Use 'USE table_name' to select a table", ErrNoTableSelected)
+	}
+	keys, err := s.ForeignKeys(ctx, s.CurrentTable)
+	if err != nil {
+		return err
+	}
+
+	references, referencedBy := []ForeignKey{}, []ForeignKey{}
+	for _, fk := range keys {
+		if fk.Table == s.CurrentTable {
+			references = append(references, fk)
+		} else {
+			referencedBy = append(referencedBy, fk)
+		}
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Relations: %s\n", ColorJSON(map[string]any{
+			"references":    references,
+			"referenced_by": referencedBy,
+		}))
+		return nil
+	}
+	if len(keys) == 0 {
+		fmt.Fprintf(s.Out, "No relations found for table '%s'\n", s.CurrentTable)
+		return nil
+	}
+
+	var results []map[string]any
+	for _, fk := range append(references, referencedBy...) {
+		direction := "references"
+		if fk.Table != s.CurrentTable {
+			direction = "referenced by"
+		}
+		results = append(results, map[string]any{
+			"Direction":  direction,
+			"Column":     fk.Table + "." + fk.Column,
+			"References": fk.RefTable + "." + fk.RefColumn,
+			"Constraint": fk.Constraint,
+		})
+	}
+	s.printTable([]string{"Direction", "Column", "References", "Constraint"}, results)
+	return nil
+}
+
+// References returns the references from table, or from every table of the
+// current database when table is empty. Besides foreign key constraints, a
+// column named after another table by the foreign_key setting, such as
+// user_id, is taken to reference the id of that table, which finds the
+// relations of tables created without constraints.
+func (s *Session) References(ctx context.Context, table string) ([]ForeignKey, error) {
+	rows, err := s.query(ctx, `SELECT CONSTRAINT_NAME, TABLE_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL
+		AND (? = '' OR TABLE_NAME = ?)
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`, table, table)
+	if err != nil {
+		return nil, err
+	}
+	var keys []ForeignKey
+	declared := make(map[string]bool)
+	for rows.Next() {
+		var fk ForeignKey
+		if err := rows.Scan(&fk.Constraint, &fk.Table, &fk.Column, &fk.RefTable, &fk.RefColumn); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		keys = append(keys, fk)
+		declared[fk.Table+"."+fk.Column] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	columns, err := databaseColumns(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	pattern := s.Config.Get("foreign_key")
+	if pattern == "" {
+		pattern = DefaultForeignKeyPattern
+	}
+	tables := make([]string, 0, len(columns))
+	for name := range columns {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
+	for _, child := range tables {
+		if table != "" && child != table {
+			continue
+		}
+		for _, parent := range tables {
+			column := foreignKeyColumn(pattern, parent)
+			if parent == child || declared[child+"."+column] ||
+				!containsString(columns[child], column) || !containsString(columns[parent], "id") {
+				continue
+			}
+			keys = append(keys, ForeignKey{Table: child, Column: column, RefTable: parent, RefColumn: "id"})
+		}
+	}
+	return keys, nil
+}
+
+// databaseColumns returns the columns of every base table of the current
+// database, by table name
+func databaseColumns(ctx context.Context, s *Session) (map[string][]string, error) {
+	rows, err := s.query(ctx, `SELECT c.TABLE_NAME, c.COLUMN_NAME
+		FROM INFORMATION_SCHEMA.COLUMNS c
+		JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE c.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+		columns[table] = append(columns[table], column)
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,96 @@
+// Package remote forwards shell commands to a noqli server started with
+// --serve, for environments where MySQL is only reachable from the server.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/bogwi/noqli/pkg/server"
+)
+
+// Client sends commands to a noqli server
+type Client struct {
+	// Base URL of the server, e.g. https://host:8080
+	URL string
+	// HTTP client used for requests
+	HTTP *http.Client
+	// Bearer token the server was started with
+	Token string
+}
+
+// NewClient creates a client for the server at url
+func NewClient(url string) *Client {
+	return &Client{
+		URL:  strings.TrimRight(url, "/"),
+		HTTP: http.DefaultClient,
+	}
+}
+
+// Exec runs one command on the server
+func (c *Client) Exec(ctx context.Context, req server.ExecRequest) (server.ExecResponse, error) {
+	var resp server.ExecResponse
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+"/exec", bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpResp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close()
+
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return resp, fmt.Errorf("invalid response from %s: %s", c.URL, httpResp.Status)
+	}
+	return resp, nil
+}
+
+// Intercept is a repl intercept that forwards every line to the server,
+// printing its output and tracking the database and table selection in s.
+// Confirmation prompts are answered locally.
+func (c *Client) Intercept(ctx context.Context, s *pkg.Session, line string) (bool, error) {
+	req := server.ExecRequest{DB: s.CurrentDB, Table: s.CurrentTable, Line: line}
+	resp, err := c.Exec(ctx, req)
+	if err != nil {
+		return true, err
+	}
+	fmt.Fprint(s.Out, resp.Output)
+
+	if resp.ConfirmationRequired {
+		if strings.ToLower(pkg.ScanForConfirmation()) != "y" {
+			return true, pkg.ErrConfirmationDeclined
+		}
+		req.Confirm = true
+		confirmed, err := c.Exec(ctx, req)
+		if err != nil {
+			return true, err
+		}
+		// The output up to the prompt was already shown
+		fmt.Fprint(s.Out, strings.TrimPrefix(confirmed.Output, resp.Output))
+		resp = confirmed
+	}
+
+	s.CurrentDB = resp.DB
+	s.CurrentTable = resp.Table
+	if resp.Error != "" {
+		return true, errors.New(resp.Error)
+	}
+	return true, nil
+}
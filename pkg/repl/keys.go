@@ -0,0 +1,169 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// functionKeys are the escape sequences terminals send for the function keys.
+// F1 to F4 have two common forms.
+var functionKeys = map[string][]string{
+	"F1":  {"\x1bOP", "\x1b[11~"},
+	"F2":  {"\x1bOQ", "\x1b[12~"},
+	"F3":  {"\x1bOR", "\x1b[13~"},
+	"F4":  {"\x1bOS", "\x1b[14~"},
+	"F5":  {"\x1b[15~"},
+	"F6":  {"\x1b[17~"},
+	"F7":  {"\x1b[18~"},
+	"F8":  {"\x1b[19~"},
+	"F9":  {"\x1b[20~"},
+	"F10": {"\x1b[21~"},
+	"F11": {"\x1b[23~"},
+	"F12": {"\x1b[24~"},
+}
+
+// reservedCtrlKeys are the control keys that can't be bound: Ctrl-C and
+// Ctrl-D abort and exit, and Ctrl-H, Ctrl-I, Ctrl-J and Ctrl-M are sent for
+// Backspace, Tab and Enter
+const reservedCtrlKeys = "CDHIJM"
+
+// keySequences returns the bytes the terminal sends for the key of a binding
+// name as pkg.CommandHistory spells it, e.g. "F5" or "CTRL-T", or nil when
+// the name is not a key that can be bound
+func keySequences(name string) []string {
+	if seqs, ok := functionKeys[name]; ok {
+		return seqs
+	}
+	letter, ok := strings.CutPrefix(name, "CTRL-")
+	if !ok || len(letter) != 1 || letter[0] < 'A' || letter[0] > 'Z' || strings.Contains(reservedCtrlKeys, letter) {
+		return nil
+	}
+	return []string{string(rune(letter[0] - 'A' + 1))}
+}
+
+// keyInput sits between the terminal and liner, which ignores function keys
+// and uses the control keys for editing. It copies what is typed to liner
+// until a bound key is pressed, which it replaces with Enter so the prompt
+// returns and remembers as the binding to run.
+type keyInput struct {
+	tty  *os.File
+	keys map[string]string // Key sequences to binding names
+	// Liner reads from r what is copied to w
+	r, w *os.File
+	done chan struct{}
+
+	mu      sync.Mutex
+	pressed string
+}
+
+// startKeyInput starts copying the terminal input for a prompt, watching for
+// the keys among names. It returns nil when none of them are keys or stdin
+// is not a terminal that can be read with a deadline, such as on Windows.
+func startKeyInput(names []string) *keyInput {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return nil
+	}
+	k := newKeyInput(tty, names)
+	if k == nil {
+		tty.Close()
+	}
+	return k
+}
+
+// newKeyInput starts copying tty for the keys among names
+func newKeyInput(tty *os.File, names []string) *keyInput {
+	keys := make(map[string]string)
+	for _, name := range names {
+		for _, seq := range keySequences(name) {
+			keys[seq] = name
+		}
+	}
+	// The copy is stopped with a deadline, so it must be supported
+	if len(keys) == 0 || tty.SetReadDeadline(time.Time{}) != nil {
+		return nil
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil
+	}
+	k := &keyInput{tty: tty, keys: keys, r: r, w: w, done: make(chan struct{})}
+	go k.copy()
+	return k
+}
+
+// copy writes what is read from the terminal to liner until a bound key is
+// pressed or the prompt stops the copy
+func (k *keyInput) copy() {
+	defer close(k.done)
+	defer k.w.Close()
+	buf := make([]byte, 256)
+	for {
+		n, err := k.tty.Read(buf)
+		if n > 0 {
+			data := buf[:n]
+			i, name := k.find(data)
+			if name != "" {
+				data = append(data[:i:i], '\r')
+			}
+			if _, err := k.w.Write(data); err != nil || name != "" {
+				k.press(name)
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// find returns the position and binding name of the first bound key in data.
+// Function keys split across reads are passed on as typed.
+func (k *keyInput) find(data []byte) (int, string) {
+	for i := range data {
+		for seq, name := range k.keys {
+			if bytes.HasPrefix(data[i:], []byte(seq)) {
+				return i, name
+			}
+		}
+	}
+	return 0, ""
+}
+
+// press records the binding whose key was pressed
+func (k *keyInput) press(name string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.pressed = name
+}
+
+// Pressed returns the binding whose key ended the prompt, or "" when the
+// prompt ended otherwise
+func (k *keyInput) Pressed() string {
+	if k == nil {
+		return ""
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.pressed
+}
+
+// stop ends the copy once the prompt returned. The pending read is cut short
+// rather than left to take the first key typed to the command that runs next.
+func (k *keyInput) stop() {
+	if k == nil {
+		return
+	}
+	k.tty.SetReadDeadline(time.Now())
+	<-k.done
+	k.tty.Close()
+	k.r.Close()
+}
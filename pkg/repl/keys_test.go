@@ -0,0 +1,71 @@
+package repl
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySequences(t *testing.T) {
+	assert.Equal(t, []string{"\x1b[15~"}, keySequences("F5"))
+	assert.Equal(t, []string{"\x1bOP", "\x1b[11~"}, keySequences("F1"))
+	assert.Equal(t, []string{"\x14"}, keySequences("CTRL-T"))
+	assert.Nil(t, keySequences("CTRL-C"))
+	assert.Nil(t, keySequences("CTRL-M"))
+	assert.Nil(t, keySequences("F13"))
+	assert.Nil(t, keySequences("GT"))
+}
+
+// startTestKeyInput copies a pipe standing in for the terminal; pipes take
+// read deadlines like terminals do
+func startTestKeyInput(t *testing.T, names ...string) (*keyInput, *os.File) {
+	t.Helper()
+	tty, typed, err := os.Pipe()
+	require.NoError(t, err)
+	t.Cleanup(func() { typed.Close() })
+	k := newKeyInput(tty, names)
+	require.NotNil(t, k)
+	return k, typed
+}
+
+func TestKeyInputPressed(t *testing.T) {
+	k, typed := startTestKeyInput(t, "F5", "CTRL-T")
+
+	_, err := typed.Write([]byte("GET\x1b[15~ignored"))
+	require.NoError(t, err)
+	got, err := io.ReadAll(k.r)
+	require.NoError(t, err)
+	assert.Equal(t, "GET\r", string(got))
+	assert.Equal(t, "F5", k.Pressed())
+	k.stop()
+}
+
+func TestKeyInputStop(t *testing.T) {
+	k, typed := startTestKeyInput(t, "CTRL-T")
+
+	_, err := typed.Write([]byte("GET users\r"))
+	require.NoError(t, err)
+	got := make([]byte, 10)
+	_, err = io.ReadFull(k.r, got)
+	require.NoError(t, err)
+	assert.Equal(t, "GET users\r", string(got))
+
+	// The pending read is interrupted without a key being typed
+	k.stop()
+	assert.Equal(t, "", k.Pressed())
+}
+
+func TestKeyInputUnbound(t *testing.T) {
+	tty, typed, err := os.Pipe()
+	require.NoError(t, err)
+	defer typed.Close()
+	defer tty.Close()
+	assert.Nil(t, newKeyInput(tty, []string{"GT", "CTRL-C"}))
+
+	var k *keyInput
+	assert.Equal(t, "", k.Pressed())
+	k.stop()
+}
@@ -0,0 +1,228 @@
+// Package repl runs the interactive noqli shell on top of a pkg.Session so it
+// can be embedded in other tools.
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/peterh/liner"
+)
+
+// IO bundles the streams the shell reads commands from and writes results to
+type IO struct {
+	In  io.Reader
+	Out io.Writer
+}
+
+// LineReader reads one line of input after displaying prompt.
+// It returns io.EOF when the input is exhausted.
+type LineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// Options customizes the shell. All fields are optional.
+type Options struct {
+	// Reader replaces the default line reader built on IO.In
+	Reader LineReader
+	// Prompt returns the prompt to display; defaults to Session.DisplayPrompt
+	Prompt func(s *pkg.Session) string
+	// Intercept is called with every input line before it is executed.
+	// Returning handled=true skips the built-in command dispatcher.
+	Intercept func(ctx context.Context, s *pkg.Session, line string) (handled bool, err error)
+	// CommandContext derives the context each command runs with, e.g. to add
+	// a timeout or cancel on interrupt
+	CommandContext func(ctx context.Context) (context.Context, context.CancelFunc)
+	// History records commands per namespace and expands key shortcuts
+	History *pkg.CommandHistory
+}
+
+// Run reads commands until EXIT, end of input or ctx is cancelled, executing
+// each one against the session. Command errors are printed and do not stop
+// the shell; Run only returns an error when reading input fails.
+func Run(ctx context.Context, s *pkg.Session, streams IO, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if streams.Out != nil {
+		s.Out = streams.Out
+	}
+
+	reader := opts.Reader
+	if reader == nil {
+		reader = NewReader(streams.In, s.Out)
+	}
+
+	prompt := opts.Prompt
+	if prompt == nil {
+		prompt = func(s *pkg.Session) string { return s.DisplayPrompt() }
+	}
+
+	if opts.History != nil {
+		opts.History.UpdateNamespace(s.CurrentDB, s.CurrentTable)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+
+		input, err := reader.ReadLine(prompt(s))
+		if err != nil {
+			if err == io.EOF {
+				fmt.Fprintln(s.Out, "EOF")
+				return nil
+			} else if err == liner.ErrPromptAborted {
+				fmt.Fprintln(s.Out, "Aborted")
+				continue
+			}
+			return fmt.Errorf("error reading input: %w", err)
+		}
+
+		// Process the command
+		trimmedInput := strings.TrimSpace(input)
+		if trimmedInput == "" {
+			continue
+		}
+
+		if opts.History != nil {
+			// Expand key shortcuts into the command they are bound to
+			if expanded, ok := opts.History.ExpandBinding(trimmedInput); ok {
+				fmt.Fprintln(s.Out, expanded)
+				trimmedInput = expanded
+			}
+
+			// Add to history if it's a valid command
+			opts.History.AddHistory(trimmedInput)
+		}
+
+		err = RunLine(ctx, s, trimmedInput, opts)
+		if errors.Is(err, ErrExit) {
+			return nil
+		}
+		PrintError(s, err)
+
+		// Update history namespace when DB/table changes
+		if opts.History != nil {
+			opts.History.UpdateNamespace(s.CurrentDB, s.CurrentTable)
+		}
+	}
+}
+
+// ErrExit is returned by RunLine when the line contains EXIT
+var ErrExit = errors.New("exit")
+
+// RunLine executes the commands of one line, separated by ';', in order. It
+// stops at the first command that fails and returns its error, or ErrExit at
+// an EXIT command.
+func RunLine(ctx context.Context, s *pkg.Session, line string, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	for _, stmt := range pkg.SplitStatements(line) {
+		// Check for exit command
+		if strings.ToUpper(stmt) == "EXIT" {
+			return ErrExit
+		}
+		if err := runCommand(ctx, s, stmt, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrintError reports a command error to the session output; nil is ignored
+func PrintError(s *pkg.Session, err error) {
+	if err == nil {
+		return
+	}
+	var parseErr *pkg.ParseError
+	switch {
+	case errors.Is(err, pkg.ErrConfirmationDeclined):
+		fmt.Fprintln(s.Out, "Operation cancelled")
+	case errors.As(err, &parseErr):
+		fmt.Fprintln(s.Out, "Error:", err)
+		fmt.Fprintln(s.Out, parseErr.Snippet())
+	default:
+		fmt.Fprintln(s.Out, "Error:", err)
+	}
+}
+
+// runCommand executes one line with the per-command context
+func runCommand(ctx context.Context, s *pkg.Session, line string, opts *Options) error {
+	if opts.CommandContext != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = opts.CommandContext(ctx)
+		defer cancel()
+	}
+
+	if opts.Intercept != nil {
+		handled, err := opts.Intercept(ctx, s, line)
+		if handled || err != nil {
+			return err
+		}
+	}
+
+	return pkg.ExecuteCommand(ctx, s, line)
+}
+
+// bufferedReader reads lines from a plain io.Reader, echoing the prompt to out
+type bufferedReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+// NewReader returns a LineReader for non-interactive input such as pipes or tests
+func NewReader(in io.Reader, out io.Writer) LineReader {
+	return &bufferedReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+// ReadLine implements LineReader
+func (r *bufferedReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// linerReader reads lines from the terminal with editing and history support
+type linerReader struct {
+	history *pkg.CommandHistory
+}
+
+// NewLinerReader returns a LineReader for interactive terminals. Each prompt
+// gets a fresh liner configured with the current namespace's history.
+func NewLinerReader(history *pkg.CommandHistory) LineReader {
+	return &linerReader{history: history}
+}
+
+// ReadLine implements LineReader
+func (r *linerReader) ReadLine(prompt string) (string, error) {
+	// liner has no key hook, so bound keys are caught before it reads them.
+	// It takes os.Stdin when created, which is swapped only for that call.
+	keys := startKeyInput(r.history.BindingNames())
+	defer keys.stop()
+	stdin := os.Stdin
+	if keys != nil {
+		os.Stdin = keys.r
+	}
+	line := r.history.SetupLiner()
+	os.Stdin = stdin
+	defer line.Close()
+
+	input, err := line.Prompt(prompt)
+	if name := keys.Pressed(); name != "" {
+		return name, nil
+	}
+	return input, err
+}
@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaxReplicaLag is how far behind the primary a replica may be to
+// serve reads when the profile does not set max_lag
+const DefaultMaxReplicaLag = 5 * time.Second
+
+// Replicas spreads the reads of GET over read replicas round-robin,
+// skipping replicas that lag behind the primary by more than MaxLag or do
+// not replicate
+type Replicas struct {
+	DBs []DBTX
+	// Hosts of DBs, for the debug log
+	Hosts  []string
+	MaxLag time.Duration
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewReplicas creates the replicas dbs at hosts allowed to lag maxLag
+func NewReplicas(dbs []DBTX, hosts []string, maxLag time.Duration) *Replicas {
+	return &Replicas{DBs: dbs, Hosts: hosts, MaxLag: maxLag}
+}
+
+// replicaLag returns how far db is behind its source. Servers that are not
+// replicas are not behind; stopped replicas have no lag and ok false.
+func replicaLag(ctx context.Context, db DBTX) (lag time.Duration, ok bool, err error) {
+	channels, err := NewSession(db).showRecords(ctx, "SHOW REPLICA STATUS", "SHOW SLAVE STATUS")
+	if err != nil {
+		return 0, false, err
+	}
+	for _, entry := range channels {
+		seconds, err := strconv.ParseInt(recordField(entry, "Seconds_Behind_Source", "Seconds_Behind_Master"), 10, 64)
+		if err != nil {
+			return 0, false, nil
+		}
+		if d := time.Duration(seconds) * time.Second; d > lag {
+			lag = d
+		}
+	}
+	return lag, true, nil
+}
+
+// pick returns the next replica keeping up with the primary and its host,
+// or ok false when none does
+func (r *Replicas) pick(ctx context.Context) (db DBTX, host string, ok bool) {
+	r.mu.Lock()
+	start := r.next
+	r.next = (r.next + 1) % len(r.DBs)
+	r.mu.Unlock()
+
+	for i := range r.DBs {
+		n := (start + i) % len(r.DBs)
+		lag, replicating, err := replicaLag(ctx, r.DBs[n])
+		if err == nil && replicating && lag <= r.MaxLag {
+			return r.DBs[n], r.Hosts[n], true
+		}
+	}
+	return nil, "", false
+}
+
+// onReplica runs the read fn of a GET with args on a replica. It reads from
+// the primary when args has primary: true, for reading a write just made,
+// inside a transaction, and when no replica keeps up.
+func (s *Session) onReplica(ctx context.Context, args map[string]any, fn func() error) error {
+	primary := false
+	for _, key := range []string{"PRIMARY", "primary"} {
+		if v, ok := args[key]; ok {
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("primary requires true or false, e.g. {id: 1, primary: true}")
+			}
+			primary = b
+			delete(args, key)
+		}
+	}
+	if _, inTx := s.DB.(*sql.Tx); primary || inTx || s.Replicas == nil || len(s.Replicas.DBs) == 0 {
+		return fn()
+	}
+
+	db, host, ok := s.Replicas.pick(ctx)
+	if !ok {
+		s.Logger.Warn("no replica keeps up, reading from the primary", "max_lag", s.Replicas.MaxLag)
+		return fn()
+	}
+	s.Logger.Debug("reading from replica", "host", host)
+	primaryDB := s.DB
+	s.DB = db
+	defer func() { s.DB = primaryDB }()
+	return fn()
+}
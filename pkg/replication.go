@@ -0,0 +1,176 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IsGetReplicationCommand checks if the command is GET replication
+func IsGetReplicationCommand(command string, args string) bool {
+	return strings.ToUpper(command) == "GET" && strings.ToLower(strings.TrimSpace(args)) == "replication"
+}
+
+// ReplicaChannel is the state of one replication channel of a replica.
+// SecondsBehind is nil while the replica is not replicating.
+type ReplicaChannel struct {
+	Channel       string `json:"channel"`
+	Source        string `json:"source"`
+	IOThread      string `json:"io_thread"`
+	SQLThread     string `json:"sql_thread"`
+	SecondsBehind *int64 `json:"seconds_behind"`
+	SourceLog     string `json:"source_log"`
+	LastIOError   string `json:"last_io_error"`
+	LastSQLError  string `json:"last_sql_error"`
+}
+
+// ReplicationStatus is the replication state of the server: its binary log
+// position and connected replicas as a primary, and its channels as a replica
+type ReplicationStatus struct {
+	Role         string           `json:"role"`
+	BinaryLog    string           `json:"binary_log"`
+	ExecutedGTID string           `json:"executed_gtid_set"`
+	Replicas     int              `json:"replicas"`
+	Channels     []ReplicaChannel `json:"channels"`
+}
+
+// showRecords runs the first of statements the server accepts and returns
+// its rows. MySQL 8.0.22 and later renamed the MASTER and SLAVE statements,
+// and 8.4 removed the old names, so both spellings are tried.
+func (s *Session) showRecords(ctx context.Context, statements ...string) ([]map[string]any, error) {
+	var lastErr error
+	for _, statement := range statements {
+		rows, err := s.query(ctx, statement)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		defer rows.Close()
+		columns, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		var records []map[string]any
+		for rows.Next() {
+			entry, err := scanRecord(rows, columns)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, entry)
+		}
+		return records, rows.Err()
+	}
+	return nil, lastErr
+}
+
+// recordField returns the first of names present in entry as a string,
+// covering the old and new column names of the replication statements
+func recordField(entry map[string]any, names ...string) string {
+	for _, name := range names {
+		if v, ok := entry[name]; ok && v != nil {
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return ""
+}
+
+// Replication returns the replication status of the server
+func (s *Session) Replication(ctx context.Context) (ReplicationStatus, error) {
+	status := ReplicationStatus{Channels: []ReplicaChannel{}}
+
+	binlog, err := s.showRecords(ctx, "SHOW BINARY LOG STATUS", "SHOW MASTER STATUS")
+	if err != nil {
+		return status, err
+	}
+	if len(binlog) > 0 {
+		status.BinaryLog = recordField(binlog[0], "File") + ":" + recordField(binlog[0], "Position")
+		status.ExecutedGTID = strings.ReplaceAll(recordField(binlog[0], "Executed_Gtid_Set"), "\n", "")
+	}
+
+	replicas, err := s.showRecords(ctx, "SHOW REPLICAS", "SHOW SLAVE HOSTS")
+	if err != nil {
+		return status, err
+	}
+	status.Replicas = len(replicas)
+
+	channels, err := s.showRecords(ctx, "SHOW REPLICA STATUS", "SHOW SLAVE STATUS")
+	if err != nil {
+		return status, err
+	}
+	for _, entry := range channels {
+		channel := ReplicaChannel{
+			Channel:      recordField(entry, "Channel_Name"),
+			Source:       recordField(entry, "Source_Host", "Master_Host") + ":" + recordField(entry, "Source_Port", "Master_Port"),
+			IOThread:     recordField(entry, "Replica_IO_Running", "Slave_IO_Running"),
+			SQLThread:    recordField(entry, "Replica_SQL_Running", "Slave_SQL_Running"),
+			SourceLog:    recordField(entry, "Relay_Source_Log_File", "Relay_Master_Log_File") + ":" + recordField(entry, "Exec_Source_Log_Pos", "Exec_Master_Log_Pos"),
+			LastIOError:  recordField(entry, "Last_IO_Error"),
+			LastSQLError: recordField(entry, "Last_SQL_Error"),
+		}
+		if n, err := strconv.ParseInt(recordField(entry, "Seconds_Behind_Source", "Seconds_Behind_Master"), 10, 64); err == nil {
+			channel.SecondsBehind = &n
+		}
+		status.Channels = append(status.Channels, channel)
+	}
+
+	switch {
+	case len(status.Channels) > 0 && status.Replicas > 0:
+		status.Role = "primary and replica"
+	case len(status.Channels) > 0:
+		status.Role = "replica"
+	case status.Replicas > 0:
+		status.Role = "primary"
+	default:
+		status.Role = "standalone"
+	}
+	return status, nil
+}
+
+// handleGetReplication shows the replication status of the server. The
+// table lists the same fields in the same order on every run, so WATCH
+// highlights only the values that changed.
+func handleGetReplication(ctx context.Context, s *Session) error {
+	status, err := s.Replication(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Replication: %s\n", ColorJSON(status))
+		return nil
+	}
+
+	var results []map[string]any
+	add := func(field, value string) {
+		if value == "" {
+			value = "-"
+		}
+		results = append(results, map[string]any{"Field": field, "Value": value})
+	}
+	add("Role", status.Role)
+	add("Binary log", status.BinaryLog)
+	if status.ExecutedGTID != "" {
+		add("Executed GTID set", status.ExecutedGTID)
+	}
+	add("Connected replicas", strconv.Itoa(status.Replicas))
+	for _, channel := range status.Channels {
+		suffix := ""
+		if channel.Channel != "" {
+			suffix = " [" + channel.Channel + "]"
+		}
+		behind := "NULL (not replicating)"
+		if channel.SecondsBehind != nil {
+			behind = strconv.FormatInt(*channel.SecondsBehind, 10)
+		}
+		add("Source"+suffix, channel.Source)
+		add("IO thread"+suffix, channel.IOThread)
+		add("SQL thread"+suffix, channel.SQLThread)
+		add("Seconds behind source"+suffix, behind)
+		add("Executed source log"+suffix, channel.SourceLog)
+		add("Last IO error"+suffix, channel.LastIOError)
+		add("Last SQL error"+suffix, channel.LastSQLError)
+	}
+	s.printTable([]string{"Field", "Value"}, results)
+	return nil
+}
@@ -0,0 +1,169 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GetReportCommandRegex returns the regex for REPORT commands
+func GetReportCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(REPORT)\s+(\S+)(?:\s+(\S+))?$`)
+}
+
+// UnusedCheck counts the rows of Table that none of the tables referencing
+// it refer to
+type UnusedCheck struct {
+	Table        string   `json:"table"`
+	ReferencedBy []string `json:"referenced_by"`
+	Unused       int64    `json:"unused"`
+	keys         []ForeignKey
+}
+
+// OrphanReport lists the rows of the current database referencing missing
+// rows and the referenced rows nothing refers to, with the DELETE
+// statements removing them when requested
+type OrphanReport struct {
+	Orphans []OrphanCheck `json:"orphans"`
+	Unused  []UnusedCheck `json:"unused"`
+	Cleanup []string      `json:"cleanup,omitempty"`
+}
+
+// UnusedRows counts, for every referenced table of the current database, the
+// rows no referencing row points to
+func (s *Session) UnusedRows(ctx context.Context) ([]UnusedCheck, error) {
+	keys, err := s.References(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	byTable := make(map[string][]ForeignKey)
+	for _, fk := range keys {
+		byTable[fk.RefTable] = append(byTable[fk.RefTable], fk)
+	}
+	tables := make([]string, 0, len(byTable))
+	for table := range byTable {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	checks := []UnusedCheck{}
+	for _, table := range tables {
+		check := UnusedCheck{Table: table, keys: byTable[table]}
+		for _, fk := range check.keys {
+			check.ReferencedBy = append(check.ReferencedBy, fk.Table+"."+fk.Column)
+		}
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s AS p WHERE %s", quoteIdent(table), unusedCondition(check.keys))
+		if err := s.queryRow(ctx, query).Scan(&check.Unused); err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// unusedCondition matches the rows of the referenced table, aliased p, that
+// none of keys refer to
+func unusedCondition(keys []ForeignKey) string {
+	conditions := make([]string, len(keys))
+	for i, fk := range keys {
+		conditions[i] = fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s AS c WHERE c.%s = p.%s)",
+			quoteIdent(fk.Table), quoteIdent(fk.Column), quoteIdent(fk.RefColumn))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// OrphanReport builds the orphan report of the current database. With
+// cleanup, it includes the DELETE statements removing the orphaned and
+// unused rows, which are not run.
+func (s *Session) OrphanReport(ctx context.Context, cleanup bool) (OrphanReport, error) {
+	var report OrphanReport
+	var err error
+	if report.Orphans, err = s.CheckReferences(ctx, ""); err != nil {
+		return report, err
+	}
+	if report.Unused, err = s.UnusedRows(ctx); err != nil {
+		return report, err
+	}
+	if !cleanup {
+		return report, nil
+	}
+
+	for _, check := range report.Orphans {
+		if check.Orphans > 0 {
+			report.Cleanup = append(report.Cleanup, "DELETE c "+orphanJoin(check.ForeignKey)+";")
+		}
+	}
+	for _, check := range report.Unused {
+		if check.Unused > 0 {
+			report.Cleanup = append(report.Cleanup, fmt.Sprintf("DELETE p FROM %s AS p WHERE %s;",
+				quoteIdent(check.Table), unusedCondition(check.keys)))
+		}
+	}
+	return report, nil
+}
+
+// handleReport prints REPORT orphans, with the cleanup statements for
+// REPORT orphans cleanup
+func handleReport(ctx context.Context, s *Session, name, option string) error {
+	if !strings.EqualFold(name, "orphans") {
+		return fmt.Errorf("unknown report %q. Use REPORT orphans", name)
+	}
+	if option != "" && !strings.EqualFold(option, "cleanup") {
+		return fmt.Errorf("usage: REPORT orphans [cleanup]")
+	}
+	if s.CurrentDB == "" {
+		return fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+
+	report, err := s.OrphanReport(ctx, option != "")
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Report: %s\n", ColorJSON(report))
+		return nil
+	}
+	if len(report.Orphans) == 0 {
+		fmt.Fprintln(s.Out, "No references found to check")
+		return nil
+	}
+
+	fmt.Fprintln(s.Out, "Rows referencing missing rows:")
+	orphans := make([]map[string]any, len(report.Orphans))
+	for i, check := range report.Orphans {
+		orphans[i] = map[string]any{
+			"Table":      check.Table,
+			"Column":     check.Column,
+			"References": check.RefTable + "." + check.RefColumn,
+			"Orphans":    check.Orphans,
+		}
+	}
+	FprintTabularResults(s.Out, []string{"Table", "Column", "References", "Orphans"}, orphans)
+
+	fmt.Fprintln(s.Out, "Rows nothing refers to:")
+	unused := make([]map[string]any, len(report.Unused))
+	for i, check := range report.Unused {
+		unused[i] = map[string]any{
+			"Table":         check.Table,
+			"Referenced by": strings.Join(check.ReferencedBy, ", "),
+			"Unused":        check.Unused,
+		}
+	}
+	s.printTable([]string{"Table", "Referenced by", "Unused"}, unused)
+
+	if option != "" {
+		if len(report.Cleanup) == 0 {
+			fmt.Fprintln(s.Out, "Nothing to clean up")
+			return nil
+		}
+		fmt.Fprintln(s.Out, "Cleanup statements, review before running them:")
+		for _, statement := range report.Cleanup {
+			fmt.Fprintf(s.Out, "  %s\n", statement)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// DefaultRetries is how often a statement failing with a transient error is
+// retried when the config does not set retries
+const DefaultRetries = 3
+
+// retryDelay is the wait before the first retry, doubled for each next one
+const retryDelay = 100 * time.Millisecond
+
+// transientError tells whether err may pass when tried again, naming it,
+// and whether it is a lost connection rather than a lock conflict
+func transientError(err error) (reason string, lostConnection bool, ok bool) {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1213:
+			return "deadlock", false, true
+		case 1205:
+			return "lock wait timeout", false, true
+		}
+		return "", false, false
+	}
+	if errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, driver.ErrBadConn) {
+		return "lost connection", true, true
+	}
+	return "", false, false
+}
+
+// retries returns how often transient errors are retried, from the retries
+// setting of the config
+func (s *Session) retries() int {
+	n, err := strconv.Atoi(s.Config.Get("retries"))
+	if err != nil || n < 0 {
+		return DefaultRetries
+	}
+	return n
+}
+
+// withRetry runs fn, running it again with exponential backoff while it
+// fails with a transient error, up to the configured retries. Lost
+// connections are only retried when idempotent is set, as a write may have
+// been applied before its connection dropped. Retries are reported while
+// SET debug is on.
+func (s *Session) withRetry(ctx context.Context, idempotent bool, fn func() error) error {
+	retries := s.retries()
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if attempt > retries {
+			if retries > 0 {
+				return fmt.Errorf("%w (after %d retries)", err, retries)
+			}
+			return err
+		}
+		reason, lostConnection, ok := transientError(err)
+		if !ok || (lostConnection && !idempotent) {
+			return err
+		}
+
+		s.Logger.Warn("retrying", "reason", reason, "attempt", attempt, "retries", retries, "delay", delay)
+		if s.Logger != nil {
+			fmt.Fprintf(s.Out, "Retrying after %s (%d/%d) in %s\n", reason, attempt, retries, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// inTx tells whether the session's statements run inside a transaction,
+// which a transient error rolls back as a whole
+func (s *Session) inTx() bool {
+	_, ok := s.DB.(*sql.Tx)
+	return ok
+}
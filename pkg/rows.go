@@ -0,0 +1,157 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Rows iterates over the records of a query one at a time, so large result
+// sets can be consumed without loading them into memory. Call Next before
+// each Scan or Map, and Close when done.
+type Rows struct {
+	rows    *sql.Rows
+	columns []string
+}
+
+// Query runs a GET-style query against the current table and returns an
+// iterator over the matching records. args accepts the same filters, column
+// selection, ordering, LIKE and LIMIT/OFFSET keys as GET; it is not modified.
+func (s *Session) Query(ctx context.Context, args map[string]any) (*Rows, error) {
+	// Resolving variables also copies args, which buildGetQuery modifies
+	filters, err := s.resolveArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if filters == nil {
+		filters = make(map[string]any)
+	}
+
+	source, err := sourceFromArgs(ctx, s, filters)
+	if err != nil {
+		return nil, err
+	}
+	builder, err := buildGetQuery(ctx, s, source, filters)
+	if err != nil {
+		return nil, err
+	}
+	query, values, err := builder.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.query(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &Rows{rows: rows, columns: columns}, nil
+}
+
+// Next advances to the next record, returning false when there are no more
+// records or an error occurred; check Err afterwards
+func (r *Rows) Next() bool {
+	return r.rows.Next()
+}
+
+// Columns returns the column names of the result
+func (r *Rows) Columns() []string {
+	return r.columns
+}
+
+// Map returns the current record as a column name to value map, with text
+// values converted to strings
+func (r *Rows) Map() (map[string]any, error) {
+	return scanRecord(r.rows, r.columns)
+}
+
+// Scan copies the current record into dest, which must be a pointer to a
+// map[string]any or to a struct. Struct fields are matched to columns by their
+// `db` tag or, without one, by case-insensitive field name; columns without a
+// matching field are skipped.
+func (r *Rows) Scan(dest any) error {
+	if m, ok := dest.(*map[string]any); ok {
+		entry, err := r.Map()
+		if err != nil {
+			return err
+		}
+		*m = entry
+		return nil
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be a pointer to a struct or map[string]any, got %T", dest)
+	}
+	fields := structFields(v.Elem())
+
+	targets := make([]any, len(r.columns))
+	for i, col := range r.columns {
+		if field, ok := fields[strings.ToLower(col)]; ok {
+			targets[i] = field.Addr().Interface()
+		} else {
+			targets[i] = new(any)
+		}
+	}
+	return r.rows.Scan(targets...)
+}
+
+// Err returns the error, if any, encountered during iteration
+func (r *Rows) Err() error {
+	return r.rows.Err()
+}
+
+// Close releases the underlying result set
+func (r *Rows) Close() error {
+	return r.rows.Close()
+}
+
+// structFields maps lower-cased column names to the exported fields of v
+func structFields(v reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		name := field.Name
+		if tag := field.Tag.Get("db"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		fields[strings.ToLower(name)] = v.Field(i)
+	}
+	return fields
+}
+
+// scanRecord reads the current row into a map, converting []byte to string
+func scanRecord(rows *sql.Rows, columns []string) (map[string]any, error) {
+	values := make([]any, len(columns))
+	valuePtrs := make([]any, len(columns))
+	for i := range columns {
+		valuePtrs[i] = &values[i]
+	}
+
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, err
+	}
+
+	entry := make(map[string]any, len(columns))
+	for i, col := range columns {
+		if b, ok := values[i].([]byte); ok {
+			entry[col] = string(b)
+		} else {
+			entry[col] = values[i]
+		}
+	}
+	return entry, nil
+}
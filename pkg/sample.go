@@ -0,0 +1,155 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// sampleRounds is the number of times random primary keys are drawn before
+// falling back to ORDER BY RAND()
+const sampleRounds = 5
+
+// randInt63n draws the random primary keys of a sample
+var randInt63n = rand.Int63n
+
+// sampleSize reads the number of rows of a {sample: n} argument
+func sampleSize(value any) (int, error) {
+	n, ok := toInt(value)
+	if !ok || n <= 0 {
+		return 0, fmt.Errorf("sample must be a positive integer")
+	}
+	return n, nil
+}
+
+// sampleSeed reads the seed of a {seed: n} argument
+func sampleSeed(value any) (int64, error) {
+	n, ok := toInt(value)
+	if !ok {
+		return 0, fmt.Errorf("seed must be an integer")
+	}
+	return int64(n), nil
+}
+
+// sampleQuery returns builder restricted to a random sample of n matching
+// rows. Random values of an integer primary key are drawn between its
+// minimum and maximum and looked up, so large tables are not sorted; gaps
+// and filters are covered by drawing again. Tables without such a key, or
+// with too few matches found, are sampled with ORDER BY RAND(). With a seed
+// the same data gives the same sample on every run.
+func sampleQuery(ctx context.Context, s *Session, source getSource, builder *QueryBuilder, n int, seed *int64) (*QueryBuilder, error) {
+	draw, shuffle := randInt63n, rand.Shuffle
+	random := builder.clone()
+	random.orderBy = []string{"RAND()"}
+	if seed != nil {
+		rng := rand.New(rand.NewSource(*seed))
+		draw, shuffle = rng.Int63n, rng.Shuffle
+		random.orderBy = []string{fmt.Sprintf("RAND(%d)", *seed)}
+	}
+	random.limit, random.offset = n, nil
+
+	key, err := integerPrimaryKey(ctx, s, source)
+	if err != nil || key == "" {
+		return random, err
+	}
+
+	var low, high sql.NullInt64
+	query := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", quoteIdent(key), quoteIdent(key), source.from)
+	if err := s.queryRow(ctx, query).Scan(&low, &high); err != nil {
+		return nil, err
+	}
+	span := high.Int64 - low.Int64 + 1
+	if !low.Valid || span <= int64(2*n) {
+		return random, nil
+	}
+
+	found := make(map[int64]bool)
+	var keys []any
+	for round := 0; round < sampleRounds && len(keys) < n; round++ {
+		// Draw twice the missing rows to cover gaps in the keys
+		var candidates []string
+		var candidateArgs []any
+		for i := 0; i < 2*(n-len(keys)); i++ {
+			candidates = append(candidates, "?")
+			candidateArgs = append(candidateArgs, low.Int64+draw(span))
+		}
+		lookup := builder.clone()
+		lookup.selectExpr = quoteIdent(key)
+		lookup.orderBy, lookup.limit, lookup.offset = nil, nil, nil
+		lookup.WhereRaw(fmt.Sprintf("%s IN (%s)", quoteIdent(key), strings.Join(candidates, ", ")), candidateArgs...)
+		query, values, err := lookup.Select()
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := s.query(ctx, query, values...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !found[id] {
+				found[id] = true
+				keys = append(keys, id)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(keys) < n {
+		return random, nil
+	}
+	// Keep a random n of the keys, put in order first so a seed picks the
+	// same ones whichever order the rounds found them in
+	sort.Slice(keys, func(i, j int) bool { return keys[i].(int64) < keys[j].(int64) })
+	shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	keys = keys[:n]
+
+	sample := builder.clone()
+	sample.limit, sample.offset = nil, nil
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")
+	sample.WhereRaw(fmt.Sprintf("%s IN (%s)", quoteIdent(key), placeholders), keys...)
+	return sample, nil
+}
+
+// integerPrimaryKey returns the primary key column of source when it is a
+// single integer column, or an empty string
+func integerPrimaryKey(ctx context.Context, s *Session, source getSource) (string, error) {
+	if source.union {
+		return "", nil
+	}
+	rows, err := s.query(ctx, fmt.Sprintf("SHOW COLUMNS FROM %s", source.table))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var keys []string
+	integer := false
+	for rows.Next() {
+		var field, fieldType, null, key, defaultVal, extra sql.NullString
+		if err := rows.Scan(&field, &fieldType, &null, &key, &defaultVal, &extra); err != nil {
+			return "", err
+		}
+		if key.String == "PRI" {
+			keys = append(keys, field.String)
+			integer = strings.Contains(strings.ToLower(fieldType.String), "int")
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if len(keys) != 1 || !integer {
+		return "", nil
+	}
+	return keys[0], nil
+}
@@ -0,0 +1,203 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GetSearchCommandRegex returns the regex for SEARCH 'value' [LIM n]
+func GetSearchCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(SEARCH)\s+(.+?)(?:\s+LIM\s+(\d+))?$`)
+}
+
+// defaultSearchLimit is the number of matching rows SEARCH shows per table
+const defaultSearchLimit = 10
+
+// matchedColumn is the column SEARCH adds to name the columns a row matched in
+const matchedColumn = "noqli_matched"
+
+// textTypes are the column types SEARCH looks in
+var textTypes = []string{"char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set"}
+
+// SearchResult holds the rows of one table matching a SEARCH
+type SearchResult struct {
+	Table   string           `json:"table"`
+	Columns []string         `json:"columns"`
+	Rows    []map[string]any `json:"rows"`
+	// Column names of the rows, in table order
+	order []string
+}
+
+// textColumnsByTable returns the text columns of every base table of the
+// current database, in column order
+func (s *Session) textColumnsByTable(ctx context.Context) ([]string, map[string][]string, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(textTypes)), ", ")
+	args := make([]any, len(textTypes))
+	for i, t := range textTypes {
+		args[i] = t
+	}
+	rows, err := s.query(ctx, `SELECT c.TABLE_NAME, c.COLUMN_NAME
+		FROM INFORMATION_SCHEMA.COLUMNS c
+		JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE c.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE' AND c.DATA_TYPE IN (`+placeholders+`)
+		ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION`, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := columns[table]; !ok {
+			tables = append(tables, table)
+		}
+		columns[table] = append(columns[table], column)
+	}
+	return tables, columns, rows.Err()
+}
+
+// Search looks for value with LIKE in the text columns of every table of
+// the current database, returning up to limit matching rows per table and
+// the columns they matched in. Values without a % wildcard match anywhere.
+func (s *Session) Search(ctx context.Context, value string, limit int) ([]SearchResult, error) {
+	if s.CurrentDB == "" {
+		return nil, fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+	tables, textColumns, err := s.textColumnsByTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := value
+	if !strings.Contains(pattern, "%") {
+		pattern = "%" + pattern + "%"
+	}
+	var results []SearchResult
+	for _, table := range tables {
+		columns := textColumns[table]
+		// Name the columns each row matched in, so they need not be guessed
+		matches := make([]string, len(columns))
+		var matchArgs []any
+		for i, col := range columns {
+			matches[i] = fmt.Sprintf("IF(%s LIKE ?, %s, NULL)", caseColumn(quoteIdent(col), s.matchCase), quoteString(col))
+			matchArgs = append(matchArgs, pattern)
+		}
+		builder := NewQueryBuilder(quoteIdent(table)).
+			SelectExpr(fmt.Sprintf("*, CONCAT_WS(',', %s) AS %s", strings.Join(matches, ", "), quoteIdent(matchedColumn))).
+			MatchCase(s.matchCase).
+			WhereLike(value, columns).
+			Limit(limit, nil)
+		query, values, err := builder.Select()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := s.searchTable(ctx, table, query, append(matchArgs, values...))
+		if err != nil {
+			return nil, fmt.Errorf("searching %s: %w", table, err)
+		}
+		if len(result.Rows) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// searchTable runs the search query of table and collects its rows and the
+// columns they matched in
+func (s *Session) searchTable(ctx context.Context, table, query string, args []any) (SearchResult, error) {
+	result := SearchResult{Table: table}
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return result, err
+	}
+	display, err := s.newColumnFormatter(rows, nil)
+	if err != nil {
+		return result, err
+	}
+	for _, col := range columns {
+		if col != matchedColumn {
+			result.order = append(result.order, col)
+		}
+	}
+	matched := make(map[string]bool)
+	for rows.Next() {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
+			return result, err
+		}
+		if names, ok := entry[matchedColumn].(string); ok {
+			for _, name := range strings.Split(names, ",") {
+				if name != "" && !matched[name] {
+					matched[name] = true
+					result.Columns = append(result.Columns, name)
+				}
+			}
+		}
+		delete(entry, matchedColumn)
+		display.format(entry)
+		result.Rows = append(result.Rows, entry)
+	}
+	return result, rows.Err()
+}
+
+// handleSearch runs SEARCH and prints the matching rows table by table
+func handleSearch(ctx context.Context, s *Session, value, limit string) error {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	if value == "" {
+		return fmt.Errorf("SEARCH needs a value, e.g. SEARCH 'john@example.com'")
+	}
+	n := defaultSearchLimit
+	if limit != "" {
+		var err error
+		if n, err = strconv.Atoi(limit); err != nil || n <= 0 {
+			return fmt.Errorf("invalid LIM %q: must be a positive integer", limit)
+		}
+	}
+
+	results, err := s.Search(ctx, value, n)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, result := range results {
+		total += len(result.Rows)
+	}
+	s.recordRows(int64(total))
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Search: %s\n", ColorJSON(results))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(s.Out, "No table of %s contains %q\n", s.CurrentDB, value)
+		return nil
+	}
+	for _, result := range results {
+		fmt.Fprintf(s.Out, "\n%s: %d rows matching in %s\n", result.Table, len(result.Rows), strings.Join(result.Columns, ", "))
+		table := s.newTableWriter(result.order, 0)
+		for _, row := range result.Rows {
+			table.Write(row)
+		}
+		table.Close()
+	}
+	fmt.Fprintf(s.Out, "\n%d rows in %d tables%s\n", total, len(results), s.timing())
+	return nil
+}
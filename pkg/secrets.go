@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keychainService is the service the OS keychain keeps NoQLi passwords under
+const keychainService = "noqli"
+
+// windowsVault loads the Windows credential vault in PowerShell
+const windowsVault = "[void][Windows.Security.Credentials.PasswordVault,Windows.Security.Credentials,ContentType=WindowsRuntime]; $v = New-Object Windows.Security.Credentials.PasswordVault; "
+
+// keychainLookup returns the command printing the password stored under name
+// in the keychain of this system
+func keychainLookup(name string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"security", "find-generic-password", "-s", keychainService, "-a", name, "-w"}
+	case "windows":
+		return []string{"powershell", "-NoProfile", "-Command", windowsVault +
+			fmt.Sprintf("$v.Retrieve('%s', '%s').Password", keychainService, strings.ReplaceAll(name, "'", "''"))}
+	}
+	return []string{"secret-tool", "lookup", "service", keychainService, "account", name}
+}
+
+// secretLookup returns the command printing the secret a password reference
+// names and the field of the secret holding the password, or ok false when
+// value is a plain password:
+//
+//	keychain:name           the OS keychain entry stored by noqli keychain name
+//	vault:path#field        a field of a HashiCorp Vault KV secret (password by default)
+//	aws:secret-id#field     an AWS Secrets Manager secret, or a field of its JSON
+func secretLookup(value string) (command []string, field string, ok bool, err error) {
+	scheme, ref, found := strings.Cut(value, ":")
+	if !found {
+		return nil, "", false, nil
+	}
+	ref, field, _ = strings.Cut(ref, "#")
+	switch scheme {
+	case "keychain":
+		if ref == "" {
+			return nil, "", true, fmt.Errorf("keychain: needs an entry name, e.g. password = keychain:prod")
+		}
+		return keychainLookup(ref), "", true, nil
+	case "vault":
+		if ref == "" {
+			return nil, "", true, fmt.Errorf("vault: needs a secret path, e.g. password = vault:secret/noqli#password")
+		}
+		if field == "" {
+			field = "password"
+		}
+		return []string{"vault", "kv", "get", "-field=" + field, ref}, "", true, nil
+	case "aws":
+		if ref == "" {
+			return nil, "", true, fmt.Errorf("aws: needs a secret id, e.g. password = aws:prod/noqli#password")
+		}
+		return []string{"aws", "secretsmanager", "get-secret-value", "--secret-id", ref, "--query", "SecretString", "--output", "text"}, field, true, nil
+	}
+	return nil, "", false, nil
+}
+
+// ResolvePassword returns the password a connection setting names. Values
+// starting with keychain:, vault: or aws: are read from the OS keychain,
+// HashiCorp Vault or AWS Secrets Manager with their command line tools;
+// other values are the password itself.
+func ResolvePassword(value string) (string, error) {
+	command, field, ok, err := secretLookup(value)
+	if !ok || err != nil {
+		return value, err
+	}
+	out, err := exec.Command(command[0], command[1:]...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			err = fmt.Errorf("%v %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("reading password %s with %s: %w", value, command[0], err)
+	}
+	secret := strings.TrimRight(string(out), "\r\n")
+
+	// AWS secrets often hold the whole connection as JSON
+	var fields map[string]any
+	if strings.HasPrefix(secret, "{") && json.Unmarshal([]byte(secret), &fields) == nil {
+		if field == "" {
+			field = "password"
+		}
+		password, ok := fields[field].(string)
+		if !ok {
+			return "", fmt.Errorf("secret %s has no %s field", value, field)
+		}
+		return password, nil
+	}
+	if field != "" {
+		return "", fmt.Errorf("secret %s is not JSON, so it has no %s field", value, field)
+	}
+	return secret, nil
+}
+
+// StoreKeychainPassword stores password under name in the OS keychain, with
+// security on macOS, the credential vault on Windows and secret-tool
+// (libsecret) elsewhere, for profiles to use as password = keychain:name
+func StoreKeychainPassword(name, password string) error {
+	cmd := keychainStoreCommand(runtime.GOOS, name, password)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// keychainStoreCommand returns the command storing password under name in
+// the keychain of goos. The password is written to its stdin, never put on
+// the command line where other users could see it with ps.
+func keychainStoreCommand(goos, name, password string) *exec.Cmd {
+	var cmd *exec.Cmd
+	switch goos {
+	case "darwin":
+		// security -i reads the command, with its quoted arguments, from stdin
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a \"%s\" -w \"%s\"\n",
+			keychainService, quote.Replace(name), quote.Replace(password)))
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", windowsVault+
+			fmt.Sprintf("$v.Add((New-Object Windows.Security.Credentials.PasswordCredential('%s', '%s', [Console]::In.ReadLine())))",
+				keychainService, strings.ReplaceAll(name, "'", "''")))
+		cmd.Stdin = strings.NewReader(password + "\n")
+	default:
+		cmd = exec.Command("secret-tool", "store", "--label", "noqli "+name, "service", keychainService, "account", name)
+		cmd.Stdin = strings.NewReader(password)
+	}
+	return cmd
+}
@@ -0,0 +1,473 @@
+// Package server exposes NoQLi queries over HTTP and WebSocket so results can
+// be consumed by other programs and live dashboards.
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/gorilla/websocket"
+)
+
+//go:embed ui
+var uiDir embed.FS
+
+// uiFiles holds the web UI served at the root
+var uiFiles, _ = fs.Sub(uiDir, "ui")
+
+// DefaultPollInterval is how often live queries check the table checksum
+const DefaultPollInterval = time.Second
+
+// QueryRequest selects the records to return
+type QueryRequest struct {
+	// Table to query
+	Table string `json:"table"`
+	// NoQLi argument object as typed after GET, e.g. "{status: active, lim: 10}"
+	Args string `json:"args,omitempty"`
+	// Refresh interval for live queries, e.g. "5s". When empty, results are
+	// sent whenever the table checksum changes.
+	Interval string `json:"interval,omitempty"`
+}
+
+// QueryResponse carries the records of a query or the error it failed with.
+// Record keys are encoded in pkg.JSONKeyOrder.
+type QueryResponse struct {
+	Columns []string     `json:"columns,omitempty"`
+	Records []pkg.Record `json:"records"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// TablesResponse lists the tables of the database
+type TablesResponse struct {
+	Tables []string `json:"tables"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// ExecRequest runs a command line the way the interactive shell would
+type ExecRequest struct {
+	// Database and table selected on the client
+	DB    string `json:"db,omitempty"`
+	Table string `json:"table,omitempty"`
+	// Command line, e.g. "GET {id: 5}" or "USE users"
+	Line string `json:"line"`
+	// Confirm answers yes to confirmation prompts such as an UPDATE without filters
+	Confirm bool `json:"confirm,omitempty"`
+}
+
+// ExecResponse carries the output of a command and the selection after it ran
+type ExecResponse struct {
+	Output string `json:"output"`
+	DB     string `json:"db"`
+	Table  string `json:"table"`
+	// Set when the command stopped at a confirmation prompt; resend the
+	// request with Confirm to proceed
+	ConfirmationRequired bool   `json:"confirmation_required,omitempty"`
+	Error                string `json:"error,omitempty"`
+}
+
+// Server serves queries against a database
+type Server struct {
+	DB *sql.DB
+	// Database the per-request sessions start in
+	Database string
+	// How often live queries without an interval check for table changes
+	PollInterval time.Duration
+	// User settings of the per-request sessions, such as timezone
+	Config pkg.Config
+	// Environment of the per-request sessions; production ones are read-only
+	Environment string
+	// Bearer token /exec requests must send in their Authorization header.
+	// /exec is refused while it is empty.
+	Token string
+
+	upgrader websocket.Upgrader
+}
+
+// New creates a server for db using the given database name
+func New(db *sql.DB, database string) *Server {
+	return &Server{
+		DB:           db,
+		Database:     database,
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// Handler returns the HTTP handler serving the web UI, POST /query, GET /tables,
+// POST /exec and the /live WebSocket
+func (srv *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(uiFiles)))
+	mux.HandleFunc("/query", srv.handleQuery)
+	mux.HandleFunc("/tables", srv.handleTables)
+	mux.HandleFunc("/exec", srv.handleExec)
+	mux.HandleFunc("/live", srv.handleLive)
+	return mux
+}
+
+// ListenAndServe serves the handler on addr
+func (srv *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, srv.Handler())
+}
+
+// ListenAndServeTLS serves the handler over HTTPS on addr with the
+// certificate and key read from certFile and keyFile
+func (srv *Server) ListenAndServeTLS(addr, certFile, keyFile string) error {
+	return http.ListenAndServeTLS(addr, certFile, keyFile, srv.Handler())
+}
+
+// handleQuery runs a single query and writes its records as JSON
+func (srv *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req QueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, QueryResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	resp, err := srv.fetch(r.Context(), req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, QueryResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleTables lists the tables of the server's database
+func (srv *Server) handleTables(w http.ResponseWriter, r *http.Request) {
+	tables, err := srv.session("").Tables(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, TablesResponse{Error: err.Error()})
+		return
+	}
+	if tables == nil {
+		tables = []string{}
+	}
+	writeJSON(w, http.StatusOK, TablesResponse{Tables: tables})
+}
+
+// handleExec runs a command line for a remote client on a dedicated connection
+// switched to the client's database
+func (srv *Server) handleExec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if srv.Token == "" {
+		writeJSON(w, http.StatusForbidden, ExecResponse{Error: "exec is disabled: start the server with a token"})
+		return
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(srv.Token)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, ExecResponse{Error: "invalid or missing token"})
+		return
+	}
+	// Browsers can't send JSON to another origin without a preflight, so
+	// requiring it keeps web pages from running commands
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, ExecResponse{Error: "Content-Type must be application/json"})
+		return
+	}
+
+	var req ExecRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ExecResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	if err := refuseCommand(req.Line); err != nil {
+		writeJSON(w, http.StatusForbidden, ExecResponse{Error: err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := srv.DB.Conn(ctx)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ExecResponse{Error: err.Error()})
+		return
+	}
+	defer conn.Close()
+
+	if req.DB != "" && req.DB != srv.Database {
+		if _, err := conn.ExecContext(ctx, "USE "+quoteIdent(req.DB)); err != nil {
+			writeJSON(w, http.StatusBadRequest, ExecResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	var out bytes.Buffer
+	s := pkg.NewSession(conn)
+	if srv.Config != nil {
+		s.Config = srv.Config
+	}
+	s.Environment = srv.Environment
+	s.CurrentDB = req.DB
+	if s.CurrentDB == "" {
+		s.CurrentDB = srv.Database
+	}
+	if req.Table != "" {
+		var exists int
+		err := conn.QueryRowContext(ctx, "SELECT 1 FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+			s.CurrentDB, req.Table).Scan(&exists)
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("table '%s' does not exist in database '%s'", req.Table, s.CurrentDB)
+		}
+		if err != nil {
+			srv.restoreDatabase(ctx, conn, s.CurrentDB)
+			writeJSON(w, http.StatusBadRequest, ExecResponse{Error: err.Error()})
+			return
+		}
+	}
+	s.CurrentTable = req.Table
+	s.Out = &out
+
+	resp := ExecResponse{}
+	s.Confirm = func() string {
+		if req.Confirm {
+			return "y"
+		}
+		resp.ConfirmationRequired = true
+		return "n"
+	}
+
+	err = pkg.ExecuteCommand(ctx, s, req.Line)
+	if err != nil && !resp.ConfirmationRequired {
+		resp.Error = err.Error()
+	}
+
+	srv.restoreDatabase(ctx, conn, s.CurrentDB)
+
+	resp.Output = out.String()
+	resp.DB = s.CurrentDB
+	resp.Table = s.CurrentTable
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// restoreDatabase switches conn from current back to the server's database
+// before it returns to the pool. A connection that can't be switched back is
+// discarded so later requests don't run in the wrong database.
+func (srv *Server) restoreDatabase(ctx context.Context, conn *sql.Conn, current string) {
+	if current == srv.Database || srv.Database == "" {
+		return
+	}
+	if _, err := conn.ExecContext(ctx, "USE "+quoteIdent(srv.Database)); err != nil {
+		conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+}
+
+// refusedCommands are the commands /exec doesn't run: those waiting on a user
+// at the server and those reading or writing its files
+var refusedCommands = map[string]bool{
+	"EDIT":     true,
+	"WATCH":    true,
+	"COPY":     true,
+	"SHOW":     true,
+	"RECORD":   true,
+	"REPLAY":   true,
+	"FIXTURES": true,
+}
+
+// refuseCommand returns an error when line runs one of refusedCommands,
+// directly or through BENCH
+func refuseCommand(line string) error {
+	line = strings.TrimSpace(line)
+	if benchMatches := pkg.GetBenchCommandRegex().FindStringSubmatch(line); benchMatches != nil {
+		return refuseCommand(benchMatches[4])
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	command := strings.ToUpper(fields[0])
+	if refusedCommands[command] {
+		return fmt.Errorf("%s can't be run on a noqli server", command)
+	}
+	return nil
+}
+
+// handleLive upgrades to a WebSocket, reads one QueryRequest and keeps sending
+// refreshed results until the client disconnects
+func (srv *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	conn, err := srv.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade already replied with an error
+	}
+	defer conn.Close()
+
+	var req QueryRequest
+	if err := conn.ReadJSON(&req); err != nil {
+		conn.WriteJSON(QueryResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	var interval time.Duration
+	if req.Interval != "" {
+		interval, err = time.ParseDuration(req.Interval)
+		if err != nil || interval <= 0 {
+			conn.WriteJSON(QueryResponse{Error: fmt.Sprintf("invalid interval: %q", req.Interval)})
+			return
+		}
+	}
+
+	// Stop when the client goes away
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	srv.live(ctx, conn, req, interval)
+}
+
+// live sends the results of req every interval or, when interval is zero,
+// each time the table checksum changes
+func (srv *Server) live(ctx context.Context, conn *websocket.Conn, req QueryRequest, interval time.Duration) {
+	if err := srv.checkTable(ctx, req.Table); err != nil {
+		conn.WriteJSON(QueryResponse{Error: err.Error()})
+		return
+	}
+	session := srv.session(req.Table)
+
+	var lastChecksum int64
+	send := func() bool {
+		if interval == 0 {
+			checksum, err := session.TableChecksum(ctx)
+			if err != nil {
+				return conn.WriteJSON(QueryResponse{Error: err.Error()}) == nil
+			}
+			if checksum == lastChecksum {
+				return true
+			}
+			lastChecksum = checksum
+		}
+
+		resp, err := srv.records(ctx, req)
+		if err != nil {
+			return conn.WriteJSON(QueryResponse{Error: err.Error()}) == nil
+		}
+		return conn.WriteJSON(resp) == nil
+	}
+
+	tick := interval
+	if tick == 0 {
+		tick = srv.PollInterval
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	if !send() {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !send() {
+				return
+			}
+		}
+	}
+}
+
+// fetch checks the table of req, runs it and returns all matching records
+func (srv *Server) fetch(ctx context.Context, req QueryRequest) (QueryResponse, error) {
+	if err := srv.checkTable(ctx, req.Table); err != nil {
+		return QueryResponse{}, err
+	}
+	return srv.records(ctx, req)
+}
+
+// records runs req against its already checked table and returns all
+// matching records
+func (srv *Server) records(ctx context.Context, req QueryRequest) (QueryResponse, error) {
+	var args map[string]any
+	if req.Args != "" {
+		var err error
+		args, err = pkg.ParseArg(req.Args)
+		if err != nil {
+			return QueryResponse{}, err
+		}
+	}
+
+	rows, err := srv.session(req.Table).Query(ctx, args)
+	if err != nil {
+		return QueryResponse{}, err
+	}
+	defer rows.Close()
+
+	records := []pkg.Record{}
+	for rows.Next() {
+		record, err := rows.Map()
+		if err != nil {
+			return QueryResponse{}, err
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return QueryResponse{}, err
+	}
+	return QueryResponse{Columns: rows.Columns(), Records: records}, nil
+}
+
+// checkTable returns an error unless table exists in the server's database.
+// Table names sent by clients end up in the statements the session builds,
+// so only names the database lists are let through.
+func (srv *Server) checkTable(ctx context.Context, table string) error {
+	if table == "" {
+		return pkg.ErrNoTableSelected
+	}
+	tables, err := srv.session("").Tables(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range tables {
+		if name == table {
+			return nil
+		}
+	}
+	return fmt.Errorf("table '%s' does not exist in database '%s'", table, srv.Database)
+}
+
+// session creates a session for one request against table
+func (srv *Server) session(table string) *pkg.Session {
+	s := pkg.NewSession(srv.DB)
+	if srv.Config != nil {
+		s.Config = srv.Config
+	}
+	s.Environment = srv.Environment
+	s.CurrentDB = srv.Database
+	s.CurrentTable = table
+	return s
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// quoteIdent wraps a database name in backticks
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,178 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serverInfoRegex matches the arguments of GET status and GET variables with
+// an optional LIKE pattern, e.g. GET variables LIKE 'max_conn%'
+var serverInfoRegex = regexp.MustCompile(`(?i)^(status|variables)(?:\s+LIKE\s+(?:'([^']*)'|"([^"]*)"))?$`)
+
+// cutServerInfo returns what GET status or GET variables shows and the LIKE
+// pattern filtering it
+func cutServerInfo(command, args string) (kind, pattern string, ok bool) {
+	if strings.ToUpper(command) != "GET" {
+		return "", "", false
+	}
+	m := serverInfoRegex.FindStringSubmatch(strings.TrimSpace(args))
+	if m == nil {
+		return "", "", false
+	}
+	return strings.ToLower(m[1]), m[2] + m[3], true
+}
+
+// keyStatus are the status counters GET status shows without a pattern
+var keyStatus = []string{
+	"Uptime", "Threads_connected", "Threads_running", "Threads_created", "Max_used_connections",
+	"Connections", "Aborted_connects", "Queries", "Slow_queries",
+	"Innodb_buffer_pool_pages_total", "Innodb_buffer_pool_pages_free", "Innodb_buffer_pool_pages_data",
+	"Innodb_buffer_pool_read_requests", "Innodb_buffer_pool_reads", "Bytes_received", "Bytes_sent",
+}
+
+// keyVariables are the settings GET variables shows without a pattern
+var keyVariables = []string{
+	"version", "max_connections", "innodb_buffer_pool_size", "max_allowed_packet", "wait_timeout",
+	"long_query_time", "slow_query_log", "character_set_server", "collation_server", "time_zone", "sql_mode",
+}
+
+// ServerValues returns the global status counters or system variables with
+// names matching the LIKE pattern, or the key ones when pattern is empty
+func (s *Session) ServerValues(ctx context.Context, kind, pattern string) ([]map[string]any, error) {
+	statement := "SHOW GLOBAL STATUS"
+	key := keyStatus
+	if kind == "variables" {
+		statement, key = "SHOW GLOBAL VARIABLES", keyVariables
+	}
+
+	var args []any
+	if pattern != "" {
+		statement += " LIKE ?"
+		args = append(args, pattern)
+	}
+	rows, err := s.query(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	var names []string
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		values[name] = value
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if pattern == "" {
+		names = nil
+		for _, name := range key {
+			if _, ok := values[name]; ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	results := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		results = append(results, map[string]any{"Variable_name": name, "Value": formatServerValue(name, values[name])})
+	}
+	if kind == "status" && pattern == "" {
+		if rate, ok := bufferPoolHitRate(values); ok {
+			results = append(results, map[string]any{"Variable_name": "Buffer pool hit rate", "Value": rate})
+		}
+	}
+	return results, nil
+}
+
+// formatServerValue makes durations and sizes readable, e.g. Uptime as
+// "3d 4h 5m" and innodb_buffer_pool_size as "128.0 MiB"
+func formatServerValue(name, value string) string {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	lower := strings.ToLower(name)
+	switch {
+	case lower == "uptime":
+		return fmt.Sprintf("%s (%s)", value, formatUptime(time.Duration(n)*time.Second))
+	case strings.HasPrefix(lower, "bytes_") || strings.HasSuffix(lower, "_size") || lower == "max_allowed_packet":
+		return fmt.Sprintf("%s (%s)", value, formatBytes(n))
+	default:
+		return value
+	}
+}
+
+// formatUptime renders d in days, hours and minutes
+func formatUptime(d time.Duration) string {
+	days := int64(d / (24 * time.Hour))
+	hours := int64(d/time.Hour) % 24
+	minutes := int64(d/time.Minute) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// formatBytes renders n bytes in binary units, e.g. 1.5 KiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := -1
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// bufferPoolHitRate returns the share of InnoDB page reads served from the
+// buffer pool
+func bufferPoolHitRate(values map[string]string) (string, bool) {
+	requests, err1 := strconv.ParseFloat(values["Innodb_buffer_pool_read_requests"], 64)
+	reads, err2 := strconv.ParseFloat(values["Innodb_buffer_pool_reads"], 64)
+	if err1 != nil || err2 != nil || requests == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%.2f%%", 100*(1-reads/requests)), true
+}
+
+// handleServerInfo shows GET status or GET variables
+func handleServerInfo(ctx context.Context, s *Session, kind, pattern string) error {
+	results, err := s.ServerValues(ctx, kind, pattern)
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		values := make(map[string]any, len(results))
+		for _, row := range results {
+			values[row["Variable_name"].(string)] = row["Value"]
+		}
+		title := "Status"
+		if kind == "variables" {
+			title = "Variables"
+		}
+		fmt.Fprintf(s.Out, "%s: %s\n", title, ColorJSON(values))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(s.Out, "No records found")
+		return nil
+	}
+	s.printTable([]string{"Variable_name", "Value"}, results)
+	return nil
+}
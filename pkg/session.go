@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Session holds the state of one noqli connection: the database handle, the
+// current database and table selection, user settings and where output goes.
+// Handlers receive the session explicitly, so several sessions can be used
+// side by side.
+type Session struct {
+	// Database handle: a *sql.DB, or a *sql.Conn or *sql.Tx to pin the
+	// session to one connection
+	DB DBTX
+	// Currently selected database
+	CurrentDB string
+	// Currently selected table
+	CurrentTable string
+	// User settings
+	Config Config
+	// Destination for handler and presenter output
+	Out io.Writer
+	// Output mode: colorized JSON when true, MySQL-style tables otherwise
+	JSONOutput bool
+	// Middleware run around every command
+	Hooks Hooks
+	// Confirm returns the user's answer to a confirmation prompt;
+	// ScanForConfirmation is used when nil
+	Confirm func() string
+	// Clipboard puts the text of COPY on the clipboard; WriteClipboard is
+	// used when nil
+	Clipboard func(text string) error
+	// Editor lets the user change the text of EDIT and returns it;
+	// EditInEditor is used when nil
+	Editor func(text string) (string, error)
+	// Session variables set with SET @name = value, by lowercase name
+	Vars map[string]any
+	// Command templates saved with SAVE, by name
+	Templates map[string]string
+	// File SAVE writes the templates to; they are kept in memory when empty
+	TemplatesFile string
+	// Directory SNAPSHOT save writes to; snapshots are kept in memory when
+	// empty
+	SnapshotDir string
+	// Width tabular output is fitted to; the width of the terminal is used
+	// when 0 and Out is a terminal
+	Width int
+	// Reads the binary logs for BINLOG; BINLOG is unavailable when nil
+	BinlogReader BinlogReader
+	// Debug log written while SET debug is on; nil when it is off
+	Logger *Logger
+	// Environment of the connection profile; a production session is
+	// read-only until UNLOCK
+	Environment string
+	// Read replicas GET reads from unless given primary: true; nil reads
+	// from DB
+	Replicas *Replicas
+
+	// Command currently being executed, used to record SQL for the hooks
+	current *CommandInfo
+	// Result of the last GET, for SNAPSHOT save
+	lastResult *Snapshot
+	// Snapshots saved while SnapshotDir is empty
+	snapshots map[string]*Snapshot
+	// "on" or "off" once SET row_numbers overrode the row_numbers setting
+	rowNumbers string
+	// "on" or "off" once SET relative_times overrode the relative_times
+	// setting
+	relativeTimes string
+	// CaseSensitive or CaseInsensitive once SET case overrode the
+	// collations for string filters
+	matchCase string
+	// Rows GET shows at a time, set by SET pagesize; 0 shows them all
+	pageSize int
+	// Throttle of bulk writes once SET throttle overrode the throttle
+	// setting
+	writeThrottle *Throttle
+	// Recording written while RECORD is on
+	recorder *Recorder
+	// When writes UNLOCK allowed on a production session end
+	writesUntil time.Time
+	// When the session was created, and the commands it ran and saw fail
+	started        time.Time
+	commandsRun    int64
+	commandsFailed int64
+}
+
+// DBTX is the part of *sql.DB, *sql.Conn and *sql.Tx a session uses
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NewSession creates a session for db writing to os.Stdout
+func NewSession(db DBTX) *Session {
+	return &Session{
+		DB:        db,
+		Config:    make(Config),
+		Out:       os.Stdout,
+		Vars:      make(map[string]any),
+		Templates: make(map[string]string),
+		started:   time.Now(),
+	}
+}
+
+// DisplayPrompt shows the appropriate prompt based on current selections
+func (s *Session) DisplayPrompt() string {
+	prompt := "noqli"
+	if s.CurrentDB != "" {
+		prompt += ":" + s.CurrentDB
+		if s.CurrentTable != "" {
+			prompt += ":" + s.CurrentTable
+		}
+	}
+	prompt += "> "
+	return prompt
+}
+
+// confirm asks the user to confirm an operation
+func (s *Session) confirm() string {
+	var answer string
+	if s.Confirm != nil {
+		answer = s.Confirm()
+	} else {
+		answer = ScanForConfirmation()
+	}
+	if s.recorder != nil {
+		s.recorder.answers = append(s.recorder.answers, answer)
+	}
+	return answer
+}
+
+// timing returns the elapsed time of the running command formatted for
+// tabular footers, e.g. " (0.120 sec)". The timing setting selects "on"
+// (default), "off", or "detailed" to also split the time between waiting for
+// MySQL and fetching and rendering the results.
+func (s *Session) timing() string {
+	if s.current == nil || s.current.Started.IsZero() {
+		return ""
+	}
+	elapsed := time.Since(s.current.Started)
+
+	switch s.Config.Get("timing") {
+	case "off":
+		return ""
+	case "detailed":
+		query := s.current.QueryDuration
+		return fmt.Sprintf(" (%.3f sec: %.3f query, %.3f fetch)", elapsed.Seconds(), query.Seconds(), (elapsed - query).Seconds())
+	default:
+		return fmt.Sprintf(" (%.3f sec)", elapsed.Seconds())
+	}
+}
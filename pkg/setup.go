@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// connectionSettings returns the settings of conn worth writing, as key
+// and value pairs, leaving out empty ones
+func connectionSettings(conn Connection, keys [5]string) [][2]string {
+	var settings [][2]string
+	for i, value := range []string{conn.Host, conn.User, conn.Password, conn.Database, conn.Environment} {
+		if value != "" && keys[i] != "" {
+			settings = append(settings, [2]string{keys[i], value})
+		}
+	}
+	return settings
+}
+
+// writePrivate writes content to path readable only by the user, creating
+// its directory; the file is appended to when appending is set
+func writePrivate(path, content string, appending bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appending {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	// An existing file keeps its mode on open, so tighten it
+	return os.Chmod(path, 0600)
+}
+
+// AddProfile adds a [profile.name] section with the settings of conn to the
+// config file at path, which is made readable only by the user. It fails
+// when the config already has the profile.
+func AddProfile(path, name string, conn Connection) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.ContainsAny(name, "[]. \t") {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, - and _", name)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	if len(config.Section("profile."+name)) > 0 {
+		return fmt.Errorf("profile %s already exists in %s", name, path)
+	}
+
+	var b strings.Builder
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\n[profile.%s]\n", name)
+	for _, setting := range connectionSettings(conn, [5]string{"host", "user", "password", "database", "environment"}) {
+		fmt.Fprintf(&b, "%s = %s\n", setting[0], setting[1])
+	}
+	return writePrivate(path, b.String(), true)
+}
+
+// envValue quotes value for a .env file when it has characters .env files
+// treat specially: single quotes keep it as is
+func envValue(value string) string {
+	if !strings.ContainsAny(value, " \t#'\"$\\=") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`).Replace(value) + `"`
+}
+
+// WriteEnvFile writes the settings of conn as the DB_HOST, DB_USER,
+// DB_PASSWORD and DB_NAME variables of a .env file at path, readable only
+// by the user
+func WriteEnvFile(path string, conn Connection) error {
+	var b strings.Builder
+	for _, setting := range connectionSettings(conn, [5]string{"DB_HOST", "DB_USER", "DB_PASSWORD", "DB_NAME", ""}) {
+		fmt.Fprintf(&b, "%s=%s\n", setting[0], envValue(setting[1]))
+	}
+	return writePrivate(path, b.String(), false)
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// IsGetSizesCommand checks if the command is GET sizes
+func IsGetSizesCommand(command string, args string) bool {
+	return strings.ToUpper(command) == "GET" && strings.ToLower(strings.TrimSpace(args)) == "sizes"
+}
+
+// TableSize is the disk usage of a table. Rows is MySQL's estimate.
+type TableSize struct {
+	Table   string `json:"table"`
+	Rows    int64  `json:"rows"`
+	Data    int64  `json:"data_bytes"`
+	Indexes int64  `json:"index_bytes"`
+	Free    int64  `json:"free_bytes"`
+}
+
+// TableSizes returns the disk usage of the tables of the current database,
+// largest first
+func (s *Session) TableSizes(ctx context.Context) ([]TableSize, error) {
+	rows, err := s.query(ctx, `SELECT TABLE_NAME, IFNULL(TABLE_ROWS, 0), IFNULL(DATA_LENGTH, 0), IFNULL(INDEX_LENGTH, 0), IFNULL(DATA_FREE, 0)
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY DATA_LENGTH + INDEX_LENGTH DESC, TABLE_NAME`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sizes := []TableSize{}
+	for rows.Next() {
+		var size TableSize
+		if err := rows.Scan(&size.Table, &size.Rows, &size.Data, &size.Indexes, &size.Free); err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, rows.Err()
+}
+
+// handleGetSizes shows how much disk each table of the current database uses
+func handleGetSizes(ctx context.Context, s *Session) error {
+	if s.CurrentDB == "" {
+		return fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+	sizes, err := s.TableSizes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Sizes: %s\n", ColorJSON(sizes))
+		return nil
+	}
+	if len(sizes) == 0 {
+		fmt.Fprintln(s.Out, "No tables found")
+		return nil
+	}
+
+	var total int64
+	results := make([]map[string]any, len(sizes))
+	for i, size := range sizes {
+		results[i] = map[string]any{
+			"Table":   size.Table,
+			"Rows":    size.Rows,
+			"Data":    formatBytes(size.Data),
+			"Indexes": formatBytes(size.Indexes),
+			"Free":    formatBytes(size.Free),
+			"Total":   formatBytes(size.Data + size.Indexes),
+		}
+		total += size.Data + size.Indexes
+	}
+	s.printTable([]string{"Table", "Rows", "Data", "Indexes", "Free", "Total"}, results)
+	fmt.Fprintf(s.Out, "Database '%s' uses %s\n", s.CurrentDB, formatBytes(total))
+	return nil
+}
@@ -0,0 +1,222 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSlowQueries is the number of queries GET slowlog shows
+const DefaultSlowQueries = 20
+
+// cutSlowlog returns the arguments of GET slowlog, e.g. {lim: 20}
+func cutSlowlog(command, args string) (string, bool) {
+	if strings.ToUpper(command) != "GET" {
+		return "", false
+	}
+	fields := strings.Fields(args)
+	if len(fields) == 0 || strings.ToLower(fields[0]) != "slowlog" {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimSpace(args)[len(fields[0]):]), true
+}
+
+// SlowQuery is a normalized statement the server ran slowly, with its
+// literals replaced by ?, and how often and how long it ran
+type SlowQuery struct {
+	Query        string  `json:"query"`
+	Schema       string  `json:"schema"`
+	Count        int64   `json:"count"`
+	MeanSeconds  float64 `json:"mean_sec"`
+	MaxSeconds   float64 `json:"max_sec"`
+	TotalSeconds float64 `json:"total_sec"`
+	RowsExamined int64   `json:"rows_examined"`
+}
+
+// SlowQueries returns the limit statements taking the most time in total
+// among those that once ran for at least minTime, or the server's
+// long_query_time when minTime is negative. They are read from the
+// performance_schema statement digests, or from the mysql.slow_log table
+// when performance_schema is off and the slow log is written to a table.
+func (s *Session) SlowQueries(ctx context.Context, limit int, minTime float64) ([]SlowQuery, error) {
+	var perfSchema int
+	var logOutput string
+	var longQueryTime float64
+	var logFile string
+	if err := s.queryRow(ctx, "SELECT @@performance_schema, @@log_output, @@long_query_time, @@slow_query_log_file").
+		Scan(&perfSchema, &logOutput, &longQueryTime, &logFile); err != nil {
+		return nil, err
+	}
+	if minTime < 0 {
+		minTime = longQueryTime
+	}
+
+	switch {
+	case perfSchema == 1:
+		return s.digestSlowQueries(ctx, limit, minTime)
+	case strings.Contains(strings.ToUpper(logOutput), "TABLE"):
+		return s.tableSlowQueries(ctx, limit, minTime)
+	default:
+		return nil, fmt.Errorf("the slow queries of the server are not readable: enable performance_schema or set log_output to TABLE; %s can only be read on the server", logFile)
+	}
+}
+
+// digestSlowQueries reads the slow statements from performance_schema,
+// which normalizes them itself. Its timers count picoseconds.
+func (s *Session) digestSlowQueries(ctx context.Context, limit int, minTime float64) ([]SlowQuery, error) {
+	rows, err := s.query(ctx, `SELECT DIGEST_TEXT, IFNULL(SCHEMA_NAME, ''), COUNT_STAR,
+		AVG_TIMER_WAIT / 1e12, MAX_TIMER_WAIT / 1e12, SUM_TIMER_WAIT / 1e12, SUM_ROWS_EXAMINED
+		FROM performance_schema.events_statements_summary_by_digest
+		WHERE DIGEST_TEXT IS NOT NULL AND MAX_TIMER_WAIT >= ? * 1e12
+		ORDER BY SUM_TIMER_WAIT DESC LIMIT ?`, minTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	queries := []SlowQuery{}
+	for rows.Next() {
+		var q SlowQuery
+		if err := rows.Scan(&q.Query, &q.Schema, &q.Count, &q.MeanSeconds, &q.MaxSeconds, &q.TotalSeconds, &q.RowsExamined); err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+// tableSlowQueries reads the slow log table and groups its statements by
+// their normalized text
+func (s *Session) tableSlowQueries(ctx context.Context, limit int, minTime float64) ([]SlowQuery, error) {
+	rows, err := s.query(ctx, `SELECT CONVERT(sql_text USING utf8mb4), db,
+		TIME_TO_SEC(query_time) + MICROSECOND(query_time) / 1e6, rows_examined
+		FROM mysql.slow_log`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make(map[string]*SlowQuery)
+	for rows.Next() {
+		var text, schema string
+		var seconds float64
+		var examined int64
+		if err := rows.Scan(&text, &schema, &seconds, &examined); err != nil {
+			return nil, err
+		}
+		query := normalizeQuery(text)
+		q, ok := groups[schema+"\x00"+query]
+		if !ok {
+			q = &SlowQuery{Query: query, Schema: schema}
+			groups[schema+"\x00"+query] = q
+		}
+		q.Count++
+		q.TotalSeconds += seconds
+		q.RowsExamined += examined
+		if seconds > q.MaxSeconds {
+			q.MaxSeconds = seconds
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queries := []SlowQuery{}
+	for _, q := range groups {
+		if q.MaxSeconds < minTime {
+			continue
+		}
+		q.MeanSeconds = q.TotalSeconds / float64(q.Count)
+		queries = append(queries, *q)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].TotalSeconds != queries[j].TotalSeconds {
+			return queries[i].TotalSeconds > queries[j].TotalSeconds
+		}
+		return queries[i].Query < queries[j].Query
+	})
+	if len(queries) > limit {
+		queries = queries[:limit]
+	}
+	return queries, nil
+}
+
+var (
+	queryStringRegex = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"`)
+	queryNumberRegex = regexp.MustCompile(`\b-?\d+(?:\.\d+)?\b`)
+	queryListRegex   = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+)
+
+// normalizeQuery replaces the literals of a statement with ?, and lists of
+// them with (...), so statements differing only in their values match
+func normalizeQuery(query string) string {
+	query = queryStringRegex.ReplaceAllString(query, "?")
+	query = queryNumberRegex.ReplaceAllString(query, "?")
+	query = queryListRegex.ReplaceAllString(query, "(...)")
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// formatSeconds renders a duration given in seconds
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Microsecond).String()
+}
+
+// handleGetSlowlog shows the statements the server spends the most time on
+// among those slower than long_query_time. lim sets how many are shown and
+// min_time, in seconds, replaces long_query_time.
+func handleGetSlowlog(ctx context.Context, s *Session, args map[string]any) error {
+	limit, minTime := DefaultSlowQueries, -1.0
+	for key, v := range args {
+		switch strings.ToLower(key) {
+		case "lim":
+			n, ok := toInt(v)
+			if !ok || n <= 0 {
+				return fmt.Errorf("lim must be a positive integer")
+			}
+			limit = n
+		case "min_time":
+			f, ok := v.(float64)
+			if n, isInt := toInt(v); isInt {
+				f, ok = float64(n), true
+			}
+			if !ok || f < 0 {
+				return fmt.Errorf("min_time must be a number of seconds")
+			}
+			minTime = f
+		default:
+			return fmt.Errorf("unknown GET slowlog option %q. Use lim and min_time", key)
+		}
+	}
+
+	queries, err := s.SlowQueries(ctx, limit, minTime)
+	if err != nil {
+		return err
+	}
+	s.recordRows(int64(len(queries)))
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Slow queries: %s\n", ColorJSON(queries))
+		return nil
+	}
+	if len(queries) == 0 {
+		fmt.Fprintln(s.Out, "No slow queries found")
+		return nil
+	}
+	results := make([]map[string]any, len(queries))
+	for i, q := range queries {
+		results[i] = map[string]any{
+			"Query":         q.Query,
+			"Schema":        q.Schema,
+			"Count":         q.Count,
+			"Mean":          formatSeconds(q.MeanSeconds),
+			"Max":           formatSeconds(q.MaxSeconds),
+			"Total":         formatSeconds(q.TotalSeconds),
+			"Rows examined": q.RowsExamined,
+		}
+	}
+	s.printTable([]string{"Query", "Schema", "Count", "Mean", "Max", "Total", "Rows examined"}, results)
+	return nil
+}
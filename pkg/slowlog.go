@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSlowLogPath returns the location of the slow query log (~/.noqli/slow.log)
+func DefaultSlowLogPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".noqli", "slow.log")
+}
+
+// SlowLogHook returns an after hook that appends every command running for
+// at least threshold to the log at path, with its SQL, duration and rows,
+// and flags it in the output
+func SlowLogHook(path string, threshold time.Duration) AfterHook {
+	var mu sync.Mutex
+	return func(ctx context.Context, s *Session, info *CommandInfo) {
+		if info.Command == "" || info.Duration < threshold {
+			return
+		}
+
+		mu.Lock()
+		err := appendSlowLog(path, s, info)
+		mu.Unlock()
+
+		if err != nil {
+			fmt.Fprintf(s.Out, "Slow query: took %.3f sec (threshold %.3f sec); could not write %s: %v\n",
+				info.Duration.Seconds(), threshold.Seconds(), path, err)
+			return
+		}
+		fmt.Fprintf(s.Out, "Slow query: took %.3f sec (threshold %.3f sec), logged to %s\n",
+			info.Duration.Seconds(), threshold.Seconds(), path)
+	}
+}
+
+// SlowLogHookFromConfig builds the slow query hook from the slow_query_time
+// setting, logging to slow_query_log or ~/.noqli/slow.log. It returns nil
+// when no threshold is configured.
+func SlowLogHookFromConfig(config Config) (AfterHook, error) {
+	threshold, ok, err := config.Duration("slow_query_time")
+	if err != nil || !ok {
+		return nil, err
+	}
+	path := config.Get("slow_query_log")
+	if path == "" {
+		path = DefaultSlowLogPath()
+	}
+	return SlowLogHook(path, threshold), nil
+}
+
+// appendSlowLog writes one entry to the slow query log
+func appendSlowLog(path string, s *Session, info *CommandInfo) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "# Time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&entry, "# Database: %s  Table: %s\n", s.CurrentDB, s.CurrentTable)
+	fmt.Fprintf(&entry, "# Duration: %.3f sec  Query: %.3f sec  Rows: %d\n",
+		info.Duration.Seconds(), info.QueryDuration.Seconds(), info.Rows)
+	if info.Err != nil {
+		fmt.Fprintf(&entry, "# Error: %v\n", info.Err)
+	}
+	fmt.Fprintf(&entry, "# Command: %s\n", info.Line)
+	for i, stmt := range info.SQL {
+		if len(info.Params[i]) > 0 {
+			fmt.Fprintf(&entry, "%s; -- %v\n", stmt, info.Params[i])
+		} else {
+			fmt.Fprintf(&entry, "%s;\n", stmt)
+		}
+	}
+	entry.WriteString("\n")
+
+	_, err = file.WriteString(entry.String())
+	return err
+}
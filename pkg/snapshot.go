@@ -0,0 +1,405 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetSnapshotCommandRegex returns the regex for SNAPSHOT commands
+func GetSnapshotCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(SNAPSHOT)(?:\s+(\S+)(?:\s+(.*))?)?$`)
+}
+
+// Snapshot is a stored GET result
+type Snapshot struct {
+	Name     string           `json:"name"`
+	Command  string           `json:"command"`
+	Database string           `json:"database"`
+	Table    string           `json:"table"`
+	Taken    time.Time        `json:"taken"`
+	Columns  []string         `json:"columns"`
+	Rows     []map[string]any `json:"rows"`
+}
+
+// SnapshotDiff lists the rows added, removed and changed between two
+// snapshots. Rows are matched by id when the results have an id column.
+type SnapshotDiff struct {
+	Added   []map[string]any `json:"added"`
+	Removed []map[string]any `json:"removed"`
+	Changed []RowChange      `json:"changed"`
+}
+
+// RowChange is a row whose values differ between two snapshots
+type RowChange struct {
+	Key    any            `json:"key"`
+	Before map[string]any `json:"before"`
+	After  map[string]any `json:"after"`
+}
+
+// DefaultSnapshotDir returns the directory snapshots are saved in
+func DefaultSnapshotDir() string {
+	return filepath.Join(filepath.Dir(DefaultConfigPath()), "snapshots")
+}
+
+// rememberResult keeps the result of the last GET for SNAPSHOT save
+func (s *Session) rememberResult(columns []string, results []map[string]any) {
+	snapshot := &Snapshot{
+		Database: s.CurrentDB,
+		Table:    s.CurrentTable,
+		Columns:  columns,
+		Rows:     results,
+	}
+	if s.current != nil {
+		snapshot.Command = s.current.Line
+	}
+	s.lastResult = snapshot
+}
+
+// resultRow returns row n of the last GET result, numbered from 1 as in the
+// # column
+func (s *Session) resultRow(n string) (int, map[string]any, error) {
+	if s.lastResult == nil || len(s.lastResult.Rows) == 0 {
+		return 0, nil, fmt.Errorf("no result. Run a GET first")
+	}
+	rows := s.lastResult.Rows
+	index, err := strconv.Atoi(n)
+	if err != nil || index < 1 || index > len(rows) {
+		return 0, nil, fmt.Errorf("no row %s: the last result has rows 1 to %d", n, len(rows))
+	}
+	return index, rows[index-1], nil
+}
+
+// resultCell returns the value of column in row of the last GET result
+func (s *Session) resultCell(row map[string]any, column string) (any, error) {
+	value, ok := row[column]
+	if !ok {
+		return nil, fmt.Errorf("unknown column %q. The last result has %s", column, strings.Join(s.lastResult.Columns, ", "))
+	}
+	return value, nil
+}
+
+// SaveSnapshot stores the result of the last GET under name, in
+// SnapshotDir when it is set
+func (s *Session) SaveSnapshot(name string) (*Snapshot, error) {
+	if !templateNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid snapshot name %q. Use letters, digits and underscores", name)
+	}
+	if s.lastResult == nil {
+		return nil, fmt.Errorf("no result to save. Run a GET first")
+	}
+
+	snapshot := *s.lastResult
+	snapshot.Name = name
+	snapshot.Taken = timeNow()
+	rows, err := normalizeRows(snapshot.Rows)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Rows = rows
+
+	if s.SnapshotDir == "" {
+		if s.snapshots == nil {
+			s.snapshots = make(map[string]*Snapshot)
+		}
+		s.snapshots[name] = &snapshot
+		return &snapshot, nil
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.SnapshotDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(s.SnapshotDir, name+".json"), data, 0644); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// LoadSnapshot returns the snapshot saved under name
+func (s *Session) LoadSnapshot(name string) (*Snapshot, error) {
+	if s.SnapshotDir == "" {
+		if snapshot, ok := s.snapshots[name]; ok {
+			return snapshot, nil
+		}
+		return nil, fmt.Errorf("unknown snapshot %q", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.SnapshotDir, name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("unknown snapshot %q", name)
+	} else if err != nil {
+		return nil, err
+	}
+	return decodeSnapshot(data)
+}
+
+// Snapshots returns the saved snapshots sorted by name
+func (s *Session) Snapshots() ([]*Snapshot, error) {
+	var snapshots []*Snapshot
+	if s.SnapshotDir == "" {
+		for _, snapshot := range s.snapshots {
+			snapshots = append(snapshots, snapshot)
+		}
+	} else {
+		paths, err := filepath.Glob(filepath.Join(s.SnapshotDir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			snapshot, err := decodeSnapshot(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+	return snapshots, nil
+}
+
+// decodeSnapshot reads a saved snapshot keeping numbers exact
+func decodeSnapshot(data []byte) (*Snapshot, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var snapshot Snapshot
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// normalizeRows converts rows to the values they are saved as, so fresh
+// results compare equal to saved ones
+func normalizeRows(rows []map[string]any) ([]map[string]any, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized []map[string]any
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// DiffSnapshots compares the rows of two snapshots
+func DiffSnapshots(before, after *Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{Added: []map[string]any{}, Removed: []map[string]any{}, Changed: []RowChange{}}
+
+	beforeRows := make(map[string]map[string]any, len(before.Rows))
+	for _, row := range before.Rows {
+		beforeRows[rowKey(row)] = row
+	}
+	seen := make(map[string]bool, len(after.Rows))
+	for _, row := range after.Rows {
+		key := rowKey(row)
+		seen[key] = true
+		old, ok := beforeRows[key]
+		if !ok {
+			diff.Added = append(diff.Added, row)
+		} else if encodeValue(old) != encodeValue(row) {
+			diff.Changed = append(diff.Changed, RowChange{Key: row["id"], Before: old, After: row})
+		}
+	}
+	for _, row := range before.Rows {
+		if !seen[rowKey(row)] {
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+	return diff
+}
+
+// rowKey identifies a row by its id, or by all its values without one
+func rowKey(row map[string]any) string {
+	if id, ok := row["id"]; ok {
+		return "id:" + encodeValue(id)
+	}
+	return encodeValue(row)
+}
+
+// encodeValue renders a value as JSON for comparisons; map keys are sorted
+func encodeValue(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// handleSnapshot runs the SNAPSHOT subcommands save, list, show and diff
+func handleSnapshot(ctx context.Context, s *Session, action, rest string) error {
+	names := strings.Fields(rest)
+	switch strings.ToLower(action) {
+	case "save":
+		if len(names) != 1 {
+			return fmt.Errorf("usage: SNAPSHOT save name")
+		}
+		snapshot, err := s.SaveSnapshot(names[0])
+		if err != nil {
+			return err
+		}
+		if s.JSONOutput {
+			fmt.Fprintf(s.Out, "Saved: %s\n", ColorJSON(map[string]any{"snapshot": snapshot.Name, "rows": len(snapshot.Rows)}))
+		} else {
+			fmt.Fprintf(s.Out, "Snapshot '%s' saved: %d rows\n", snapshot.Name, len(snapshot.Rows))
+		}
+		return nil
+	case "list":
+		return listSnapshots(s)
+	case "show":
+		if len(names) != 1 {
+			return fmt.Errorf("usage: SNAPSHOT show name")
+		}
+		snapshot, err := s.LoadSnapshot(names[0])
+		if err != nil {
+			return err
+		}
+		if s.JSONOutput {
+			fmt.Fprintf(s.Out, "Snapshot: %s\n", ColorJSON(snapshot))
+		} else if len(snapshot.Rows) == 0 {
+			fmt.Fprintln(s.Out, "No records found")
+		} else {
+			s.printTable(snapshot.Columns, snapshot.Rows)
+		}
+		return nil
+	case "diff":
+		if len(names) < 1 || len(names) > 2 {
+			return fmt.Errorf("usage: SNAPSHOT diff name [other]")
+		}
+		before, err := s.LoadSnapshot(names[0])
+		if err != nil {
+			return err
+		}
+		var after *Snapshot
+		if len(names) == 2 {
+			after, err = s.LoadSnapshot(names[1])
+		} else {
+			after, err = s.rerunSnapshot(ctx, before)
+		}
+		if err != nil {
+			return err
+		}
+		printSnapshotDiff(s, DiffSnapshots(before, after))
+		return nil
+	default:
+		return fmt.Errorf("usage: SNAPSHOT save name, SNAPSHOT list, SNAPSHOT show name or SNAPSHOT diff name [other]")
+	}
+}
+
+// rerunSnapshot runs the command of snapshot again and returns its current
+// result
+func (s *Session) rerunSnapshot(ctx context.Context, snapshot *Snapshot) (*Snapshot, error) {
+	if snapshot.Table != s.CurrentTable || snapshot.Database != s.CurrentDB {
+		return nil, fmt.Errorf("snapshot %s was taken on %s.%s. Use it first or compare two snapshots", snapshot.Name, snapshot.Database, snapshot.Table)
+	}
+
+	out, jsonOutput := s.Out, s.JSONOutput
+	s.Out = io.Discard
+	s.lastResult = nil
+	err := ExecuteCommand(ctx, s, snapshot.Command)
+	s.Out, s.JSONOutput = out, jsonOutput
+	if err != nil && !errors.Is(err, ErrNoRecords) {
+		return nil, err
+	}
+
+	current := &Snapshot{Columns: snapshot.Columns}
+	if s.lastResult != nil {
+		rows, err := normalizeRows(s.lastResult.Rows)
+		if err != nil {
+			return nil, err
+		}
+		current.Rows = rows
+	}
+	return current, nil
+}
+
+// listSnapshots prints the saved snapshots
+func listSnapshots(s *Session) error {
+	snapshots, err := s.Snapshots()
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Fprintln(s.Out, "No snapshots saved")
+		return nil
+	}
+
+	results := make([]map[string]any, len(snapshots))
+	for i, snapshot := range snapshots {
+		results[i] = map[string]any{
+			"Snapshot": snapshot.Name,
+			"Table":    snapshot.Table,
+			"Rows":     len(snapshot.Rows),
+			"Taken":    snapshot.Taken.Format("2006-01-02 15:04:05"),
+			"Command":  snapshot.Command,
+		}
+	}
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Snapshots: %s\n", ColorJSON(results))
+		return nil
+	}
+	s.printTable([]string{"Snapshot", "Table", "Rows", "Taken", "Command"}, results)
+	return nil
+}
+
+// printSnapshotDiff prints the rows that were added, removed or changed
+func printSnapshotDiff(s *Session, diff SnapshotDiff) {
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Diff: %s\n", ColorJSON(diff))
+		return
+	}
+
+	fmt.Fprintf(s.Out, "%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		return
+	}
+
+	var results []map[string]any
+	for _, row := range diff.Added {
+		results = append(results, map[string]any{"Change": "+", "Row": encodeValue(row)})
+	}
+	for _, row := range diff.Removed {
+		results = append(results, map[string]any{"Change": "-", "Row": encodeValue(row)})
+	}
+	for _, change := range diff.Changed {
+		var fields []string
+		for _, col := range sortedKeys(change.After) {
+			before, after := encodeValue(change.Before[col]), encodeValue(change.After[col])
+			if before != after {
+				fields = append(fields, fmt.Sprintf("%s: %s -> %s", col, before, after))
+			}
+		}
+		results = append(results, map[string]any{
+			"Change": "~",
+			"Row":    fmt.Sprintf("id %s: %s", encodeValue(change.Key), strings.Join(fields, ", ")),
+		})
+	}
+	FprintTabularResults(s.Out, []string{"Change", "Row"}, results)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
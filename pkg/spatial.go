@@ -0,0 +1,218 @@
+package pkg
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Point is a spatial point literal such as point(10.75, 59.91). X is the
+// longitude and Y the latitude when used with distance filters.
+type Point struct {
+	X, Y float64
+}
+
+// String renders the point as WKT, e.g. POINT(10.75 59.91)
+func (p Point) String() string {
+	return fmt.Sprintf("POINT(%s %s)", formatCoordinate(p.X), formatCoordinate(p.Y))
+}
+
+// MarshalJSON renders the point as WKT
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// Distance filters a spatial column to the points within Meters of From on
+// the earth's surface, e.g. {location: within(point(10.75, 59.91), 5km)}
+type Distance struct {
+	From   Point
+	Meters float64
+}
+
+// MarshalJSON renders the filter as it is written
+func (d Distance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("within(%s, %sm)", d.From, formatCoordinate(d.Meters)))
+}
+
+const coordinatePattern = `([+-]?\d+(?:\.\d+)?)`
+
+// pointLiteralRegex matches point(x, y) and withinRegex matches
+// within(point(x, y), distance) with an optional m or km unit
+var (
+	pointLiteralRegex = regexp.MustCompile(`(?i)^point\(\s*` + coordinatePattern + `\s*,\s*` + coordinatePattern + `\s*\)$`)
+	withinRegex       = regexp.MustCompile(`(?i)^within\(\s*(point\([^)]*\))\s*,\s*` + coordinatePattern + `\s*(m|km)?\s*\)$`)
+)
+
+// parseSpatialLiteral converts a point(x, y) or within(point(x, y), 5km)
+// literal
+func parseSpatialLiteral(text string) (any, bool) {
+	if m := pointLiteralRegex.FindStringSubmatch(text); m != nil {
+		x, _ := strconv.ParseFloat(m[1], 64)
+		y, _ := strconv.ParseFloat(m[2], 64)
+		return Point{X: x, Y: y}, true
+	}
+	if m := withinRegex.FindStringSubmatch(text); m != nil {
+		from, ok := parseSpatialLiteral(m[1])
+		if !ok {
+			return nil, false
+		}
+		meters, _ := strconv.ParseFloat(m[2], 64)
+		if strings.EqualFold(m[3], "km") {
+			meters *= 1000
+		}
+		return Distance{From: from.(Point), Meters: meters}, true
+	}
+	return nil, false
+}
+
+// formatCoordinate formats a coordinate without trailing zeros
+func formatCoordinate(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// WKB geometry types
+const (
+	wkbPoint = iota + 1
+	wkbLineString
+	wkbPolygon
+	wkbMultiPoint
+	wkbMultiLineString
+	wkbMultiPolygon
+	wkbGeometryCollection
+)
+
+var wkbTypeNames = map[uint32]string{
+	wkbPoint:              "POINT",
+	wkbLineString:         "LINESTRING",
+	wkbPolygon:            "POLYGON",
+	wkbMultiPoint:         "MULTIPOINT",
+	wkbMultiLineString:    "MULTILINESTRING",
+	wkbMultiPolygon:       "MULTIPOLYGON",
+	wkbGeometryCollection: "GEOMETRYCOLLECTION",
+}
+
+// geometryToWKT converts a spatial value in MySQL's internal format, a 4 byte
+// SRID followed by WKB, to WKT
+func geometryToWKT(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", fmt.Errorf("invalid geometry value")
+	}
+	r := &wkbReader{data: data[4:]}
+	wkt := r.geometry(true)
+	if r.err != nil {
+		return "", r.err
+	}
+	return wkt, nil
+}
+
+// wkbReader decodes WKB, remembering the first error
+type wkbReader struct {
+	data  []byte
+	order binary.ByteOrder
+	err   error
+}
+
+// geometry reads one geometry, with its type name when named is set
+func (r *wkbReader) geometry(named bool) string {
+	if r.err != nil || len(r.data) < 5 {
+		r.fail()
+		return ""
+	}
+	if r.data[0] == 0 {
+		r.order = binary.BigEndian
+	} else {
+		r.order = binary.LittleEndian
+	}
+	r.data = r.data[1:]
+	kind := r.uint32()
+	name, ok := wkbTypeNames[kind]
+	if !ok {
+		r.err = fmt.Errorf("unsupported geometry type %d", kind)
+		return ""
+	}
+
+	var body string
+	switch kind {
+	case wkbPoint:
+		body = "(" + r.coordinates() + ")"
+	case wkbLineString:
+		body = r.points()
+	case wkbPolygon:
+		body = r.rings()
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon:
+		n := r.uint32()
+		parts := make([]string, 0, n)
+		for i := uint32(0); i < n && r.err == nil; i++ {
+			parts = append(parts, r.geometry(false))
+		}
+		body = "(" + strings.Join(parts, ",") + ")"
+	case wkbGeometryCollection:
+		n := r.uint32()
+		parts := make([]string, 0, n)
+		for i := uint32(0); i < n && r.err == nil; i++ {
+			parts = append(parts, r.geometry(true))
+		}
+		body = "(" + strings.Join(parts, ",") + ")"
+	}
+	if !named {
+		return body
+	}
+	return name + body
+}
+
+// coordinates reads one x y pair
+func (r *wkbReader) coordinates() string {
+	x := r.float64()
+	y := r.float64()
+	return formatCoordinate(x) + " " + formatCoordinate(y)
+}
+
+// points reads a counted list of coordinates
+func (r *wkbReader) points() string {
+	n := r.uint32()
+	points := make([]string, 0, n)
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		points = append(points, r.coordinates())
+	}
+	return "(" + strings.Join(points, ",") + ")"
+}
+
+// rings reads a counted list of point lists
+func (r *wkbReader) rings() string {
+	n := r.uint32()
+	rings := make([]string, 0, n)
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		rings = append(rings, r.points())
+	}
+	return "(" + strings.Join(rings, ",") + ")"
+}
+
+func (r *wkbReader) uint32() uint32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.fail()
+		return 0
+	}
+	v := r.order.Uint32(r.data)
+	r.data = r.data[4:]
+	return v
+}
+
+func (r *wkbReader) float64() float64 {
+	if r.err != nil || len(r.data) < 8 {
+		r.fail()
+		return 0
+	}
+	v := math.Float64frombits(r.order.Uint64(r.data))
+	r.data = r.data[8:]
+	return v
+}
+
+func (r *wkbReader) fail() {
+	if r.err == nil {
+		r.err = fmt.Errorf("truncated geometry value")
+	}
+}
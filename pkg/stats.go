@@ -0,0 +1,252 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GetStatsCommandRegex returns the regex for STATS commands
+func GetStatsCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(STATS)$`)
+}
+
+// IsGetStatsCommand checks if the command is GET stats
+func IsGetStatsCommand(command string, args string) bool {
+	return strings.ToUpper(command) == "GET" && strings.ToLower(strings.TrimSpace(args)) == "stats"
+}
+
+// statsTopValues is the number of most frequent values reported per column
+const statsTopValues = 3
+
+// ValueCount is a column value and the number of rows holding it
+type ValueCount struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
+}
+
+// ColumnStats profiles the values of one column. Min and Max are not
+// computed for JSON, BLOB and spatial columns, Avg only for numeric ones.
+type ColumnStats struct {
+	Column   string       `json:"column"`
+	Type     string       `json:"type"`
+	Nulls    int64        `json:"nulls"`
+	Distinct int64        `json:"distinct"`
+	Min      any          `json:"min"`
+	Max      any          `json:"max"`
+	Avg      any          `json:"avg,omitempty"`
+	Top      []ValueCount `json:"top"`
+}
+
+// TableStats profiles a table
+type TableStats struct {
+	Table   string        `json:"table"`
+	Rows    int64         `json:"rows"`
+	Columns []ColumnStats `json:"columns"`
+}
+
+// TableStats profiles the current table: per column the number of NULLs and
+// distinct values, the minimum, maximum and average, and the most frequent
+// values
+func (s *Session) TableStats(ctx context.Context) (TableStats, error) {
+	stats := TableStats{Table: s.CurrentTable}
+	if s.CurrentTable == "" {
+		return stats, ErrNoTableSelected
+	}
+
+	info, err := s.DescribeTable(ctx, s.CurrentTable)
+	if err != nil {
+		return stats, err
+	}
+
+	// All aggregates are computed by a single query
+	exprs := []string{"COUNT(*) AS `rows`"}
+	for i, col := range info.Columns {
+		name := quoteIdent(col.Field)
+		exprs = append(exprs, fmt.Sprintf("SUM(%s IS NULL) AS nulls_%d", name, i))
+		if !comparableType(col.Type) {
+			continue
+		}
+		exprs = append(exprs,
+			fmt.Sprintf("COUNT(DISTINCT %s) AS distinct_%d", name, i),
+			fmt.Sprintf("MIN(%s) AS min_%d", name, i),
+			fmt.Sprintf("MAX(%s) AS max_%d", name, i))
+		if numericType(col.Type) {
+			exprs = append(exprs, fmt.Sprintf("AVG(%s) AS avg_%d", name, i))
+		}
+	}
+	query, values, err := NewQueryBuilder(s.CurrentTable).SelectExpr(strings.Join(exprs, ", ")).Select()
+	if err != nil {
+		return stats, err
+	}
+	aggregates, err := queryRecord(ctx, s, query, values)
+	if err != nil {
+		return stats, err
+	}
+	stats.Rows, _ = toInt64(aggregates["rows"])
+
+	for i, col := range info.Columns {
+		cs := ColumnStats{Column: col.Field, Type: col.Type}
+		cs.Nulls, _ = toInt64(aggregates[fmt.Sprintf("nulls_%d", i)])
+		if comparableType(col.Type) {
+			cs.Distinct, _ = toInt64(aggregates[fmt.Sprintf("distinct_%d", i)])
+			cs.Min = aggregates[fmt.Sprintf("min_%d", i)]
+			cs.Max = aggregates[fmt.Sprintf("max_%d", i)]
+			cs.Avg = aggregates[fmt.Sprintf("avg_%d", i)]
+
+			if cs.Top, err = topValues(ctx, s, col.Field); err != nil {
+				return stats, err
+			}
+		}
+		stats.Columns = append(stats.Columns, cs)
+	}
+	return stats, nil
+}
+
+// topValues returns the most frequent values of column
+func topValues(ctx context.Context, s *Session, column string) ([]ValueCount, error) {
+	name := quoteIdent(column)
+	query := fmt.Sprintf("SELECT %s AS value, COUNT(*) AS count FROM %s GROUP BY %s ORDER BY count DESC, value LIMIT %d",
+		name, s.CurrentTable, name, statsTopValues)
+	rows, err := s.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	display, err := s.newColumnFormatter(rows, nil)
+	if err != nil {
+		return nil, err
+	}
+	top := []ValueCount{}
+	for rows.Next() {
+		entry, err := scanRecord(rows, []string{"value", "count"})
+		if err != nil {
+			return nil, err
+		}
+		display.format(entry)
+		count, _ := toInt64(entry["count"])
+		top = append(top, ValueCount{Value: entry["value"], Count: count})
+	}
+	return top, rows.Err()
+}
+
+// queryRecord runs a query returning one row and returns it formatted for
+// display
+func queryRecord(ctx context.Context, s *Session, query string, values []any) (map[string]any, error) {
+	rows, err := s.query(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	display, err := s.newColumnFormatter(rows, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w returned", ErrNoRecords)
+	}
+	entry, err := scanRecord(rows, columns)
+	if err != nil {
+		return nil, err
+	}
+	display.format(entry)
+	return entry, rows.Err()
+}
+
+// comparableType reports whether values of the MySQL column type can be
+// ordered and grouped
+func comparableType(columnType string) bool {
+	t := strings.ToLower(columnType)
+	for _, prefix := range []string{"json", "blob", "tinyblob", "mediumblob", "longblob", "geometry", "point", "linestring", "polygon", "multi"} {
+		if strings.HasPrefix(t, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// numericType reports whether the MySQL column type is numeric
+func numericType(columnType string) bool {
+	t := strings.ToLower(columnType)
+	for _, prefix := range []string{"tinyint", "smallint", "mediumint", "int", "bigint", "decimal", "float", "double"} {
+		if strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// toInt64 converts a scanned integer, which may arrive as text, to an int64
+func toInt64(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case Decimal:
+		var n int64
+		_, err := fmt.Sscan(string(v), &n)
+		return n, err == nil
+	case string:
+		var n int64
+		_, err := fmt.Sscan(v, &n)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// handleStats prints the profile of the current table
+func handleStats(ctx context.Context, s *Session) error {
+	stats, err := s.TableStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Stats: %s\n", ColorJSON(stats))
+		return nil
+	}
+
+	fmt.Fprintf(s.Out, "Table '%s': %d rows\n", stats.Table, stats.Rows)
+	columns := []string{"Column", "Type", "Nulls", "Distinct", "Min", "Max", "Avg", "Top values"}
+	results := make([]map[string]any, len(stats.Columns))
+	for i, cs := range stats.Columns {
+		top := make([]string, len(cs.Top))
+		for j, vc := range cs.Top {
+			value := "NULL"
+			if vc.Value != nil {
+				value = fmt.Sprintf("%v", vc.Value)
+			}
+			top[j] = fmt.Sprintf("%s (%d)", value, vc.Count)
+		}
+		results[i] = map[string]any{
+			"Column":     cs.Column,
+			"Type":       cs.Type,
+			"Nulls":      cs.Nulls,
+			"Distinct":   cs.Distinct,
+			"Min":        statsValue(cs.Min),
+			"Max":        statsValue(cs.Max),
+			"Avg":        statsValue(cs.Avg),
+			"Top values": strings.Join(top, ", "),
+		}
+	}
+	s.printTable(columns, results)
+	return nil
+}
+
+// statsValue renders a missing statistic as NULL
+func statsValue(v any) any {
+	if v == nil {
+		return "NULL"
+	}
+	return v
+}
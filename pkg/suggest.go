@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// unknownColumnRegex reads the column of MySQL's unknown column error
+var unknownColumnRegex = regexp.MustCompile(`Unknown column '(?:[^'.]*\.)?([^'.]+)'`)
+
+// editDistance returns the number of single character insertions,
+// deletions, substitutions and swaps of neighbors turning a into b, ignoring
+// case
+func editDistance(a, b string) int {
+	x, y := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	d := make([][]int, len(x)+1)
+	for i := range d {
+		d[i] = make([]int, len(y)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			best := d[i-1][j] + 1
+			if v := d[i][j-1] + 1; v < best {
+				best = v
+			}
+			if v := d[i-1][j-1] + cost; v < best {
+				best = v
+			}
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				if v := d[i-2][j-2] + 1; v < best {
+					best = v
+				}
+			}
+			d[i][j] = best
+		}
+	}
+	return d[len(x)][len(y)]
+}
+
+// closestName returns the name of names that name is most likely a typo
+// of: one edit away for short names, two for longer ones
+func closestName(name string, names []string) (string, bool) {
+	limit := 1
+	if len([]rune(name)) > 4 {
+		limit = 2
+	}
+	best, bestDistance := "", limit+1
+	for _, candidate := range names {
+		if candidate == name {
+			continue
+		}
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best, best != ""
+}
+
+// confirmNewColumn asks before creating column when it looks like a typo of
+// an existing one, returning ErrConfirmationDeclined when the user declines
+func confirmNewColumn(s *Session, column string, existing []string) error {
+	suggestion, ok := closestName(column, existing)
+	if !ok {
+		return nil
+	}
+	fmt.Fprintf(s.Out, "Column '%s' does not exist, did you mean '%s'? (create it? y/N) ", column, suggestion)
+	if strings.ToLower(s.confirm()) != "y" {
+		return fmt.Errorf("%w: column '%s' does not exist, did you mean '%s'?", ErrConfirmationDeclined, column, suggestion)
+	}
+	return nil
+}
+
+// suggestColumn adds the likely meant column of the current table to an
+// unknown column error, such as one raised by a filter
+func (s *Session) suggestColumn(ctx context.Context, err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1054 || s.CurrentTable == "" {
+		return err
+	}
+	m := unknownColumnRegex.FindStringSubmatch(mysqlErr.Message)
+	if m == nil {
+		return err
+	}
+	columns, colErr := getColumns(ctx, s)
+	if colErr != nil {
+		return err
+	}
+	if suggestion, ok := closestName(m[1], columns); ok {
+		return fmt.Errorf("%w (did you mean '%s'?)", err, suggestion)
+	}
+	return err
+}
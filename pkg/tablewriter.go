@@ -0,0 +1,363 @@
+package pkg
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// Values of the wide_tables setting, choosing how tables wider than the
+// terminal are written
+const (
+	// Shrink the widest columns, truncating their values, or write the rows
+	// vertically when the columns cannot be shrunk enough
+	WideTablesFit = "fit"
+	// Write the rows vertically, one line per column
+	WideTablesVertical = "vertical"
+	// Write the table at full width and let the terminal wrap it
+	WideTablesWrap = "wrap"
+)
+
+// minFitWidth is the narrowest a column is shrunk to when fitting a table
+const minFitWidth = 6
+
+// TableWriter renders rows in the MySQL-like tabular format as they arrive.
+// Column widths are computed from the first sampleSize rows, which are
+// buffered; later rows are written immediately, so memory use does not grow
+// with the size of the result. Values wider than their sampled column are
+// written in full and push the rest of their row to the right.
+type TableWriter struct {
+	// Appended to the row count line, e.g. " (0.120 sec)"
+	Suffix string
+	// Width the table must fit in, usually that of the terminal; 0 leaves
+	// it unbounded
+	MaxWidth int
+	// How a table wider than MaxWidth is written: WideTablesFit (the
+	// default), WideTablesVertical or WideTablesWrap
+	WideTables string
+	// Length in bytes above which a value is shown as a preview of its
+	// kind, size and first characters; binary values always are. 0 shows
+	// values in full.
+	PreviewSize int
+
+	w          io.Writer
+	columns    []string
+	widths     []int
+	numeric    []bool
+	sampleSize int
+	sample     [][]string
+	started    bool
+	count      int
+	numbered   bool
+	// Set by layout: values are cut to their column width, or rows are
+	// written vertically
+	truncate bool
+	vertical bool
+	written  int
+}
+
+// NewTableWriter creates a writer for columns sampling sampleSize rows for
+// the column widths. A sampleSize of zero or less buffers all rows.
+func NewTableWriter(w io.Writer, columns []string, sampleSize int) *TableWriter {
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = textWidth(col)
+	}
+	return &TableWriter{w: w, columns: columns, widths: widths, numeric: make([]bool, len(columns)), sampleSize: sampleSize}
+}
+
+// textWidth returns the number of terminal cells s takes: CJK characters
+// and emoji take two, combining marks none
+func textWidth(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// truncateText cuts s to at most width cells, ending it with an ellipsis
+func truncateText(s string, width int) string {
+	return runewidth.Truncate(s, width, "…")
+}
+
+// NumberRows adds a leading # column holding the ordinal of each row, unless
+// the first column already is #. It must be called before the first Write.
+func (t *TableWriter) NumberRows() {
+	if t.numbered || t.count > 0 || (len(t.columns) > 0 && t.columns[0] == "#") {
+		return
+	}
+	t.numbered = true
+	t.columns = append([]string{"#"}, t.columns...)
+	t.widths = append([]int{1}, t.widths...)
+	t.numeric = append([]bool{true}, t.numeric...)
+}
+
+// Write adds one row. Columns holding Decimal values are right-aligned.
+func (t *TableWriter) Write(row map[string]any) {
+	t.count++
+	values := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		if t.numbered && i == 0 {
+			values[i] = strconv.Itoa(t.count)
+			continue
+		}
+		if _, ok := row[col].(Decimal); ok {
+			t.numeric[i] = true
+		}
+		if v, ok := row[col].(string); ok && t.PreviewSize > 0 {
+			values[i] = previewValue(v, t.PreviewSize)
+			continue
+		}
+		values[i] = fmt.Sprintf("%v", row[col])
+	}
+
+	if t.started {
+		t.writeRow(values)
+		return
+	}
+
+	t.sample = append(t.sample, values)
+	for i, v := range values {
+		if w := textWidth(v); w > t.widths[i] {
+			t.widths[i] = w
+		}
+	}
+	if t.sampleSize > 0 && len(t.sample) >= t.sampleSize {
+		t.flush()
+	}
+}
+
+// Close writes any buffered rows and the row count. Nothing is written when
+// there were no rows.
+func (t *TableWriter) Close() {
+	if t.count == 0 {
+		return
+	}
+	t.flush()
+	fmt.Fprintf(t.w, "\n%d rows in set%s\n", t.count, t.Suffix)
+}
+
+// flush writes the header and the sampled rows
+func (t *TableWriter) flush() {
+	if !t.started {
+		t.started = true
+		t.layout()
+		if !t.vertical {
+			t.writeHeader()
+		}
+	}
+
+	for _, values := range t.sample {
+		t.writeRow(values)
+	}
+	t.sample = nil
+}
+
+// tableWidth returns the width of a line of the table
+func (t *TableWriter) tableWidth() int {
+	width := 1
+	for _, w := range t.widths {
+		width += w + 3
+	}
+	return width
+}
+
+// layout fits the table in MaxWidth as WideTables asks. Fitting shrinks
+// each column wider than minFitWidth in proportion to its excess width; the
+// rows are written vertically when that is not enough.
+func (t *TableWriter) layout() {
+	if t.MaxWidth <= 0 || t.tableWidth() <= t.MaxWidth {
+		return
+	}
+	switch t.WideTables {
+	case WideTablesWrap:
+		return
+	case WideTablesVertical:
+		t.vertical = true
+		return
+	}
+
+	excess := t.tableWidth() - t.MaxWidth
+	shrinkable := 0
+	for _, w := range t.widths {
+		if w > minFitWidth {
+			shrinkable += w - minFitWidth
+		}
+	}
+	if excess > shrinkable {
+		t.vertical = true
+		return
+	}
+	shrunk := 0
+	for i, w := range t.widths {
+		if w > minFitWidth {
+			cut := excess * (w - minFitWidth) / shrinkable
+			t.widths[i] -= cut
+			shrunk += cut
+		}
+	}
+	// Take what rounding left over from the widest columns
+	for ; shrunk < excess; shrunk++ {
+		widest := 0
+		for i, w := range t.widths {
+			if w > t.widths[widest] {
+				widest = i
+			}
+		}
+		t.widths[widest]--
+	}
+	t.truncate = true
+}
+
+// writeHeader prints the column names and the separator line
+func (t *TableWriter) writeHeader() {
+	fmt.Fprintln(t.w)
+	for i, col := range t.columns {
+		if t.truncate {
+			col = truncateText(col, t.widths[i])
+		}
+		fmt.Fprintf(t.w, "| %s ", runewidth.FillRight(col, t.widths[i]))
+	}
+	fmt.Fprintln(t.w, "|")
+
+	for i := range t.columns {
+		fmt.Fprint(t.w, "+")
+		fmt.Fprint(t.w, strings.Repeat("-", t.widths[i]+2))
+	}
+	fmt.Fprintln(t.w, "+")
+}
+
+// writeRow prints one formatted row
+func (t *TableWriter) writeRow(values []string) {
+	t.written++
+	if t.vertical {
+		t.writeVertical(values)
+		return
+	}
+	for i, v := range values {
+		if t.truncate {
+			v = truncateText(v, t.widths[i])
+		}
+		if t.numeric[i] {
+			v = runewidth.FillLeft(v, t.widths[i])
+		} else {
+			v = runewidth.FillRight(v, t.widths[i])
+		}
+		fmt.Fprintf(t.w, "| %s ", v)
+	}
+	fmt.Fprintln(t.w, "|")
+}
+
+// writeVertical prints one row as a "name: value" line per column, like
+// the \G output of the mysql client
+func (t *TableWriter) writeVertical(values []string) {
+	nameWidth := 0
+	for _, col := range t.columns {
+		if w := textWidth(col); w > nameWidth {
+			nameWidth = w
+		}
+	}
+	stars := strings.Repeat("*", 27)
+	fmt.Fprintf(t.w, "%s %d. row %s\n", stars, t.written, stars)
+	for i, v := range values {
+		if t.numbered && i == 0 {
+			continue
+		}
+		fmt.Fprintf(t.w, "%s: %s\n", runewidth.FillLeft(t.columns[i], nameWidth), v)
+	}
+}
+
+// sampleRows returns the configured number of rows sampled for column widths
+// when streaming tabular output; zero disables streaming
+func (s *Session) sampleRows() (int, error) {
+	value := s.Config.Get("sample_rows")
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid sample_rows %q: must be a non-negative integer", value)
+	}
+	return n, nil
+}
+
+// showRowNumbers reports whether tabular output starts with a # column
+// numbering the rows: SET row_numbers on|off, or else the row_numbers setting
+func (s *Session) showRowNumbers() bool {
+	value := s.rowNumbers
+	if value == "" {
+		value = s.Config.Get("row_numbers")
+	}
+	return strings.EqualFold(value, "on")
+}
+
+// terminalWidth returns the width tabular output is fitted to: Width when
+// set, else that of the terminal Out writes to, or 0 when Out is not one
+func (s *Session) terminalWidth() int {
+	if s.Width > 0 {
+		return s.Width
+	}
+	file, ok := s.Out.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// newTableWriter creates a table writer for s.Out, previewing long values
+// and numbering the rows when row numbers are on
+func (s *Session) newTableWriter(columns []string, sampleSize int) *TableWriter {
+	table := NewTableWriter(s.Out, columns, sampleSize)
+	table.MaxWidth = s.terminalWidth()
+	table.WideTables = strings.ToLower(s.Config.Get("wide_tables"))
+	table.PreviewSize = DefaultPreviewSize
+	if s.showRowNumbers() {
+		table.NumberRows()
+	}
+	return table
+}
+
+// streamTabular renders rows as they are read, sampling sampleSize rows for
+// the column widths
+func streamTabular(s *Session, rows *sql.Rows, columns []string, sampleSize int, display *columnFormatter) error {
+	// Streamed rows are not kept, so there is no result to copy or snapshot
+	s.lastResult = nil
+	table := s.newTableWriter(columns, sampleSize)
+	for rows.Next() {
+		entry, err := scanRecord(rows, columns)
+		if err != nil {
+			return err
+		}
+		display.format(entry)
+		table.Write(entry)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.recordRows(int64(table.count))
+	if table.count == 0 {
+		fmt.Fprintln(s.Out, "No records found")
+		return nil
+	}
+	table.Suffix = s.timing()
+	table.Close()
+	return nil
+}
+
+// printTable renders results in the tabular format followed by the timing
+func (s *Session) printTable(columns []string, results []map[string]any) {
+	table := s.newTableWriter(columns, 0)
+	for _, row := range results {
+		table.Write(row)
+	}
+	table.Suffix = s.timing()
+	table.Close()
+}
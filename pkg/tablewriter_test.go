@@ -0,0 +1,85 @@
+package pkg_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// wideRows returns a row too wide for a 40 column terminal
+func wideRows() []map[string]any {
+	return []map[string]any{
+		{"id": 1, "name": "Ann", "bio": strings.Repeat("lorem ipsum ", 6)},
+		{"id": 2, "name": "Bob", "bio": "short"},
+	}
+}
+
+func TestTableWriterFit(t *testing.T) {
+	var buf bytes.Buffer
+	table := pkg.NewTableWriter(&buf, []string{"id", "name", "bio"}, 0)
+	table.MaxWidth = 40
+	for _, row := range wideRows() {
+		table.Write(row)
+	}
+	table.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for _, line := range lines[:4] {
+		assert.LessOrEqual(t, len([]rune(line)), 40, line)
+	}
+	assert.Contains(t, buf.String(), "| 1  | Ann  | lorem ipsum lorem ipsum…")
+	assert.Contains(t, buf.String(), "2 rows in set")
+}
+
+func TestTableWriterVertical(t *testing.T) {
+	// Columns that cannot shrink enough are written vertically
+	var buf bytes.Buffer
+	table := pkg.NewTableWriter(&buf, []string{"id", "name", "bio"}, 0)
+	table.MaxWidth = 12
+	for _, row := range wideRows() {
+		table.Write(row)
+	}
+	table.Close()
+	assert.Contains(t, buf.String(), "*************************** 2. row ***************************\n  id: 2\nname: Bob\n bio: short\n")
+
+	// wrap keeps the full width
+	buf.Reset()
+	table = pkg.NewTableWriter(&buf, []string{"id", "name", "bio"}, 0)
+	table.MaxWidth = 40
+	table.WideTables = pkg.WideTablesWrap
+	for _, row := range wideRows() {
+		table.Write(row)
+	}
+	table.Close()
+	assert.Contains(t, buf.String(), strings.Repeat("lorem ipsum ", 6))
+}
+
+func TestTableWriterWideCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	pkg.FprintTabularResults(&buf, []string{"name", "city"}, []map[string]any{
+		{"name": "山田太郎", "city": "東京"},
+		{"name": "Zoë 🎉", "city": "Oslo"},
+	})
+	assert.Contains(t, buf.String(), "| name     | city |")
+	assert.Contains(t, buf.String(), "| 山田太郎 | 東京 |")
+	assert.Contains(t, buf.String(), "| Zoë 🎉   | Oslo |")
+}
+
+func TestTableWriterPreview(t *testing.T) {
+	var buf bytes.Buffer
+	table := pkg.NewTableWriter(&buf, []string{"id", "body"}, 0)
+	table.PreviewSize = 64
+	table.Write(map[string]any{"id": 1, "body": strings.Repeat("Lorem ipsum\n", 100)})
+	table.Write(map[string]any{"id": 2, "body": strings.Repeat("QUJD", 40)})
+	table.Write(map[string]any{"id": 3, "body": "\x89PNG\r\n\x1a\n"})
+	table.Write(map[string]any{"id": 4, "body": "short"})
+	table.Close()
+
+	assert.Contains(t, buf.String(), `<text, 1.2 KiB> "Lorem ipsum\nLore…"`)
+	assert.Contains(t, buf.String(), `<base64, 160 B> "QUJDQUJDQUJDQUJD…"`)
+	assert.Contains(t, buf.String(), `<binary, 8 B> 89504e470d0a1a0a`)
+	assert.Contains(t, buf.String(), "| 4  | short ")
+}
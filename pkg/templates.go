@@ -0,0 +1,223 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GetSaveCommandRegex returns the regex for SAVE commands
+func GetSaveCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(SAVE)(?:\s+(\S+)(?:\s+(.*))?)?$`)
+}
+
+// GetRunCommandRegex returns the regex for RUN commands
+func GetRunCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^RUN\s+(\S+)(?:\s+(.*))?$`)
+}
+
+// templateNameRegex matches the names templates are saved under
+var templateNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// templateCommandRegex matches the commands a template can hold
+var templateCommandRegex = regexp.MustCompile(`(?i)^(CREATE|GET|UPDATE|DELETE)\b`)
+
+// DefaultTemplatesPath returns the path of the saved templates file
+func DefaultTemplatesPath() string {
+	return filepath.Join(filepath.Dir(DefaultConfigPath()), "templates.json")
+}
+
+// LoadTemplates reads the templates saved in path. A missing file yields no
+// templates.
+func LoadTemplates(path string) (map[string]string, error) {
+	templates := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return templates, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("invalid templates file %s: %w", path, err)
+	}
+	return templates, nil
+}
+
+// SaveTemplate stores a command template under name, writing all templates
+// to TemplatesFile when it is set
+func (s *Session) SaveTemplate(name, command string) error {
+	if !templateNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid template name %q. Use letters, digits and underscores", name)
+	}
+	command = strings.TrimSpace(command)
+	if !templateCommandRegex.MatchString(command) {
+		return fmt.Errorf("a template must be a CREATE, GET, UPDATE or DELETE command")
+	}
+
+	if s.Templates == nil {
+		s.Templates = make(map[string]string)
+	}
+	s.Templates[name] = command
+	if s.TemplatesFile == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.Templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.TemplatesFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.TemplatesFile, data, 0644)
+}
+
+// ExpandTemplate returns the command of the template name with its $1, $2,
+// ... placeholders replaced by params. Every placeholder needs a parameter.
+func (s *Session) ExpandTemplate(name string, params []string) (string, error) {
+	command, ok := s.Templates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template %q. Use 'SAVE %s command' first", name, name)
+	}
+
+	var b strings.Builder
+	used := 0
+	quote := byte(0)
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(command) {
+				b.WriteByte(c)
+				i++
+				c = command[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '$' && i+1 < len(command) && isDigit(command[i+1]):
+			j := i + 1
+			for j < len(command) && isDigit(command[j]) {
+				j++
+			}
+			n, _ := strconv.Atoi(command[i+1 : j])
+			if n < 1 {
+				return "", fmt.Errorf("invalid placeholder $%d in template %s", n, name)
+			}
+			if n > len(params) {
+				return "", fmt.Errorf("template %s needs parameter $%d, got %d parameter(s)", name, n, len(params))
+			}
+			if n > used {
+				used = n
+			}
+			b.WriteString(params[n-1])
+			i = j - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if used < len(params) {
+		return "", fmt.Errorf("template %s takes %d parameter(s), got %d", name, used, len(params))
+	}
+	return b.String(), nil
+}
+
+// splitTemplateParams splits the parameters of a RUN command at spaces.
+// Quoted strings, lists and objects are kept whole.
+func splitTemplateParams(str string) ([]string, error) {
+	var params []string
+	i := 0
+	for i < len(str) {
+		if str[i] == ' ' || str[i] == '\t' {
+			i++
+			continue
+		}
+		start := i
+		depth := 0
+		quote := byte(0)
+		for ; i < len(str); i++ {
+			c := str[i]
+			if quote != 0 {
+				if c == '\\' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			if depth == 0 && (c == ' ' || c == '\t') {
+				break
+			}
+			switch c {
+			case '\'', '"':
+				quote = c
+			case '[', '{', '(':
+				depth++
+			case ']', '}', ')':
+				depth--
+			}
+		}
+		if quote != 0 {
+			return nil, newParseError(str, start, "unterminated string").withHint("close the quote, e.g. 'active'")
+		}
+		params = append(params, str[start:i])
+	}
+	return params, nil
+}
+
+// handleSave saves a template, or lists them all when name is empty
+func handleSave(s *Session, name, command string) error {
+	if name == "" {
+		return listTemplates(s)
+	}
+	if command == "" {
+		return fmt.Errorf("SAVE requires a command, e.g. SAVE by_status GET {status: $1}")
+	}
+	if err := s.SaveTemplate(name, command); err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Saved: %s\n", ColorJSON(map[string]any{name: s.Templates[name]}))
+	} else {
+		fmt.Fprintf(s.Out, "Template '%s' saved\n", name)
+	}
+	return nil
+}
+
+// listTemplates prints the saved templates sorted by name
+func listTemplates(s *Session) error {
+	if len(s.Templates) == 0 {
+		fmt.Fprintln(s.Out, "No templates saved")
+		return nil
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Templates: %s\n", ColorJSON(s.Templates))
+		return nil
+	}
+
+	names := make([]string, 0, len(s.Templates))
+	for name := range s.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := make([]map[string]any, len(names))
+	for i, name := range names {
+		results[i] = map[string]any{"Template": name, "Command": s.Templates[name]}
+	}
+	s.printTable([]string{"Template", "Command"}, results)
+	return nil
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
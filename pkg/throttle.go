@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// throttleRegex matches SET throttle off|n|duration
+var throttleRegex = regexp.MustCompile(`(?i)^throttle\s+(\S+)$`)
+
+// Throttle slows down bulk writes: to at most RowsPerSecond rows a second,
+// or by sleeping Pause after each batch. The zero Throttle does not slow down.
+type Throttle struct {
+	RowsPerSecond int
+	Pause         time.Duration
+}
+
+// ParseThrottle reads a throttle setting: off, a number of rows per second
+// such as 500, or a pause between batches such as 200ms
+func ParseThrottle(value string) (Throttle, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "off") {
+		return Throttle{}, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(value), "/s")); err == nil && n > 0 {
+		return Throttle{RowsPerSecond: n}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return Throttle{Pause: d}, nil
+	}
+	return Throttle{}, fmt.Errorf("invalid throttle %q: use rows per second such as 500, a pause between batches such as 200ms, or off", value)
+}
+
+// active tells whether the throttle slows writes down
+func (t Throttle) active() bool {
+	return t.RowsPerSecond > 0 || t.Pause > 0
+}
+
+// String describes the throttle for output
+func (t Throttle) String() string {
+	switch {
+	case t.RowsPerSecond > 0:
+		return fmt.Sprintf("%d rows/sec", t.RowsPerSecond)
+	case t.Pause > 0:
+		return fmt.Sprintf("%s between batches", t.Pause)
+	}
+	return "off"
+}
+
+// batchSize returns the rows per batch under the throttle: no more than a
+// second's worth, so the rate is kept over short spans too
+func (t Throttle) batchSize(size int) int {
+	if t.RowsPerSecond > 0 && t.RowsPerSecond < size {
+		return t.RowsPerSecond
+	}
+	return size
+}
+
+// wait sleeps after a batch of rows that took elapsed, until the rate allows
+// the next one or for the pause, returning early when ctx is cancelled
+func (t Throttle) wait(ctx context.Context, rows int64, elapsed time.Duration) error {
+	sleep := t.Pause
+	if t.RowsPerSecond > 0 {
+		sleep = time.Duration(float64(rows)/float64(t.RowsPerSecond)*float64(time.Second)) - elapsed
+	}
+	if sleep <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(sleep)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttle returns the throttle set by SET throttle, or else by the
+// throttle setting of the config
+func (s *Session) throttle() (Throttle, error) {
+	if s.writeThrottle != nil {
+		return *s.writeThrottle, nil
+	}
+	return ParseThrottle(s.Config.Get("throttle"))
+}
+
+// handleSetThrottle sets how fast UPDATE, DELETE, ANONYMIZE and batch
+// CREATE write for SET throttle
+func handleSetThrottle(s *Session, value string) error {
+	t, err := ParseThrottle(value)
+	if err != nil {
+		return err
+	}
+	s.writeThrottle = &t
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Throttle: %s\n", ColorJSON(map[string]any{"rows_per_sec": t.RowsPerSecond, "pause_ms": t.Pause.Milliseconds()}))
+	} else if t.active() {
+		fmt.Fprintf(s.Out, "Throttling writes to %s\n", t)
+	} else {
+		fmt.Fprintln(s.Out, "Throttle off")
+	}
+	return nil
+}
+
+// writeInBatches runs write for batches of up to batch rows of the current
+// table matching filters, in id order, passing the WHERE clause selecting
+// each batch, and waits between batches as throttle asks. It returns the
+// rows written.
+func (s *Session) writeInBatches(ctx context.Context, filters map[string]any, batch int, throttle Throttle, write func(clause string, args []any) (sql.Result, error)) (int64, error) {
+	var total int64
+	var last int64
+	first := true
+	for {
+		start := time.Now()
+		// Find the id ending the next batch
+		bounds := NewQueryBuilder(s.CurrentTable).Columns("id").MatchCase(s.matchCase).Where(filters)
+		if !first {
+			bounds.WhereRaw("`id` > ?", last)
+		}
+		bounds.OrderBy("id", false).Limit(batch, nil)
+		idQuery, idValues, err := bounds.Select()
+		if err != nil {
+			return total, err
+		}
+		var upper sql.NullInt64
+		if err := s.queryRow(ctx, fmt.Sprintf("SELECT MAX(`id`) FROM (%s) AS batch", idQuery), idValues...).Scan(&upper); err != nil {
+			return total, err
+		}
+		if !upper.Valid {
+			return total, nil
+		}
+
+		scope := NewQueryBuilder(s.CurrentTable).MatchCase(s.matchCase).Where(filters)
+		if !first {
+			scope.WhereRaw("`id` > ?", last)
+		}
+		scope.WhereRaw("`id` <= ?", upper.Int64)
+		clause, whereArgs := scope.WhereClause()
+		if scope.err != nil {
+			return total, scope.err
+		}
+		result, err := write(clause, whereArgs)
+		if err != nil {
+			return total, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+		last, first = upper.Int64, false
+
+		if err := throttle.wait(ctx, affected, time.Since(start)); err != nil {
+			return total, fmt.Errorf("stopped after %d rows: %w", total, err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// unionPrefixRegex matches the "FROM [tables]" start of a GET reading from
+// several tables, e.g. GET FROM [users_2023, users_2024] {status: 'active'}
+var unionPrefixRegex = regexp.MustCompile(`(?is)^FROM\s*\[([^\]]*)\]\s*(.*)$`)
+
+// sourceTableColumn is the column naming the table each row of a union
+// came from
+const sourceTableColumn = "source_table"
+
+// cutUnionTables splits the arguments of a GET into the tables listed after
+// FROM and the remaining arguments
+func cutUnionTables(args string) ([]string, string, bool, error) {
+	m := unionPrefixRegex.FindStringSubmatch(strings.TrimSpace(args))
+	if m == nil {
+		return nil, args, false, nil
+	}
+	var tables []string
+	for _, table := range strings.Split(m[1], ",") {
+		table = strings.Trim(strings.TrimSpace(table), "`'\"")
+		if table == "" {
+			continue
+		}
+		if !identifierRegex.MatchString(table) {
+			return nil, "", false, fmt.Errorf("invalid table name %q in FROM", table)
+		}
+		tables = append(tables, table)
+	}
+	if len(tables) == 0 {
+		return nil, "", false, fmt.Errorf("FROM requires at least one table, e.g. GET FROM [users_2023, users_2024] {}")
+	}
+	return tables, m[2], true, nil
+}
+
+// identifierRegex matches the table names FROM accepts
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// getSource is what a GET reads from: the current table, or the UNION ALL of
+// tables with the same columns
+type getSource struct {
+	from  string // FROM expression
+	table string // table whose columns the rows have
+	union bool
+}
+
+// sourceFromArgs returns the source of a GET, removing the _from tables set
+// by GET FROM [...] from args
+func sourceFromArgs(ctx context.Context, s *Session, args map[string]any) (getSource, error) {
+	tables, ok := args["_from"].([]string)
+	if !ok {
+		if s.CurrentTable == "" {
+			return getSource{}, ErrNoTableSelected
+		}
+		return getSource{from: s.CurrentTable, table: s.CurrentTable}, nil
+	}
+	delete(args, "_from")
+
+	from, err := unionFrom(ctx, s, tables)
+	if err != nil {
+		return getSource{}, err
+	}
+	return getSource{from: from, table: tables[0], union: true}, nil
+}
+
+// unionFrom returns a derived table holding the rows of all tables with a
+// source_table column naming where each came from. The tables must have the
+// same columns in the same order.
+func unionFrom(ctx context.Context, s *Session, tables []string) (string, error) {
+	if s.CurrentDB == "" {
+		return "", fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+
+	var first []string
+	selects := make([]string, len(tables))
+	for i, table := range tables {
+		columns, err := tableColumns(ctx, s, quoteIdent(table))
+		if err != nil {
+			return "", err
+		}
+		if i == 0 {
+			if containsString(columns, sourceTableColumn) {
+				return "", fmt.Errorf("table %s already has a %s column", table, sourceTableColumn)
+			}
+			first = columns
+		} else if strings.Join(columns, ",") != strings.Join(first, ",") {
+			return "", fmt.Errorf("cannot combine %s and %s: their columns differ", tables[0], table)
+		}
+		selects[i] = fmt.Sprintf("SELECT %s AS %s, %s.* FROM %s",
+			quoteString(table), sourceTableColumn, quoteIdent(table), quoteIdent(table))
+	}
+	return "(" + strings.Join(selects, " UNION ALL ") + ") AS combined", nil
+}
+
+// containsString reports whether list holds str
+func containsString(list []string, str string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+	return false
+}
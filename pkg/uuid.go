@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// ServerFunc is a value computed by MySQL when the command runs, e.g.
+// UUID_SHORT() for {token: uuid_short()}
+type ServerFunc string
+
+// MarshalJSON renders the function call
+func (f ServerFunc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(f))
+}
+
+// BinaryUUID is a UUID written to or compared with a BINARY(16) column. It is
+// sent as text and packed into its 16 bytes by MySQL.
+type BinaryUUID string
+
+// binaryUUIDExpr packs the text of a UUID into 16 bytes. UUID_TO_BIN needs
+// MySQL 8.0, so the dashes are removed and the digits unhexed instead.
+const binaryUUIDExpr = "UNHEX(REPLACE(?, '-', ''))"
+
+// uuidTextRegex matches a UUID in the 8-4-4-4-12 form or as 32 hex digits
+var uuidTextRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+
+// newUUID returns a random (version 4) UUID
+var newUUID = func() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b[:])
+}
+
+// parseUUIDFunc converts uuid(), generated client-side, and uuid_short(),
+// evaluated by MySQL
+func parseUUIDFunc(text string) (any, bool) {
+	switch strings.ToLower(text) {
+	case "uuid()":
+		return newUUID(), true
+	case "uuid_short()":
+		return ServerFunc("UUID_SHORT()"), true
+	default:
+		return nil, false
+	}
+}
+
+// formatUUID formats 16 bytes in the 8-4-4-4-12 form
+func formatUUID(b []byte) string {
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// binaryUUIDs converts the UUIDs among the values of fields on BINARY(16)
+// columns to BinaryUUID, including those in lists, comparisons and operator
+// objects
+func binaryUUIDs(columns map[string]ColumnSchema, fields map[string]any) {
+	for key, value := range fields {
+		if col, ok := columns[key]; ok && strings.EqualFold(col.Type, "binary(16)") {
+			fields[key], _ = binaryUUIDValue(value)
+		}
+	}
+}
+
+// binaryUUIDFilters converts the UUIDs among the filters on BINARY(16)
+// columns of table to BinaryUUID. The columns are only looked up when a
+// filter holds a UUID.
+func binaryUUIDFilters(ctx context.Context, s *Session, table string, filters map[string]any) error {
+	found := false
+	for _, value := range filters {
+		if _, found = binaryUUIDValue(value); found {
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	schema, err := tableSchema(ctx, s, table)
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]ColumnSchema, len(schema))
+	for _, col := range schema {
+		columns[col.Name] = col
+	}
+	binaryUUIDs(columns, filters)
+	return nil
+}
+
+// binaryUUIDValue returns value with its UUIDs converted to BinaryUUID, and
+// whether it held any. Ranges are left alone.
+func binaryUUIDValue(value any) (any, bool) {
+	switch v := value.(type) {
+	case string:
+		if uuidTextRegex.MatchString(v) {
+			return BinaryUUID(v), true
+		}
+	case []any:
+		converted, found := make([]any, len(v)), false
+		for i, elem := range v {
+			var ok bool
+			converted[i], ok = binaryUUIDValue(elem)
+			found = found || ok
+		}
+		return converted, found
+	case Comparison:
+		var found bool
+		v.Value, found = binaryUUIDValue(v.Value)
+		return v, found
+	case map[string]any:
+		if isRange(v) {
+			return v, false
+		}
+		converted, found := make(map[string]any, len(v)), false
+		for key, elem := range v {
+			var ok bool
+			converted[key], ok = binaryUUIDValue(elem)
+			found = found || ok
+		}
+		return converted, found
+	}
+	return value, false
+}
@@ -0,0 +1,281 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// integerBits are the sizes of the MySQL integer types
+var integerBits = map[string]uint{
+	"tinyint":   8,
+	"smallint":  16,
+	"mediumint": 24,
+	"int":       32,
+	"integer":   32,
+	"bigint":    64,
+}
+
+// textBytes are the sizes of the MySQL text and blob types in bytes
+var textBytes = map[string]int{
+	"tinytext":   255,
+	"tinyblob":   255,
+	"text":       65535,
+	"blob":       65535,
+	"mediumtext": 16777215,
+	"mediumblob": 16777215,
+}
+
+// enumOptionRegex matches a quoted option of an enum or set type
+var enumOptionRegex = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+// timestampMin and timestampMax bound the values of TIMESTAMP columns
+var (
+	timestampMin = time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC)
+	timestampMax = time.Date(2038, 1, 19, 3, 14, 7, 0, time.UTC)
+)
+
+// validateRecords checks that the values of records fit the types of their
+// columns, so a write fails with the column and the reason rather than with
+// a MySQL truncation error
+func validateRecords(columns map[string]ColumnSchema, records []map[string]any) error {
+	for i, record := range records {
+		for _, key := range sortedKeys(record) {
+			col, ok := columns[key]
+			if !ok {
+				continue
+			}
+			reason := checkValue(col, record[key])
+			if reason == "" {
+				continue
+			}
+			err := fmt.Errorf("%w for column '%s': %s", ErrInvalidValue, key, reason)
+			if len(records) > 1 {
+				return fmt.Errorf("record %d: %w", i+1, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkValue returns why value does not fit col, or "" when it does.
+// Values computed by MySQL, such as points and uuid_short(), are not checked.
+func checkValue(col ColumnSchema, value any) string {
+	if value == nil {
+		if !col.Nullable && !col.AutoIncrement {
+			return "the column cannot be NULL"
+		}
+		return ""
+	}
+
+	colType := strings.ToLower(col.Type)
+	base, params := colType, ""
+	if i := strings.IndexAny(colType, "( "); i >= 0 {
+		base = colType[:i]
+		if open, end := strings.Index(colType, "("), strings.Index(colType, ")"); open >= 0 && end > open {
+			params = colType[open+1 : end]
+		}
+	}
+
+	if bits, ok := integerBits[base]; ok {
+		return checkInteger(value, bits, strings.Contains(colType, "unsigned"), col.Type)
+	}
+	switch base {
+	case "decimal", "numeric":
+		return checkDecimal(value, params, col.Type)
+	case "float", "double", "real":
+		if _, text, ok := numberValue(value); !ok && text != "" {
+			return fmt.Sprintf("%q is not a number", text)
+		}
+	case "year":
+		if n, text, ok := numberValue(value); ok {
+			if year := n.FloatString(0); year != "0" && (len(year) != 4 || year < "1901" || year > "2155") {
+				return fmt.Sprintf("%s is not a year from 1901 to 2155", text)
+			}
+		} else if text != "" {
+			return fmt.Sprintf("%q is not a year", text)
+		}
+	case "char", "varchar":
+		if text, ok := textValue(value); ok {
+			limit, _ := strconv.Atoi(params)
+			if n := utf8.RuneCountInString(text); n > limit {
+				return fmt.Sprintf("%d characters is longer than %s allows", n, col.Type)
+			}
+		}
+	case "binary", "varbinary":
+		if text, ok := textValue(value); ok {
+			limit, _ := strconv.Atoi(params)
+			if len(text) > limit {
+				return fmt.Sprintf("%d bytes is longer than %s allows", len(text), col.Type)
+			}
+		}
+	case "tinytext", "tinyblob", "text", "blob", "mediumtext", "mediumblob":
+		if text, ok := textValue(value); ok && len(text) > textBytes[base] {
+			return fmt.Sprintf("%d bytes is longer than %s allows (%d bytes)", len(text), base, textBytes[base])
+		}
+	case "enum", "set":
+		return checkOption(value, base, params)
+	case "date", "datetime", "timestamp":
+		return checkDate(value, base)
+	case "json":
+		if text, ok := value.(string); ok && !json.Valid([]byte(text)) {
+			return fmt.Sprintf("%q is not valid JSON", text)
+		}
+	}
+	return ""
+}
+
+// textValue returns the text value is stored as in a string column, or ok
+// false for values not checked
+func textValue(value any) (string, bool) {
+	switch value.(type) {
+	case string, map[string]any, []any:
+		stored, err := columnValue(value)
+		if err != nil {
+			return "", false
+		}
+		text, ok := stored.(string)
+		return text, ok
+	}
+	return "", false
+}
+
+// numberValue returns value as an exact number along with its text, or ok
+// false with the text of a string that is not a number. Values that are
+// neither numbers nor strings are not checked and have no text.
+func numberValue(value any) (n *big.Rat, text string, ok bool) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return big.NewRat(1, 1), "true", true
+		}
+		return big.NewRat(0, 1), "false", true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		text = fmt.Sprint(v)
+	case string:
+		text = strings.TrimSpace(v)
+	default:
+		return nil, "", false
+	}
+	if strings.Contains(text, "/") {
+		return nil, text, false
+	}
+	n, ok = new(big.Rat).SetString(text)
+	return n, text, ok
+}
+
+// checkInteger checks value for an integer column of bits, which MySQL
+// rounds to a whole number
+func checkInteger(value any, bits uint, unsigned bool, colType string) string {
+	n, text, ok := numberValue(value)
+	if !ok {
+		if text != "" {
+			return fmt.Sprintf("%q is not a number", text)
+		}
+		return ""
+	}
+	whole, _ := new(big.Int).SetString(n.FloatString(0), 10)
+
+	min := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	min.Neg(min)
+	max := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	if unsigned {
+		min = big.NewInt(0)
+		max.Lsh(max, 1)
+	}
+	max.Sub(max, big.NewInt(1))
+	if whole.Cmp(min) < 0 || whole.Cmp(max) > 0 {
+		return fmt.Sprintf("%s is out of range for %s (%s to %s)", text, colType, min, max)
+	}
+	return ""
+}
+
+// checkDecimal checks value for a decimal column with precision and scale
+// params, such as 10,2, which MySQL rounds to the scale
+func checkDecimal(value any, params, colType string) string {
+	n, text, ok := numberValue(value)
+	if !ok {
+		if text != "" {
+			return fmt.Sprintf("%q is not a number", text)
+		}
+		return ""
+	}
+	precision, scale := 10, 0
+	if parts := strings.Split(params, ","); params != "" {
+		precision, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
+		if len(parts) > 1 {
+			scale, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+		}
+	}
+	whole := strings.TrimPrefix(strings.SplitN(n.FloatString(scale), ".", 2)[0], "-")
+	if whole == "0" {
+		whole = ""
+	}
+	if len(whole) > precision-scale {
+		return fmt.Sprintf("%s has more than %d digits before the decimal point for %s", text, precision-scale, colType)
+	}
+	return ""
+}
+
+// checkOption checks value for an enum or set column with the quoted
+// options params. Options match regardless of case, as MySQL compares them.
+func checkOption(value any, base, params string) string {
+	text, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	var options []string
+	for _, m := range enumOptionRegex.FindAllStringSubmatch(params, -1) {
+		options = append(options, strings.ReplaceAll(m[1], "''", "'"))
+	}
+	isOption := func(s string) bool {
+		for _, option := range options {
+			if strings.EqualFold(s, option) {
+				return true
+			}
+		}
+		return false
+	}
+
+	values := []string{text}
+	if base == "set" {
+		if text == "" {
+			return ""
+		}
+		values = strings.Split(text, ",")
+	}
+	for _, v := range values {
+		if !isOption(v) {
+			return fmt.Sprintf("%q is not one of '%s'", v, strings.Join(options, "', '"))
+		}
+	}
+	return ""
+}
+
+// checkDate checks value for a date, datetime or timestamp column: strings
+// must be dates and timestamps within their range
+func checkDate(value any, base string) string {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case string:
+		parsed, ok := parseDateLiteral(strings.TrimSpace(v))
+		if !ok {
+			return fmt.Sprintf("%q is not a valid date, use e.g. 2024-06-01 or 2024-06-01 14:30:00", v)
+		}
+		t = parsed
+	default:
+		return ""
+	}
+	if base == "timestamp" && (t.Before(timestampMin) || t.After(timestampMax)) {
+		return fmt.Sprintf("%s is outside the TIMESTAMP range 1970-01-01 to 2038-01-19", t.Format("2006-01-02 15:04:05"))
+	}
+	return ""
+}
@@ -0,0 +1,243 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// variableRegex matches a variable reference such as @uid
+var variableRegex = regexp.MustCompile(`^@([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// Variable is a reference to a session variable in an argument, e.g. @uid.
+// References are replaced by their values before a command runs.
+type Variable struct {
+	Name string
+}
+
+// GetSetCommandRegex returns the regex for SET commands
+func GetSetCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(SET)(?:\s+(.*))?$`)
+}
+
+// ParseAssignment parses '@name = value'. The value takes the same forms as
+// in object notation.
+func ParseAssignment(str string) (string, any, error) {
+	p := &argParser{src: str}
+	p.skipSpace()
+	if !p.consume('@') {
+		return "", nil, p.unexpected("a variable like @name").withHint("assignments look like SET @uid = 42")
+	}
+	start := p.pos
+	for !p.atEnd() && (p.peek() == '_' || isAlphanumeric(p.peek())) {
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	if !variableRegex.MatchString("@" + name) {
+		return "", nil, newParseError(str, start, "invalid variable name %q", name)
+	}
+
+	p.skipSpace()
+	if !p.consume('=') {
+		return "", nil, p.unexpected("'='")
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := p.expectEnd(); err != nil {
+		return "", nil, err
+	}
+	return strings.ToLower(name), value, nil
+}
+
+// SetVariable stores a session variable. Names are case-insensitive.
+func (s *Session) SetVariable(name string, value any) {
+	if s.Vars == nil {
+		s.Vars = make(map[string]any)
+	}
+	s.Vars[strings.ToLower(name)] = value
+}
+
+// resolveVariables returns value with every variable reference replaced by
+// the variable's value
+func (s *Session) resolveVariables(value any) (any, error) {
+	switch v := value.(type) {
+	case Variable:
+		resolved, ok := s.Vars[strings.ToLower(v.Name)]
+		if !ok {
+			return nil, fmt.Errorf("undefined variable @%s. Use 'SET @%s = value' first", v.Name, v.Name)
+		}
+		return resolved, nil
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, elem := range v {
+			resolved, err := s.resolveVariables(elem)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(v))
+		for i, elem := range v {
+			resolved, err := s.resolveVariables(elem)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	case Comparison:
+		resolved, err := s.resolveVariables(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		v.Value = resolved
+		return v, nil
+	case BoolExpr:
+		resolved, err := s.resolveVariables(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		result := BoolExpr{Op: v.Op, Field: v.Field, Value: resolved}
+		for _, arg := range v.Args {
+			resolvedArg, err := s.resolveVariables(arg)
+			if err != nil {
+				return nil, err
+			}
+			result.Args = append(result.Args, resolvedArg.(BoolExpr))
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveArgs replaces the variable references in the arguments of a command
+func (s *Session) resolveArgs(args map[string]any) (map[string]any, error) {
+	if args == nil {
+		return nil, nil
+	}
+	resolved, err := s.resolveVariables(args)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.(map[string]any), nil
+}
+
+// switchSettingRegex matches the settings SET turns on and off, e.g.
+// SET debug on or SET row_numbers off
+var switchSettingRegex = regexp.MustCompile(`(?i)^(debug|row_numbers|relative_times)\s+(on|off)$`)
+
+// handleSet assigns a session variable, lists them all when assignment is empty,
+// turns the debug log, row numbers or relative times on or off, or sets the
+// page size of GET, how strings are matched or how fast bulk writes run
+func handleSet(s *Session, assignment string) error {
+	if strings.TrimSpace(assignment) == "" {
+		return listVariables(s)
+	}
+	if m := switchSettingRegex.FindStringSubmatch(strings.TrimSpace(assignment)); m != nil {
+		switch strings.ToLower(m[1]) {
+		case "debug":
+			return handleSetDebug(s, m[2])
+		case "relative_times":
+			return handleSetRelativeTimes(s, m[2])
+		}
+		return handleSetRowNumbers(s, m[2])
+	}
+	if m := pageSizeRegex.FindStringSubmatch(strings.TrimSpace(assignment)); m != nil {
+		return handleSetPageSize(s, m[1])
+	}
+	if m := matchCaseRegex.FindStringSubmatch(strings.TrimSpace(assignment)); m != nil {
+		return handleSetMatchCase(s, m[1])
+	}
+	if m := throttleRegex.FindStringSubmatch(strings.TrimSpace(assignment)); m != nil {
+		return handleSetThrottle(s, m[1])
+	}
+
+	name, value, err := ParseAssignment(assignment)
+	if err != nil {
+		return err
+	}
+	value, err = s.resolveVariables(value)
+	if err != nil {
+		return err
+	}
+	s.SetVariable(name, value)
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Set: %s\n", ColorJSON(map[string]any{"@" + name: value}))
+	} else {
+		fmt.Fprintf(s.Out, "Query OK, 0 rows affected%s\n", s.timing())
+	}
+	return nil
+}
+
+// handleSetRowNumbers turns the # column of tabular output on or off for
+// SET row_numbers
+func handleSetRowNumbers(s *Session, setting string) error {
+	s.rowNumbers = strings.ToLower(setting)
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Row numbers: %s\n", ColorJSON(map[string]any{"row_numbers": s.rowNumbers == "on"}))
+	} else {
+		fmt.Fprintf(s.Out, "Row numbers %s\n", s.rowNumbers)
+	}
+	return nil
+}
+
+// handleSetRelativeTimes turns relative DATETIME and TIMESTAMP values in
+// tabular output on or off for SET relative_times
+func handleSetRelativeTimes(s *Session, setting string) error {
+	s.relativeTimes = strings.ToLower(setting)
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Relative times: %s\n", ColorJSON(map[string]any{"relative_times": s.relativeTimes == "on"}))
+	} else {
+		fmt.Fprintf(s.Out, "Relative times %s\n", s.relativeTimes)
+	}
+	return nil
+}
+
+// listVariables prints the session variables sorted by name
+func listVariables(s *Session) error {
+	if len(s.Vars) == 0 {
+		fmt.Fprintln(s.Out, "No variables set")
+		return nil
+	}
+
+	names := make([]string, 0, len(s.Vars))
+	for name := range s.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if s.JSONOutput {
+		vars := make(map[string]any, len(names))
+		for _, name := range names {
+			vars["@"+name] = s.Vars[name]
+		}
+		fmt.Fprintf(s.Out, "Variables: %s\n", ColorJSON(vars))
+		return nil
+	}
+
+	results := make([]map[string]any, len(names))
+	for i, name := range names {
+		value, err := columnValue(s.Vars[name])
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			value = "NULL"
+		}
+		results[i] = map[string]any{"Variable": "@" + name, "Value": value}
+	}
+	s.printTable([]string{"Variable", "Value"}, results)
+	return nil
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or digit
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
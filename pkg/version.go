@@ -0,0 +1,203 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Build information, set when building with
+// -ldflags "-X github.com/bogwi/noqli/pkg.Version=v1.2.0 -X ..."
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// LatestReleaseURL is the GitHub API endpoint VERSION check reads the
+// latest release from
+var LatestReleaseURL = "https://api.github.com/repos/bogwi/noqli/releases/latest"
+
+// updateCheckTimeout bounds the release request so a slow network does not
+// block the shell
+const updateCheckTimeout = 5 * time.Second
+
+// GetVersionCommandRegex returns the regex for VERSION and VERSION check
+func GetVersionCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(VERSION)(?:\s+(check))?$`)
+}
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// CurrentBuild returns the build information of the running binary. What
+// ldflags did not set is read from the build info Go embeds: the module
+// version for go install, and the commit and its time for builds from a
+// git checkout.
+func CurrentBuild() BuildInfo {
+	build := BuildInfo{Version: Version, Commit: Commit, BuildDate: BuildDate, GoVersion: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	if build.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		build.Version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if build.Commit == "" {
+				build.Commit = setting.Value
+			}
+		case "vcs.time":
+			if build.BuildDate == "" {
+				build.BuildDate = setting.Value
+			}
+		}
+	}
+	if len(build.Commit) > 12 {
+		build.Commit = build.Commit[:12]
+	}
+	return build
+}
+
+// String renders the build as one line, e.g.
+// noqli v1.2.0 (commit 1a2b3c4d5e6f, built 2026-10-16T09:00:00Z, go1.22.1)
+func (b BuildInfo) String() string {
+	details := []string{}
+	if b.Commit != "" {
+		details = append(details, "commit "+b.Commit)
+	}
+	if b.BuildDate != "" {
+		details = append(details, "built "+b.BuildDate)
+	}
+	details = append(details, b.GoVersion)
+	return fmt.Sprintf("noqli %s (%s)", b.Version, strings.Join(details, ", "))
+}
+
+// LatestRelease returns the tag of the latest release published on GitHub
+func LatestRelease(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, updateCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, LatestReleaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("release check returned %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("release check returned no version")
+	}
+	return release.TagName, nil
+}
+
+// parseSemver reads the major, minor and patch numbers of a version such as
+// v1.2.3 or 1.2.3-rc.1, ignoring pre-release and build suffixes
+func parseSemver(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	fields := strings.Split(version, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// newerVersion reports whether latest is a later version than current.
+// Versions that are not semantic, such as dev builds, are never older.
+func newerVersion(current, latest string) bool {
+	cur, ok := parseSemver(current)
+	if !ok {
+		return false
+	}
+	next, ok := parseSemver(latest)
+	if !ok {
+		return false
+	}
+	for i := range cur {
+		if next[i] != cur[i] {
+			return next[i] > cur[i]
+		}
+	}
+	return false
+}
+
+// UpdateMessage describes whether a release newer than build is available
+func UpdateMessage(build BuildInfo, latest string) string {
+	if _, ok := parseSemver(build.Version); !ok {
+		return fmt.Sprintf("Latest release: %s", latest)
+	}
+	if newerVersion(build.Version, latest) {
+		return fmt.Sprintf("Update available: %s (go install github.com/bogwi/noqli/cmd/noqli@%s)", latest, latest)
+	}
+	return fmt.Sprintf("Up to date (latest release %s)", latest)
+}
+
+// handleVersion prints the build information, and with check whether a
+// newer release is available
+func handleVersion(ctx context.Context, s *Session, check bool) error {
+	build := CurrentBuild()
+	latest := ""
+	if check {
+		var err error
+		if latest, err = LatestRelease(ctx); err != nil {
+			return fmt.Errorf("checking for updates: %w", err)
+		}
+	}
+
+	if s.JSONOutput {
+		version := map[string]any{
+			"version":    build.Version,
+			"commit":     build.Commit,
+			"build_date": build.BuildDate,
+			"go_version": build.GoVersion,
+		}
+		if check {
+			version["latest"] = latest
+			version["update_available"] = newerVersion(build.Version, latest)
+		}
+		fmt.Fprintf(s.Out, "Version: %s\n", ColorJSON(version))
+		return nil
+	}
+	fmt.Fprintln(s.Out, build)
+	if check {
+		fmt.Fprintln(s.Out, UpdateMessage(build, latest))
+	}
+	return nil
+}
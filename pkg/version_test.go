@@ -0,0 +1,46 @@
+package pkg_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersion(t *testing.T) {
+	session, _, buf := mockSession(t)
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "VERSION"))
+	assert.Contains(t, buf.String(), "noqli dev (")
+
+	buf.Reset()
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "version"))
+	assert.Contains(t, buf.String(), "go_version")
+}
+
+func TestVersionCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name": "v1.4.0"}`))
+	}))
+	defer server.Close()
+	defer func(url, version string) { pkg.LatestReleaseURL, pkg.Version = url, version }(pkg.LatestReleaseURL, pkg.Version)
+	pkg.LatestReleaseURL = server.URL
+
+	session, _, buf := mockSession(t)
+	pkg.Version = "v1.3.2"
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "VERSION check"))
+	assert.Contains(t, buf.String(), "noqli v1.3.2")
+	assert.Contains(t, buf.String(), "Update available: v1.4.0")
+
+	buf.Reset()
+	pkg.Version = "v1.4.0"
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "VERSION check"))
+	assert.Contains(t, buf.String(), "Up to date (latest release v1.4.0)")
+
+	buf.Reset()
+	pkg.Version = "v1.10.0-rc.1"
+	require.NoError(t, pkg.ExecuteCommand(ctx, session, "VERSION check"))
+	assert.Contains(t, buf.String(), "Up to date")
+}
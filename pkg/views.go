@@ -0,0 +1,175 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// viewSuffixRegex matches the "AS VIEW name" ending of a GET that creates a
+// view, e.g. GET {status: 'open'} AS VIEW open_orders
+var viewSuffixRegex = regexp.MustCompile(`(?is)^(.*?)\s*\bAS\s+VIEW\s+([A-Za-z_][A-Za-z0-9_$]*)\s*$`)
+
+// cutViewSuffix splits the arguments of a GET into the filter and the name
+// of the view to create
+func cutViewSuffix(args string) (string, string, bool) {
+	m := viewSuffixRegex.FindStringSubmatch(args)
+	if m == nil {
+		return args, "", false
+	}
+	return m[1], m[2], true
+}
+
+// IsGetViewsCommand checks if the command is GET views
+func IsGetViewsCommand(command string, args string) bool {
+	return strings.ToUpper(command) == "GET" && strings.ToLower(strings.TrimSpace(args)) == "views"
+}
+
+// handleCreateView creates a view named name from the SELECT a GET with args
+// would run
+func handleCreateView(ctx context.Context, s *Session, args map[string]any, name string) error {
+	source, err := sourceFromArgs(ctx, s, args)
+	if err != nil {
+		return err
+	}
+	builder, err := buildGetQuery(ctx, s, source, args)
+	if err != nil {
+		return err
+	}
+	query, values, err := builder.Select()
+	if err != nil {
+		return err
+	}
+
+	// View definitions cannot have parameters, so the values are inlined
+	query, err = inlineParams(query, values)
+	if err != nil {
+		return err
+	}
+	if _, err := s.exec(ctx, fmt.Sprintf("CREATE VIEW %s AS %s", quoteIdent(name), query)); err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "View created: %s\n", ColorJSON(map[string]any{"view": name, "query": query}))
+	} else {
+		fmt.Fprintf(s.Out, "Query OK, 0 rows affected%s\n", s.timing())
+	}
+	return nil
+}
+
+// handleGetViews shows the views in the current database
+func handleGetViews(ctx context.Context, s *Session) error {
+	if s.CurrentDB == "" {
+		return fmt.Errorf("%w. Use 'USE database_name' first", ErrNoDatabaseSelected)
+	}
+
+	rows, err := s.query(ctx, "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.VIEWS WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME", s.CurrentDB)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	views := []string{}
+	for rows.Next() {
+		var view string
+		if err := rows.Scan(&view); err != nil {
+			return err
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if s.JSONOutput {
+		fmt.Fprintf(s.Out, "Views in %s: %s\n", s.CurrentDB, ColorJSON(views))
+		return nil
+	}
+	if len(views) == 0 {
+		fmt.Fprintln(s.Out, "No views found")
+		return nil
+	}
+	column := fmt.Sprintf("Views_in_%s", s.CurrentDB)
+	results := make([]map[string]any, len(views))
+	for i, view := range views {
+		results[i] = map[string]any{column: view}
+	}
+	s.printTable([]string{column}, results)
+	return nil
+}
+
+// inlineParams replaces the placeholders of query with the SQL literals of
+// values. Question marks inside quotes and backticks are left alone.
+func inlineParams(query string, values []any) (string, error) {
+	var b strings.Builder
+	quote := byte(0)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' && i+1 < len(query) {
+				b.WriteByte(c)
+				i++
+				c = query[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			if n >= len(values) {
+				return "", fmt.Errorf("query has more placeholders than values")
+			}
+			literal, err := sqlLiteral(values[n])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(literal)
+			n++
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if n != len(values) {
+		return "", fmt.Errorf("query has fewer placeholders than values")
+	}
+	return b.String(), nil
+}
+
+// sqlLiteral renders value as a SQL literal
+func sqlLiteral(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return quoteString(v), nil
+	case time.Time:
+		return quoteString(v.Format(dateTimeLayout)), nil
+	case []byte:
+		return fmt.Sprintf("X'%X'", v), nil
+	default:
+		return "", fmt.Errorf("cannot use %T value in a view", value)
+	}
+}
+
+// quoteString quotes s as a SQL string literal
+func quoteString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`)
+	return "'" + replacer.Replace(s) + "'"
+}
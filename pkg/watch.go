@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetWatchCommandRegex returns the regex for WATCH commands
+func GetWatchCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^WATCH\s+(\S+)\s+(.+)$`)
+}
+
+// Terminal control sequences used to redraw and highlight watched output
+const (
+	clearScreen    = "\033[H\033[2J"
+	highlightStart = "\033[7m"
+	highlightEnd   = "\033[0m"
+)
+
+// watchStatusRegex matches the lines that change on every run, such as the
+// timing after the row count, which are not highlighted
+var watchStatusRegex = regexp.MustCompile(`^(\d+ rows? in set|Query OK)`)
+
+// parseWatchInterval reads an interval given in seconds, e.g. 5 or 0.5, or as
+// a duration such as 500ms
+func parseWatchInterval(str string) (time.Duration, error) {
+	var interval time.Duration
+	if seconds, err := strconv.ParseFloat(str, 64); err == nil {
+		interval = time.Duration(seconds * float64(time.Second))
+	} else if d, err := time.ParseDuration(str); err == nil {
+		interval = d
+	} else {
+		return 0, fmt.Errorf("invalid WATCH interval %q. Use seconds such as 5 or a duration such as 500ms", str)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("WATCH interval must be positive")
+	}
+	return interval, nil
+}
+
+// handleWatch runs command every interval, redrawing its output and
+// highlighting what changed since the previous run, until ctx is cancelled
+func handleWatch(ctx context.Context, s *Session, interval string, command string) error {
+	every, err := parseWatchInterval(interval)
+	if err != nil {
+		return err
+	}
+	if GetWatchCommandRegex().MatchString(command) {
+		return fmt.Errorf("WATCH cannot watch another WATCH")
+	}
+
+	out := s.Out
+	defer func() { s.Out = out }()
+
+	var previous []string
+	for {
+		var buf bytes.Buffer
+		s.Out = &buf
+		err := ExecuteCommand(ctx, s, command)
+		s.Out = out
+		if ctx.Err() != nil {
+			return nil // interrupted
+		}
+		if err != nil {
+			return err
+		}
+
+		lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+		fmt.Fprint(out, clearScreen)
+		fmt.Fprintf(out, "Every %s: %s    %s\n", every, command, timeNow().Format("15:04:05"))
+		for i, line := range lines {
+			if previous == nil {
+				fmt.Fprintln(out, line)
+			} else if i < len(previous) {
+				fmt.Fprintln(out, highlightChanges(line, previous[i]))
+			} else {
+				fmt.Fprintln(out, highlightStart+line+highlightEnd)
+			}
+		}
+		previous = lines
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(every):
+		}
+	}
+}
+
+// highlightChanges marks the parts of line that differ from the same line of
+// the previous run: the changed cells of table rows, or the whole line
+func highlightChanges(line, previous string) string {
+	if line == previous || watchStatusRegex.MatchString(line) {
+		return line
+	}
+	cells := strings.Split(line, "|")
+	previousCells := strings.Split(previous, "|")
+	if len(cells) < 3 || len(cells) != len(previousCells) {
+		return highlightStart + line + highlightEnd
+	}
+	for i := range cells {
+		if cells[i] != previousCells[i] {
+			cells[i] = highlightStart + cells[i] + highlightEnd
+		}
+	}
+	return strings.Join(cells, "|")
+}
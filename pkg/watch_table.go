@@ -0,0 +1,204 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetWatchTableCommandRegex returns the regex for WATCH TABLE [table] [interval]
+func GetWatchTableCommandRegex() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(WATCH)\s+TABLE(?:\s+(\S+))?(?:\s+(\S+))?$`)
+}
+
+// DefaultWatchTableInterval is how often WATCH TABLE looks for changes when
+// no interval is given
+const DefaultWatchTableInterval = time.Second
+
+// changeFeed returns the changes made to a table since it was last called
+type changeFeed func(ctx context.Context) ([]BinlogChange, error)
+
+// binlogFeed follows the changes to table in the binary log, from its
+// current end. It fails when the session cannot read the binary log or the
+// server does not log rows.
+func (s *Session) binlogFeed(ctx context.Context, table string, columns []string) (changeFeed, error) {
+	if s.BinlogReader == nil {
+		return nil, fmt.Errorf("mysqlbinlog cannot be run")
+	}
+	var format string
+	if err := s.queryRow(ctx, "SELECT @@binlog_format").Scan(&format); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(format, "ROW") {
+		return nil, fmt.Errorf("binlog_format is %s rather than ROW", format)
+	}
+	logs, err := s.showRecords(ctx, "SHOW BINARY LOGS")
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("binary logging is off")
+	}
+
+	// Changes are read from the event at position of file on
+	file := recordField(logs[len(logs)-1], "Log_name")
+	position, _ := strconv.ParseInt(recordField(logs[len(logs)-1], "File_size"), 10, 64)
+	return func(ctx context.Context) ([]BinlogChange, error) {
+		logs, err := s.showRecords(ctx, "SHOW BINARY LOGS")
+		if err != nil {
+			return nil, err
+		}
+		start := 0
+		for i, entry := range logs {
+			if recordField(entry, "Log_name") == file {
+				start = i
+			}
+		}
+
+		var changes []BinlogChange
+		for _, entry := range logs[start:] {
+			name := recordField(entry, "Log_name")
+			size, _ := strconv.ParseInt(recordField(entry, "File_size"), 10, 64)
+			if name != file {
+				file, position = name, 0
+			}
+			if size <= position {
+				continue
+			}
+			next := size
+			err := s.readBinlog(ctx, table, name, columns, func(change BinlogChange) {
+				if change.Position >= position {
+					changes = append(changes, change)
+					if change.Position >= next {
+						next = change.Position + 1
+					}
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+			position = next
+		}
+		return changes, nil
+	}, nil
+}
+
+// pollFeed finds the changes to table by reading its rows each time and
+// comparing them, by id, with the rows read the time before
+func (s *Session) pollFeed(ctx context.Context, table string) (changeFeed, error) {
+	readRows := func(ctx context.Context) ([]map[string]any, error) {
+		rows, err := s.query(ctx, "SELECT * FROM "+table)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		columns, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		var results []map[string]any
+		for rows.Next() {
+			row, err := scanRecord(rows, columns)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, row)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return normalizeRows(results)
+	}
+
+	previous, err := readRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) ([]BinlogChange, error) {
+		current, err := readRows(ctx)
+		if err != nil {
+			return nil, err
+		}
+		diff := DiffSnapshots(&Snapshot{Rows: previous}, &Snapshot{Rows: current})
+		previous = current
+
+		now := timeNow().Format("2006-01-02 15:04:05")
+		var changes []BinlogChange
+		for _, row := range diff.Added {
+			changes = append(changes, BinlogChange{Time: now, Operation: "INSERT", After: row})
+		}
+		for _, change := range diff.Changed {
+			changes = append(changes, BinlogChange{Time: now, Operation: "UPDATE", Before: change.Before, After: change.After})
+		}
+		for _, row := range diff.Removed {
+			changes = append(changes, BinlogChange{Time: now, Operation: "DELETE", Before: row})
+		}
+		return changes, nil
+	}, nil
+}
+
+// handleWatchTable prints a live feed of the rows of table, or of the
+// current table, inserted, updated and deleted, with the fields changed,
+// until ctx is cancelled. Changes are read from the binary log when the
+// session can, and else found by reading the table every interval.
+func handleWatchTable(ctx context.Context, s *Session, table, interval string) error {
+	if table == "" {
+		table = s.CurrentTable
+	}
+	if table == "" {
+		return fmt.Errorf("%w. Use 'USE table_name' or 'WATCH TABLE table_name'", ErrNoTableSelected)
+	}
+	every := DefaultWatchTableInterval
+	if interval != "" {
+		var err error
+		if every, err = parseWatchInterval(interval); err != nil {
+			return err
+		}
+	}
+
+	columns, err := tableColumns(ctx, s, table)
+	if err != nil {
+		return err
+	}
+	source := "the binary log"
+	feed, err := s.binlogFeed(ctx, table, columns)
+	if err != nil {
+		s.Logger.Debug("watching by reading the table", "table", table, "reason", err)
+		source = fmt.Sprintf("reading it every %s", every)
+		if feed, err = s.pollFeed(ctx, table); err != nil {
+			return err
+		}
+	}
+
+	if !s.JSONOutput {
+		fmt.Fprintf(s.Out, "Watching %s for changes from %s. Press Ctrl+C to stop.\n", table, source)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(every):
+		}
+		changes, err := feed(ctx)
+		if ctx.Err() != nil {
+			return nil // interrupted
+		}
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if s.JSONOutput {
+				fmt.Fprintf(s.Out, "Change: %s\n", ColorJSON(change))
+				continue
+			}
+			line := fmt.Sprintf("%s %s %s", change.Time, change.Operation, summarizeChange(change, columns))
+			if change.Thread != 0 {
+				line += fmt.Sprintf(" (thread %d)", change.Thread)
+			}
+			fmt.Fprintln(s.Out, line)
+		}
+	}
+}
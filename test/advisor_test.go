@@ -0,0 +1,53 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexAdvisor(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+	defer testDB.Exec("DROP INDEX `idx_email_name` ON users")
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+	session.AddAfterHook(pkg.IndexAdvisorHook(1))
+
+	// Filtering on the primary key does not scan the table
+	answered := false
+	session.Confirm = func() string {
+		answered = true
+		return "n"
+	}
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {id: 1}"))
+	assert.NotContains(t, buf.String(), "Hint:")
+	assert.False(t, answered)
+
+	// An unindexed filter gets a suggestion, with every filtered column
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: 'User 2', email: 'user2@example.com'}"))
+	assert.Contains(t, buf.String(), "Hint:")
+	assert.Contains(t, buf.String(), "CREATE INDEX `idx_email_name` ON users (`email`, `name`)")
+	assert.True(t, answered)
+
+	// Accepting creates the index
+	session.Confirm = func() string { return "y" }
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: 'User 2', email: 'user2@example.com'}"))
+	assert.Contains(t, buf.String(), "Index 'idx_email_name' created")
+
+	var count int
+	err := testDB.QueryRow("SELECT COUNT(*) FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = 'users' AND INDEX_NAME = 'idx_email_name'", testDBName).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Disabled with advise_rows = 0
+	hook, err := pkg.IndexAdvisorHookFromConfig(pkg.Config{"advise_rows": "0"})
+	assert.NoError(t, err)
+	assert.Nil(t, hook)
+}
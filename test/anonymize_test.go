@@ -0,0 +1,45 @@
+package test
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymize(t *testing.T) {
+	resetTable(t)
+	_, err := testDB.Exec(`INSERT INTO users (name, email, status) VALUES
+		('Real Person', 'real@corp.com', 'active'), ('Another One', 'another@corp.com', 'active'),
+		('Third', 'third@corp.com', 'active'), ('Kept', 'kept@corp.com', 'inactive')`)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	// Small batches rewrite every matching row
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "ANONYMIZE {email: fake.email, name: fake.name, status: ['active'], batch: 2}"))
+	assert.Contains(t, buf.String(), "3 rows affected")
+
+	var email, name string
+	assert.NoError(t, testDB.QueryRow("SELECT email, name FROM users WHERE id = 2").Scan(&email, &name))
+	assert.Equal(t, "user2@example.com", email)
+	assert.NotEqual(t, "Another One", name)
+	assert.NoError(t, testDB.QueryRow("SELECT email FROM users WHERE id = 4").Scan(&email))
+	assert.Equal(t, "kept@corp.com", email)
+
+	// Rewriting all rows asks first
+	session.Confirm = func() string { return "n" }
+	assert.ErrorIs(t, pkg.ExecuteCommand(ctx, session, "ANONYMIZE {email: null}"), pkg.ErrConfirmationDeclined)
+	session.Confirm = func() string { return "y" }
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "ANONYMIZE {email: null}"))
+	var nullEmail sql.NullString
+	assert.NoError(t, testDB.QueryRow("SELECT email FROM users WHERE id = 4").Scan(&nullEmail))
+	assert.False(t, nullEmail.Valid)
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "ANONYMIZE {email: fake.nope, id: 1}"), "unknown fake value")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "ANONYMIZE {missing: null, id: 1}"), "unknown column")
+}
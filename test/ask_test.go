@@ -0,0 +1,56 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAskCommand(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	originalCompletion := pkg.AskCompletion
+	originalScan := pkg.ScanForConfirmation
+	defer func() {
+		pkg.AskCompletion = originalCompletion
+		pkg.ScanForConfirmation = originalScan
+	}()
+
+	var sentSystem, sentPrompt string
+	pkg.AskCompletion = func(ctx context.Context, config pkg.Config, system, prompt string) (string, error) {
+		sentSystem, sentPrompt = system, prompt
+		return "```\nget {down: id, lim: 2}\n```", nil
+	}
+
+	var buf bytes.Buffer
+	session := testSession(true)
+	session.Out = &buf
+
+	// Confirmed: the generated command runs
+	pkg.ScanForConfirmation = func() string { return "y" }
+	err := pkg.ExecuteCommand(ctx, session, `ASK "show me the 2 newest users"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "show me the 2 newest users", sentPrompt)
+	assert.Contains(t, sentSystem, "email")
+	assert.Contains(t, buf.String(), "Generated: get {down: id, lim: 2}")
+	assert.Contains(t, buf.String(), "user3@example.com")
+	assert.NotContains(t, buf.String(), "user1@example.com")
+
+	// Declined: nothing runs
+	buf.Reset()
+	pkg.ScanForConfirmation = func() string { return "n" }
+	err = pkg.ExecuteCommand(ctx, session, `ASK "show me the 2 newest users"`)
+	assert.ErrorIs(t, err, pkg.ErrConfirmationDeclined)
+	assert.NotContains(t, buf.String(), "user3@example.com")
+
+	// Replies without a command are rejected
+	pkg.AskCompletion = func(ctx context.Context, config pkg.Config, system, prompt string) (string, error) {
+		return "I cannot help with that.", nil
+	}
+	err = pkg.ExecuteCommand(ctx, session, `ASK "drop everything"`)
+	assert.Error(t, err)
+}
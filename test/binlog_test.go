@@ -0,0 +1,68 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBinlog returns binary log output changing rows 3 and 4 of users
+func fakeBinlog(ctx context.Context, file string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(fmt.Sprintf(`# at 200
+#261016 12:46:34 server id 1  end_log_pos 280 CRC32 0x1 	Query	thread_id=8	exec_time=0	error_code=0
+BEGIN
+/*!*/;
+# at 280
+#261016 12:46:34 server id 1  end_log_pos 330 CRC32 0x2 	Rows_query
+# UPDATE users SET name = 'Changed' WHERE id = 3
+# at 330
+#261016 12:46:34 server id 1  end_log_pos 400 CRC32 0x3 	Table_map: `+"`%[1]s`.`users`"+` mapped to number 92
+# at 400
+#261016 12:46:34 server id 1  end_log_pos 500 CRC32 0x4 	Update_rows: table id 92 flags: STMT_END_F
+### UPDATE `+"`%[1]s`.`users`"+`
+### WHERE
+###   @1=3
+###   @2='User 3'
+### SET
+###   @1=3
+###   @2='Changed'
+# at 500
+#261016 12:47:01 server id 1  end_log_pos 600 CRC32 0x5 	Delete_rows: table id 92 flags: STMT_END_F
+### DELETE FROM `+"`%[1]s`.`users`"+`
+### WHERE
+###   @1=4
+###   @2='User 4'
+`, testDBName))), nil
+}
+
+func TestBinlogTail(t *testing.T) {
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+	session.BinlogReader = fakeBinlog
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "BINLOG tail"))
+	assert.Contains(t, buf.String(), "name: 'User 3' -> 'Changed'")
+	assert.Contains(t, buf.String(), "UPDATE users SET name = 'Changed' WHERE id = 3")
+	assert.Contains(t, buf.String(), "DELETE")
+
+	changes, err := session.BinlogTail(ctx, "users", 1, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "DELETE", changes[0].Operation)
+	}
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "BINLOG tail 3"))
+	assert.Contains(t, buf.String(), "Changed")
+	assert.NotContains(t, buf.String(), "User 4")
+
+	session.BinlogReader = nil
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "BINLOG tail"))
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBooleanColumns(t *testing.T) {
+	resetTable(t)
+	t.Cleanup(func() {
+		testDB.Exec("ALTER TABLE users DROP COLUMN active")
+	})
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	// The new column is created as TINYINT(1)
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "CREATE {name: 'A', active: true}"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "CREATE {name: 'B', active: false}"))
+
+	var columnType string
+	err := testDB.QueryRow("SELECT COLUMN_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = 'users' AND COLUMN_NAME = 'active'", testDBName).Scan(&columnType)
+	assert.NoError(t, err)
+	assert.Equal(t, "tinyint(1)", columnType)
+
+	// Values are shown as true and false and filter as booleans
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {active, name: ['A', 'B']}"))
+	assert.Contains(t, buf.String(), "| true   |")
+	assert.Contains(t, buf.String(), "| false  |")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name, active: false}"))
+	assert.Contains(t, buf.String(), "| B    |")
+	assert.NotContains(t, buf.String(), "| A    |")
+
+	// unless numeric output is configured
+	session.Config = pkg.Config{"booleans": "numeric"}
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {active, name: 'A'}"))
+	assert.Contains(t, buf.String(), "| 1      |")
+}
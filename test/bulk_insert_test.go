@@ -0,0 +1,68 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchCreate(t *testing.T) {
+	resetTable(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+	session.Config = pkg.Config{"batch_size": "2"}
+
+	var statements []string
+	session.AddAfterHook(func(ctx context.Context, s *pkg.Session, info *pkg.CommandInfo) {
+		statements = append(statements, info.SQL...)
+	})
+
+	err := pkg.ExecuteCommand(ctx, session, "CREATE [{name: 'A', email: 'a@example.com'}, {name: 'B'}, {name: 'C', email: 'c@example.com'}]")
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Query OK, 3 rows affected")
+	assert.Contains(t, buf.String(), "rows/sec")
+
+	// Three rows in batches of two
+	var inserts int
+	for _, stmt := range statements {
+		if strings.HasPrefix(stmt, "INSERT") {
+			inserts++
+		}
+	}
+	assert.Equal(t, 2, inserts)
+
+	var count int
+	err = testDB.QueryRow("SELECT COUNT(*) FROM users WHERE email IS NULL AND name = 'B'").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestBulkInsertRollsBack(t *testing.T) {
+	resetTable(t)
+
+	session := testSession(true)
+	session.Config = pkg.Config{"batch_size": "1"}
+
+	// The second batch fails on a duplicate primary key, so the first is rolled back
+	_, err := pkg.BulkInsert(ctx, session, []map[string]any{
+		{"id": 100, "name": "first"},
+		{"id": 100, "name": "duplicate"},
+	})
+	assert.Error(t, err)
+
+	var count int
+	err = testDB.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// Invalid batch sizes are rejected
+	session.Config = pkg.Config{"batch_size": "0"}
+	_, err = pkg.BulkInsert(ctx, session, []map[string]any{{"name": "x"}})
+	assert.Error(t, err)
+}
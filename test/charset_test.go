@@ -0,0 +1,58 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCharsetConfig(t *testing.T) {
+	assert.Equal(t, "utf8mb4", pkg.Config{}.Charset())
+	assert.Equal(t, "latin1", pkg.Config{"charset": "latin1"}.Charset())
+	assert.Equal(t, "charset=utf8mb4", pkg.DSNCharsetParams("utf8mb4"))
+}
+
+func TestDescribe(t *testing.T) {
+	resetTable(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "DESCRIBE"))
+	assert.Contains(t, buf.String(), "Table 'users': charset")
+	assert.Contains(t, buf.String(), "| Collation")
+	assert.Contains(t, buf.String(), "| email")
+
+	info, err := session.DescribeTable(ctx, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, "id", info.Columns[0].Field)
+	assert.Nil(t, info.Columns[0].Charset, "numeric columns have no charset")
+	assert.NotNil(t, info.Columns[1].Charset)
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "desc missing_table"), "does not exist")
+}
+
+func TestNarrowCharsetWarning(t *testing.T) {
+	resetTable(t)
+	_, err := testDB.Exec("ALTER TABLE users MODIFY notes VARCHAR(255) CHARACTER SET utf8mb3")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		testDB.Exec("ALTER TABLE users MODIFY notes VARCHAR(255) CHARACTER SET utf8mb4")
+	})
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	// Plain text does not warn
+	pkg.ExecuteCommand(ctx, session, "CREATE {name: 'A', notes: 'plain'}")
+	assert.NotContains(t, buf.String(), "Warning")
+
+	buf.Reset()
+	pkg.ExecuteCommand(ctx, session, "CREATE {name: '🎉', notes: 'party 🎉'}")
+	assert.Contains(t, buf.String(), "Warning: column 'notes' uses utf8mb3")
+	assert.NotContains(t, buf.String(), "column 'name'")
+}
@@ -0,0 +1,28 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChart(t *testing.T) {
+	resetTable(t)
+	_, err := testDB.Exec("INSERT INTO users (name, numeric_value) VALUES ('A', 20), ('B', 10), ('C', NULL)")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name, numeric_value, chart: numeric_value}"))
+	output := buf.String()
+	assert.Contains(t, output, "| chart ")
+	assert.Contains(t, output, "| "+strings.Repeat("#", 30)+" |")
+	assert.Contains(t, output, "| "+strings.Repeat("#", 15)+" ")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {name, chart: numeric_value}"), "not in the result")
+}
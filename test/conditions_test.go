@@ -0,0 +1,42 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupedConditions(t *testing.T) {
+	resetTable(t)
+	_, err := testDB.Exec(`
+		INSERT INTO users (name, status, priority) VALUES
+		('A', 'new', 'high'),
+		('B', 'open', 'high'),
+		('C', 'open', 'low'),
+		('D', 'closed', 'high')
+	`)
+	assert.NoError(t, err)
+
+	session := testSession(true)
+
+	args, err := pkg.ParseArg("{name, (status: 'new' or status: 'open') and priority: 'high', up: name}")
+	assert.NoError(t, err)
+
+	var names []string
+	rows, err := session.Query(ctx, args)
+	assert.NoError(t, err)
+	for rows.Next() {
+		var row struct{ Name string }
+		assert.NoError(t, rows.Scan(&row))
+		names = append(names, row.Name)
+	}
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, []string{"A", "B"}, names)
+
+	// Conditions are filters in UPDATE
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "update {not status: 'open' and priority: high, category: 'done'}"))
+	var count int
+	assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM users WHERE category = 'done' AND name IN ('A', 'D')").Scan(&count))
+	assert.Equal(t, 2, count)
+}
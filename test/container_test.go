@@ -0,0 +1,45 @@
+//go:build containers
+
+package test
+
+import (
+	"context"
+	"net"
+	"os"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
+)
+
+// testServerImage is the image the tests run on with -tags containers;
+// TEST_DB_IMAGE replaces it, e.g. with mysql:8.4
+const testServerImage = "mysql:8.0"
+
+func init() {
+	startTestServer = func(ctx context.Context) (func(), error) {
+		container, err := mysql.RunContainer(ctx,
+			testcontainers.WithImage(getEnvOrDefault("TEST_DB_IMAGE", testServerImage)),
+			mysql.WithUsername("root"),
+			mysql.WithPassword("1234"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		stop := func() { container.Terminate(context.Background()) }
+
+		host, err := container.Host(ctx)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		port, err := container.MappedPort(ctx, "3306/tcp")
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		os.Setenv("TEST_DB_HOST", net.JoinHostPort(host, port.Port()))
+		os.Setenv("TEST_DB_USER", "root")
+		os.Setenv("TEST_DB_PASS", "1234")
+		return stop, nil
+	}
+}
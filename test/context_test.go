@@ -0,0 +1,29 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelledContext(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := pkg.HandleGet(cancelled, testSession(true), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	err = pkg.HandleCreate(cancelled, testSession(true), map[string]any{"name": "Never Inserted"})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Nothing was written by the cancelled CREATE
+	var count int
+	err = testDB.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
@@ -48,7 +48,7 @@ func TestCreateCommand(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := pkg.HandleCreate(testDB, tc.args, true)
+			err := pkg.HandleCreate(ctx, testSession(true), tc.args)
 
 			if tc.expected == nil {
 				assert.NoError(t, err)
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateFilters(t *testing.T) {
+	resetTable(t)
+	t.Cleanup(func() {
+		testDB.Exec("ALTER TABLE users DROP COLUMN created_at")
+	})
+
+	session := testSession(true)
+
+	// The new column is created as DATETIME
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "create {name: 'Old', created_at: 2020-01-15}"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "create {name: 'June', created_at: 2024-06-10 12:00}"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "create {name: 'Recent', created_at: now()-1h}"))
+
+	var columnType string
+	err := testDB.QueryRow("SELECT DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = 'users' AND COLUMN_NAME = 'created_at'", testDBName).Scan(&columnType)
+	assert.NoError(t, err)
+	assert.Equal(t, "datetime", columnType)
+
+	countWhere := func(arg string) int {
+		args, err := pkg.ParseArg(arg)
+		assert.NoError(t, err)
+		query, params, err := pkg.NewQueryBuilder("users").SelectExpr("COUNT(*)").Where(args).Select()
+		assert.NoError(t, err)
+		var count int
+		assert.NoError(t, testDB.QueryRow(query, params...).Scan(&count))
+		return count
+	}
+
+	assert.Equal(t, 1, countWhere("{created_at: > now()-7d}"))
+	assert.Equal(t, 1, countWhere("{created_at: (2024-06-01, 2024-06-30)}"))
+	assert.Equal(t, 2, countWhere("{created_at: >= 2024-01-01}"))
+	assert.Equal(t, 1, countWhere("{created_at: 2020-01-15}"))
+
+	// Comparisons are filters in UPDATE
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "update {created_at: < 2021-01-01, status: 'archived'}"))
+	var name string
+	assert.NoError(t, testDB.QueryRow("SELECT name FROM users WHERE status = 'archived'").Scan(&name))
+	assert.Equal(t, "Old", name)
+}
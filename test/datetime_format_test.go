@@ -0,0 +1,57 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDSNTimeParams(t *testing.T) {
+	params := pkg.DSNTimeParams(time.UTC)
+	assert.Contains(t, params, "parseTime=true")
+	assert.Contains(t, params, "loc=UTC")
+	assert.Contains(t, params, "time_zone=%27%2B00%3A00%27")
+}
+
+func TestConfigLocation(t *testing.T) {
+	loc, err := pkg.Config{}.Location()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Local, loc)
+
+	loc, err = pkg.Config{"timezone": "UTC"}.Location()
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", loc.String())
+
+	_, err = pkg.Config{"timezone": "Mars/Olympus"}.Location()
+	assert.ErrorContains(t, err, "invalid timezone")
+}
+
+func TestDateTimeFormatting(t *testing.T) {
+	resetTable(t)
+	t.Cleanup(func() {
+		testDB.Exec("ALTER TABLE users DROP COLUMN created_at")
+	})
+
+	var buf bytes.Buffer
+	session := testSession(true)
+	session.Out = &buf
+	session.Config = pkg.Config{"timezone": "UTC"}
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "create {name: 'June', created_at: 2024-06-01 12:00}"))
+
+	// ISO 8601 by default
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {name: 'June'}"))
+	assert.Contains(t, buf.String(), "2024-06-01T12:00:00Z")
+
+	// MySQL style on request
+	session.Config["date_format"] = "mysql"
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {name: 'June'}"))
+	assert.Contains(t, buf.String(), "2024-06-01 12:00:00")
+	assert.False(t, strings.Contains(buf.String(), "T12:00"))
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalFormatting(t *testing.T) {
+	// Decimals are written as exact JSON numbers
+	out, err := json.Marshal(map[string]any{"price": pkg.Decimal("1234567890.123456789")})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"price":1234567890.123456789}`, string(out))
+
+	// and right-aligned in tables
+	var buf bytes.Buffer
+	pkg.FprintTabularResults(&buf, []string{"name", "price"}, []map[string]any{
+		{"name": "Pen", "price": pkg.Decimal("1.50")},
+		{"name": "Desk", "price": pkg.Decimal("120.00")},
+	})
+	assert.Contains(t, buf.String(), "| Pen  |   1.50 |")
+	assert.Contains(t, buf.String(), "| Desk | 120.00 |")
+}
+
+func TestDecimalColumns(t *testing.T) {
+	resetTable(t)
+	_, err := testDB.Exec("ALTER TABLE users ADD COLUMN price DECIMAL(20,2)")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		testDB.Exec("ALTER TABLE users DROP COLUMN price")
+	})
+	_, err = testDB.Exec("INSERT INTO users (name, price) VALUES ('A', 0.10), ('B', 0.20), ('C', 12345678901234567.89)")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	session := testSession(true)
+	session.Out = &buf
+
+	// Values keep every digit and are shown as numbers
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {name: 'C'}"))
+	assert.Contains(t, buf.String(), "12345678901234567.89")
+	assert.NotContains(t, buf.String(), `"12345678901234567.89"`)
+
+	// Sums are exact
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {sum: price, name: ['A', 'B']}"))
+	assert.Contains(t, buf.String(), "0.30")
+	assert.NotContains(t, buf.String(), "0.30000000000000004")
+
+	buf.Reset()
+	session.JSONOutput = false
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {price, name: ['A', 'C']}"))
+	assert.Contains(t, buf.String(), "|                 0.10 |")
+}
@@ -40,6 +40,15 @@ func TestDeleteCommand(t *testing.T) {
 			expectedIDs: []int{3},
 			shouldError: false,
 		},
+		{
+			name: "Delete Excluding Users",
+			args: map[string]any{
+				"id":   pkg.Comparison{Op: "NOT IN", Value: []any{1}},
+				"name": pkg.Comparison{Op: "!=", Value: "User 3"},
+			},
+			expectedIDs: []int{1, 3},
+			shouldError: false,
+		},
 		{
 			name: "Delete Non-existent User",
 			args: map[string]any{
@@ -63,7 +72,7 @@ func TestDeleteCommand(t *testing.T) {
 			resetTable(t)
 			insertTestData(t)
 
-			err := pkg.HandleDelete(testDB, tc.args, true)
+			err := pkg.HandleDelete(ctx, testSession(true), tc.args)
 
 			if tc.shouldError {
 				assert.Error(t, err)
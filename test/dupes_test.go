@@ -0,0 +1,56 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDupes(t *testing.T) {
+	resetTable(t)
+	_, err := testDB.Exec(`INSERT INTO users (name, email) VALUES
+		('Ann', 'ann@example.com'), ('Bob', 'bob@example.com'), ('Ann 2', 'ann@example.com'),
+		('Ann 3', 'ann@example.com'), ('Bob 2', 'bob@example.com'), ('Cid', 'cid@example.com'),
+		('No mail', NULL), ('No mail', NULL)`)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "DUPES {by: [email]}"))
+	out := buf.String()
+	assert.Contains(t, out, "2 rows in set")
+	assert.Contains(t, out, "1,3,4")
+	assert.NotContains(t, out, "cid@example.com")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "DUPES {by: email, name: 'Ann'}"))
+	assert.Contains(t, buf.String(), "No duplicates found")
+
+	// Declining the prompt leaves the rows alone
+	session.Confirm = func() string { return "n" }
+	assert.ErrorIs(t, pkg.ExecuteCommand(ctx, session, "DUPES {by: [email], keep: 'lowest id'}"), pkg.ErrConfirmationDeclined)
+
+	buf.Reset()
+	session.Confirm = func() string { return "y" }
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "DUPES {by: [email], keep: 'highest id'}"))
+	assert.Contains(t, buf.String(), "Delete 3 duplicate rows?")
+	assert.Contains(t, buf.String(), "3 rows affected")
+
+	var ids []int
+	rows, err := testDB.Query("SELECT id FROM users WHERE email IS NOT NULL ORDER BY id")
+	assert.NoError(t, err)
+	for rows.Next() {
+		var id int
+		assert.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	rows.Close()
+	assert.Equal(t, []int{4, 5, 6}, ids)
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "DUPES {}"), "requires the columns")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "DUPES {by: email, keep: 'newest'}"), "keep must be")
+}
@@ -12,13 +12,13 @@ func TestDynamicSchema(t *testing.T) {
 	resetTable(t)
 
 	// Try to create a user with fields that don't exist yet
-	err := pkg.HandleCreate(testDB, map[string]interface{}{
+	err := pkg.HandleCreate(ctx, testSession(true), map[string]interface{}{
 		"name":     "Dynamic User",
 		"email":    "dynamic@example.com",
 		"age":      25,
 		"location": "New York",
 		"active":   true,
-	}, true)
+	})
 	assert.NoError(t, err)
 
 	// Check if columns were created
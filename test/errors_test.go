@@ -0,0 +1,69 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedErrors(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	// No table selected
+	err := pkg.HandleGet(ctx, pkg.NewSession(testDB), nil)
+	assert.ErrorIs(t, err, pkg.ErrNoTableSelected)
+
+	// No records matched
+	err = pkg.HandleDelete(ctx, testSession(true), map[string]any{"id": 999})
+	assert.ErrorIs(t, err, pkg.ErrNoRecords)
+
+	err = pkg.HandleUpdate(ctx, testSession(true), map[string]any{"id": 999, "name": "Nobody"})
+	assert.ErrorIs(t, err, pkg.ErrNoRecords)
+
+	// Declined confirmation
+	original := pkg.ScanForConfirmation
+	pkg.ScanForConfirmation = func() string { return "n" }
+	defer func() { pkg.ScanForConfirmation = original }()
+
+	err = pkg.HandleUpdate(ctx, testSession(true), map[string]any{"status": "archived"})
+	assert.ErrorIs(t, err, pkg.ErrConfirmationDeclined)
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	_, err := pkg.ParseArg("  invalid")
+	assert.ErrorIs(t, err, pkg.ErrParse)
+
+	var parseErr *pkg.ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 2, parseErr.Pos)
+
+	_, err = pkg.ParseArg("{id: (1, x)}")
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 8, parseErr.Pos)
+	assert.Contains(t, parseErr.Error(), "invalid range end")
+}
+
+func TestParseErrorSnippet(t *testing.T) {
+	session := pkg.NewSession(nil)
+	session.CurrentTable = testTable
+
+	// Positions refer to the whole command line
+	err := pkg.ExecuteCommand(ctx, session, "GET {id: (1, xy)}")
+	var parseErr *pkg.ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, "GET {id: (1, xy)}", parseErr.Input)
+	assert.Equal(t, 12, parseErr.Pos)
+	assert.Equal(t, "GET {id: (1, xy)}\n            ^~~\nhint: range bounds are numbers, dates or variables, e.g. (1, 10)", parseErr.Snippet())
+
+	err = pkg.ExecuteCommand(ctx, session, "get {name: 'John}")
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 11, parseErr.Pos)
+	assert.Contains(t, parseErr.Snippet(), "           ^~~~~~\nhint: close the string")
+
+	err = pkg.ExecuteCommand(ctx, session, "SET uid = 1")
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 4, parseErr.Pos)
+}
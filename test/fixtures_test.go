@@ -0,0 +1,56 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFixture(t *testing.T, dir, name, content string) {
+	t.Helper()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestFixturesLoad(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+	createOrderTables(t)
+
+	dir := t.TempDir()
+	writeFixture(t, dir, "users.yaml", `
+- {id: 10, name: Ann, email: ann@example.com}
+- {id: 11, name: Bob}
+`)
+	// orders sorts before users but references them, so users load first
+	writeFixture(t, dir, "orders.json", `[{"id": 1, "buyer": 10, "total": 9.99}, {"id": 2, "buyer": 11, "total": 5}]`)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+	session.Confirm = func() string { return "y" }
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "FIXTURES load "+dir))
+	assert.Contains(t, buf.String(), "Replace all rows of orders, users")
+
+	var users, orders int
+	assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM users").Scan(&users))
+	assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM orders WHERE buyer IN (10, 11)").Scan(&orders))
+	assert.Equal(t, 2, users)
+	assert.Equal(t, 2, orders)
+
+	// A file can also map tables to rows; a failure leaves the tables as they were
+	writeFixture(t, dir, "broken.yml", "users:\n  - {id: 20, nickname: Cy}\n")
+	fixtures, err := pkg.ReadFixtures(filepath.Join(dir, "broken.yml"))
+	assert.NoError(t, err)
+	_, err = session.LoadFixtures(ctx, fixtures)
+	assert.ErrorContains(t, err, "nickname")
+	assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM users").Scan(&users))
+	assert.Equal(t, 2, users)
+
+	session.Confirm = func() string { return "n" }
+	assert.ErrorIs(t, pkg.ExecuteCommand(ctx, session, "FIXTURES load "+filepath.Join(dir, "users.yaml")), pkg.ErrConfirmationDeclined)
+}
@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"database/sql"
 	"fmt"
-	"io"
-	"os"
 	"regexp"
 	"strings"
 	"testing"
@@ -66,11 +64,6 @@ func TestGetCommandAggregate(t *testing.T) {
 				_ = row.Scan(&intResult)
 			}
 
-			// Capture stdout using os.Pipe
-			r, w, _ := os.Pipe()
-			oldStdout := os.Stdout
-			os.Stdout = w
-
 			// Parse command string as user would type
 			cmdStr := tc.command
 			args, err := pkg.ParseArg(strings.TrimPrefix(strings.TrimSpace(cmdStr), "get "))
@@ -79,16 +72,12 @@ func TestGetCommandAggregate(t *testing.T) {
 			}
 			assert.NoError(t, err, "ParseArg failed for: %s", cmdStr)
 
-			// Set output mode
-			useJson := tc.jsonMode
-			err = pkg.HandleGet(testDB, args, useJson)
-			assert.NoError(t, err, "HandleGet failed for: %s", cmdStr)
-			w.Close()
-			os.Stdout = oldStdout
-
+			// Set output mode and capture output
 			var buf bytes.Buffer
-			io.Copy(&buf, r)
-			r.Close()
+			session := testSession(tc.jsonMode)
+			session.Out = &buf
+			err = pkg.HandleGet(ctx, session, args)
+			assert.NoError(t, err, "HandleGet failed for: %s", cmdStr)
 
 			output := buf.String()
 			// Check output contains the expected value
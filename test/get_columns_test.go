@@ -117,7 +117,7 @@ func TestGetCommandColumns(t *testing.T) {
 			assert.NoError(t, err, "Failed to parse command string: %s", tc.commandStr)
 
 			// Call noqli
-			err = pkg.HandleGet(testDB, args, true)
+			err = pkg.HandleGet(ctx, testSession(true), args)
 			if tc.shouldError {
 				assert.Error(t, err)
 				return
@@ -93,7 +93,7 @@ func TestGetCommandCount(t *testing.T) {
 			assert.NoError(t, err, "Failed to parse command string: %s", tc.commandStr)
 
 			// Run the actual NoQLi command
-			err = pkg.HandleGet(testDB, args, true)
+			err = pkg.HandleGet(ctx, testSession(true), args)
 			assert.NoError(t, err, "HandleGet failed for: %s", tc.commandStr)
 
 			// Validate count directly from database
@@ -43,7 +43,7 @@ func TestGetCommandIN(t *testing.T) {
 	assert.NoError(t, err, "Failed to update phone")
 
 	// First verify we can retrieve all records
-	err = pkg.HandleGet(testDB, nil, true)
+	err = pkg.HandleGet(ctx, testSession(true), nil)
 	assert.NoError(t, err, "Failed to get all records")
 
 	// Test the IN clause with string values using actual command strings
@@ -107,7 +107,7 @@ func TestGetCommandIN(t *testing.T) {
 			t.Logf("Parsed args: %+v", args)
 
 			// Execute the noqli command with the parsed args
-			err = pkg.HandleGet(testDB, args, true)
+			err = pkg.HandleGet(ctx, testSession(true), args)
 			if tc.shouldError {
 				assert.Error(t, err)
 				return
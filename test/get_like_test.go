@@ -90,7 +90,7 @@ func TestGetCommandLike(t *testing.T) {
 			}
 
 			// Execute the actual NoQLi function we're testing
-			err := pkg.HandleGet(testDB, argsCopy, true)
+			err := pkg.HandleGet(ctx, testSession(true), argsCopy)
 			assert.NoError(t, err)
 
 			// For manual verification, execute a direct SQL query
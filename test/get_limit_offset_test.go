@@ -74,7 +74,7 @@ func TestGetCommandLimitOffset(t *testing.T) {
 			for k, v := range tc.args {
 				argsCopy[k] = v
 			}
-			err := pkg.HandleGet(testDB, argsCopy, true)
+			err := pkg.HandleGet(ctx, testSession(true), argsCopy)
 			if tc.shouldError {
 				assert.Error(t, err)
 				return // Don't validate results if error is expected
@@ -148,7 +148,7 @@ func TestGetCommand(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := pkg.HandleGet(testDB, tc.args, true)
+			err := pkg.HandleGet(ctx, testSession(true), tc.args)
 
 			if tc.shouldError {
 				assert.Error(t, err)
@@ -1,7 +1,6 @@
 package test
 
 import (
-	"database/sql"
 	"fmt"
 	"strings"
 	"testing"
@@ -56,16 +55,16 @@ func TestHandleCommand(t *testing.T) {
 	}
 
 	// First create a test user for update/delete operations
-	err := pkg.HandleCreate(testDB, map[string]any{
+	err := pkg.HandleCreate(ctx, testSession(true), map[string]any{
 		"name":  "Test User",
 		"email": "test@example.com",
-	}, true)
+	})
 	assert.NoError(t, err)
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// We need to recreate handleCommand here for testing
-			err := func(db *sql.DB, line string) error {
+			err := func(s *pkg.Session, line string) error {
 				trimmed := strings.TrimSpace(line)
 
 				// Parse command using regex
@@ -91,17 +90,17 @@ func TestHandleCommand(t *testing.T) {
 
 				switch command {
 				case "CREATE":
-					return pkg.HandleCreate(db, argObj, true)
+					return pkg.HandleCreate(ctx, s, argObj)
 				case "GET":
-					return pkg.HandleGet(db, argObj, true)
+					return pkg.HandleGet(ctx, s, argObj)
 				case "UPDATE":
-					return pkg.HandleUpdate(db, argObj, true)
+					return pkg.HandleUpdate(ctx, s, argObj)
 				case "DELETE":
-					return pkg.HandleDelete(db, argObj, true)
+					return pkg.HandleDelete(ctx, s, argObj)
 				default:
 					return fmt.Errorf("unknown command: %s", command)
 				}
-			}(testDB, tc.input)
+			}(testSession(true), tc.input)
 
 			if tc.shouldPass {
 				assert.NoError(t, err)
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "STATUS"))
+	assert.Contains(t, buf.String(), "Server version")
+	assert.Contains(t, buf.String(), "Latency")
+	assert.Contains(t, buf.String(), "Pool connections")
+	assert.Contains(t, buf.String(), "None (autocommit)")
+	assert.Contains(t, buf.String(), "0 failed")
+
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "GET {missing_column: 1}"))
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "STATUS"))
+	assert.Contains(t, buf.String(), "2 (1 failed)")
+
+	health, err := session.Health(ctx)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, health.Version)
+	assert.NotEmpty(t, health.Charset)
+	assert.Positive(t, health.Latency)
+	assert.NotNil(t, health.Pool)
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "status"))
+	assert.Contains(t, buf.String(), "latency_ms")
+	assert.Contains(t, buf.String(), "commands_run")
+}
+
+func TestConfigProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	content := `[profile.prod]
+host = db.example.com:3306
+user = reader
+database = shop
+environment = Production
+
+[profile.replicated]
+replicas = r1:3306, r2:3306
+`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	config, err := pkg.LoadConfig(path)
+	assert.NoError(t, err)
+
+	base := pkg.Connection{Host: "localhost", User: "root", Password: "secret", Database: "app"}
+	conn, err := config.Profile("Prod", base)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.Connection{Host: "db.example.com:3306", User: "reader", Password: "secret", Database: "shop", Environment: "production"}, conn)
+
+	_, err = config.Profile("staging", base)
+	assert.Error(t, err)
+
+	conn, err = config.Profile("replicated", base)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r1:3306", "r2:3306"}, conn.Replicas)
+	assert.Equal(t, pkg.DefaultMaxReplicaLag, conn.MaxLag)
+}
+
+func TestAddProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".noqli", "config")
+	conn := pkg.Connection{Host: "db.example.com:3306", User: "app", Password: "keychain:prod", Database: "shop", Environment: "production"}
+	require.NoError(t, pkg.AddProfile(path, "Prod", conn))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	config, err := pkg.LoadConfig(path)
+	require.NoError(t, err)
+	got, err := config.Profile("prod", pkg.Connection{})
+	require.NoError(t, err)
+	assert.Equal(t, conn, got)
+	assert.ErrorContains(t, pkg.AddProfile(path, "prod", conn), "already exists")
+
+	env := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, pkg.WriteEnvFile(env, pkg.Connection{Host: "localhost", User: "root", Password: "a b#c"}))
+	data, err := os.ReadFile(env)
+	require.NoError(t, err)
+	assert.Equal(t, "DB_HOST=localhost\nDB_USER=root\nDB_PASSWORD='a b#c'\n", string(data))
+}
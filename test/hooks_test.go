@@ -0,0 +1,78 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandHooks(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	session := testSession(true)
+	session.Out = &bytes.Buffer{}
+
+	var before, after []*pkg.CommandInfo
+	session.AddBeforeHook(func(ctx context.Context, s *pkg.Session, info *pkg.CommandInfo) error {
+		before = append(before, info)
+		return nil
+	})
+	session.AddAfterHook(func(ctx context.Context, s *pkg.Session, info *pkg.CommandInfo) {
+		after = append(after, info)
+	})
+
+	err := pkg.ExecuteCommand(ctx, session, "get {id: [1, 2]}")
+	assert.NoError(t, err)
+
+	assert.Len(t, before, 1)
+	assert.Len(t, after, 1)
+	info := after[0]
+	assert.Equal(t, "GET", info.Command)
+	assert.Equal(t, "get {id: [1, 2]}", info.Line)
+	assert.Equal(t, []any{1, 2}, info.Args["id"])
+	assert.Contains(t, info.SQL, "SELECT * FROM "+testTable+" WHERE `id` IN (?,?)")
+	assert.Equal(t, int64(2), info.Rows)
+	assert.NoError(t, info.Err)
+
+	// Failed commands still reach the after hooks
+	err = pkg.ExecuteCommand(ctx, session, "delete {id: 999}")
+	assert.Error(t, err)
+	assert.Len(t, after, 2)
+	assert.ErrorIs(t, after[1].Err, pkg.ErrNoRecords)
+}
+
+func TestBeforeHookAbortsCommand(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	session := testSession(true)
+	session.Out = &bytes.Buffer{}
+
+	errReadOnly := errors.New("read-only session")
+	session.AddBeforeHook(func(ctx context.Context, s *pkg.Session, info *pkg.CommandInfo) error {
+		if info.Command == "DELETE" {
+			return errReadOnly
+		}
+		return nil
+	})
+
+	var executed []string
+	session.AddAfterHook(func(ctx context.Context, s *pkg.Session, info *pkg.CommandInfo) {
+		executed = append(executed, info.SQL...)
+	})
+
+	err := pkg.ExecuteCommand(ctx, session, "delete {id: 1}")
+	assert.ErrorIs(t, err, errReadOnly)
+	assert.Empty(t, executed)
+
+	// The record is still there
+	var count int
+	err = testDB.QueryRow("SELECT COUNT(*) FROM " + testTable + " WHERE id = 1").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckForeignKeys(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+	createOrderTables(t)
+	_, err := testDB.Exec("INSERT INTO orders (buyer, total) VALUES (1, 5)")
+	assert.NoError(t, err)
+	_, err = testDB.Exec("INSERT INTO notes (user_id, body) VALUES (3, 'ok'), (9, 'gone'), (9, 'gone too'), (NULL, 'none')")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	// users references nothing
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "CHECK fks"))
+	assert.Contains(t, buf.String(), "No references found to check")
+
+	// notes references users by the user_id convention only
+	session.CurrentTable = "notes"
+	checks, err := session.CheckReferences(ctx, "notes")
+	assert.NoError(t, err)
+	if assert.Len(t, checks, 1) {
+		assert.Equal(t, "users", checks[0].RefTable)
+		assert.Equal(t, int64(2), checks[0].Orphans)
+		assert.Len(t, checks[0].Missing, 1)
+	}
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "CHECK fks all"))
+	out := buf.String()
+	assert.Contains(t, out, "orders")
+	assert.Contains(t, out, "users.id")
+	assert.Contains(t, out, "2 orphaned rows found")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "CHECK indexes"), "unknown check")
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigBindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	content := `# noqli config
+[bind]
+F5 = !GET
+Ctrl-T = GET tables
+`
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.NoError(t, err)
+
+	config, err := pkg.LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET tables", config.Get("bind.Ctrl-T"))
+	assert.Equal(t, map[string]string{"F5": "!GET", "Ctrl-T": "GET tables"}, config.Section("bind"))
+
+	// Missing file is not an error
+	config, err = pkg.LoadConfig(filepath.Join(t.TempDir(), "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, config)
+}
+
+func TestExpandBinding(t *testing.T) {
+	history := pkg.NewCommandHistory(10)
+	history.UpdateNamespace(testDBName, testTable)
+	history.SetBindings(map[string]string{
+		"F5":     "!GET",
+		"Ctrl-T": "GET tables",
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		found    bool
+	}{
+		{name: "Plain Binding", input: "Ctrl-T", expected: "GET tables", found: true},
+		{name: "Caret Notation", input: "^t", expected: "GET tables", found: true},
+		{name: "Emacs Notation", input: "C-t", expected: "GET tables", found: true},
+		{name: "Rerun Without History", input: "F5", expected: "", found: false},
+		{name: "Unbound Key", input: "F6", expected: "", found: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expanded, ok := history.ExpandBinding(tc.input)
+			assert.Equal(t, tc.found, ok)
+			assert.Equal(t, tc.expected, expanded)
+		})
+	}
+
+	// F5 re-runs the most recent GET
+	history.AddHistory("GET {id: 1}")
+	history.AddHistory("UPDATE {id: 1, name: 'x'}")
+	history.AddHistory("get {name}")
+	history.AddHistory("DELETE {id: 2}")
+
+	expanded, ok := history.ExpandBinding("f5")
+	assert.True(t, ok)
+	assert.Equal(t, "get {name}", expanded)
+}
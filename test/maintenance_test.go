@@ -0,0 +1,31 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizeAndAnalyze(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "ANALYZE"))
+	assert.Contains(t, buf.String(), "Analyzing users (1/1)...")
+	assert.Contains(t, buf.String(), "analyze")
+	assert.Contains(t, buf.String(), "OK")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "OPTIMIZE all"))
+	assert.Contains(t, buf.String(), "Optimizing users")
+	assert.Contains(t, buf.String(), "optimize")
+
+	session.CurrentTable = ""
+	assert.ErrorIs(t, pkg.ExecuteCommand(ctx, session, "OPTIMIZE"), pkg.ErrNoTableSelected)
+}
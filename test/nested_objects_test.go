@@ -0,0 +1,43 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNestedObjects(t *testing.T) {
+	resetTable(t)
+	t.Cleanup(func() {
+		testDB.Exec("ALTER TABLE users DROP COLUMN meta")
+	})
+
+	session := testSession(true)
+
+	// The new column is created as JSON
+	err := pkg.ExecuteCommand(ctx, session, "create {name: 'Job', meta: {source: 'api', attempt: 2}}")
+	assert.NoError(t, err)
+
+	var columnType string
+	err = testDB.QueryRow("SELECT DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = 'users' AND COLUMN_NAME = 'meta'", testDBName).Scan(&columnType)
+	assert.NoError(t, err)
+	assert.Equal(t, "json", columnType)
+
+	var source string
+	var attempt int
+	err = testDB.QueryRow("SELECT meta->>'$.source', meta->'$.attempt' FROM users WHERE name = 'Job'").Scan(&source, &attempt)
+	assert.NoError(t, err)
+	assert.Equal(t, "api", source)
+	assert.Equal(t, 2, attempt)
+
+	// Updating an existing column with a nested object sets it rather than filtering on it
+	err = pkg.ExecuteCommand(ctx, session, "update {name: ['Job'], meta: {source: 'cli', tags: [a, b]}}")
+	assert.NoError(t, err)
+
+	var tags string
+	err = testDB.QueryRow("SELECT meta->>'$.source', meta->'$.tags' FROM users WHERE name = 'Job'").Scan(&source, &tags)
+	assert.NoError(t, err)
+	assert.Equal(t, "cli", source)
+	assert.JSONEq(t, `["a", "b"]`, tags)
+}
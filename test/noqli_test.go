@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -18,6 +19,7 @@ const (
 )
 
 var (
+	ctx        = context.Background()
 	testDB     *sql.DB
 	mainDB     *sql.DB
 	testDBHost string
@@ -25,20 +27,32 @@ var (
 	testDBPass string
 )
 
+// startTestServer starts a throwaway MySQL server for the tests and points
+// TEST_DB_HOST, TEST_DB_USER and TEST_DB_PASS at it. It is only set when the
+// tests are built with -tags containers.
+var startTestServer func(ctx context.Context) (stop func(), err error)
+
 func TestMain(m *testing.M) {
+	// Start a MySQL container instead of using a local server
+	stopTestServer := func() {}
+	if startTestServer != nil {
+		var err error
+		if stopTestServer, err = startTestServer(ctx); err != nil {
+			fmt.Printf("Failed to start test server: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Load test environment variables
 	loadTestEnv()
 
 	// Setup test database
 	if err := setupTestDatabase(); err != nil {
 		fmt.Printf("Failed to set up test database: %v\n", err)
+		stopTestServer()
 		os.Exit(1)
 	}
 
-	// Set the CurrentDB and CurrentTable for testing
-	pkg.CurrentDB = testDBName
-	pkg.CurrentTable = testTable
-
 	// Run tests
 	exitCode := m.Run()
 
@@ -46,6 +60,7 @@ func TestMain(m *testing.M) {
 	if err := cleanupTestDatabase(); err != nil {
 		fmt.Printf("Failed to clean up test database: %v\n", err)
 	}
+	stopTestServer()
 
 	os.Exit(exitCode)
 }
@@ -187,6 +202,15 @@ func cleanupTestDatabase() error {
 	return nil
 }
 
+// Helper function to create a session bound to the test database and table
+func testSession(useJsonOutput bool) *pkg.Session {
+	session := pkg.NewSession(testDB)
+	session.CurrentDB = testDBName
+	session.CurrentTable = testTable
+	session.JSONOutput = useJsonOutput
+	return session
+}
+
 // Helper function to reset table between tests
 func resetTable(t *testing.T) {
 	_, err := testDB.Exec("TRUNCATE TABLE users")
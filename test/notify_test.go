@@ -0,0 +1,58 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifyLongCommands(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var messages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		messages = append(messages, payload["text"])
+	}))
+	defer ts.Close()
+
+	session := testSession(true)
+	session.Out = &bytes.Buffer{}
+
+	// Every command exceeds a zero threshold
+	hook, err := pkg.NotifyHookFromConfig(pkg.Config{"notify.webhook": ts.URL, "notify.after": "0"})
+	assert.NoError(t, err)
+	session.AddAfterHook(hook)
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {id: 1}"))
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "delete {id: 999}"))
+
+	assert.Len(t, messages, 2)
+	assert.Contains(t, messages[0], "`get {id: 1}`")
+	assert.Contains(t, messages[0], "finished")
+	assert.Contains(t, messages[1], "failed")
+
+	// Commands faster than the threshold are not reported
+	messages = nil
+	quiet := testSession(true)
+	quiet.Out = &bytes.Buffer{}
+	quiet.AddAfterHook(pkg.NotifyHook(ts.URL, time.Hour))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, quiet, "get {id: 1}"))
+	assert.Empty(t, messages)
+
+	// Without a webhook there is no hook
+	hook, err = pkg.NotifyHookFromConfig(pkg.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, hook)
+
+	_, err = pkg.NotifyHookFromConfig(pkg.Config{"notify.webhook": ts.URL, "notify.after": "soon"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNullAndBooleanLiterals(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	session := testSession(true)
+
+	// null in an update sets the column to NULL
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "update {id: 1, email: null, processed: TRUE}"))
+
+	var email *string
+	var processed bool
+	err := testDB.QueryRow("SELECT email, processed FROM users WHERE id = 1").Scan(&email, &processed)
+	assert.NoError(t, err)
+	assert.Nil(t, email)
+	assert.True(t, processed)
+
+	// and matches NULL in filters
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "update {email: [null], name: 'No email'}"))
+	var name string
+	assert.NoError(t, testDB.QueryRow("SELECT name FROM users WHERE id = 1").Scan(&name))
+	assert.Equal(t, "No email", name)
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "update {email: != null, processed: false}"))
+	var count int
+	assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM users WHERE processed = 0").Scan(&count))
+	assert.Equal(t, 2, count)
+}
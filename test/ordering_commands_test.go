@@ -42,7 +42,7 @@ func TestOrderingCommands(t *testing.T) {
 		args := map[string]any{
 			"up": "name",
 		}
-		err = pkg.HandleGet(testDB, args, false)
+		err = pkg.HandleGet(ctx, testSession(false), args)
 		assert.NoError(t, err)
 
 		// The expectedNames should be: [Alice, Bob, Charlie, David, Eve]
@@ -70,7 +70,7 @@ func TestOrderingCommands(t *testing.T) {
 		args := map[string]any{
 			"down": "name",
 		}
-		err = pkg.HandleGet(testDB, args, false)
+		err = pkg.HandleGet(ctx, testSession(false), args)
 		assert.NoError(t, err)
 
 		// The expectedNames should be: [Eve, David, Charlie, Bob, Alice]
@@ -83,7 +83,7 @@ func TestOrderingCommands(t *testing.T) {
 		args := map[string]any{
 			"UP": "name",
 		}
-		err = pkg.HandleGet(testDB, args, true)
+		err = pkg.HandleGet(ctx, testSession(true), args)
 		assert.NoError(t, err)
 	})
 
@@ -91,7 +91,7 @@ func TestOrderingCommands(t *testing.T) {
 		args := map[string]any{
 			"DOWN": "name",
 		}
-		err = pkg.HandleGet(testDB, args, true)
+		err = pkg.HandleGet(ctx, testSession(true), args)
 		assert.NoError(t, err)
 	})
 
@@ -102,7 +102,7 @@ func TestOrderingCommands(t *testing.T) {
 			"name": []any{"Alice", "Bob", "Charlie"},
 			"up":   "name",
 		}
-		err = pkg.HandleGet(testDB, args, false)
+		err = pkg.HandleGet(ctx, testSession(false), args)
 		assert.NoError(t, err)
 
 		// Verify the actual results with direct query
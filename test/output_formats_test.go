@@ -1,6 +1,7 @@
 package test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/bogwi/noqli/pkg"
@@ -11,37 +12,65 @@ func TestOutputFormats(t *testing.T) {
 	resetTable(t)
 
 	// Insert a test record
-	err := pkg.HandleCreate(testDB, map[string]any{
+	err := pkg.HandleCreate(ctx, testSession(true), map[string]any{
 		"name":  "Format Test User",
 		"email": "format@example.com",
-	}, true) // JSON output
+	}) // JSON output
 	assert.NoError(t, err)
 
 	// Test JSON output (lowercase commands)
-	err = pkg.HandleGet(testDB, nil, true)
+	err = pkg.HandleGet(ctx, testSession(true), nil)
 	assert.NoError(t, err)
 
 	// Test tabular output (uppercase commands)
-	err = pkg.HandleGet(testDB, nil, false)
+	err = pkg.HandleGet(ctx, testSession(false), nil)
 	assert.NoError(t, err)
 
 	// Test update with JSON output
-	err = pkg.HandleUpdate(testDB, map[string]any{
+	err = pkg.HandleUpdate(ctx, testSession(true), map[string]any{
 		"id":   1,
 		"name": "Updated Format User",
-	}, true)
+	})
 	assert.NoError(t, err)
 
 	// Test update with tabular output
-	err = pkg.HandleUpdate(testDB, map[string]any{
+	err = pkg.HandleUpdate(ctx, testSession(false), map[string]any{
 		"id":    1,
 		"email": "updated@example.com",
-	}, false)
+	})
 	assert.NoError(t, err)
 
 	// Test delete with JSON output
-	err = pkg.HandleDelete(testDB, map[string]any{
+	err = pkg.HandleDelete(ctx, testSession(true), map[string]any{
 		"id": 1,
-	}, true)
+	})
 	assert.NoError(t, err)
 }
+
+func TestOutputCapture(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(true)
+	session.Out = &buf
+
+	// JSON output is written to the injected writer
+	err := pkg.HandleGet(ctx, session, map[string]any{"id": 1})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Record:")
+	assert.Contains(t, buf.String(), "user1@example.com")
+
+	// Tabular output is written to the injected writer
+	buf.Reset()
+	session.JSONOutput = false
+	err = pkg.HandleGet(ctx, session, map[string]any{"_columns": []string{"name"}})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "| name")
+	assert.Contains(t, buf.String(), "3 rows in set")
+
+	// Presenters accept an explicit writer
+	buf.Reset()
+	pkg.FprintTabularResults(&buf, []string{"name"}, []map[string]any{{"name": "User 1"}})
+	assert.Contains(t, buf.String(), "| User 1 |")
+}
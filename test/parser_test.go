@@ -2,6 +2,7 @@ package test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/bogwi/noqli/pkg"
 	"github.com/stretchr/testify/assert"
@@ -58,6 +59,147 @@ func TestParserFunctions(t *testing.T) {
 			},
 			isError: false,
 		},
+		{
+			name:  "Parse Nested Object",
+			input: "{name: 'Job', meta: {source: 'api', attempt: 2, tags: [a, 'b, c']}}",
+			expected: map[string]any{
+				"name": "Job",
+				"meta": map[string]any{
+					"source":  "api",
+					"attempt": 2,
+					"tags":    []any{"a", "b, c"},
+				},
+			},
+			isError: false,
+		},
+		{
+			name:  "Parse Columns And Quoted Keys",
+			input: `{name, email, "full name": 'It\'s', id: (1, 3)}`,
+			expected: map[string]any{
+				"_columns":  []string{"name", "email"},
+				"full name": "It's",
+				"id": map[string]any{
+					"range": []int{1, 3},
+				},
+			},
+			isError: false,
+		},
+		{
+			name:  "Parse Booleans And Null",
+			input: `{a: true, b: FALSE, c: null, d: NULL, e: 'null', f: [true, null], [g, h] = null, i: != null}`,
+			expected: map[string]any{
+				"a": true,
+				"b": false,
+				"c": nil,
+				"d": nil,
+				"e": "null",
+				"f": []any{true, nil},
+				"g": nil,
+				"h": nil,
+				"i": pkg.Comparison{Op: "!=", Value: nil},
+			},
+			isError: false,
+		},
+		{
+			name:  "Parse Grouped Conditions",
+			input: "{name, (status: 'new' or status: open) and not priority: low, genre: rock and roll}",
+			expected: map[string]any{
+				"_columns": []string{"name"},
+				pkg.WhereKey: pkg.BoolExpr{Op: "and", Args: []pkg.BoolExpr{
+					{Op: "or", Args: []pkg.BoolExpr{
+						{Field: "status", Value: "new"},
+						{Field: "status", Value: "open"},
+					}},
+					{Op: "not", Args: []pkg.BoolExpr{{Field: "priority", Value: "low"}}},
+				}},
+				"genre": "rock and roll",
+			},
+			isError: false,
+		},
+		{
+			name:  "Parse Or Objects",
+			input: "{or: [{status: 'active'}, {priority: 'high', and: [{a: 1}, {b: 2}]}], lim: 5}",
+			expected: map[string]any{
+				pkg.WhereKey: pkg.BoolExpr{Op: "or", Args: []pkg.BoolExpr{
+					{Op: "and", Args: []pkg.BoolExpr{{Field: "status", Value: "active"}}},
+					{Op: "and", Args: []pkg.BoolExpr{
+						{Field: "priority", Value: "high"},
+						{Op: "and", Args: []pkg.BoolExpr{
+							{Op: "and", Args: []pkg.BoolExpr{{Field: "a", Value: 1}}},
+							{Op: "and", Args: []pkg.BoolExpr{{Field: "b", Value: 2}}},
+						}},
+					}},
+				}},
+				"lim": 5,
+			},
+			isError: false,
+		},
+		{
+			name:     "Parse Or Empty Object",
+			input:    "{or: [{status: 'active'}, {}]}",
+			expected: nil,
+			isError:  true,
+		},
+		{
+			name:     "Parse Unterminated Group",
+			input:    "{(a: 1 or b: 2}",
+			expected: nil,
+			isError:  true,
+		},
+		{
+			name:  "Parse Numbers",
+			input: "{a: -42, b: 1_000_000, c: 3.25, d: -2.5e-3, e: 1E6, f: .5, g: (-1.5, 10), h: [1, 2.0], i: '7', j: 12345678901234567890, k: 1.2.3}",
+			expected: map[string]any{
+				"a": -42,
+				"b": 1000000,
+				"c": 3.25,
+				"d": -0.0025,
+				"e": 1e6,
+				"f": 0.5,
+				"g": map[string]any{
+					"range": []any{-1.5, 10},
+				},
+				"h": []any{1, 2.0},
+				"i": "7",
+				"j": "12345678901234567890",
+				"k": "1.2.3",
+			},
+			isError: false,
+		},
+		{
+			name:  "Parse Dates",
+			input: "{created_at: 2024-06-01, updated_at: (2024-06-01 08:30, 2024-06-30T18:00:00), age: >= 18}",
+			expected: map[string]any{
+				"created_at": time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local),
+				"updated_at": map[string]any{
+					"range": []any{
+						time.Date(2024, 6, 1, 8, 30, 0, 0, time.Local),
+						time.Date(2024, 6, 30, 18, 0, 0, 0, time.Local),
+					},
+				},
+				"age": pkg.Comparison{Op: ">=", Value: 18},
+			},
+			isError: false,
+		},
+		{
+			name:  "Parse Quoted Date Range",
+			input: "{created_at: ('2024-01-01', \"2024-03-31 23:59:59\")}",
+			expected: map[string]any{
+				"created_at": map[string]any{
+					"range": []any{
+						time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+						time.Date(2024, 3, 31, 23, 59, 59, 0, time.Local),
+					},
+				},
+			},
+			isError: false,
+		},
+		{
+			name:     "Parse Unterminated Nested Object",
+			input:    "{meta: {source: 'api'}",
+			expected: nil,
+			isError:  true,
+		},
 		{
 			name:     "Parse Invalid Input",
 			input:    "invalid",
@@ -79,3 +221,94 @@ func TestParserFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRelativeDate(t *testing.T) {
+	before := time.Now()
+	result, err := pkg.ParseArg("{created_at: > now() - 7d, due: < today()+1w}")
+	assert.NoError(t, err)
+
+	created, ok := result["created_at"].(pkg.Comparison)
+	assert.True(t, ok)
+	assert.Equal(t, ">", created.Op)
+	createdAt, ok := created.Value.(time.Time)
+	assert.True(t, ok)
+	assert.WithinDuration(t, before.AddDate(0, 0, -7), createdAt, time.Second)
+
+	due, ok := result["due"].(pkg.Comparison)
+	assert.True(t, ok)
+	year, month, day := before.Date()
+	assert.Equal(t, time.Date(year, month, day+7, 0, 0, 0, 0, time.Local), due.Value)
+
+	// {last: period} stays an object until it is used as a filter
+	result, err = pkg.ParseArg("{created_at: {last: '7d'}, updated_at: {LAST: '12h'}}")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"last": "7d"}, result["created_at"])
+	query, args, err := pkg.NewQueryBuilder("orders").Where(result).Select()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM orders WHERE `created_at` >= ? AND `updated_at` >= ?", query)
+	assert.Len(t, args, 2)
+	for i, want := range []time.Time{before.AddDate(0, 0, -7), before.Add(-12 * time.Hour)} {
+		got, err := time.ParseInLocation("2006-01-02 15:04:05.999999", args[i].(string), time.Local)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, want, got, time.Second)
+	}
+}
+
+func TestParseArgList(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []map[string]any
+		isError  bool
+	}{
+		{
+			name:  "Parse Two Objects",
+			input: "[{name: 'A B', age: 30}, {name: 'C}'}]",
+			expected: []map[string]any{
+				{"name": "A B", "age": 30},
+				{"name": "C}"},
+			},
+		},
+		{
+			name:     "Parse Nested Array Value",
+			input:    " [{tags: [1, 2]}] ",
+			expected: []map[string]any{{"tags": []any{1, 2}}},
+		},
+		{
+			name:  "Parse Nested Objects",
+			input: "[{meta: {a: 1}}, {meta: {b: [{c: true}]}}]",
+			expected: []map[string]any{
+				{"meta": map[string]any{"a": 1}},
+				{"meta": map[string]any{"b": []any{map[string]any{"c": true}}}},
+			},
+		},
+		{
+			name:    "Parse Empty List",
+			input:   "[]",
+			isError: true,
+		},
+		{
+			name:    "Parse Stray Value",
+			input:   "[{name: a}, b]",
+			isError: true,
+		},
+		{
+			name:    "Parse Unterminated Object",
+			input:   "[{name: a]",
+			isError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := pkg.ParseArgList(tc.input)
+
+			if tc.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessesAndKill(t *testing.T) {
+	// A connection of its own to kill
+	conn, err := testDB.Conn(ctx)
+	assert.NoError(t, err)
+	defer conn.Close()
+	var id int64
+	assert.NoError(t, conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id))
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET processes"))
+	assert.Contains(t, buf.String(), "SHOW FULL PROCESSLIST")
+	assert.Contains(t, buf.String(), fmt.Sprintf("| %d ", id))
+
+	session.Confirm = func() string { return "n" }
+	assert.ErrorIs(t, pkg.ExecuteCommand(ctx, session, fmt.Sprintf("KILL %d", id)), pkg.ErrConfirmationDeclined)
+
+	session.Confirm = func() string { return "y" }
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, fmt.Sprintf("KILL QUERY %d", id)))
+	assert.Contains(t, buf.String(), "Query OK")
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, fmt.Sprintf("KILL %d", id)))
+	var one int
+	assert.Error(t, conn.QueryRowContext(ctx, "SELECT 1").Scan(&one))
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "KILL abc"), "invalid process id")
+}
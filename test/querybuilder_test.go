@@ -0,0 +1,319 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderSelect(t *testing.T) {
+	tests := []struct {
+		name          string
+		build         func() *pkg.QueryBuilder
+		expectedQuery string
+		expectedArgs  []any
+		isError       bool
+	}{
+		{
+			name: "Select All",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users")
+			},
+			expectedQuery: "SELECT * FROM users",
+		},
+		{
+			name: "Select Columns With Equality",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").
+					Columns("name", "email").
+					Where(map[string]any{"status": "active", "id": 5})
+			},
+			expectedQuery: "SELECT `name`, `email` FROM users WHERE `id` = ? AND `status` = ?",
+			expectedArgs:  []any{5, "active"},
+		},
+		{
+			name: "IN And Range",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{
+					"id":     map[string]any{"range": []int{1, 10}},
+					"status": []any{"new", "open"},
+				})
+			},
+			expectedQuery: "SELECT * FROM users WHERE `id` >= ? AND `id` <= ? AND `status` IN (?,?)",
+			expectedArgs:  []any{1, 10, "new", "open"},
+		},
+		{
+			name: "Date Comparison And Range",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{
+					"created_at": pkg.Comparison{Op: ">", Value: time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)},
+					"updated_at": map[string]any{"range": []any{
+						time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local),
+						time.Date(2024, 6, 30, 23, 59, 59, 0, time.Local),
+					}},
+				})
+			},
+			expectedQuery: "SELECT * FROM users WHERE `created_at` > ? AND `updated_at` >= ? AND `updated_at` <= ?",
+			expectedArgs:  []any{"2024-06-01 00:00:00", "2024-06-01 00:00:00", "2024-06-30 23:59:59"},
+		},
+		{
+			name: "Float Range Keeps Floats",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{
+					"score": map[string]any{"range": []any{-0.5, 2}},
+				})
+			},
+			expectedQuery: "SELECT * FROM users WHERE `score` >= ? AND `score` <= ?",
+			expectedArgs:  []any{-0.5, 2},
+		},
+		{
+			name: "Fractional Limit",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Limit(2.5, nil)
+			},
+			isError: true,
+		},
+		{
+			name: "Null And Booleans",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{
+					"email":     nil,
+					"name":      pkg.Comparison{Op: "!=", Value: nil},
+					"processed": []any{true, nil},
+					"status":    []any{nil},
+				})
+			},
+			expectedQuery: "SELECT * FROM users WHERE `email` IS NULL AND `name` IS NOT NULL AND (`processed` IN (?) OR `processed` IS NULL) AND `status` IS NULL",
+			expectedArgs:  []any{true},
+		},
+		{
+			name: "Null With Ordering Operator",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{
+					"email": pkg.Comparison{Op: ">", Value: nil},
+				})
+			},
+			isError: true,
+		},
+		{
+			name: "Boolean Expression Precedence",
+			build: func() *pkg.QueryBuilder {
+				args, err := pkg.ParseArg("{a: 1 or b: 2 and not (c: (1, 5) or d: null), e: 'x'}")
+				if err != nil {
+					panic(err)
+				}
+				return pkg.NewQueryBuilder("users").Where(args)
+			},
+			expectedQuery: "SELECT * FROM users WHERE (`a` = ? OR (`b` = ? AND NOT ((`c` >= ? AND `c` <= ?) OR `d` IS NULL))) AND `e` = ?",
+			expectedArgs:  []any{1, 2, 1, 5, "x"},
+		},
+		{
+			name: "Operator Objects And Quoted Comparisons",
+			build: func() *pkg.QueryBuilder {
+				args, err := pkg.ParseArg("{score: {gt: 50}, age: '>= 18', rank: {LTE: 3}, owner: {ne: null}, note: '> soon'}")
+				if err != nil {
+					panic(err)
+				}
+				return pkg.NewQueryBuilder("users").Where(args)
+			},
+			expectedQuery: "SELECT * FROM users WHERE `age` >= ? AND `note` = ? AND `owner` IS NOT NULL AND `rank` <= ? AND `score` > ?",
+			expectedArgs:  []any{18, "> soon", 3, 50},
+		},
+		{
+			name: "Exclusions",
+			build: func() *pkg.QueryBuilder {
+				args, err := pkg.ParseArg("{status: {not: 'inactive'}, id: {notin: [1, 2, 3]}, tag: {not: [a, null]}, owner: {NOTIN: [null]}, kind: {notin: []}}")
+				if err != nil {
+					panic(err)
+				}
+				return pkg.NewQueryBuilder("users").Where(args)
+			},
+			expectedQuery: "SELECT * FROM users WHERE `id` NOT IN (?,?,?) AND 1=1 AND `owner` IS NOT NULL AND `status` != ? AND NOT (`tag` IN (?) OR `tag` IS NULL)",
+			expectedArgs:  []any{1, 2, 3, "inactive", "a"},
+		},
+		{
+			name: "Per-Column Like",
+			build: func() *pkg.QueryBuilder {
+				args, err := pkg.ParseArg("{name: {like: 'Smi%'}, email: {LIKE: example}}")
+				if err != nil {
+					panic(err)
+				}
+				return pkg.NewQueryBuilder("users").Where(args)
+			},
+			expectedQuery: "SELECT * FROM users WHERE `email` LIKE ? AND `name` LIKE ?",
+			expectedArgs:  []any{"%example%", "Smi%"},
+		},
+		{
+			name: "Distinct",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Columns("status").Distinct(true).OrderBy("status", false)
+			},
+			expectedQuery: "SELECT DISTINCT `status` FROM users ORDER BY `status` ASC",
+		},
+		{
+			name: "Null Operators",
+			build: func() *pkg.QueryBuilder {
+				args, err := pkg.ParseArg("{email: null, phone: {notnull: true}, fax: {NOTNULL: false}}")
+				if err != nil {
+					panic(err)
+				}
+				return pkg.NewQueryBuilder("users").Where(args)
+			},
+			expectedQuery: "SELECT * FROM users WHERE `email` IS NULL AND `fax` IS NULL AND `phone` IS NOT NULL",
+		},
+		{
+			name: "Regexp",
+			build: func() *pkg.QueryBuilder {
+				args, err := pkg.ParseArg("{name: {regexp: '^A.*son$'}, email: {REGEXP: 'example[.]com$', flags: 'i'}}")
+				if err != nil {
+					panic(err)
+				}
+				return pkg.NewQueryBuilder("users").Where(args)
+			},
+			expectedQuery: "SELECT * FROM users WHERE REGEXP_LIKE(`email`, ?, ?) AND `name` REGEXP ?",
+			expectedArgs:  []any{"example[.]com$", "i", "^A.*son$"},
+		},
+		{
+			name: "Regexp Invalid Flag",
+			build: func() *pkg.QueryBuilder {
+				args, err := pkg.ParseArg("{name: {regexp: '^A', flags: 'x'}}")
+				if err != nil {
+					panic(err)
+				}
+				return pkg.NewQueryBuilder("users").Where(args)
+			},
+			isError: true,
+		},
+		{
+			name: "Empty Array Matches Nothing",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{"id": []any{}})
+			},
+			expectedQuery: "SELECT * FROM users WHERE 0=1",
+		},
+		{
+			name: "Like Order Limit Offset",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").
+					WhereLike("john", []string{"name", "email"}).
+					OrderBy("name", true).
+					Limit(10, 20)
+			},
+			expectedQuery: "SELECT * FROM users WHERE (`name` LIKE ? OR `email` LIKE ?) ORDER BY `name` DESC LIMIT ? OFFSET ?",
+			expectedArgs:  []any{"%john%", "%john%", 10, 20},
+		},
+		{
+			name: "Offset Without Limit Is Ignored",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Limit(nil, 5)
+			},
+			expectedQuery: "SELECT * FROM users",
+		},
+		{
+			name: "Negative Limit",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Limit(-1, nil)
+			},
+			isError: true,
+		},
+		{
+			name: "Fuzzy Match",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{
+					"name": map[string]any{"fuzzy": "Jonson"},
+				})
+			},
+			expectedQuery: "SELECT * FROM users WHERE (SOUNDEX(`name`) = SOUNDEX(?) OR " +
+				"(LOWER(`name`) LIKE ?) + (LOWER(`name`) LIKE ?) + (LOWER(`name`) LIKE ?) + (LOWER(`name`) LIKE ?) >= ?)",
+			expectedArgs: []any{"Jonson", "%jon%", "%ons%", "%nso%", "%son%", 2},
+		},
+		{
+			name: "Fuzzy Match Of A Short Term",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{
+					"code": map[string]any{"fuzzy": "a_"},
+				})
+			},
+			expectedQuery: "SELECT * FROM users WHERE (SOUNDEX(`code`) = SOUNDEX(?) OR `code` LIKE ?)",
+			expectedArgs:  []any{"a_", `%a\_%`},
+		},
+		{
+			name: "Case Sensitive Matching",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").
+					MatchCase(pkg.CaseSensitive).
+					Where(map[string]any{"name": "Ann", "id": 5, "status": []any{"new", nil}}).
+					WhereLike("jo", []string{"email"})
+			},
+			expectedQuery: "SELECT * FROM users WHERE `id` = ? AND CAST(`name` AS BINARY) = ? AND " +
+				"(CAST(`status` AS BINARY) IN (?) OR `status` IS NULL) AND (CAST(`email` AS BINARY) LIKE ?)",
+			expectedArgs: []any{5, "Ann", "new", "%jo%"},
+		},
+		{
+			name: "Case Insensitive Matching",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").
+					MatchCase(pkg.CaseInsensitive).
+					Where(map[string]any{"name": "ann"})
+			},
+			expectedQuery: "SELECT * FROM users WHERE CONVERT(`name` USING utf8mb4) COLLATE utf8mb4_general_ci = ?",
+			expectedArgs:  []any{"ann"},
+		},
+		{
+			name: "Invalid Match Case",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").MatchCase("upper")
+			},
+			isError: true,
+		},
+		{
+			name: "Invalid Range",
+			build: func() *pkg.QueryBuilder {
+				return pkg.NewQueryBuilder("users").Where(map[string]any{
+					"id": map[string]any{"range": []int{1}},
+				})
+			},
+			isError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, args, err := tc.build().Select()
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedQuery, query)
+			assert.Equal(t, tc.expectedArgs, args)
+		})
+	}
+}
+
+func TestQueryBuilderUpdateDelete(t *testing.T) {
+	query, args, err := pkg.NewQueryBuilder("users").
+		Set(map[string]any{"name": "Jane", "email": "jane@example.com"}).
+		Where(map[string]any{"id": []any{1, 2}}).
+		Update()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET `email` = ?, `name` = ? WHERE `id` IN (?,?)", query)
+	assert.Equal(t, []any{"jane@example.com", "Jane", 1, 2}, args)
+
+	// UPDATE without assignments is rejected
+	_, _, err = pkg.NewQueryBuilder("users").Where(map[string]any{"id": 1}).Update()
+	assert.Error(t, err)
+
+	query, args, err = pkg.NewQueryBuilder("users").
+		Where(map[string]any{"id": 3}).
+		Delete()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE `id` = ?", query)
+	assert.Equal(t, []any{3}, args)
+
+	// DELETE without a filter is rejected
+	_, _, err = pkg.NewQueryBuilder("users").Delete()
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// createOrderTables creates orders, referencing users by a foreign key, and
+// notes, referencing them only by the user_id naming convention. users cannot
+// be truncated until the tables are dropped.
+func createOrderTables(t *testing.T) {
+	_, err := testDB.Exec("DROP TABLE IF EXISTS orders, notes")
+	assert.NoError(t, err)
+	_, err = testDB.Exec(`CREATE TABLE orders (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		buyer INT,
+		total DECIMAL(10,2),
+		FOREIGN KEY (buyer) REFERENCES users (id) ON DELETE CASCADE
+	)`)
+	assert.NoError(t, err)
+	_, err = testDB.Exec("CREATE TABLE notes (id INT AUTO_INCREMENT PRIMARY KEY, user_id INT, body TEXT)")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		testDB.Exec("DROP TABLE IF EXISTS orders, notes")
+	})
+}
+
+func TestGetRelated(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+	createOrderTables(t)
+	_, err := testDB.Exec("INSERT INTO orders (buyer, total) VALUES (1, 10.50), (2, 20.00), (2, 30.00)")
+	assert.NoError(t, err)
+	_, err = testDB.Exec("INSERT INTO notes (user_id, body) VALUES (3, 'call back')")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	// The foreign key is followed from users to orders
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET 2 -> orders"))
+	assert.Contains(t, buf.String(), "2 rows in set")
+	assert.Contains(t, buf.String(), "30.00")
+	assert.NotContains(t, buf.String(), "10.50")
+
+	// and back from orders to users
+	session.CurrentTable = "orders"
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {total: > 15} -> users"))
+	assert.Contains(t, buf.String(), "user2@example.com")
+	assert.Contains(t, buf.String(), "1 rows in set")
+
+	// Without a constraint the user_id column links notes to users
+	session.CurrentTable = testTable
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: 'User 3'} -> notes"))
+	assert.Contains(t, buf.String(), "call back")
+
+	// A custom convention
+	session.Config = pkg.Config{"foreign_key": "{table}_ref"}
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET 3 -> notes"), "users_ref")
+}
+
+func TestGetRelations(t *testing.T) {
+	resetTable(t)
+	createOrderTables(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET relations"))
+	assert.Contains(t, buf.String(), "referenced by")
+	assert.Contains(t, buf.String(), "orders.buyer")
+	assert.Contains(t, buf.String(), "users.id")
+
+	session.CurrentTable = "orders"
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET relations"))
+	assert.Contains(t, buf.String(), "references")
+	assert.NotContains(t, buf.String(), "referenced by")
+
+	// Relations by naming convention only are not constraints
+	session.CurrentTable = "notes"
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET relations"))
+	assert.Contains(t, buf.String(), "No relations found for table 'notes'")
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/bogwi/noqli/pkg/remote"
+	"github.com/bogwi/noqli/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteClient(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	srv := server.New(testDB, testDBName)
+	srv.Token = "secret"
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	client := remote.NewClient(ts.URL)
+	client.Token = "secret"
+
+	var buf bytes.Buffer
+	session := pkg.NewSession(nil)
+	session.Out = &buf
+
+	run := func(line string) error {
+		handled, err := client.Intercept(ctx, session, line)
+		assert.True(t, handled)
+		return err
+	}
+
+	// The selection made on the server is tracked locally
+	assert.NoError(t, run("USE "+testTable))
+	assert.Equal(t, testDBName, session.CurrentDB)
+	assert.Equal(t, testTable, session.CurrentTable)
+	assert.Contains(t, buf.String(), "Using table 'users'")
+
+	buf.Reset()
+	assert.NoError(t, run("GET {id: 2}"))
+	assert.Contains(t, buf.String(), "user2@example.com")
+
+	// Server errors are returned
+	err := run("delete {id: 999}")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no records found")
+
+	// Confirmation prompts are answered locally
+	originalScan := pkg.ScanForConfirmation
+	defer func() { pkg.ScanForConfirmation = originalScan }()
+
+	buf.Reset()
+	pkg.ScanForConfirmation = func() string { return "n" }
+	err = run("update {status: archived}")
+	assert.ErrorIs(t, err, pkg.ErrConfirmationDeclined)
+	assert.Contains(t, buf.String(), "Do you want to continue?")
+
+	buf.Reset()
+	pkg.ScanForConfirmation = func() string { return "y" }
+	assert.NoError(t, run("update {status: archived}"))
+	assert.Contains(t, buf.String(), "Updated 3 record(s)")
+}
+
+func TestRemoteClientRefused(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	srv := server.New(testDB, testDBName)
+	srv.Token = "secret"
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	exec := func(token, contentType string, req server.ExecRequest) (int, server.ExecResponse) {
+		body, _ := json.Marshal(req)
+		httpReq, _ := http.NewRequest(http.MethodPost, ts.URL+"/exec", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", contentType)
+		if token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(httpReq)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var result server.ExecResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+	get := server.ExecRequest{Table: testTable, Line: "GET {id: 1}"}
+
+	// The token is required
+	status, _ := exec("", "application/json", get)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = exec("wrong", "application/json", get)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// So is a JSON body, which pages on other sites can't send
+	status, _ = exec("secret", "text/plain", get)
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+
+	status, result := exec("secret", "application/json; charset=utf-8", get)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, result.Output, "user1@example.com")
+
+	// Tables are checked before the command runs
+	_, result = exec("secret", "application/json", server.ExecRequest{Table: "users WHERE 1=0 UNION SELECT user()", Line: "GET"})
+	assert.Contains(t, result.Error, "does not exist")
+
+	// Interactive and file commands are refused
+	for _, line := range []string{"EDIT 1", "watch GET {id: 1}", "RECORD session.txt", "FIXTURES load fixtures", "BENCH 2 EDIT 1"} {
+		_, result = exec("secret", "application/json", server.ExecRequest{Table: testTable, Line: line})
+		assert.Contains(t, result.Error, "can't be run on a noqli server", line)
+	}
+
+	// A server without a token refuses every command
+	ts.Config.Handler = server.New(testDB, testDBName).Handler()
+	status, _ = exec("", "application/json", get)
+	assert.Equal(t, http.StatusForbidden, status)
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/bogwi/noqli/pkg/repl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplRun(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	session := pkg.NewSession(testDB)
+	session.CurrentDB = testDBName
+
+	var out bytes.Buffer
+	input := strings.Join([]string{
+		"USE " + testTable,
+		"get {id: 2}",
+		"bogus command",
+		"EXIT",
+		"get {id: 3}", // never reached
+	}, "\n")
+
+	err := repl.Run(ctx, session, repl.IO{In: strings.NewReader(input), Out: &out}, nil)
+	assert.NoError(t, err)
+
+	output := out.String()
+	assert.Contains(t, output, "noqli:"+testDBName+"> ")
+	assert.Contains(t, output, "Using table '"+testTable+"'")
+	assert.Contains(t, output, "user2@example.com")
+	assert.Contains(t, output, "Error: invalid command")
+	assert.NotContains(t, output, "user3@example.com")
+	assert.Equal(t, testTable, session.CurrentTable)
+}
+
+func TestReplHooks(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var out bytes.Buffer
+	var intercepted []string
+
+	opts := &repl.Options{
+		Prompt: func(s *pkg.Session) string { return "custom> " },
+		Intercept: func(ctx context.Context, s *pkg.Session, line string) (bool, error) {
+			intercepted = append(intercepted, line)
+			if line == "PING" {
+				s.Out.Write([]byte("PONG\n"))
+				return true, nil
+			}
+			return false, nil
+		},
+	}
+
+	input := "PING\nget {id: 1}\n"
+	err := repl.Run(ctx, testSession(true), repl.IO{In: strings.NewReader(input), Out: &out}, opts)
+	assert.NoError(t, err)
+
+	output := out.String()
+	assert.Contains(t, output, "custom> ")
+	assert.Contains(t, output, "PONG")
+	assert.Contains(t, output, "user1@example.com")
+	assert.Contains(t, output, "EOF")
+	assert.Equal(t, []string{"PING", "get {id: 1}"}, intercepted)
+}
+
+func TestReplMultipleStatements(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	session := pkg.NewSession(testDB)
+	session.CurrentDB = testDBName
+
+	var out bytes.Buffer
+	input := strings.Join([]string{
+		"USE " + testTable + "; get {name: 'a;b'}; get {id: 2};",
+		"get {id: 1}; bogus; get {id: 3}", // stops at the error
+		"get {lim: 0}; EXIT; get {id: 3}",
+	}, "\n")
+
+	err := repl.Run(ctx, session, repl.IO{In: strings.NewReader(input), Out: &out}, nil)
+	assert.NoError(t, err)
+
+	output := out.String()
+	assert.Contains(t, output, "Using table '"+testTable+"'")
+	assert.Contains(t, output, "user2@example.com")
+	assert.Contains(t, output, "user1@example.com")
+	assert.Contains(t, output, "Error: invalid command")
+	assert.NotContains(t, output, "user3@example.com")
+	assert.NotContains(t, output, "EOF")
+}
+
+func TestSplitStatements(t *testing.T) {
+	assert.Equal(t,
+		[]string{"USE app", "USE users", "GET {name: 'x;y', tags: [a; b]}", `ASK "a; b"`},
+		pkg.SplitStatements(` USE app; USE users;; GET {name: 'x;y', tags: [a; b]} ; ASK "a; b";`))
+	assert.Empty(t, pkg.SplitStatements(" ; "))
+}
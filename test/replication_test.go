@@ -0,0 +1,30 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReplication(t *testing.T) {
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET replication"))
+	assert.Contains(t, buf.String(), "Role")
+	assert.Contains(t, buf.String(), "Connected replicas")
+
+	status, err := session.Replication(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"standalone", "primary", "replica", "primary and replica"}, status.Role)
+	assert.Equal(t, status.Role == "replica" || status.Role == "primary and replica", len(status.Channels) > 0)
+
+	buf.Reset()
+	session.JSONOutput = true
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get replication"))
+	assert.Contains(t, buf.String(), "Replication:")
+	assert.Contains(t, buf.String(), "channels")
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportOrphans(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+	createOrderTables(t)
+	_, err := testDB.Exec("INSERT INTO orders (buyer, total) VALUES (1, 5)")
+	assert.NoError(t, err)
+	_, err = testDB.Exec("INSERT INTO notes (user_id, body) VALUES (3, 'ok'), (9, 'gone')")
+	assert.NoError(t, err)
+
+	session := testSession(false)
+	report, err := session.OrphanReport(ctx, true)
+	assert.NoError(t, err)
+
+	var orphans int64
+	for _, check := range report.Orphans {
+		orphans += check.Orphans
+	}
+	assert.Equal(t, int64(1), orphans)
+	if assert.Len(t, report.Unused, 1) {
+		// User 2 has neither orders nor notes
+		assert.Equal(t, "users", report.Unused[0].Table)
+		assert.Equal(t, int64(1), report.Unused[0].Unused)
+		assert.ElementsMatch(t, []string{"orders.buyer", "notes.user_id"}, report.Unused[0].ReferencedBy)
+	}
+	if assert.Len(t, report.Cleanup, 2) {
+		assert.True(t, strings.HasPrefix(report.Cleanup[0], "DELETE c FROM `notes`"))
+		assert.True(t, strings.HasPrefix(report.Cleanup[1], "DELETE p FROM `users`"))
+
+		// The statements are printed for review, not run
+		var count int
+		assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count))
+		assert.Equal(t, 2, count)
+
+		_, err = testDB.Exec(report.Cleanup[0])
+		assert.NoError(t, err)
+		assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count))
+		assert.Equal(t, 1, count)
+	}
+
+	var buf bytes.Buffer
+	session.Out = &buf
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "REPORT orphans"))
+	assert.Contains(t, buf.String(), "Rows nothing refers to:")
+	assert.NotContains(t, buf.String(), "Cleanup statements")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "REPORT sizes"), "unknown report")
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryIterator(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	session := testSession(true)
+
+	// Iterate as maps
+	args := map[string]any{"id": map[string]any{"range": []int{1, 2}}, "up": "id"}
+	rows, err := session.Query(ctx, args)
+	assert.NoError(t, err)
+
+	var emails []string
+	for rows.Next() {
+		record, err := rows.Map()
+		assert.NoError(t, err)
+		emails = append(emails, record["email"].(string))
+	}
+	assert.NoError(t, rows.Err())
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, []string{"user1@example.com", "user2@example.com"}, emails)
+
+	// The arguments are left untouched
+	assert.Contains(t, args, "up")
+
+	// Iterate into structs
+	type user struct {
+		ID      int64
+		Name    string
+		Address string `db:"email"`
+	}
+	rows, err = session.Query(ctx, map[string]any{"down": "id", "lim": 2})
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	var users []user
+	for rows.Next() {
+		var u user
+		assert.NoError(t, rows.Scan(&u))
+		users = append(users, u)
+	}
+	assert.NoError(t, rows.Err())
+	assert.Equal(t, []user{
+		{ID: 3, Name: "User 3", Address: "user3@example.com"},
+		{ID: 2, Name: "User 2", Address: "user2@example.com"},
+	}, users)
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSample(t *testing.T) {
+	resetTable(t)
+	values := make([]string, 200)
+	for i := range values {
+		status := "active"
+		if i%2 == 1 {
+			status = "inactive"
+		}
+		values[i] = fmt.Sprintf("('User %d', '%s')", i+1, status)
+	}
+	_, err := testDB.Exec("INSERT INTO users (name, status) VALUES " + strings.Join(values, ", "))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	// Few rows of a large key range are drawn by primary key
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {sample: 5}"))
+	assert.Contains(t, buf.String(), "5 rows in set")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {SAMPLE: 10, status: 'inactive'}"))
+	assert.Contains(t, buf.String(), "10 rows in set")
+	assert.NotContains(t, buf.String(), "| active")
+
+	// Large samples fall back to ORDER BY RAND()
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {sample: 150}"))
+	assert.Contains(t, buf.String(), "150 rows in set")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {sample: 500, status: 'active'}"))
+	assert.Contains(t, buf.String(), "100 rows in set")
+
+	// A seed gives the same sample on every run, by key and by ORDER BY RAND(n)
+	for _, command := range []string{"GET {sample: 5, seed: 42}", "GET {sample: 150, seed: 7}"} {
+		buf.Reset()
+		assert.NoError(t, pkg.ExecuteCommand(ctx, session, command))
+		first := timingPattern.ReplaceAllString(buf.String(), "")
+		buf.Reset()
+		assert.NoError(t, pkg.ExecuteCommand(ctx, session, command))
+		assert.Equal(t, first, timingPattern.ReplaceAllString(buf.String(), ""), command)
+	}
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {sample: 0}"), "positive integer")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET {seed: 42}"), "seed requires sample")
+}
+
+// timingPattern matches the timing of a row count line
+var timingPattern = regexp.MustCompile(` \(\d+\.\d+ sec[^)]*\)`)
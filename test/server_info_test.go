@@ -0,0 +1,36 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerStatusAndVariables(t *testing.T) {
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET status"))
+	assert.Contains(t, buf.String(), "Threads_connected")
+	assert.Contains(t, buf.String(), "Uptime")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET variables"))
+	assert.Contains(t, buf.String(), "innodb_buffer_pool_size")
+	assert.Contains(t, buf.String(), "MiB")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET variables LIKE 'max_conn%'"))
+	assert.Contains(t, buf.String(), "max_connections")
+	assert.NotContains(t, buf.String(), "innodb_buffer_pool_size")
+
+	values, err := session.ServerValues(ctx, "status", "Threads_%")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, values)
+	for _, row := range values {
+		assert.Regexp(t, "^Threads_", row["Variable_name"])
+	}
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bogwi/noqli/pkg/server"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerQuery(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	ts := httptest.NewServer(server.New(testDB, testDBName).Handler())
+	defer ts.Close()
+
+	body, _ := json.Marshal(server.QueryRequest{Table: testTable, Args: "{id: [1, 3]}"})
+	resp, err := http.Post(ts.URL+"/query", "application/json", bytes.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result server.QueryResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Empty(t, result.Error)
+	assert.Len(t, result.Records, 2)
+}
+
+func TestServerQueryUnknownTable(t *testing.T) {
+	resetTable(t)
+
+	ts := httptest.NewServer(server.New(testDB, testDBName).Handler())
+	defer ts.Close()
+
+	// Table names are checked against the database before reaching SQL
+	body, _ := json.Marshal(server.QueryRequest{Table: "users WHERE 1=0 UNION SELECT user()"})
+	resp, err := http.Post(ts.URL+"/query", "application/json", bytes.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result server.QueryResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Contains(t, result.Error, "does not exist")
+	assert.Empty(t, result.Records)
+}
+
+func TestServerLiveQuery(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	srv := server.New(testDB, testDBName)
+	srv.PollInterval = 50 * time.Millisecond
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/live", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	assert.NoError(t, conn.WriteJSON(server.QueryRequest{Table: testTable}))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// Initial results
+	var result server.QueryResponse
+	assert.NoError(t, conn.ReadJSON(&result))
+	assert.Empty(t, result.Error)
+	assert.Len(t, result.Records, 3)
+
+	// A change to the table pushes refreshed results
+	_, err = testDB.Exec("INSERT INTO users (name, email) VALUES ('User 4', 'user4@example.com')")
+	assert.NoError(t, err)
+
+	assert.NoError(t, conn.ReadJSON(&result))
+	assert.Empty(t, result.Error)
+	assert.Len(t, result.Records, 4)
+}
+
+func TestServerWebUI(t *testing.T) {
+	resetTable(t)
+
+	ts := httptest.NewServer(server.New(testDB, testDBName).Handler())
+	defer ts.Close()
+
+	// The UI page is served at the root
+	resp, err := http.Get(ts.URL + "/")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+
+	// The table browser lists the database's tables
+	resp, err = http.Get(ts.URL + "/tables")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var tables server.TablesResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&tables))
+	assert.Contains(t, tables.Tables, testTable)
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionPrompt(t *testing.T) {
+	session := pkg.NewSession(testDB)
+	assert.Equal(t, "noqli> ", session.DisplayPrompt())
+
+	session.CurrentDB = testDBName
+	assert.Equal(t, "noqli:"+testDBName+"> ", session.DisplayPrompt())
+
+	session.CurrentTable = testTable
+	assert.Equal(t, "noqli:"+testDBName+":"+testTable+"> ", session.DisplayPrompt())
+}
+
+func TestIndependentSessions(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	// Two sessions on the same handle keep their own selection and output
+	var jsonBuf, tableBuf bytes.Buffer
+	jsonSession := testSession(true)
+	jsonSession.Out = &jsonBuf
+	tableSession := testSession(false)
+	tableSession.Out = &tableBuf
+
+	err := pkg.HandleGet(ctx, jsonSession, map[string]any{"id": 2})
+	assert.NoError(t, err)
+	err = pkg.HandleGet(ctx, tableSession, map[string]any{"id": 3})
+	assert.NoError(t, err)
+
+	assert.Contains(t, jsonBuf.String(), "user2@example.com")
+	assert.NotContains(t, jsonBuf.String(), "user3@example.com")
+	assert.Contains(t, tableBuf.String(), "user3@example.com")
+	assert.Contains(t, tableBuf.String(), "1 rows in set")
+
+	// A session without a table selected is rejected
+	empty := pkg.NewSession(testDB)
+	err = pkg.HandleGet(ctx, empty, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSizes(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	session := testSession(false)
+	sizes, err := session.TableSizes(ctx)
+	assert.NoError(t, err)
+	found := false
+	for i, size := range sizes {
+		if i > 0 {
+			assert.LessOrEqual(t, size.Data+size.Indexes, sizes[i-1].Data+sizes[i-1].Indexes)
+		}
+		if size.Table == testTable {
+			found = true
+			assert.Greater(t, size.Data, int64(0))
+		}
+	}
+	assert.True(t, found)
+
+	var buf bytes.Buffer
+	session.Out = &buf
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET sizes"))
+	assert.Contains(t, buf.String(), "Indexes")
+	assert.Contains(t, buf.String(), "KiB")
+	assert.Contains(t, buf.String(), "Database '"+testDBName+"' uses")
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSlowlog(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: 'User 1'}"))
+	}
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET slowlog {lim: 50, min_time: 0}"))
+	assert.Contains(t, buf.String(), "Rows examined")
+
+	queries, err := session.SlowQueries(ctx, 1000, 0)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, queries)
+	for _, q := range queries {
+		assert.NotContains(t, q.Query, "User 1")
+	}
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get slowlog {lim: 1, min_time: 0}"))
+	assert.Contains(t, buf.String(), "Slow queries:")
+
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "GET slowlog {lim: 0}"))
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "GET slowlog {unknown: 1}"))
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowQueryLog(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	logPath := filepath.Join(t.TempDir(), "slow.log")
+
+	var buf bytes.Buffer
+	session := testSession(true)
+	session.Out = &buf
+
+	// Every command is slower than a zero threshold
+	hook, err := pkg.SlowLogHookFromConfig(pkg.Config{"slow_query_time": "0", "slow_query_log": logPath})
+	assert.NoError(t, err)
+	session.AddAfterHook(hook)
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {id: 2}"))
+	assert.Contains(t, buf.String(), "Slow query: took")
+	assert.Contains(t, buf.String(), logPath)
+
+	content, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "# Command: get {id: 2}")
+	assert.Contains(t, string(content), "SELECT * FROM users WHERE `id` = ?; -- [2]")
+	assert.Contains(t, string(content), "Rows: 1")
+
+	// Without a threshold there is no hook
+	hook, err = pkg.SlowLogHookFromConfig(pkg.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, hook)
+
+	_, err = pkg.SlowLogHookFromConfig(pkg.Config{"slow_query_time": "slow"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	before := &pkg.Snapshot{Rows: []map[string]any{
+		{"id": json.Number("1"), "status": "open"},
+		{"id": json.Number("2"), "status": "open"},
+		{"id": json.Number("3"), "status": "closed"},
+	}}
+	after := &pkg.Snapshot{Rows: []map[string]any{
+		{"id": json.Number("1"), "status": "open"},
+		{"id": json.Number("2"), "status": "closed"},
+		{"id": json.Number("4"), "status": "new"},
+	}}
+
+	diff := pkg.DiffSnapshots(before, after)
+	assert.Equal(t, []map[string]any{{"id": json.Number("4"), "status": "new"}}, diff.Added)
+	assert.Equal(t, []map[string]any{{"id": json.Number("3"), "status": "closed"}}, diff.Removed)
+	if assert.Len(t, diff.Changed, 1) {
+		assert.Equal(t, json.Number("2"), diff.Changed[0].Key)
+		assert.Equal(t, "open", diff.Changed[0].Before["status"])
+		assert.Equal(t, "closed", diff.Changed[0].After["status"])
+	}
+
+	// Rows without an id are matched by their values
+	diff = pkg.DiffSnapshots(&pkg.Snapshot{Rows: []map[string]any{{"name": "a"}}}, &pkg.Snapshot{Rows: []map[string]any{{"name": "b"}}})
+	assert.Len(t, diff.Added, 1)
+	assert.Len(t, diff.Removed, 1)
+	assert.Empty(t, diff.Changed)
+}
+
+func TestSnapshotCommands(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+	session.SnapshotDir = t.TempDir()
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "SNAPSHOT show before"), "unknown snapshot")
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {}"))
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SNAPSHOT save before"))
+	assert.Contains(t, buf.String(), "Snapshot 'before' saved: 3 rows")
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {id: 2, name: 'Fixed'}"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {id: 3}"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "CREATE {name: 'User 4', email: 'user4@example.com'}"))
+
+	// Comparing against the current state reruns the saved GET
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SNAPSHOT diff before"))
+	assert.Contains(t, buf.String(), "1 added, 1 removed, 1 changed")
+	assert.Contains(t, buf.String(), `name: "User 2" -> "Fixed"`)
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {}"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SNAPSHOT save after"))
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "snapshot diff before after"))
+	assert.Contains(t, buf.String(), `"changed"`)
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SNAPSHOT list"))
+	assert.Contains(t, buf.String(), "before")
+	assert.Contains(t, buf.String(), "after")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SNAPSHOT show before"))
+	assert.Contains(t, buf.String(), "user3@example.com")
+
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "SNAPSHOT drop before"))
+}
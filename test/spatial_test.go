@@ -0,0 +1,57 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSpatialLiterals(t *testing.T) {
+	args, err := pkg.ParseArg("{location: point(10.75, 59.91), near: within(point(-0.12, 51.5), 2.5km), far: within(point(0, 0), 300)}")
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.Point{X: 10.75, Y: 59.91}, args["location"])
+	assert.Equal(t, pkg.Distance{From: pkg.Point{X: -0.12, Y: 51.5}, Meters: 2500}, args["near"])
+	assert.Equal(t, pkg.Distance{From: pkg.Point{X: 0, Y: 0}, Meters: 300}, args["far"])
+
+	query, params, err := pkg.NewQueryBuilder("places").Where(map[string]any{"location": args["near"]}).Select()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM places WHERE ST_Distance_Sphere(`location`, ST_GeomFromText(?)) <= ?", query)
+	assert.Equal(t, []any{"POINT(-0.12 51.5)", 2500.0}, params)
+}
+
+func TestSpatialColumns(t *testing.T) {
+	resetTable(t)
+	t.Cleanup(func() {
+		testDB.Exec("ALTER TABLE users DROP COLUMN location")
+	})
+
+	var buf bytes.Buffer
+	session := testSession(true)
+	session.Out = &buf
+
+	// The new column is created as POINT
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "create {name: 'Oslo', location: point(10.75, 59.91)}"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "create {name: 'Bergen', location: point(5.32, 60.39)}"))
+
+	var columnType string
+	err := testDB.QueryRow("SELECT DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = 'users' AND COLUMN_NAME = 'location'", testDBName).Scan(&columnType)
+	assert.NoError(t, err)
+	assert.Equal(t, "point", columnType)
+
+	// Values are shown as WKT
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {name: 'Oslo'}"))
+	assert.Contains(t, buf.String(), "POINT(10.75 59.91)")
+
+	// Oslo and Bergen are about 300 km apart
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {location: within(point(10.7, 59.9), 50km)}"))
+	assert.Contains(t, buf.String(), "Oslo")
+	assert.NotContains(t, buf.String(), "Bergen")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {location: within(point(10.7, 59.9), 400km)}"))
+	assert.Contains(t, buf.String(), "Bergen")
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableStats(t *testing.T) {
+	resetTable(t)
+	_, err := testDB.Exec(`
+		INSERT INTO users (name, status, numeric_value) VALUES
+		('A', 'open', 10),
+		('B', 'open', 20),
+		('C', 'closed', 30),
+		('D', NULL, NULL)
+	`)
+	assert.NoError(t, err)
+
+	session := testSession(true)
+	stats, err := session.TableStats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), stats.Rows)
+
+	byName := make(map[string]pkg.ColumnStats)
+	for _, cs := range stats.Columns {
+		byName[cs.Column] = cs
+	}
+
+	status := byName["status"]
+	assert.Equal(t, int64(1), status.Nulls)
+	assert.Equal(t, int64(2), status.Distinct)
+	assert.Equal(t, "closed", status.Min)
+	assert.Equal(t, "open", status.Max)
+	assert.Nil(t, status.Avg)
+	assert.Equal(t, pkg.ValueCount{Value: "open", Count: 2}, status.Top[0])
+
+	value := byName["numeric_value"]
+	assert.Equal(t, int64(1), value.Nulls)
+	assert.Equal(t, pkg.Decimal("20.0000"), value.Avg)
+
+	// STATS and GET stats print the report
+	var buf bytes.Buffer
+	session.Out = &buf
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "STATS"))
+	assert.Contains(t, buf.String(), "Table 'users': 4 rows")
+	assert.Contains(t, buf.String(), "open (2), NULL (1), closed (1)")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get stats"))
+	assert.Contains(t, buf.String(), "Stats:")
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableWriter(t *testing.T) {
+	columns := []string{"id", "name"}
+	rows := []map[string]any{
+		{"id": 1, "name": "Al"},
+		{"id": 2, "name": "Bo"},
+		{"id": 3, "name": "Christopher"},
+	}
+
+	// Buffering all rows matches the regular tabular output
+	var all, expected bytes.Buffer
+	pkg.FprintTabularResults(&expected, columns, rows)
+	table := pkg.NewTableWriter(&all, columns, 0)
+	for _, row := range rows {
+		table.Write(row)
+	}
+	table.Close()
+	assert.Equal(t, expected.String(), all.String())
+	assert.Contains(t, all.String(), "| 3  | Christopher |")
+
+	// Sampling two rows streams the third with the sampled widths
+	var sampled bytes.Buffer
+	table = pkg.NewTableWriter(&sampled, columns, 2)
+	table.Write(rows[0])
+	table.Write(rows[1])
+	assert.Contains(t, sampled.String(), "| 2  | Bo   |", "sampled rows are written once the sample is full")
+	table.Write(rows[2])
+	table.Close()
+	assert.Contains(t, sampled.String(), "| 3  | Christopher |")
+	assert.Contains(t, sampled.String(), "+----+------+")
+	assert.Contains(t, sampled.String(), "3 rows in set")
+
+	// Nothing is written without rows
+	var empty bytes.Buffer
+	pkg.NewTableWriter(&empty, columns, 2).Close()
+	assert.Empty(t, empty.String())
+}
+
+func TestStreamingTabularGet(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+	session.Config = pkg.Config{"sample_rows": "1"}
+
+	err := pkg.HandleGet(ctx, session, map[string]any{"_columns": []any{"id", "email"}})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "| 1  | user1@example.com |")
+	assert.Contains(t, buf.String(), "3 rows in set")
+
+	buf.Reset()
+	err = pkg.HandleGet(ctx, session, map[string]any{"id": 999})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "No records found")
+}
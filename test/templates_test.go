@@ -0,0 +1,75 @@
+package test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	session := pkg.NewSession(nil)
+	assert.NoError(t, session.SaveTemplate("by_status", "GET {status: $1, lim: $2, note: 'costs $1'}"))
+
+	command, err := session.ExpandTemplate("by_status", []string{"'active'", "20"})
+	assert.NoError(t, err)
+	assert.Equal(t, "GET {status: 'active', lim: 20, note: 'costs $1'}", command)
+
+	_, err = session.ExpandTemplate("by_status", []string{"'active'"})
+	assert.ErrorContains(t, err, "needs parameter $2")
+
+	_, err = session.ExpandTemplate("by_status", []string{"'active'", "20", "3"})
+	assert.ErrorContains(t, err, "takes 2 parameter(s), got 3")
+
+	_, err = session.ExpandTemplate("missing", nil)
+	assert.ErrorContains(t, err, "unknown template")
+
+	assert.Error(t, session.SaveTemplate("bad name", "GET {}"))
+	assert.Error(t, session.SaveTemplate("nested", "RUN by_status 1 2"))
+}
+
+func TestTemplatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.json")
+
+	session := pkg.NewSession(nil)
+	session.TemplatesFile = path
+	assert.NoError(t, session.SaveTemplate("recent", "GET {down: id, lim: $1}"))
+
+	templates, err := pkg.LoadTemplates(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"recent": "GET {down: id, lim: $1}"}, templates)
+
+	templates, err = pkg.LoadTemplates(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, templates)
+}
+
+func TestSaveAndRunTemplate(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SAVE by_name GET {name: $1}"))
+	assert.Contains(t, buf.String(), "Template 'by_name' saved")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "RUN by_name 'User 2'"))
+	assert.Contains(t, buf.String(), "user2@example.com")
+	assert.NotContains(t, buf.String(), "user1@example.com")
+
+	// Lists work as parameters too
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "RUN by_name ['User 1', 'User 3']"))
+	assert.Contains(t, buf.String(), "2 rows in set")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "RUN by_name"), "needs parameter $1")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SAVE"))
+	assert.Contains(t, buf.String(), "by_name")
+}
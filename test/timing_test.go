@@ -0,0 +1,39 @@
+package test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandTiming(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	// Timing is shown by default with millisecond precision
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET"))
+	assert.Regexp(t, regexp.MustCompile(`3 rows in set \(\d+\.\d{3} sec\)`), buf.String())
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "DELETE {id: 3}"))
+	assert.Regexp(t, regexp.MustCompile(`Query OK, 1 rows affected \(\d+\.\d{3} sec\)`), buf.String())
+
+	// Detailed timing splits query and fetch time
+	buf.Reset()
+	session.Config = pkg.Config{"timing": "detailed"}
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET"))
+	assert.Regexp(t, regexp.MustCompile(`2 rows in set \(\d+\.\d{3} sec: \d+\.\d{3} query, \d+\.\d{3} fetch\)`), buf.String())
+
+	// And it can be turned off
+	buf.Reset()
+	session.Config = pkg.Config{"timing": "off"}
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET"))
+	assert.Contains(t, buf.String(), "2 rows in set\n")
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFromUnion(t *testing.T) {
+	for _, table := range []string{"users_2023", "users_2024", "users_other"} {
+		_, err := testDB.Exec("DROP TABLE IF EXISTS " + table)
+		assert.NoError(t, err)
+	}
+	_, err := testDB.Exec("CREATE TABLE users_2023 (id INT PRIMARY KEY, name VARCHAR(50), status VARCHAR(20))")
+	assert.NoError(t, err)
+	_, err = testDB.Exec("CREATE TABLE users_2024 LIKE users_2023")
+	assert.NoError(t, err)
+	_, err = testDB.Exec("CREATE TABLE users_other (id INT PRIMARY KEY, email VARCHAR(50))")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		testDB.Exec("DROP TABLE IF EXISTS users_2023, users_2024, users_other")
+	})
+	_, err = testDB.Exec("INSERT INTO users_2023 VALUES (1, 'Ann', 'active'), (2, 'Bob', 'inactive')")
+	assert.NoError(t, err)
+	_, err = testDB.Exec("INSERT INTO users_2024 VALUES (1, 'Cid', 'active'), (2, 'Dee', 'active')")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET FROM [users_2023, users_2024] {status: 'active', up: name}"))
+	out := buf.String()
+	assert.Contains(t, out, "source_table")
+	assert.Contains(t, out, "3 rows in set")
+	assert.NotContains(t, out, "Bob")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("Ann")), bytes.Index(buf.Bytes(), []byte("Cid")))
+
+	// Selected columns keep the source table
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get FROM [users_2023, users_2024] {name, name: 'Dee'}"))
+	assert.Contains(t, buf.String(), "users_2024")
+	assert.NotContains(t, buf.String(), "users_2023")
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET FROM [users_2023, users_2024] {COUNT: '*'}"))
+	assert.Contains(t, buf.String(), "| 4 ")
+
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET FROM [users_2023, users_other]"), "columns differ")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "GET FROM []"), "at least one table")
+}
@@ -62,7 +62,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"notes":  "New field with dynamic column creation",
 		}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		verifyUpdate(
@@ -88,7 +88,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"modified": true,
 		}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		verifyUpdate(
@@ -109,7 +109,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"range_updated": "yes",
 		}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		verifyUpdate(
@@ -143,7 +143,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"email":  "filtered.user@example.com", // This will be an update field, not a filter
 		}
 
-		err = pkg.HandleUpdate(testDB, args, true)
+		err = pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		// Verify the update using ID as the filter
@@ -181,7 +181,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"bulk_update": "processed",
 		}
 
-		err = pkg.HandleUpdate(testDB, args, true)
+		err = pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		// Query the database directly to see exactly which records were matched and updated
@@ -222,7 +222,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"global_field": "applied-to-all",
 		}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		// Verify all records were updated
@@ -241,7 +241,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"field": "value",
 		}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no records matched")
 	})
@@ -252,7 +252,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"status": []any{"active", "pending"},
 		}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "requires fields to update")
 	})
@@ -261,7 +261,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 	t.Run("Update with Empty Args", func(t *testing.T) {
 		args := map[string]any{}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.Error(t, err)
 	})
 
@@ -274,7 +274,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"field": "value",
 		}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.Error(t, err) // Should error because no records match
 	})
 
@@ -288,7 +288,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"nullish":       nil,
 		}
 
-		err := pkg.HandleUpdate(testDB, args, true)
+		err := pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		// Verify different field types were updated correctly
@@ -317,7 +317,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"new_status": "special",       // This is an update field
 		}
 
-		err = pkg.HandleUpdate(testDB, args, true)
+		err = pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		// Verify only type-A records were updated
@@ -364,7 +364,7 @@ func TestComprehensiveUpdateOperations(t *testing.T) {
 			"score":      95.5,
 		}
 
-		err = pkg.HandleUpdate(testDB, args, true)
+		err = pkg.HandleUpdate(ctx, testSession(true), args)
 		assert.NoError(t, err)
 
 		// This should match users 1 and 4 (active+high priority)
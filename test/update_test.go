@@ -150,7 +150,7 @@ func TestUpdateCommand(t *testing.T) {
 			resetTable(t)
 			insertTestData(t)
 
-			err := pkg.HandleUpdate(testDB, tc.args, true)
+			err := pkg.HandleUpdate(ctx, testSession(true), tc.args)
 
 			if tc.shouldError {
 				assert.Error(t, err)
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+var uuidV4Regex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestParseUUIDFunctions(t *testing.T) {
+	args, err := pkg.ParseArg("{ref: uuid(), other: UUID(), token: uuid_short()}")
+	assert.NoError(t, err)
+	assert.Regexp(t, uuidV4Regex, args["ref"])
+	assert.Regexp(t, uuidV4Regex, args["other"])
+	assert.NotEqual(t, args["ref"], args["other"])
+	assert.Equal(t, pkg.ServerFunc("UUID_SHORT()"), args["token"])
+
+	// Server functions are evaluated by MySQL
+	query, params, err := pkg.NewQueryBuilder("users").Set(map[string]any{"token": args["token"], "name": "A"}).Where(map[string]any{"id": 1}).Update()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET `name` = ?, `token` = UUID_SHORT() WHERE `id` = ?", query)
+	assert.Equal(t, []any{"A", 1}, params)
+}
+
+func TestUUIDColumns(t *testing.T) {
+	resetTable(t)
+	t.Cleanup(func() {
+		testDB.Exec("ALTER TABLE users DROP COLUMN token")
+		testDB.Exec("ALTER TABLE users DROP COLUMN ref")
+		testDB.Exec("ALTER TABLE users DROP COLUMN bin_ref")
+	})
+
+	var buf bytes.Buffer
+	session := testSession(true)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "create {name: 'A', ref: uuid(), token: uuid_short()}"))
+
+	var ref string
+	var token uint64
+	assert.NoError(t, testDB.QueryRow("SELECT ref, token FROM users WHERE name = 'A'").Scan(&ref, &token))
+	assert.Regexp(t, uuidV4Regex, ref)
+	assert.NotZero(t, token)
+
+	// BINARY(16) columns are shown as UUIDs
+	_, err := testDB.Exec("ALTER TABLE users ADD COLUMN bin_ref BINARY(16)")
+	assert.NoError(t, err)
+	_, err = testDB.Exec("UPDATE users SET bin_ref = UNHEX('3F2504E04F8941D39A0C0305E82C3301') WHERE name = 'A'")
+	assert.NoError(t, err)
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {name: 'A'}"))
+	assert.Contains(t, buf.String(), "3f2504e0-4f89-41d3-9a0c-0305e82c3301")
+
+	// UUIDs are written to and compared with BINARY(16) columns as 16 bytes
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "create {name: 'B', bin_ref: '9a0c0305-e82c-3301-3f25-04e04f8941d3'}"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "update {name: ['A'], bin_ref: '1b4e28ba-2fa1-11d2-883f-0016d3cca427'}"))
+	var hex string
+	assert.NoError(t, testDB.QueryRow("SELECT HEX(bin_ref) FROM users WHERE name = 'B'").Scan(&hex))
+	assert.Equal(t, "9A0C0305E82C33013F2504E04F8941D3", hex)
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "get {bin_ref: '1b4e28ba-2fa1-11d2-883f-0016d3cca427'}"))
+	assert.Contains(t, buf.String(), "1b4e28ba-2fa1-11d2-883f-0016d3cca427")
+	assert.NotContains(t, buf.String(), "9a0c0305-e82c-3301-3f25-04e04f8941d3")
+
+	session.Confirm = func() string { return "y" }
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "delete {bin_ref: ['9a0c0305-e82c-3301-3f25-04e04f8941d3']}"))
+	var count int
+	assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM users WHERE name = 'B'").Scan(&count))
+	assert.Zero(t, count)
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAssignment(t *testing.T) {
+	name, value, err := pkg.ParseAssignment(" @UserId = 42")
+	assert.NoError(t, err)
+	assert.Equal(t, "userid", name)
+	assert.Equal(t, 42, value)
+
+	_, value, err = pkg.ParseAssignment("@tags = ['a', b]")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"a", "b"}, value)
+
+	_, _, err = pkg.ParseAssignment("uid = 1")
+	assert.ErrorIs(t, err, pkg.ErrParse)
+
+	_, _, err = pkg.ParseAssignment("@uid 1")
+	assert.ErrorIs(t, err, pkg.ErrParse)
+}
+
+func TestSessionVariables(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SET @uid = 2"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SET @ids = [@uid, 3]"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SET @status = 'archived'"))
+
+	// Variables can be used for filters and values
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "UPDATE {id: @ids, status: @STATUS}"))
+	var count int
+	assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM users WHERE status = 'archived' AND id IN (2, 3)").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {id: @uid}"))
+	assert.Contains(t, buf.String(), "user2@example.com")
+	assert.NotContains(t, buf.String(), "user3@example.com")
+
+	// SET alone lists them
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "SET"))
+	assert.Contains(t, buf.String(), "@ids")
+	assert.Contains(t, buf.String(), "@status")
+	assert.Contains(t, buf.String(), "3 rows in set")
+
+	err := pkg.ExecuteCommand(ctx, session, "GET {id: @missing}")
+	assert.ErrorContains(t, err, "undefined variable @missing")
+}
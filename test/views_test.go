@@ -0,0 +1,43 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateViewFromGet(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+	t.Cleanup(func() {
+		testDB.Exec("DROP VIEW IF EXISTS first_users")
+	})
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, `GET {name, email, id: [1, 2], name: != 'O\'Brien'} AS VIEW first_users`))
+	assert.Contains(t, buf.String(), "Query OK")
+
+	// The view holds the filtered rows
+	var count int
+	assert.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM first_users").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET views"))
+	assert.Contains(t, buf.String(), "first_users")
+
+	// and can be used like a table
+	buf.Reset()
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "USE first_users"))
+	assert.NoError(t, pkg.ExecuteCommand(ctx, session, "GET {name: 'User 2'}"))
+	assert.Contains(t, buf.String(), "user2@example.com")
+
+	// A view of the same name is not replaced
+	session.CurrentTable = testTable
+	assert.Error(t, pkg.ExecuteCommand(ctx, session, "GET AS VIEW first_users"))
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bogwi/noqli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchInterval(t *testing.T) {
+	session := pkg.NewSession(nil)
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "WATCH soon GET {}"), "invalid WATCH interval")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "WATCH 0 GET {}"), "must be positive")
+	assert.ErrorContains(t, pkg.ExecuteCommand(ctx, session, "WATCH 1 WATCH 1 GET {}"), "cannot watch another WATCH")
+}
+
+func TestWatch(t *testing.T) {
+	resetTable(t)
+	insertTestData(t)
+
+	var buf bytes.Buffer
+	session := testSession(false)
+	session.Out = &buf
+
+	// Add a row after the first run
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		testDB.Exec("INSERT INTO users (name) VALUES ('User 4')")
+	}()
+
+	watchCtx, cancel := context.WithTimeout(ctx, 350*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, pkg.ExecuteCommand(watchCtx, session, "WATCH 100ms GET {COUNT: '*'}"))
+
+	output := buf.String()
+	assert.GreaterOrEqual(t, strings.Count(output, "Every 100ms: GET {COUNT: '*'}"), 2)
+	assert.Contains(t, output, "| 3     |")
+	assert.Contains(t, output, "\033[7m 4     \033[0m", "the changed count is highlighted")
+}